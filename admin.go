@@ -0,0 +1,95 @@
+package main
+
+import (
+	"log"
+	"sort"
+
+	"discordbot/admin"
+	"discordbot/config"
+	"discordbot/control"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// adminBackend exposes the bot to the local admin socket
+type adminBackend struct {
+	s *discordgo.Session
+}
+
+// startAdmin serves the admin interface on ADMIN_SOCKET if it is set
+func startAdmin(s *discordgo.Session) {
+	path := config.String("ADMIN_SOCKET", "")
+	if path == "" {
+		return
+	}
+	go func() {
+		log.Printf("Serving the admin interface on %s", path)
+		if err := admin.Serve(ctx, path, adminBackend{s: s}); err != nil {
+			log.Printf("Admin interface failed: %v", err)
+		}
+	}()
+}
+
+// Guilds lists the guilds the bot is in, playing ones first
+func (b adminBackend) Guilds() []admin.Guild {
+	b.s.State.RLock()
+	guilds := make([]admin.Guild, 0, len(b.s.State.Guilds))
+	for _, g := range b.s.State.Guilds {
+		guilds = append(guilds, admin.Guild{ID: g.ID, Name: g.Name})
+	}
+	b.s.State.RUnlock()
+
+	player := botPlayer{s: b.s}
+	for idx := range guilds {
+		state := player.State(guilds[idx].ID)
+		guilds[idx].ChannelID = state.ChannelID
+		guilds[idx].Connected = state.Connected
+		guilds[idx].Playing = state.Playing
+		guilds[idx].Paused = state.Paused
+		guilds[idx].NowPlaying = state.NowPlaying
+		guilds[idx].QueueLength = len(state.Queue)
+	}
+	sort.SliceStable(guilds, func(a, c int) bool {
+		return guilds[a].Connected && !guilds[c].Connected
+	})
+	return guilds
+}
+
+// State returns a guild's player state and queue
+func (b adminBackend) State(guildID string) control.State {
+	return botPlayer{s: b.s}.State(guildID)
+}
+
+// Disconnect makes the bot leave voice in a guild, dropping its queue
+func (b adminBackend) Disconnect(guildID string) error {
+	vi := voiceManager.GetVoiceInstance(guildID)
+	if !vi.Connected() {
+		return control.ErrNotConnected
+	}
+	log.Printf("Disconnecting from voice in guild %s on admin request", guildID)
+	if err := vi.Leave(); err != nil {
+		return err
+	}
+	forgetBitrateWarning(guildID)
+	return nil
+}
+
+// FlushCache removes every cached track that isn't playing
+func (b adminBackend) FlushCache() (admin.FlushResult, error) {
+	removed, err := audioCache.Flush()
+	if err != nil {
+		return admin.FlushResult{}, err
+	}
+	result := admin.FlushResult{Removed: len(removed)}
+	for _, entry := range removed {
+		result.Bytes += entry.Size
+	}
+	log.Printf("Flushed %d tracks from the cache on admin request", result.Removed)
+	return result, nil
+}
+
+// ReloadConfig reloads the configuration like SIGHUP does
+func (b adminBackend) ReloadConfig() error {
+	log.Println("Reloading configuration on admin request")
+	return config.Reload()
+}
@@ -0,0 +1,170 @@
+// Package admin serves a local gRPC admin interface over a Unix socket. Only
+// users who can open the socket file, normally the bot's own, can reach it.
+// The service is defined in adminpb/admin.proto.
+package admin
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative adminpb/admin.proto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"discordbot/admin/adminpb"
+	"discordbot/control"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Guild is a guild the bot is in, with a summary of its player
+type Guild struct {
+	ID          string
+	Name        string
+	ChannelID   string
+	Connected   bool
+	Playing     bool
+	Paused      bool
+	NowPlaying  *control.Track
+	QueueLength int
+}
+
+// FlushResult is what flushing the audio cache removed
+type FlushResult struct {
+	Removed int
+	Bytes   int64
+}
+
+// Backend is the bot as seen by the admin interface
+type Backend interface {
+	Guilds() []Guild
+	State(guildID string) control.State
+	Disconnect(guildID string) error
+	FlushCache() (FlushResult, error)
+	ReloadConfig() error
+}
+
+// Serve answers admin calls on a Unix socket at path until ctx is done.
+// A socket left behind by a previous run is replaced.
+func Serve(ctx context.Context, path string, backend Backend) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing old admin socket: %v", err)
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return fmt.Errorf("error listening on admin socket: %v", err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return fmt.Errorf("error restricting admin socket: %v", err)
+	}
+
+	server := grpc.NewServer()
+	adminpb.RegisterAdminServer(server, &service{backend: backend})
+	go func() {
+		<-ctx.Done()
+		server.GracefulStop()
+		os.Remove(path)
+	}()
+	if err := server.Serve(listener); err != nil && err != grpc.ErrServerStopped {
+		return err
+	}
+	return nil
+}
+
+// service implements the Admin gRPC service on a Backend
+type service struct {
+	adminpb.UnimplementedAdminServer
+	backend Backend
+}
+
+// ListGuilds lists the guilds the bot is in
+func (s *service) ListGuilds(context.Context, *adminpb.ListGuildsRequest) (*adminpb.ListGuildsResponse, error) {
+	guilds := s.backend.Guilds()
+	resp := &adminpb.ListGuildsResponse{Guilds: make([]*adminpb.Guild, 0, len(guilds))}
+	for _, g := range guilds {
+		resp.Guilds = append(resp.Guilds, &adminpb.Guild{
+			Id:          g.ID,
+			Name:        g.Name,
+			ChannelId:   g.ChannelID,
+			Connected:   g.Connected,
+			Playing:     g.Playing,
+			Paused:      g.Paused,
+			NowPlaying:  trackToProto(g.NowPlaying),
+			QueueLength: int32(g.QueueLength),
+		})
+	}
+	return resp, nil
+}
+
+// GetQueue returns a guild's player state and queue
+func (s *service) GetQueue(_ context.Context, req *adminpb.GetQueueRequest) (*adminpb.PlayerState, error) {
+	state := s.backend.State(req.GetGuildId())
+	resp := &adminpb.PlayerState{
+		GuildId:    state.GuildID,
+		ChannelId:  state.ChannelID,
+		Connected:  state.Connected,
+		Playing:    state.Playing,
+		Paused:     state.Paused,
+		Repeat:     state.Repeat,
+		Autoplay:   state.Autoplay,
+		NowPlaying: trackToProto(state.NowPlaying),
+		Queue:      make([]*adminpb.Track, 0, len(state.Queue)),
+	}
+	for idx := range state.Queue {
+		resp.Queue = append(resp.Queue, trackToProto(&state.Queue[idx]))
+	}
+	return resp, nil
+}
+
+// Disconnect makes the bot leave voice in a guild
+func (s *service) Disconnect(_ context.Context, req *adminpb.DisconnectRequest) (*adminpb.DisconnectResponse, error) {
+	if err := s.backend.Disconnect(req.GetGuildId()); err != nil {
+		return nil, statusError(err)
+	}
+	return &adminpb.DisconnectResponse{}, nil
+}
+
+// FlushCache removes every cached track that isn't playing
+func (s *service) FlushCache(context.Context, *adminpb.FlushCacheRequest) (*adminpb.FlushCacheResponse, error) {
+	result, err := s.backend.FlushCache()
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &adminpb.FlushCacheResponse{Removed: int32(result.Removed), Bytes: result.Bytes}, nil
+}
+
+// ReloadConfig reloads the configuration
+func (s *service) ReloadConfig(context.Context, *adminpb.ReloadConfigRequest) (*adminpb.ReloadConfigResponse, error) {
+	if err := s.backend.ReloadConfig(); err != nil {
+		return nil, statusError(err)
+	}
+	return &adminpb.ReloadConfigResponse{}, nil
+}
+
+// statusError turns a backend error into a gRPC status
+func statusError(err error) error {
+	if errors.Is(err, control.ErrNotConnected) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// trackToProto converts a track for the wire, keeping nil as nil
+func trackToProto(t *control.Track) *adminpb.Track {
+	if t == nil {
+		return nil
+	}
+	return &adminpb.Track{Url: t.URL, Title: t.Title, DurationSeconds: t.Duration, Requester: t.Requester}
+}
+
+// trackFromProto converts a track from the wire, keeping nil as nil
+func trackFromProto(t *adminpb.Track) *control.Track {
+	if t == nil {
+		return nil
+	}
+	return &control.Track{URL: t.GetUrl(), Title: t.GetTitle(), Duration: t.GetDurationSeconds(), Requester: t.GetRequester()}
+}
@@ -0,0 +1,97 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"discordbot/control"
+)
+
+// fakeBackend is a Backend with fixed answers
+type fakeBackend struct {
+	reloaded bool
+}
+
+func (b *fakeBackend) Guilds() []Guild {
+	return []Guild{{ID: "1", Name: "One", Connected: true, Playing: true, NowPlaying: &control.Track{Title: "Song"}, QueueLength: 2}}
+}
+
+func (b *fakeBackend) State(guildID string) control.State {
+	return control.State{GuildID: guildID, Connected: true, Queue: []control.Track{{URL: "https://example.com/a", Duration: 61.5}}}
+}
+
+func (b *fakeBackend) Disconnect(guildID string) error {
+	return control.ErrNotConnected
+}
+
+func (b *fakeBackend) FlushCache() (FlushResult, error) {
+	return FlushResult{Removed: 3, Bytes: 4096}, nil
+}
+
+func (b *fakeBackend) ReloadConfig() error {
+	b.reloaded = true
+	return nil
+}
+
+func TestServeOverSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "admin.sock")
+	backend := &fakeBackend{}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- Serve(ctx, path, backend) }()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Serve: %v", err)
+		}
+	}()
+
+	// The client fails fast rather than waiting for the socket to appear
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("admin socket never appeared")
+		}
+	}
+
+	client, err := NewClient(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	guilds, err := client.Guilds()
+	if err != nil {
+		t.Fatalf("Guilds: %v", err)
+	}
+	if len(guilds) != 1 || guilds[0].Name != "One" || guilds[0].NowPlaying == nil || guilds[0].NowPlaying.Title != "Song" || guilds[0].QueueLength != 2 {
+		t.Errorf("Guilds got %+v, want guild One playing Song with 2 queued", guilds)
+	}
+
+	state, err := client.Queue("1")
+	if err != nil {
+		t.Fatalf("Queue: %v", err)
+	}
+	if state.GuildID != "1" || state.NowPlaying != nil || len(state.Queue) != 1 || state.Queue[0].Duration != 61.5 {
+		t.Errorf("Queue got %+v, want one queued track of 61.5s", state)
+	}
+
+	if err := client.Disconnect("1"); !errors.Is(err, control.ErrNotConnected) {
+		t.Errorf("Disconnect got %v, want %v", err, control.ErrNotConnected)
+	}
+
+	result, err := client.FlushCache()
+	if err != nil || result != (FlushResult{Removed: 3, Bytes: 4096}) {
+		t.Errorf("FlushCache got %+v, %v, want 3 removed and 4096 bytes", result, err)
+	}
+
+	if err := client.ReloadConfig(); err != nil || !backend.reloaded {
+		t.Errorf("ReloadConfig got %v, reloaded %v, want no error and a reload", err, backend.reloaded)
+	}
+}
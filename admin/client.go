@@ -0,0 +1,133 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"discordbot/admin/adminpb"
+	"discordbot/control"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// callTimeout bounds each admin call
+const callTimeout = 30 * time.Second
+
+// Client talks to a running bot's admin socket
+type Client struct {
+	conn  *grpc.ClientConn
+	admin adminpb.AdminClient
+}
+
+// NewClient creates a client for the admin socket at path. The socket is
+// only dialled on the first call.
+func NewClient(path string) (*Client, error) {
+	// The socket's file permissions are its access control
+	conn, err := grpc.NewClient("unix://"+path, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("error creating admin client: %v", err)
+	}
+	return &Client{conn: conn, admin: adminpb.NewAdminClient(conn)}, nil
+}
+
+// Close closes the connection to the admin socket
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Guilds lists the guilds the bot is in
+func (c *Client) Guilds() ([]Guild, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	resp, err := c.admin.ListGuilds(ctx, &adminpb.ListGuildsRequest{})
+	if err != nil {
+		return nil, callError(err)
+	}
+	guilds := make([]Guild, 0, len(resp.GetGuilds()))
+	for _, g := range resp.GetGuilds() {
+		guilds = append(guilds, Guild{
+			ID:          g.GetId(),
+			Name:        g.GetName(),
+			ChannelID:   g.GetChannelId(),
+			Connected:   g.GetConnected(),
+			Playing:     g.GetPlaying(),
+			Paused:      g.GetPaused(),
+			NowPlaying:  trackFromProto(g.GetNowPlaying()),
+			QueueLength: int(g.GetQueueLength()),
+		})
+	}
+	return guilds, nil
+}
+
+// Queue returns a guild's player state and queue
+func (c *Client) Queue(guildID string) (control.State, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	resp, err := c.admin.GetQueue(ctx, &adminpb.GetQueueRequest{GuildId: guildID})
+	if err != nil {
+		return control.State{}, callError(err)
+	}
+	state := control.State{
+		GuildID:    resp.GetGuildId(),
+		ChannelID:  resp.GetChannelId(),
+		Connected:  resp.GetConnected(),
+		Playing:    resp.GetPlaying(),
+		Paused:     resp.GetPaused(),
+		Repeat:     resp.GetRepeat(),
+		Autoplay:   resp.GetAutoplay(),
+		NowPlaying: trackFromProto(resp.GetNowPlaying()),
+		Queue:      make([]control.Track, 0, len(resp.GetQueue())),
+	}
+	for _, t := range resp.GetQueue() {
+		state.Queue = append(state.Queue, *trackFromProto(t))
+	}
+	return state, nil
+}
+
+// Disconnect makes the bot leave voice in a guild
+func (c *Client) Disconnect(guildID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	_, err := c.admin.Disconnect(ctx, &adminpb.DisconnectRequest{GuildId: guildID})
+	return callError(err)
+}
+
+// FlushCache removes every cached track that isn't playing
+func (c *Client) FlushCache() (FlushResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	resp, err := c.admin.FlushCache(ctx, &adminpb.FlushCacheRequest{})
+	if err != nil {
+		return FlushResult{}, callError(err)
+	}
+	return FlushResult{Removed: int(resp.GetRemoved()), Bytes: resp.GetBytes()}, nil
+}
+
+// ReloadConfig makes the bot reload its configuration, like SIGHUP does
+func (c *Client) ReloadConfig() error {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	_, err := c.admin.ReloadConfig(ctx, &adminpb.ReloadConfigRequest{})
+	return callError(err)
+}
+
+// callError turns a gRPC status back into a plain error, keeping
+// ErrNotConnected recognisable
+func callError(err error) error {
+	if err == nil {
+		return nil
+	}
+	st := status.Convert(err)
+	switch st.Code() {
+	case codes.FailedPrecondition:
+		return control.ErrNotConnected
+	case codes.Unavailable:
+		return fmt.Errorf("error reaching the bot's admin socket: %s", st.Message())
+	}
+	return errors.New(st.Message())
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"discordbot/aliases"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// resolveAlias expands a /play argument that names one of the user's or guild's aliases
+func resolveAlias(i *discordgo.InteractionCreate, query string) string {
+	if target, ok := aliasStore.Resolve(i.GuildID, i.Member.User.ID, query); ok {
+		log.Printf("Resolved alias %q to %s", query, target)
+		return target
+	}
+	return query
+}
+
+// handleAlias handles the /alias command and its subcommands
+func handleAlias(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		editResponse(s, i, tr(i, "command.choose_subcommand"))
+		return
+	}
+
+	subcommand := options[0]
+	var name, target string
+	var shared bool
+	for _, option := range subcommand.Options {
+		switch option.Name {
+		case "name":
+			name = option.StringValue()
+		case "target":
+			target = option.StringValue()
+		case "shared":
+			shared = option.BoolValue()
+		}
+	}
+
+	// Guild-shared aliases can only be changed by DJs
+	if shared && subcommand.Name != "list" && !isDJ(s, i) {
+		errorResponse(s, i, tr(i, "alias.dj_only"))
+		return
+	}
+
+	switch subcommand.Name {
+	case "add":
+		// Targets that name one of the user's playlists point to that playlist
+		if pl, err := playlistStore.Get(i.Member.User.ID, target); err == nil {
+			target = playlistRef(pl.OwnerID, pl.Name)
+		}
+
+		var err error
+		if shared {
+			err = aliasStore.SetGuild(i.GuildID, name, target)
+		} else {
+			err = aliasStore.SetUser(i.Member.User.ID, name, target)
+		}
+		if err != nil {
+			errorResponse(s, i, tr(i, "alias.save_failed", err))
+			return
+		}
+		editResponse(s, i, tr(i, "alias.saved", aliases.Normalize(name), describeAliasTarget(i, target)))
+
+	case "remove":
+		var removed bool
+		var err error
+		if shared {
+			removed, err = aliasStore.RemoveGuild(i.GuildID, name)
+		} else {
+			removed, err = aliasStore.RemoveUser(i.Member.User.ID, name)
+		}
+		if err != nil {
+			errorResponse(s, i, tr(i, "alias.remove_failed", err))
+			return
+		}
+		if !removed {
+			editResponse(s, i, tr(i, "alias.not_found", aliases.Normalize(name)))
+			return
+		}
+		editResponse(s, i, tr(i, "alias.removed", aliases.Normalize(name)))
+
+	case "list":
+		personal, err := aliasStore.User(i.Member.User.ID)
+		if err != nil {
+			log.Printf("Failed to load user aliases: %v", err)
+		}
+		guild, err := aliasStore.Guild(i.GuildID)
+		if err != nil {
+			log.Printf("Failed to load guild aliases: %v", err)
+		}
+
+		if len(personal) == 0 && len(guild) == 0 {
+			editResponse(s, i, tr(i, "alias.none"))
+			return
+		}
+
+		var msg strings.Builder
+		if len(personal) > 0 {
+			msg.WriteString(tr(i, "alias.personal_header") + "\n")
+			writeAliases(i, &msg, personal)
+		}
+		if len(guild) > 0 {
+			msg.WriteString(tr(i, "alias.guild_header") + "\n")
+			writeAliases(i, &msg, guild)
+		}
+		editResponse(s, i, msg.String())
+	}
+}
+
+// writeAliases writes aliases sorted by name
+func writeAliases(i *discordgo.InteractionCreate, msg *strings.Builder, list map[string]string) {
+	names := make([]string, 0, len(list))
+	for name := range list {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(msg, "`%s` → %s\n", name, describeAliasTarget(i, list[name]))
+	}
+}
+
+// describeAliasTarget formats an alias target for display
+func describeAliasTarget(i *discordgo.InteractionCreate, target string) string {
+	if _, name, ok := parsePlaylistRef(target); ok {
+		return tr(i, "alias.playlist_target", name)
+	}
+	return target
+}
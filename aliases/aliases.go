@@ -0,0 +1,150 @@
+package aliases
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"discordbot/storage"
+)
+
+const (
+	userCollection  = "aliases_user"
+	guildCollection = "aliases_guild"
+)
+
+// Store keeps per-user and guild-shared aliases for tracks and playlists
+type Store struct {
+	store storage.Store
+	mu    sync.Mutex
+}
+
+// NewStore creates a new alias store
+func NewStore(store storage.Store) *Store {
+	return &Store{store: store}
+}
+
+// Normalize returns the canonical form of an alias name
+func Normalize(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// load returns the aliases stored under key in collection
+func (s *Store) load(collection, key string) (map[string]string, error) {
+	aliases := make(map[string]string)
+	err := s.store.Get(collection, key, &aliases)
+	if err == storage.ErrNotFound {
+		return aliases, nil
+	}
+	return aliases, err
+}
+
+// set adds or replaces an alias
+func (s *Store) set(collection, key, name, target string) error {
+	name = Normalize(name)
+	if name == "" {
+		return fmt.Errorf("alias name cannot be empty")
+	}
+	if strings.TrimSpace(target) == "" {
+		return fmt.Errorf("alias target cannot be empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	aliases, err := s.load(collection, key)
+	if err != nil {
+		return fmt.Errorf("error loading aliases: %v", err)
+	}
+	aliases[name] = strings.TrimSpace(target)
+	return s.store.Put(collection, key, aliases)
+}
+
+// remove deletes an alias, reporting whether it existed
+func (s *Store) remove(collection, key, name string) (bool, error) {
+	name = Normalize(name)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	aliases, err := s.load(collection, key)
+	if err != nil {
+		return false, fmt.Errorf("error loading aliases: %v", err)
+	}
+	if _, ok := aliases[name]; !ok {
+		return false, nil
+	}
+	delete(aliases, name)
+	return true, s.store.Put(collection, key, aliases)
+}
+
+// SetUser saves a personal alias for a user
+func (s *Store) SetUser(userID, name, target string) error {
+	return s.set(userCollection, userID, name, target)
+}
+
+// SetGuild saves an alias shared by every member of a guild
+func (s *Store) SetGuild(guildID, name, target string) error {
+	return s.set(guildCollection, guildID, name, target)
+}
+
+// RemoveUser deletes a personal alias
+func (s *Store) RemoveUser(userID, name string) (bool, error) {
+	return s.remove(userCollection, userID, name)
+}
+
+// RemoveGuild deletes a guild-shared alias
+func (s *Store) RemoveGuild(guildID, name string) (bool, error) {
+	return s.remove(guildCollection, guildID, name)
+}
+
+// User returns the personal aliases of a user
+func (s *Store) User(userID string) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(userCollection, userID)
+}
+
+// Guild returns the shared aliases of a guild
+func (s *Store) Guild(guildID string) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(guildCollection, guildID)
+}
+
+// Resolve looks up an alias for a user in a guild.
+// Personal aliases take precedence over guild-shared ones.
+func (s *Store) Resolve(guildID, userID, name string) (string, bool) {
+	name = Normalize(name)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if aliases, err := s.load(userCollection, userID); err == nil {
+		if target, ok := aliases[name]; ok {
+			return target, true
+		}
+	}
+	if aliases, err := s.load(guildCollection, guildID); err == nil {
+		if target, ok := aliases[name]; ok {
+			return target, true
+		}
+	}
+	return "", false
+}
+
+// ReplaceGuild swaps all shared aliases of a guild for the given ones
+func (s *Store) ReplaceGuild(guildID string, aliases map[string]string) error {
+	normalized := make(map[string]string, len(aliases))
+	for name, target := range aliases {
+		name = Normalize(name)
+		if name == "" || strings.TrimSpace(target) == "" {
+			continue
+		}
+		normalized[name] = strings.TrimSpace(target)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.store.Put(guildCollection, guildID, normalized)
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"discordbot/control"
+	"discordbot/events"
+)
+
+// defaultRequester is shown as the requester of tracks queued without one
+const defaultRequester = "REST API"
+
+// Tokens resolves API tokens to the guild they grant access to
+type Tokens interface {
+	Lookup(token string) (guildID string, ok bool)
+}
+
+// Server serves the token-authenticated REST API under /api/v1/
+type Server struct {
+	player control.Player
+	tokens Tokens
+	hub    *hub
+}
+
+// New creates a REST API controlling player and streaming the events published on bus
+func New(player control.Player, tokens Tokens, bus *events.Bus) *Server {
+	return &Server{player: player, tokens: tokens, hub: newHub(bus)}
+}
+
+// Register mounts the API on mux
+func (a *Server) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/guilds/{guild}/queue", a.guild(a.handleQueue))
+	mux.HandleFunc("POST /api/v1/guilds/{guild}/queue", a.guild(a.handleEnqueue))
+	mux.HandleFunc("GET /api/v1/guilds/{guild}/now-playing", a.guild(a.handleNowPlaying))
+	mux.HandleFunc("POST /api/v1/guilds/{guild}/skip", a.guild(a.control((control.Player).Skip)))
+	mux.HandleFunc("POST /api/v1/guilds/{guild}/pause", a.guild(a.control((control.Player).Pause)))
+	mux.HandleFunc("POST /api/v1/guilds/{guild}/resume", a.guild(a.control((control.Player).Resume)))
+	mux.HandleFunc("GET /api/v1/guilds/{guild}/events", a.guild(a.handleEvents))
+}
+
+// guild wraps a handler so it only runs for requests carrying a token for the requested guild.
+// The token may also be passed as ?token= for WebSocket clients that can't set headers,
+// such as browser sources in streaming software.
+func (a *Server) guild(next func(w http.ResponseWriter, r *http.Request, guildID string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			token = r.URL.Query().Get("token")
+		}
+		if token == "" {
+			writeError(w, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+
+		tokenGuild, ok := a.tokens.Lookup(token)
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+
+		guildID := r.PathValue("guild")
+		if tokenGuild != guildID {
+			writeError(w, http.StatusForbidden, "the token doesn't grant access to that server")
+			return
+		}
+		next(w, r, guildID)
+	}
+}
+
+// handleQueue returns the current track and the queue
+func (a *Server) handleQueue(w http.ResponseWriter, r *http.Request, guildID string) {
+	state := a.player.State(guildID)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"now_playing": state.NowPlaying,
+		"queue":       state.Queue,
+	})
+}
+
+// handleNowPlaying returns the current track and playback flags
+func (a *Server) handleNowPlaying(w http.ResponseWriter, r *http.Request, guildID string) {
+	state := a.player.State(guildID)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"now_playing": state.NowPlaying,
+		"playing":     state.Playing,
+		"paused":      state.Paused,
+		"repeat":      state.Repeat,
+		"autoplay":    state.Autoplay,
+	})
+}
+
+// enqueueRequest is the body of POST /queue
+type enqueueRequest struct {
+	URL       string `json:"url"`
+	Requester string `json:"requester,omitempty"`
+}
+
+// handleEnqueue adds a URL to the end of the queue
+func (a *Server) handleEnqueue(w http.ResponseWriter, r *http.Request, guildID string) {
+	var req enqueueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.URL = strings.TrimSpace(req.URL)
+	if !strings.HasPrefix(req.URL, "https://") && !strings.HasPrefix(req.URL, "http://") {
+		writeError(w, http.StatusBadRequest, "url must be an http(s) URL")
+		return
+	}
+	if req.Requester == "" {
+		req.Requester = defaultRequester
+	}
+
+	track, err := a.player.Enqueue(guildID, req.URL, req.Requester)
+	if err != nil {
+		writeControlError(w, err)
+		return
+	}
+	log.Printf("REST API: queued %s in guild %s", req.URL, guildID)
+	writeJSON(w, http.StatusCreated, track)
+}
+
+// control returns a handler running a player control that takes no arguments
+func (a *Server) control(run func(control.Player, string) error) func(http.ResponseWriter, *http.Request, string) {
+	return func(w http.ResponseWriter, r *http.Request, guildID string) {
+		if err := run(a.player, guildID); err != nil {
+			writeControlError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, a.player.State(guildID))
+	}
+}
+
+// writeControlError maps player errors to HTTP statuses. Anything else,
+// such as a guild limit, is reported as a conflict with the current state.
+func writeControlError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, control.ErrBadPosition):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		writeError(w, http.StatusConflict, err.Error())
+	}
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error writing API response: %v", err)
+	}
+}
+
+// writeError writes an API error as JSON
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"discordbot/control"
+	"discordbot/events"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// streamBuffer is how many messages may queue up for a client before it is dropped
+	streamBuffer = 64
+	// streamWriteTimeout is how long a single write to a client may take
+	streamWriteTimeout = 10 * time.Second
+	// streamPingInterval is how often clients are pinged to detect dead connections
+	streamPingInterval = 30 * time.Second
+)
+
+// upgrader accepts WebSocket connections from any origin. Clients authenticate
+// with a guild token, and overlays like OBS browser sources have no useful origin.
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// streamMessage is a player event as sent to WebSocket clients
+type streamMessage struct {
+	Type    events.Type      `json:"type"`
+	GuildID string           `json:"guild_id"`
+	Time    time.Time        `json:"time"`
+	Track   *control.Track   `json:"track,omitempty"`
+	Queue   *[]control.Track `json:"queue,omitempty"`
+	Error   string           `json:"error,omitempty"`
+	State   *control.State   `json:"state,omitempty"`
+}
+
+// stateMessage is sent once when a client connects, so it can render
+// the current player without waiting for the next event
+const stateMessage events.Type = "state"
+
+// streamClient is a connected WebSocket client following one guild
+type streamClient struct {
+	guildID string
+	send    chan []byte
+}
+
+// hub fans player events out to the connected WebSocket clients
+type hub struct {
+	mu      sync.Mutex
+	clients map[*streamClient]bool
+}
+
+// newHub creates a hub receiving the events published on bus
+func newHub(bus *events.Bus) *hub {
+	h := &hub{clients: make(map[*streamClient]bool)}
+	bus.Subscribe(h.broadcast)
+	return h
+}
+
+// broadcast sends an event to every client following its guild.
+// Clients that can't keep up are disconnected instead of delaying the others.
+func (h *hub) broadcast(e events.Event) {
+	msg := streamMessage{Type: e.Type, GuildID: e.GuildID, Time: e.Time, Error: e.Error}
+	if e.Track != nil {
+		track := control.TrackFrom(e.Track)
+		msg.Track = &track
+	}
+	if e.Type == events.QueueUpdate {
+		queue := make([]control.Track, 0, len(e.Queue))
+		for _, t := range e.Queue {
+			queue = append(queue, control.TrackFrom(t))
+		}
+		msg.Queue = &queue
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("Error encoding stream event: %v", err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.clients {
+		if c.guildID != e.GuildID {
+			continue
+		}
+		select {
+		case c.send <- data:
+		default:
+			log.Printf("Dropping slow event stream client in guild %s", c.guildID)
+			h.remove(c)
+		}
+	}
+}
+
+// add registers a client
+func (h *hub) add(c *streamClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.clients[c] = true
+}
+
+// remove unregisters a client and closes its send channel. The caller must hold h.mu.
+func (h *hub) remove(c *streamClient) {
+	if h.clients[c] {
+		delete(h.clients, c)
+		close(c.send)
+	}
+}
+
+// handleEvents upgrades the request to a WebSocket and streams the guild's player events
+func (a *Server) handleEvents(w http.ResponseWriter, r *http.Request, guildID string) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader already wrote an error response
+		return
+	}
+
+	state := a.player.State(guildID)
+	hello, err := json.Marshal(streamMessage{Type: stateMessage, GuildID: guildID, Time: time.Now(), State: &state})
+	if err != nil {
+		conn.Close()
+		return
+	}
+
+	c := &streamClient{guildID: guildID, send: make(chan []byte, streamBuffer)}
+	c.send <- hello
+	a.hub.add(c)
+
+	go a.readStream(conn, c)
+	a.writeStream(conn, c)
+}
+
+// readStream discards client messages and unregisters the client once the connection closes
+func (a *Server) readStream(conn *websocket.Conn, c *streamClient) {
+	defer func() {
+		a.hub.mu.Lock()
+		a.hub.remove(c)
+		a.hub.mu.Unlock()
+	}()
+
+	conn.SetReadLimit(512)
+	conn.SetReadDeadline(time.Now().Add(2 * streamPingInterval))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * streamPingInterval))
+	})
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// writeStream sends queued messages and pings to the client until it goes away
+func (a *Server) writeStream(conn *websocket.Conn, c *streamClient) {
+	ticker := time.NewTicker(streamPingInterval)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case data, ok := <-c.send:
+			conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "too slow"))
+				return
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package apitokens
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"discordbot/storage"
+)
+
+const collection = "api_tokens"
+
+// tokenPrefix makes bot tokens recognizable, e.g. in secret scanners
+const tokenPrefix = "mbt_"
+
+// Token is a REST API token scoped to a single guild
+type Token struct {
+	GuildID   string    `json:"guild_id"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Store keeps API tokens keyed by their SHA-256 hash, so a leaked data
+// directory doesn't leak usable tokens
+type Store struct {
+	store storage.Store
+	mu    sync.Mutex
+}
+
+// NewStore creates a new API token store
+func NewStore(store storage.Store) *Store {
+	return &Store{store: store}
+}
+
+// hash returns the key a token is stored under
+func hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Create issues a new token for a guild, revoking the guild's previous token.
+// The plain token is only returned here and can't be recovered later.
+func (s *Store) Create(guildID, userID string) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating token: %v", err)
+	}
+	token := tokenPrefix + hex.EncodeToString(b)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.revoke(guildID); err != nil {
+		return "", err
+	}
+
+	err := s.store.Put(collection, hash(token), Token{
+		GuildID:   guildID,
+		CreatedBy: userID,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Revoke deletes a guild's token and reports whether it had one
+func (s *Store) Revoke(guildID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.revoke(guildID)
+}
+
+// revoke deletes all tokens of a guild. The caller must hold s.mu.
+func (s *Store) revoke(guildID string) (bool, error) {
+	keys, err := s.store.Keys(collection)
+	if err != nil {
+		return false, fmt.Errorf("error listing API tokens: %v", err)
+	}
+
+	revoked := false
+	for _, key := range keys {
+		var t Token
+		if err := s.store.Get(collection, key, &t); err != nil || t.GuildID != guildID {
+			continue
+		}
+		if err := s.store.Delete(collection, key); err != nil {
+			return revoked, err
+		}
+		revoked = true
+	}
+	return revoked, nil
+}
+
+// Lookup returns the guild a token grants access to
+func (s *Store) Lookup(token string) (string, bool) {
+	var t Token
+	if err := s.store.Get(collection, hash(token), &t); err != nil {
+		return "", false
+	}
+	return t.GuildID, true
+}
@@ -0,0 +1,244 @@
+package cache
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"discordbot/config"
+)
+
+// defaultMaxBytes is the cache size used when CACHE_MAX_MB isn't set
+const defaultMaxBytes = 2 << 30
+
+// ext is the extension of cached audio files, which hold pre-encoded Opus frames
+const ext = ".dca"
+
+// legacyExts are the extensions of files cached in earlier formats, and of
+// downloads that couldn't be encoded. They are never played again and are
+// evicted before anything else.
+var legacyExts = []string{".mp3", ".opus"}
+
+// PlayCounter returns how often a video was played
+type PlayCounter func(videoID string) int
+
+// Entry is a cached audio file
+type Entry struct {
+	VideoID  string
+	Path     string
+	Size     int64
+	LastUsed time.Time
+	Plays    int
+	Score    float64
+}
+
+// Cache keeps downloaded audio on disk so repeated plays skip yt-dlp. When it
+// grows beyond its size limit the entries with the lowest score are evicted first.
+type Cache struct {
+	Dir      string
+	maxBytes int64
+	plays    PlayCounter
+	mu       sync.Mutex
+	inUse    map[string]int
+}
+
+// New creates a cache in dir. plays may be nil, in which case only recency counts.
+func New(dir string, maxBytes int64, plays PlayCounter) *Cache {
+	return &Cache{
+		Dir:      dir,
+		maxBytes: maxBytes,
+		plays:    plays,
+		inUse:    make(map[string]int),
+	}
+}
+
+// DefaultDir returns the cache directory from CACHE_DIR, or a directory under the system temp dir
+func DefaultDir() string {
+	return config.String("CACHE_DIR", filepath.Join(os.TempDir(), "discordbot", "cache"))
+}
+
+// DefaultMaxBytes returns the cache size limit from CACHE_MAX_MB, or 2 GiB
+func DefaultMaxBytes() int64 {
+	mb := config.Int("CACHE_MAX_MB", defaultMaxBytes>>20, func(mb int) bool { return mb > 0 })
+	return int64(mb) << 20
+}
+
+// MaxBytes returns how large the cache may grow
+func (c *Cache) MaxBytes() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.maxBytes
+}
+
+// SetMaxBytes changes how large the cache may grow; it shrinks on the next Evict
+func (c *Cache) SetMaxBytes(maxBytes int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxBytes = maxBytes
+}
+
+// Path returns where a video's audio is stored in the cache
+func (c *Cache) Path(videoID string) string {
+	return filepath.Join(c.Dir, videoID+ext)
+}
+
+// Contains reports whether a video is cached without marking it as used
+func (c *Cache) Contains(videoID string) bool {
+	_, err := os.Stat(c.Path(videoID))
+	return err == nil
+}
+
+// Lookup returns the cached file of a video and marks it as recently used
+func (c *Cache) Lookup(videoID string) (string, bool) {
+	path := c.Path(videoID)
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil {
+		log.Printf("Failed to update cache entry %s: %v", videoID, err)
+	}
+	return path, true
+}
+
+// Acquire protects a video's file from eviction until the returned release func is called
+func (c *Cache) Acquire(videoID string) (release func()) {
+	c.mu.Lock()
+	c.inUse[videoID]++
+	c.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			if c.inUse[videoID]--; c.inUse[videoID] <= 0 {
+				delete(c.inUse, videoID)
+			}
+		})
+	}
+}
+
+// score rates how valuable keeping an entry is. Popular tracks are kept
+// longer, and a track's weight fades with every day it isn't played.
+func score(plays int, lastUsed time.Time) float64 {
+	days := time.Since(lastUsed).Hours() / 24
+	if days < 0 {
+		days = 0
+	}
+	return float64(plays+1) / (1 + days)
+}
+
+// Entries lists the cached files, highest score first
+func (c *Cache) Entries() ([]Entry, error) {
+	files, err := os.ReadDir(c.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading cache directory: %v", err)
+	}
+
+	var entries []Entry
+	for _, file := range files {
+		name := file.Name()
+		legacy := legacyExt(name) != ""
+		if file.IsDir() || !strings.HasSuffix(name, ext) && !legacy {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+
+		entry := Entry{
+			VideoID:  strings.TrimSuffix(strings.TrimSuffix(name, ext), legacyExt(name)),
+			Path:     filepath.Join(c.Dir, name),
+			Size:     info.Size(),
+			LastUsed: info.ModTime(),
+		}
+		if c.plays != nil {
+			entry.Plays = c.plays(entry.VideoID)
+		}
+		if !legacy {
+			entry.Score = score(entry.Plays, entry.LastUsed)
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(a, b int) bool {
+		return entries[a].Score > entries[b].Score
+	})
+	return entries, nil
+}
+
+// legacyExt returns the legacy extension of a file name, if it has one
+func legacyExt(name string) string {
+	for _, legacy := range legacyExts {
+		if strings.HasSuffix(name, legacy) {
+			return legacy
+		}
+	}
+	return ""
+}
+
+// Evict removes the lowest scored entries until the cache fits in its size limit.
+// Files that are currently playing are never removed.
+func (c *Cache) Evict() ([]Entry, error) {
+	return c.evictTo(c.MaxBytes())
+}
+
+// Flush removes every entry that isn't currently playing
+func (c *Cache) Flush() ([]Entry, error) {
+	return c.evictTo(0)
+}
+
+// evictTo removes the lowest scored entries that aren't playing until the
+// cache holds at most limit bytes
+func (c *Cache) evictTo(limit int64) ([]Entry, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var evicted []Entry
+	for idx := len(entries) - 1; idx >= 0 && total > limit; idx-- {
+		entry := entries[idx]
+		if c.inUse[entry.VideoID] > 0 {
+			continue
+		}
+		if err := os.Remove(entry.Path); err != nil {
+			log.Printf("Failed to evict %s from the cache: %v", entry.VideoID, err)
+			continue
+		}
+		total -= entry.Size
+		evicted = append(evicted, entry)
+	}
+	return evicted, nil
+}
+
+// Size returns the total size of the cached files in bytes
+func (c *Cache) Size() (int64, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+	return total, nil
+}
@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// LockDownload takes an exclusive lock on downloading a video, waiting while
+// another process sharing the cache directory holds it. Call the returned
+// func to release the lock once the download is cached or has failed.
+func (c *Cache) LockDownload(videoID string) (unlock func(), err error) {
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating cache directory: %v", err)
+	}
+	path := filepath.Join(c.Dir, "."+videoID+".lock")
+
+	for {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("error opening download lock: %v", err)
+		}
+		if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("error locking download: %v", err)
+		}
+
+		// The previous holder removes the lock file when it's done, so the
+		// one we locked may be gone already; start over on the current one
+		var locked, current syscall.Stat_t
+		if syscall.Fstat(int(file.Fd()), &locked) == nil && syscall.Stat(path, &current) == nil &&
+			locked.Dev == current.Dev && locked.Ino == current.Ino {
+			return func() {
+				os.Remove(path)
+				file.Close()
+			}, nil
+		}
+		file.Close()
+	}
+}
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockDownloadWaitsForHolder(t *testing.T) {
+	c := New(t.TempDir(), 1<<20, nil)
+	unlock, err := c.LockDownload("video")
+	if err != nil {
+		t.Fatalf("LockDownload: %v", err)
+	}
+
+	locked := make(chan func())
+	go func() {
+		unlock, err := c.LockDownload("video")
+		if err != nil {
+			t.Errorf("second LockDownload: %v", err)
+		}
+		locked <- unlock
+	}()
+
+	select {
+	case <-locked:
+		t.Fatalf("second LockDownload got the lock while it was held")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case unlock := <-locked:
+		if unlock != nil {
+			unlock()
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("second LockDownload still waiting after the lock was released")
+	}
+
+	if _, err := os.Stat(filepath.Join(c.Dir, ".video.lock")); !os.IsNotExist(err) {
+		t.Errorf("lock file left behind: got %v, want not exist", err)
+	}
+}
+
+func TestLockDownloadPerVideo(t *testing.T) {
+	c := New(t.TempDir(), 1<<20, nil)
+	unlockA, err := c.LockDownload("a")
+	if err != nil {
+		t.Fatalf("LockDownload a: %v", err)
+	}
+	defer unlockA()
+
+	done := make(chan error)
+	go func() {
+		unlock, err := c.LockDownload("b")
+		if err == nil {
+			unlock()
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("LockDownload b: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("LockDownload b waited for the lock on a")
+	}
+}
@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// soundsDir is the cache subdirectory soundboard clips are kept in. Eviction
+// only looks at top-level files, so clips stay until they are removed.
+const soundsDir = "sounds"
+
+// soundExt is the extension of soundboard clips, which are MP3 files. Clips
+// saved before were MP3 files named like cached tracks, with ext.
+const soundExt = ".mp3"
+
+// soundNamePattern is what soundboard clip names may look like. It keeps
+// names from reaching outside the guild's directory.
+var soundNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// ErrInvalidSoundName is returned for clip names that don't match soundNamePattern
+var ErrInvalidSoundName = errors.New("invalid clip name")
+
+// ValidSoundName reports whether name may be used for a soundboard clip
+func ValidSoundName(name string) bool {
+	return soundNamePattern.MatchString(name)
+}
+
+// SoundPath returns where a guild's soundboard clip is stored
+func (c *Cache) SoundPath(guildID, name string) (string, error) {
+	if !ValidSoundName(name) {
+		return "", ErrInvalidSoundName
+	}
+	return filepath.Join(c.Dir, soundsDir, guildID, name+soundExt), nil
+}
+
+// MigrateSounds renames clips saved with the extension of cached tracks to
+// soundExt. A clip already saved under the new name wins.
+func (c *Cache) MigrateSounds() {
+	old, err := filepath.Glob(filepath.Join(c.Dir, soundsDir, "*", "*"+ext))
+	if err != nil || len(old) == 0 {
+		return
+	}
+	migrated := 0
+	for _, path := range old {
+		renamed := strings.TrimSuffix(path, ext) + soundExt
+		if _, err := os.Stat(renamed); err == nil {
+			os.Remove(path)
+			continue
+		}
+		if err := os.Rename(path, renamed); err != nil {
+			log.Printf("Warning: failed to rename sound %s: %v", path, err)
+			continue
+		}
+		migrated++
+	}
+	log.Printf("Renamed %d soundboard clips to %s", migrated, soundExt)
+}
+
+// PrepareSound creates the directory for a guild's clip and returns the path to write it to
+func (c *Cache) PrepareSound(guildID, name string) (string, error) {
+	path, err := c.SoundPath(guildID, name)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("error creating sound directory: %v", err)
+	}
+	return path, nil
+}
+
+// HasSound reports whether a guild has a clip with the given name
+func (c *Cache) HasSound(guildID, name string) bool {
+	path, err := c.SoundPath(guildID, name)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// RemoveSound deletes a guild's clip. It returns false if there was no such clip.
+func (c *Cache) RemoveSound(guildID, name string) (bool, error) {
+	path, err := c.SoundPath(guildID, name)
+	if err != nil {
+		return false, err
+	}
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error removing sound: %v", err)
+	}
+	return true, nil
+}
+
+// Sounds lists the names of a guild's clips in alphabetical order
+func (c *Cache) Sounds(guildID string) ([]string, error) {
+	files, err := os.ReadDir(filepath.Join(c.Dir, soundsDir, guildID))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading sound directory: %v", err)
+	}
+
+	var names []string
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), soundExt) {
+			names = append(names, strings.TrimSuffix(file.Name(), soundExt))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMigrateSounds(t *testing.T) {
+	c := New(t.TempDir(), 1<<20, nil)
+	dir := filepath.Join(c.Dir, soundsDir, "guild")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	for name, data := range map[string]string{
+		"airhorn.dca": "old airhorn",
+		"rimshot.dca": "old rimshot",
+		"rimshot.mp3": "new rimshot",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	c.MigrateSounds()
+
+	names, err := c.Sounds("guild")
+	if err != nil {
+		t.Fatalf("Sounds: %v", err)
+	}
+	if want := []string{"airhorn", "rimshot"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Sounds got %q, want %q", names, want)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "airhorn"+soundExt)); string(data) != "old airhorn" {
+		t.Errorf("migrated clip holds %q, want %q", data, "old airhorn")
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "rimshot"+soundExt)); string(data) != "new rimshot" {
+		t.Errorf("clip saved under the new name holds %q, want %q", data, "new rimshot")
+	}
+	if old, _ := filepath.Glob(filepath.Join(dir, "*"+ext)); len(old) != 0 {
+		t.Errorf("clips left with the old extension: %q", old)
+	}
+}
+
+func TestSoundPathRejectsInvalidNames(t *testing.T) {
+	c := New(t.TempDir(), 1<<20, nil)
+	victim := filepath.Join(c.Dir, soundsDir, "other", "x"+soundExt)
+	if err := os.MkdirAll(filepath.Dir(victim), 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(victim, []byte("clip"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	for _, name := range []string{"../other/x", "..", "a/b", "", "Airhorn", "a.b"} {
+		if _, err := c.SoundPath("guild", name); err != ErrInvalidSoundName {
+			t.Errorf("SoundPath(%q) got error %v, want %v", name, err, ErrInvalidSoundName)
+		}
+		if c.HasSound("guild", name) {
+			t.Errorf("HasSound(%q) got true, want false", name)
+		}
+		if removed, err := c.RemoveSound("guild", name); removed || err == nil {
+			t.Errorf("RemoveSound(%q) got %v, %v, want an error", name, removed, err)
+		}
+	}
+	if _, err := os.Stat(victim); err != nil {
+		t.Errorf("other guild's clip: got %v, want it kept", err)
+	}
+	if _, err := c.SoundPath("guild", "air-horn_2"); err != nil {
+		t.Errorf("SoundPath of a valid name got error %v", err)
+	}
+}
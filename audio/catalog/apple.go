@@ -0,0 +1,72 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+// appleMusicLink matches music.apple.com album, song and playlist links
+var appleMusicLink = regexp.MustCompile(`^https?://(?:geo\.)?music\.apple\.com/([a-z]{2})/(album|song|playlist)/(?:[^/?]+/)?([a-zA-Z0-9.\-]+)`)
+
+// ErrApplePlaylist is returned for Apple Music playlists, which the public
+// iTunes lookup API doesn't list
+var ErrApplePlaylist = errors.New("Apple Music playlists can't be read, share an album or song instead")
+
+// itunesLookup is the subset of an iTunes lookup response used here
+type itunesLookup struct {
+	Results []struct {
+		WrapperType     string `json:"wrapperType"`
+		ArtistName      string `json:"artistName"`
+		TrackName       string `json:"trackName"`
+		TrackTimeMillis int64  `json:"trackTimeMillis"`
+	} `json:"results"`
+}
+
+// isAppleMusicLink reports whether link points to Apple Music
+func isAppleMusicLink(link string) bool {
+	return appleMusicLink.MatchString(link)
+}
+
+// resolveAppleMusic reads the songs behind an Apple Music link through the iTunes lookup API.
+// A song shared from an album page is an album link with the song's ID in the i parameter.
+func resolveAppleMusic(link string) ([]Song, error) {
+	matches := appleMusicLink.FindStringSubmatch(link)
+	country, kind, id := matches[1], matches[2], matches[3]
+	if kind == "playlist" {
+		return nil, ErrApplePlaylist
+	}
+
+	var songID string
+	if parsed, err := url.Parse(link); err == nil {
+		songID = parsed.Query().Get("i")
+	}
+	if kind == "song" {
+		songID = id
+	}
+
+	query := url.Values{"id": {id}, "country": {country}, "entity": {"song"}, "limit": {"200"}}
+	if songID != "" {
+		query = url.Values{"id": {songID}, "country": {country}}
+	}
+
+	var result itunesLookup
+	if err := getJSON("https://itunes.apple.com/lookup?"+query.Encode(), &result); err != nil {
+		return nil, fmt.Errorf("couldn't read Apple Music %s: %v", kind, err)
+	}
+
+	var songs []Song
+	for _, item := range result.Results {
+		if item.WrapperType != "track" {
+			continue
+		}
+		songs = append(songs, Song{
+			Artist:   item.ArtistName,
+			Title:    item.TrackName,
+			Duration: time.Duration(item.TrackTimeMillis) * time.Millisecond,
+		})
+	}
+	return songs, nil
+}
@@ -0,0 +1,64 @@
+// Package catalog reads track metadata from music services whose links can't
+// be played directly, so the tracks can be found on YouTube instead
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Song is a track listed by a music service
+type Song struct {
+	Artist   string
+	Title    string
+	Duration time.Duration
+}
+
+// Query returns a search term for finding the song elsewhere
+func (s Song) Query() string {
+	if s.Artist == "" {
+		return s.Title
+	}
+	return s.Artist + " - " + s.Title
+}
+
+// httpClient is shared by the catalog lookups
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// Resolve returns up to limit songs behind a Deezer or Apple Music track,
+// album or playlist link. ok is false if link belongs to neither service.
+func Resolve(link string, limit int) (songs []Song, ok bool, err error) {
+	switch {
+	case isDeezerLink(link):
+		songs, err = resolveDeezer(link)
+	case isAppleMusicLink(link):
+		songs, err = resolveAppleMusic(link)
+	default:
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, true, err
+	}
+	if len(songs) == 0 {
+		return nil, true, fmt.Errorf("no tracks found behind %s", link)
+	}
+	if limit > 0 && len(songs) > limit {
+		songs = songs[:limit]
+	}
+	return songs, true, nil
+}
+
+// getJSON fetches url and decodes its JSON body into v
+func getJSON(url string, v interface{}) error {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,85 @@
+package catalog
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// deezerLink matches deezer.com track, album and playlist links, with or without a locale
+var deezerLink = regexp.MustCompile(`^https?://(?:www\.)?deezer\.com/(?:[a-z]{2}(?:-[a-z]{2})?/)?(track|album|playlist)/(\d+)`)
+
+// deezerTrack is the subset of a Deezer API track used here
+type deezerTrack struct {
+	Title    string `json:"title"`
+	Duration int    `json:"duration"` // Seconds
+	Artist   struct {
+		Name string `json:"name"`
+	} `json:"artist"`
+}
+
+// deezerResponse covers the Deezer API's track, album and playlist objects
+type deezerResponse struct {
+	deezerTrack
+	Tracks struct {
+		Data []deezerTrack `json:"data"`
+	} `json:"tracks"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// isDeezerLink reports whether link points to Deezer, including its short links
+func isDeezerLink(link string) bool {
+	return deezerLink.MatchString(link) ||
+		strings.HasPrefix(link, "https://deezer.page.link/") ||
+		strings.HasPrefix(link, "https://link.deezer.com/")
+}
+
+// resolveDeezer reads the songs behind a Deezer link through Deezer's public API
+func resolveDeezer(link string) ([]Song, error) {
+	matches := deezerLink.FindStringSubmatch(link)
+	if matches == nil {
+		// Short links redirect to the full one
+		resp, err := httpClient.Head(link)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't follow Deezer link: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("couldn't follow Deezer link: %s", resp.Status)
+		}
+		matches = deezerLink.FindStringSubmatch(resp.Request.URL.String())
+		if matches == nil {
+			return nil, fmt.Errorf("unsupported Deezer link: %s", link)
+		}
+	}
+	kind, id := matches[1], matches[2]
+
+	var result deezerResponse
+	if err := getJSON(fmt.Sprintf("https://api.deezer.com/%s/%s", kind, id), &result); err != nil {
+		return nil, fmt.Errorf("couldn't read Deezer %s: %v", kind, err)
+	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("couldn't read Deezer %s: %s", kind, result.Error.Message)
+	}
+
+	tracks := result.Tracks.Data
+	if kind == "track" {
+		tracks = []deezerTrack{result.deezerTrack}
+	}
+	songs := make([]Song, 0, len(tracks))
+	for _, track := range tracks {
+		if track.Title == "" {
+			continue
+		}
+		songs = append(songs, Song{
+			Artist:   track.Artist.Name,
+			Title:    track.Title,
+			Duration: time.Duration(track.Duration) * time.Second,
+		})
+	}
+	return songs, nil
+}
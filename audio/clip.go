@@ -0,0 +1,61 @@
+package audio
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProbeDuration returns how long a file plays using ffprobe
+func ProbeDuration(filePath string) (time.Duration, error) {
+	output, err := exec.Command("ffprobe",
+		"-v", "error", // Only print errors
+		"-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1", // Just the value
+		filePath).Output()
+	if err != nil {
+		return 0, fmt.Errorf("ffprobe failed: %v", err)
+	}
+
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(string(output)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: %v", strings.TrimSpace(string(output)), err)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// ConvertClip converts a short audio file to a 48 kHz stereo MP3 at dst.
+// Files longer than maxLength are rejected.
+func ConvertClip(src, dst string, maxLength time.Duration) error {
+	length, err := ProbeDuration(src)
+	if err != nil {
+		return fmt.Errorf("not a playable audio file: %v", err)
+	}
+	if length > maxLength {
+		return fmt.Errorf("clip is %.1fs long, the limit is %.0fs", length.Seconds(), maxLength.Seconds())
+	}
+
+	// Write next to dst first so a failed conversion never replaces a working clip
+	tmp := dst + ".tmp"
+	output, err := exec.Command("ffmpeg",
+		"-y",      // Overwrite leftovers of an earlier attempt
+		"-i", src, // Input file
+		"-vn",          // Drop cover art and video
+		"-ar", "48000", // Discord's sample rate
+		"-ac", "2", // Stereo
+		"-f", "mp3",
+		"-loglevel", "error",
+		tmp).CombinedOutput()
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("ffmpeg failed: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("error saving clip: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// dcaExt is the extension of pre-encoded DCA files
+const dcaExt = ".dca"
+
+// dcaMagic starts every DCA file, followed by the length of its JSON metadata
+const dcaMagic = "DCA1"
+
+// dcaMetadata is the JSON header of a DCA file
+type dcaMetadata struct {
+	Opus struct {
+		SampleRate int `json:"sample_rate"`
+		FrameSize  int `json:"frame_size"`
+		Channels   int `json:"channels"`
+	} `json:"opus"`
+	// Gain is the volume the frames were encoded with. Files cached before
+	// it was recorded leave it out and were encoded at full volume.
+	Gain float64 `json:"gain,omitempty"`
+}
+
+// EncodeDCA stores src at dst as a DCA file: 20ms Opus frames, each prefixed
+// with its length, that play without ffmpeg. Every file is encoded once with
+// ffmpeg, through the same filters as live playback, so cached tracks play
+// as loud as the rest.
+func EncodeDCA(src, dst string) error {
+	// Write next to dst first so a failed encode never leaves a broken file
+	tmp := dst + ".part"
+	if err := encodeDCA(src, tmp); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("error saving %s: %v", dst, err)
+	}
+	return nil
+}
+
+// encodeDCA encodes any audio file to 20ms Opus frames with ffmpeg and writes them to a DCA file
+func encodeDCA(src, dst string) error {
+	cmd := exec.Command("ffmpeg",
+		"-i", src, // Input file
+		"-map", "0:a:0", // First audio stream only
+		"-af", normalQuality.Filters, // Same volume and resampling as live playback
+		"-c:a", "libopus", // Encode to Opus
+		"-b:a", fmt.Sprint(normalQuality.Bitrate), // Same bitrate as live encoding
+		"-frame_duration", "20", // The frame length Discord expects
+		"-ar", "48000", // Discord's sample rate
+		"-ac", "2", // Stereo
+		"-loglevel", "error", // Only show errors
+		"-f", "ogg", // Ogg is simple to split into packets
+		"pipe:1") // Output to stdout
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("error creating stdout pipe: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("error starting ffmpeg: %v", err)
+	}
+
+	err = writeDCA(dst, newOggReader(stdout), normalQuality.Gain)
+	if err != nil {
+		cmd.Process.Kill()
+	}
+	if waitErr := cmd.Wait(); waitErr != nil && err == nil {
+		err = fmt.Errorf("ffmpeg failed: %v: %s", waitErr, strings.TrimSpace(stderr.String()))
+	}
+	return err
+}
+
+// writeDCA writes the Opus packets of an Ogg stream, encoded with gain, to a
+// DCA file. It returns errPacketDuration if a packet isn't a single 20ms frame.
+func writeDCA(dst string, packets *oggReader, gain float64) error {
+	head, err := packets.ReadPacket()
+	if err != nil {
+		return fmt.Errorf("error reading audio data: %v", err)
+	}
+	if !bytes.HasPrefix(head, []byte("OpusHead")) {
+		return errors.New("not an Opus stream")
+	}
+	// Skip the OpusTags packet
+	if _, err := packets.ReadPacket(); err != nil {
+		return fmt.Errorf("error reading audio data: %v", err)
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	w := bufio.NewWriter(out)
+
+	var metadata dcaMetadata
+	metadata.Opus.SampleRate = 48000
+	metadata.Opus.FrameSize = frameSize
+	metadata.Opus.Channels = channels
+	metadata.Gain = gain
+	header, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	w.WriteString(dcaMagic)
+	binary.Write(w, binary.LittleEndian, int32(len(header)))
+	w.Write(header)
+
+	for {
+		packet, err := packets.ReadPacket()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading audio data: %v", err)
+		}
+		if opusPacketDuration(packet) != frameDuration {
+			return errPacketDuration
+		}
+		binary.Write(w, binary.LittleEndian, int16(len(packet)))
+		w.Write(packet)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// dcaReader reads the frames of a DCA file
+type dcaReader struct {
+	file *os.File
+	r    *bufio.Reader
+	gain float64 // Volume the frames were encoded with
+}
+
+// openDCA opens a DCA file positioned at the frame start into it
+func openDCA(path string, start time.Duration) (*dcaReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	d := &dcaReader{file: file, r: bufio.NewReaderSize(file, 16384)}
+
+	magic := make([]byte, len(dcaMagic))
+	var headerSize int32
+	if _, err := io.ReadFull(d.r, magic); err != nil || string(magic) != dcaMagic {
+		file.Close()
+		return nil, fmt.Errorf("%s is not a DCA file", path)
+	}
+	if err := binary.Read(d.r, binary.LittleEndian, &headerSize); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(d.r, header); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	var metadata dcaMetadata
+	if err := json.Unmarshal(header, &metadata); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error reading %s: %v", path, err)
+	}
+	d.gain = metadata.Gain
+	if d.gain == 0 {
+		d.gain = 1
+	}
+
+	// Every frame is 20ms long, so seeking is skipping frames
+	for skip := int(start / frameDuration); skip > 0; skip-- {
+		var size int16
+		if err := binary.Read(d.r, binary.LittleEndian, &size); err != nil {
+			break
+		}
+		if _, err := d.r.Discard(int(size)); err != nil {
+			break
+		}
+	}
+	return d, nil
+}
+
+// ReadPacket returns the next Opus frame. It returns io.EOF at the end of the
+// file and io.ErrUnexpectedEOF inside a frame.
+func (d *dcaReader) ReadPacket() ([]byte, error) {
+	var size int16
+	if err := binary.Read(d.r, binary.LittleEndian, &size); err != nil {
+		return nil, err
+	}
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid frame size %d", size)
+	}
+	frame := make([]byte, size)
+	if _, err := io.ReadFull(d.r, frame); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return frame, nil
+}
+
+// Close closes the file
+func (d *dcaReader) Close() error {
+	return d.file.Close()
+}
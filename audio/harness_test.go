@@ -0,0 +1,172 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// fakeConnection returns a voice connection that is ready to carry audio
+// without a Discord session behind it. Frames sent to it arrive on OpusSend.
+func fakeConnection() *discordgo.VoiceConnection {
+	return &discordgo.VoiceConnection{Ready: true, OpusSend: make(chan []byte)}
+}
+
+// newTestInstance returns a voice instance that is playing on a fake connection
+func newTestInstance() *VoiceInstance {
+	vc := fakeConnection()
+	return &VoiceInstance{
+		GuildID:    "guild",
+		Connection: vc,
+		IsPlaying:  true,
+		StopChan:   make(chan bool, 1),
+		sender:     newFrameSender(vc),
+	}
+}
+
+// opusSink collects the frames written to a fake connection, taking pace to
+// accept each one the way Discord's sender paces frames
+type opusSink struct {
+	mu     sync.Mutex
+	frames [][]byte
+	done   chan struct{}
+}
+
+// newOpusSink starts collecting frames from vc
+func newOpusSink(vc *discordgo.VoiceConnection, pace time.Duration) *opusSink {
+	sink := &opusSink{done: make(chan struct{})}
+	go func() {
+		for {
+			select {
+			case frame := <-vc.OpusSend:
+				sink.mu.Lock()
+				sink.frames = append(sink.frames, frame)
+				sink.mu.Unlock()
+				time.Sleep(pace)
+			case <-sink.done:
+				return
+			}
+		}
+	}()
+	return sink
+}
+
+// Close stops collecting frames
+func (s *opusSink) Close() {
+	close(s.done)
+}
+
+// Frames returns a copy of the frames collected so far
+func (s *opusSink) Frames() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]byte(nil), s.frames...)
+}
+
+// WaitFrames waits until at least n frames have arrived
+func (s *opusSink) WaitFrames(t *testing.T, n int) [][]byte {
+	t.Helper()
+	waitFor(t, fmt.Sprintf("%d frames", n), func() bool { return len(s.Frames()) >= n })
+	return s.Frames()
+}
+
+// pcmSource returns frames of synthetic PCM followed by extra samples. Every
+// sample of a frame holds the frame's index, so encoded frames can be told apart.
+func pcmSource(frames, extra int) *bytes.Reader {
+	var buf bytes.Buffer
+	for n := 0; n < frames; n++ {
+		frame := make([]int16, frameSize*channels)
+		for i := range frame {
+			frame[i] = int16(n)
+		}
+		binary.Write(&buf, binary.LittleEndian, frame)
+	}
+	binary.Write(&buf, binary.LittleEndian, make([]int16, extra))
+	return bytes.NewReader(buf.Bytes())
+}
+
+// endlessPCM is a PCM source that never runs out, numbering its frames like pcmSource
+type endlessPCM struct {
+	sample int
+}
+
+// Read fills p with samples holding the index of the frame they belong to
+func (e *endlessPCM) Read(p []byte) (int, error) {
+	n := len(p) &^ 1
+	for i := 0; i < n; i += 2 {
+		binary.LittleEndian.PutUint16(p[i:], uint16(e.sample/(frameSize*channels)))
+		e.sample++
+	}
+	return n, nil
+}
+
+// indexEncoder is a frameEncoder that encodes each frame as the index held
+// in its first sample, recording the frame sizes it was given
+type indexEncoder struct {
+	mu    sync.Mutex
+	sizes []int
+}
+
+// Encode returns the frame's index as a two-byte frame
+func (e *indexEncoder) Encode(pcm []int16, frameSize, maxDataBytes int) ([]byte, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.sizes = append(e.sizes, len(pcm))
+	if len(pcm) != frameSize*channels {
+		return nil, fmt.Errorf("got %d samples, want %d", len(pcm), frameSize*channels)
+	}
+	frame := make([]byte, 2)
+	binary.LittleEndian.PutUint16(frame, uint16(pcm[0]))
+	return frame, nil
+}
+
+// Count returns how many frames were encoded
+func (e *indexEncoder) Count() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.sizes)
+}
+
+// frameIndex decodes a frame produced by indexEncoder
+func frameIndex(frame []byte) int {
+	return int(binary.LittleEndian.Uint16(frame))
+}
+
+// waitFor polls until cond holds, failing the test after a few seconds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// stream runs streamFrames for vi in the background and returns its result channel
+func stream(vi *VoiceInstance, source io.Reader, encoder frameEncoder, start time.Duration) <-chan error {
+	result := make(chan error, 1)
+	go func() {
+		result <- vi.streamFrames(vi.Connection, vi.sender, source, encoder, start, vi.StopChan)
+	}()
+	return result
+}
+
+// await returns the result of a stream, failing the test if it doesn't end
+func await(t *testing.T, result <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-result:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("playback didn't end")
+		return nil
+	}
+}
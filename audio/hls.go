@@ -0,0 +1,108 @@
+package audio
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"discordbot/bandwidth"
+)
+
+// hlsSegmentRetries is how often ffmpeg retries a segment of an HLS stream before skipping it
+const hlsSegmentRetries = 3
+
+// hlsClient fetches HLS playlists to see whether a stream is over
+var hlsClient = &http.Client{Timeout: 10 * time.Second}
+
+// IsHLS reports whether link points to an HLS (m3u8) playlist, such as a
+// radio station's stream or a YouTube live manifest. Only http and https
+// links count, so local files never get the live stream treatment.
+func IsHLS(link string) bool {
+	parsed, err := url.Parse(link)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return false
+	}
+	path := strings.ToLower(parsed.Path)
+	return strings.HasSuffix(path, ".m3u8") || strings.Contains(path, "/hls_playlist/")
+}
+
+// streamInput returns the ffmpeg arguments for reading a live stream at link
+func streamInput(link string) []string {
+	args := []string{
+		"-reconnect", "1", // Reconnect dropped HTTP connections
+		"-reconnect_streamed", "1", // Also for streamed input
+		"-reconnect_delay_max", "5", // Give up on the connection after 5s
+		"-rw_timeout", fmt.Sprint(liveReadTimeout.Microseconds()), // Treat a silent stream as stalled
+	}
+	if IsHLS(link) {
+		args = append(args,
+			"-f", "hls", // Don't rely on probing, some servers send odd content types
+			"-live_start_index", "-3", // Start close to the live edge
+			"-seg_max_retry", fmt.Sprint(hlsSegmentRetries), // Ride out segments that fail to load
+		)
+	}
+	return append(args, "-i", link)
+}
+
+// HLSFinished reports whether the HLS playlist at link is a recording that
+// won't grow any more, as marked by #EXT-X-ENDLIST. Master playlists are
+// followed to their first variant. Live playlists and failed fetches count as
+// not finished.
+func HLSFinished(link string) bool {
+	for redirects := 0; redirects < 2; redirects++ {
+		lines, err := fetchPlaylist(link)
+		if err != nil {
+			return false
+		}
+		variant := ""
+		for idx, line := range lines {
+			if line == "#EXT-X-ENDLIST" {
+				return true
+			}
+			if strings.HasPrefix(line, "#EXT-X-STREAM-INF") && idx+1 < len(lines) && variant == "" {
+				variant = lines[idx+1]
+			}
+		}
+		if variant == "" {
+			return false
+		}
+		base, err := url.Parse(link)
+		if err != nil {
+			return false
+		}
+		ref, err := url.Parse(variant)
+		if err != nil {
+			return false
+		}
+		link = base.ResolveReference(ref).String()
+	}
+	return false
+}
+
+// fetchPlaylist returns the non-empty lines of the playlist at link, read at
+// its share of the bot's download rate limit
+func fetchPlaylist(link string) ([]string, error) {
+	rate, done := bandwidth.Default.Start(0)
+	defer done()
+
+	resp, err := hlsClient.Get(link)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("playlist returned %s", resp.Status)
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bandwidth.Reader(resp.Body, rate))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
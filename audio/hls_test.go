@@ -0,0 +1,24 @@
+package audio
+
+import "testing"
+
+func TestIsHLS(t *testing.T) {
+	tests := []struct {
+		link string
+		want bool
+	}{
+		{"https://radio.example.com/live/stream.m3u8", true},
+		{"http://radio.example.com/live/STREAM.M3U8?token=x", true},
+		{"https://manifest.googlevideo.com/api/manifest/hls_playlist/id/abc/file/index", true},
+		{"https://radio.example.com/live/stream.mp3", false},
+		{"/tmp/x.m3u8", false},
+		{"file:///tmp/x.m3u8", false},
+		{"ftp://radio.example.com/x.m3u8", false},
+		{"x.m3u8", false},
+	}
+	for _, test := range tests {
+		if got := IsHLS(test.link); got != test.want {
+			t.Errorf("IsHLS(%q) got %v, want %v", test.link, got, test.want)
+		}
+	}
+}
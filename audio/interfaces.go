@@ -0,0 +1,13 @@
+package audio
+
+// Downloader fetches a video's audio to a local file. *youtube.Client implements it.
+type Downloader interface {
+	GetVideoID(url string) (string, error)
+	// DownloadAudio caps the download at rateLimit bytes per second if it's positive
+	DownloadAudio(videoID string, rateLimit int64) (string, error)
+}
+
+// Searcher turns a search term into tracks
+type Searcher interface {
+	SearchTracks(query string, limit int) ([]*Track, error)
+}
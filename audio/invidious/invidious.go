@@ -0,0 +1,88 @@
+// Package invidious reads feeds from an Invidious instance, an alternative
+// YouTube front end with a public API
+package invidious
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client talks to the API of one Invidious instance
+type Client struct {
+	BaseURL string
+	HTTP    *http.Client
+}
+
+// NewClient creates a client for the Invidious instance at baseURL
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		HTTP:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Video is a YouTube video listed by Invidious
+type Video struct {
+	ID       string
+	Title    string
+	Author   string
+	Duration time.Duration
+}
+
+// URL returns the video's YouTube watch link
+func (v Video) URL() string {
+	return "https://www.youtube.com/watch?v=" + v.ID
+}
+
+// TrendingMusic returns the trending music videos of a region, given as a
+// two-letter country code. An empty region uses the instance's default.
+func (c *Client) TrendingMusic(region string) ([]Video, error) {
+	query := url.Values{"type": {"music"}}
+	if region != "" {
+		query.Set("region", strings.ToUpper(region))
+	}
+
+	var feed []struct {
+		VideoID       string `json:"videoId"`
+		Title         string `json:"title"`
+		Author        string `json:"author"`
+		LengthSeconds int    `json:"lengthSeconds"`
+	}
+	if err := c.getJSON("/api/v1/trending?"+query.Encode(), &feed); err != nil {
+		return nil, err
+	}
+
+	videos := make([]Video, 0, len(feed))
+	for _, entry := range feed {
+		if entry.VideoID == "" {
+			continue
+		}
+		videos = append(videos, Video{
+			ID:       entry.VideoID,
+			Title:    entry.Title,
+			Author:   entry.Author,
+			Duration: time.Duration(entry.LengthSeconds) * time.Second,
+		})
+	}
+	return videos, nil
+}
+
+// getJSON fetches path from the instance and decodes its JSON body into v
+func (c *Client) getJSON(path string, v interface{}) error {
+	resp, err := c.HTTP.Get(c.BaseURL + path)
+	if err != nil {
+		return fmt.Errorf("error reaching Invidious: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Invidious returned %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode the Invidious response: %v", err)
+	}
+	return nil
+}
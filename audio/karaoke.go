@@ -0,0 +1,42 @@
+package audio
+
+// vocalRemovalFilter cancels what is mixed into the center of a stereo
+// track, usually the lead vocals, by subtracting the right channel from the
+// left. Both channels get the same difference: with opposite signs they would
+// cancel out again wherever the listener's device mixes them down to mono.
+const vocalRemovalFilter = "aformat=channel_layouts=stereo,pan=stereo|c0=c0-c1|c1=c0-c1"
+
+// karaokeOn reports whether vocals are removed from the guild's tracks
+func (vi *VoiceInstance) karaokeOn() bool {
+	vi.Mu.Lock()
+	defer vi.Mu.Unlock()
+	return vi.Karaoke
+}
+
+// karaokeFilters prepends vocal removal to filters if karaoke is on
+func (vi *VoiceInstance) karaokeFilters(filters string) string {
+	if !vi.karaokeOn() {
+		return filters
+	}
+	return vocalRemovalFilter + "," + filters
+}
+
+// removeVocals applies vocalRemovalFilter to an interleaved stereo frame
+func removeVocals(frame []int16) {
+	for idx := 0; idx+1 < len(frame); idx += 2 {
+		left, right := int32(frame[idx]), int32(frame[idx+1])
+		side := clampSample(left - right)
+		frame[idx], frame[idx+1] = side, side
+	}
+}
+
+// clampSample limits a mixed sample to the 16-bit range
+func clampSample(sample int32) int16 {
+	if sample > 32767 {
+		return 32767
+	}
+	if sample < -32768 {
+		return -32768
+	}
+	return int16(sample)
+}
@@ -0,0 +1,516 @@
+package lavalink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// clientName identifies the bot to the node
+	clientName = "discordbot"
+	// voiceTimeout is how long Connect waits for Discord to assign a voice server
+	voiceTimeout = 10 * time.Second
+	// reconnectDelay is how long to wait before reconnecting to a lost node
+	reconnectDelay = 5 * time.Second
+)
+
+// ErrNotConnected is returned while the node's websocket is down
+var ErrNotConnected = errors.New("lavalink node not connected")
+
+// Config locates a Lavalink v4 node
+type Config struct {
+	Address  string // host:port of the node
+	Password string
+	Secure   bool // Use https and wss
+}
+
+// voiceState collects the two halves of a guild's Discord voice session
+// the node needs to connect: our session ID and the voice server
+type voiceState struct {
+	sessionID string
+	token     string
+	endpoint  string
+	ready     chan struct{} // Closed once all fields are known
+	complete  bool
+}
+
+// update closes ready once the voice state is complete. The caller must hold the node's lock.
+func (vs *voiceState) update() {
+	if !vs.complete && vs.sessionID != "" && vs.token != "" && vs.endpoint != "" {
+		vs.complete = true
+		close(vs.ready)
+	}
+}
+
+// Node plays audio through a Lavalink node. It implements audio.Remote.
+type Node struct {
+	cfg    Config
+	s      *discordgo.Session
+	client *http.Client
+
+	mu        sync.Mutex
+	sessionID string                   // Lavalink session, empty while disconnected
+	voice     map[string]*voiceState   // Voice sessions by guild
+	players   map[string]chan error    // Track end notifications by guild
+	positions map[string]time.Duration // Last reported playback positions by guild
+}
+
+// New creates a node client. It forwards the bot's voice sessions to the node,
+// so the bot must not open voice connections itself while it is in use.
+func New(cfg Config, s *discordgo.Session) *Node {
+	n := &Node{
+		cfg:       cfg,
+		s:         s,
+		client:    &http.Client{Timeout: 15 * time.Second},
+		voice:     make(map[string]*voiceState),
+		players:   make(map[string]chan error),
+		positions: make(map[string]time.Duration),
+	}
+	s.AddHandler(n.onVoiceStateUpdate)
+	s.AddHandler(n.onVoiceServerUpdate)
+	return n
+}
+
+// Connected reports whether the node's websocket is up
+func (n *Node) Connected() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.sessionID != ""
+}
+
+// Run keeps the websocket to the node open until ctx is done.
+// It must be called after the Discord session is open.
+func (n *Node) Run(ctx context.Context) {
+	for {
+		if err := n.listen(ctx); err != nil {
+			log.Printf("Lavalink connection lost: %v", err)
+		}
+		n.disconnected()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// listen connects to the node and handles its messages until the connection fails
+func (n *Node) listen(ctx context.Context) error {
+	scheme := "ws"
+	if n.cfg.Secure {
+		scheme = "wss"
+	}
+	if n.s.State.User == nil {
+		return errors.New("waiting for the Discord session")
+	}
+	header := http.Header{}
+	header.Set("Authorization", n.cfg.Password)
+	header.Set("User-Id", n.s.State.User.ID)
+	header.Set("Client-Name", clientName)
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, scheme+"://"+n.cfg.Address+"/v4/websocket", header)
+	if err != nil {
+		return fmt.Errorf("error connecting to node: %v", err)
+	}
+	defer conn.Close()
+
+	// Close the connection when shutting down so ReadMessage returns
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		n.handleMessage(data)
+	}
+}
+
+// message is a websocket message from the node
+type message struct {
+	Op        string `json:"op"`
+	SessionID string `json:"sessionId"`
+	Type      string `json:"type"`
+	GuildID   string `json:"guildId"`
+	Reason    string `json:"reason"`
+	Code      int    `json:"code"`
+	State     *struct {
+		Position int64 `json:"position"`
+	} `json:"state"`
+	Exception *struct {
+		Message string `json:"message"`
+	} `json:"exception"`
+}
+
+// handleMessage processes a websocket message from the node
+func (n *Node) handleMessage(data []byte) {
+	var msg message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		log.Printf("Invalid Lavalink message: %v", err)
+		return
+	}
+
+	switch msg.Op {
+	case "ready":
+		log.Printf("Connected to Lavalink node %s", n.cfg.Address)
+		n.mu.Lock()
+		n.sessionID = msg.SessionID
+		var guilds []string
+		for guildID, vs := range n.voice {
+			if vs.complete {
+				guilds = append(guilds, guildID)
+			}
+		}
+		n.mu.Unlock()
+
+		// A new session has no players, so hand the node our voice sessions again
+		for _, guildID := range guilds {
+			if err := n.updateVoice(guildID); err != nil {
+				log.Printf("Failed to restore Lavalink voice in guild %s: %v", guildID, err)
+			}
+		}
+
+	case "playerUpdate":
+		if msg.State != nil {
+			n.mu.Lock()
+			n.positions[msg.GuildID] = time.Duration(msg.State.Position) * time.Millisecond
+			n.mu.Unlock()
+		}
+
+	case "event":
+		switch msg.Type {
+		case "TrackEndEvent":
+			if msg.Reason == "loadFailed" {
+				n.trackEnded(msg.GuildID, errors.New("the node couldn't load the track"))
+			} else if msg.Reason != "replaced" {
+				n.trackEnded(msg.GuildID, nil)
+			}
+		case "TrackExceptionEvent":
+			reason := "unknown error"
+			if msg.Exception != nil {
+				reason = msg.Exception.Message
+			}
+			n.trackEnded(msg.GuildID, fmt.Errorf("playback failed: %s", reason))
+		case "TrackStuckEvent":
+			n.trackEnded(msg.GuildID, errors.New("track got stuck"))
+		case "WebSocketClosedEvent":
+			log.Printf("Lavalink voice connection closed in guild %s (code %d, %s)", msg.GuildID, msg.Code, msg.Reason)
+		}
+	}
+}
+
+// trackEnded wakes the Play call waiting in a guild
+func (n *Node) trackEnded(guildID string, err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if end, ok := n.players[guildID]; ok {
+		select {
+		case end <- err:
+		default:
+			// An earlier event already ended the track
+		}
+	}
+}
+
+// disconnected forgets the node session and fails all tracks that were playing on it
+func (n *Node) disconnected() {
+	n.mu.Lock()
+	n.sessionID = ""
+	var guilds []string
+	for guildID := range n.players {
+		guilds = append(guilds, guildID)
+	}
+	n.mu.Unlock()
+
+	for _, guildID := range guilds {
+		n.trackEnded(guildID, ErrNotConnected)
+	}
+}
+
+// onVoiceStateUpdate records the bot's voice session ID
+func (n *Node) onVoiceStateUpdate(s *discordgo.Session, v *discordgo.VoiceStateUpdate) {
+	if s.State.User == nil || v.UserID != s.State.User.ID || v.ChannelID == "" {
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if vs, ok := n.voice[v.GuildID]; ok {
+		vs.sessionID = v.SessionID
+		vs.update()
+	}
+}
+
+// onVoiceServerUpdate records the voice server Discord assigned. If it changes
+// while connected, e.g. after a region change, the node is told to reconnect.
+func (n *Node) onVoiceServerUpdate(s *discordgo.Session, v *discordgo.VoiceServerUpdate) {
+	n.mu.Lock()
+	vs, ok := n.voice[v.GuildID]
+	if !ok {
+		n.mu.Unlock()
+		return
+	}
+	moved := vs.complete
+	vs.token = v.Token
+	vs.endpoint = v.Endpoint
+	vs.update()
+	n.mu.Unlock()
+
+	if moved {
+		if err := n.updateVoice(v.GuildID); err != nil {
+			log.Printf("Failed to move Lavalink voice in guild %s: %v", v.GuildID, err)
+		}
+	}
+}
+
+// Connect joins a voice channel and hands the voice session to the node
+func (n *Node) Connect(guildID, channelID string) error {
+	n.mu.Lock()
+	n.voice[guildID] = &voiceState{ready: make(chan struct{})}
+	ready := n.voice[guildID].ready
+	n.mu.Unlock()
+
+	if err := n.s.ChannelVoiceJoinManual(guildID, channelID, false, true); err != nil {
+		return fmt.Errorf("failed to join voice channel: %v", err)
+	}
+
+	select {
+	case <-ready:
+	case <-time.After(voiceTimeout):
+		return errors.New("timed out waiting for a voice server")
+	}
+	return n.updateVoice(guildID)
+}
+
+// Disconnect leaves the guild's voice channel and destroys its player on the node
+func (n *Node) Disconnect(guildID string) error {
+	n.mu.Lock()
+	delete(n.voice, guildID)
+	delete(n.positions, guildID)
+	sessionID := n.sessionID
+	n.mu.Unlock()
+
+	err := n.s.ChannelVoiceJoinManual(guildID, "", false, true)
+	if sessionID != "" {
+		if destroyErr := n.do(http.MethodDelete, n.playerPath(sessionID, guildID), nil, nil); destroyErr != nil {
+			log.Printf("Failed to destroy Lavalink player in guild %s: %v", guildID, destroyErr)
+		}
+	}
+	return err
+}
+
+// Play loads url on the node and streams it from start, blocking until it ends or stop is signaled
+func (n *Node) Play(guildID, url string, start time.Duration, stop <-chan bool) error {
+	track, err := n.load(url)
+	if err != nil {
+		return err
+	}
+
+	end := make(chan error, 1)
+	n.mu.Lock()
+	n.players[guildID] = end
+	n.positions[guildID] = start
+	n.mu.Unlock()
+	defer func() {
+		n.mu.Lock()
+		if n.players[guildID] == end {
+			delete(n.players, guildID)
+		}
+		n.mu.Unlock()
+	}()
+
+	paused := false
+	position := start.Milliseconds()
+	if err := n.updatePlayer(guildID, playerUpdate{Track: &trackUpdate{Encoded: &track}, Position: &position, Paused: &paused}); err != nil {
+		return err
+	}
+
+	select {
+	case err := <-end:
+		return err
+	case <-stop:
+		if err := n.updatePlayer(guildID, playerUpdate{Track: &trackUpdate{}}); err != nil {
+			log.Printf("Failed to stop Lavalink player in guild %s: %v", guildID, err)
+		}
+		return nil
+	}
+}
+
+// Pause pauses or resumes the guild's player
+func (n *Node) Pause(guildID string, paused bool) error {
+	return n.updatePlayer(guildID, playerUpdate{Paused: &paused})
+}
+
+// Position returns the playback position the node last reported for a guild
+func (n *Node) Position(guildID string) time.Duration {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.positions[guildID]
+}
+
+// loadResult is the response of the loadtracks endpoint
+type loadResult struct {
+	LoadType string          `json:"loadType"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// loadedTrack is a track resolved by the node
+type loadedTrack struct {
+	Encoded string `json:"encoded"`
+}
+
+// load resolves url to an encoded track
+func (n *Node) load(trackURL string) (string, error) {
+	var result loadResult
+	if err := n.do(http.MethodGet, "/v4/loadtracks?identifier="+url.QueryEscape(trackURL), nil, &result); err != nil {
+		return "", err
+	}
+
+	var tracks []loadedTrack
+	switch result.LoadType {
+	case "track":
+		var track loadedTrack
+		if err := json.Unmarshal(result.Data, &track); err != nil {
+			return "", fmt.Errorf("error decoding track: %v", err)
+		}
+		tracks = append(tracks, track)
+	case "playlist":
+		var playlist struct {
+			Tracks []loadedTrack `json:"tracks"`
+		}
+		if err := json.Unmarshal(result.Data, &playlist); err != nil {
+			return "", fmt.Errorf("error decoding playlist: %v", err)
+		}
+		tracks = playlist.Tracks
+	case "search":
+		if err := json.Unmarshal(result.Data, &tracks); err != nil {
+			return "", fmt.Errorf("error decoding search results: %v", err)
+		}
+	case "error":
+		var failure struct {
+			Message string `json:"message"`
+		}
+		json.Unmarshal(result.Data, &failure)
+		return "", fmt.Errorf("the node couldn't load the track: %s", failure.Message)
+	}
+
+	if len(tracks) == 0 {
+		return "", errors.New("the node found nothing to play at that URL")
+	}
+	return tracks[0].Encoded, nil
+}
+
+// trackUpdate sets or clears the playing track. A nil Encoded stops playback.
+type trackUpdate struct {
+	Encoded *string `json:"encoded"`
+}
+
+// voiceUpdate hands a Discord voice session to the node
+type voiceUpdate struct {
+	Token     string `json:"token"`
+	Endpoint  string `json:"endpoint"`
+	SessionID string `json:"sessionId"`
+}
+
+// playerUpdate is the body of the update player endpoint
+type playerUpdate struct {
+	Track    *trackUpdate `json:"track,omitempty"`
+	Position *int64       `json:"position,omitempty"`
+	Paused   *bool        `json:"paused,omitempty"`
+	Voice    *voiceUpdate `json:"voice,omitempty"`
+}
+
+// updateVoice sends the guild's voice session to the node
+func (n *Node) updateVoice(guildID string) error {
+	n.mu.Lock()
+	vs, ok := n.voice[guildID]
+	if !ok || !vs.complete {
+		n.mu.Unlock()
+		return errors.New("no voice session for that guild")
+	}
+	update := voiceUpdate{Token: vs.token, Endpoint: vs.endpoint, SessionID: vs.sessionID}
+	n.mu.Unlock()
+
+	return n.updatePlayer(guildID, playerUpdate{Voice: &update})
+}
+
+// updatePlayer changes the guild's player on the node
+func (n *Node) updatePlayer(guildID string, update playerUpdate) error {
+	n.mu.Lock()
+	sessionID := n.sessionID
+	n.mu.Unlock()
+	if sessionID == "" {
+		return ErrNotConnected
+	}
+	return n.do(http.MethodPatch, n.playerPath(sessionID, guildID), update, nil)
+}
+
+// playerPath returns the REST path of a guild's player
+func (n *Node) playerPath(sessionID, guildID string) string {
+	return "/v4/sessions/" + sessionID + "/players/" + guildID
+}
+
+// do sends a REST request to the node and decodes the response into out, if set
+func (n *Node) do(method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("error encoding request: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	scheme := "http"
+	if n.cfg.Secure {
+		scheme = "https"
+	}
+	req, err := http.NewRequest(method, scheme+"://"+n.cfg.Address+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", n.cfg.Password)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error contacting lavalink: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var failure struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&failure)
+		return fmt.Errorf("lavalink returned %s: %s", resp.Status, failure.Message)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
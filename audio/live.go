@@ -0,0 +1,99 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	// maxLiveReconnects caps how often in a row a live stream may stall
+	// without playing anything before playback gives up on it
+	maxLiveReconnects = 5
+
+	// liveReconnectDelay is the wait before reconnecting to a stalled live stream
+	liveReconnectDelay = 2 * time.Second
+
+	// liveReadTimeout is how long ffmpeg waits on the stream before treating it as stalled
+	liveReadTimeout = 15 * time.Second
+)
+
+// ErrStreamEnded is returned by a StreamResolver once the broadcast is over
+var ErrStreamEnded = errors.New("the live stream has ended")
+
+// StreamResolver returns a fresh URL for a live stream's manifest. Manifest
+// URLs expire, so it is called again on every reconnect.
+type StreamResolver func() (string, error)
+
+// PlayStream plays a live stream through ffmpeg as it airs, without
+// downloading it first. If the stream stalls or drops, it reconnects with a
+// freshly resolved manifest. It blocks until the stream ends, the track is
+// skipped or the stream keeps failing.
+func (vi *VoiceInstance) PlayStream(resolve StreamResolver) error {
+	// Let a message spoken between tracks finish first
+	vi.waitForSpeech()
+
+	vi.Mu.Lock()
+
+	if vi.Connection == nil {
+		vi.Mu.Unlock()
+		return errors.New("not connected to a voice channel")
+	}
+
+	vi.IsPlaying = true
+	vi.Paused = false
+	vi.position = 0
+	vi.mixing = true
+	vc := vi.Connection
+	sender := vi.sender
+	stop := vi.StopChan
+	custom := vi.Bitrate
+	vi.Mu.Unlock()
+	defer vi.stopMixing()
+
+	// Set speaking state
+	err := vc.Speaking(true)
+	if err != nil {
+		return fmt.Errorf("error setting speaking state: %v", err)
+	}
+	defer vc.Speaking(false)
+
+	profile := vi.quality.Profile().withBitrate(custom)
+	failures := 0
+	for {
+		url, err := resolve()
+		if errors.Is(err, ErrStreamEnded) {
+			return nil
+		}
+		if err == nil {
+			// The position keeps counting across reconnects
+			began := vi.Position()
+			var ended bool
+			ended, err = vi.decode(streamInput(url), vi.karaokeFilters(profile.Filters), profile, vc, sender, began, stop)
+			if err != nil {
+				return err
+			}
+			if !ended {
+				// Skipped or stopped
+				return nil
+			}
+			if vi.Position() > began {
+				failures = 0
+			}
+			err = errors.New("the stream stalled")
+		}
+
+		failures++
+		if failures >= maxLiveReconnects {
+			return fmt.Errorf("live stream failed %d times in a row: %v", failures, err)
+		}
+		log.Printf("Live stream in guild %s interrupted (%v), reconnecting", vi.GuildID, err)
+
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(liveReconnectDelay):
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package audio
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// oggReader splits a single Ogg stream into the packets it carries
+type oggReader struct {
+	r       *bufio.Reader
+	packets [][]byte // Complete packets of the current page
+	partial []byte   // Packet continued on the next page
+}
+
+// newOggReader reads Ogg pages from r
+func newOggReader(r io.Reader) *oggReader {
+	return &oggReader{r: bufio.NewReaderSize(r, 16384)}
+}
+
+// ReadPacket returns the next packet of the stream. It returns io.EOF once
+// the stream ends on a page boundary and io.ErrUnexpectedEOF inside a page.
+func (o *oggReader) ReadPacket() ([]byte, error) {
+	for len(o.packets) == 0 {
+		if err := o.readPage(); err != nil {
+			return nil, err
+		}
+	}
+	packet := o.packets[0]
+	o.packets = o.packets[1:]
+	return packet, nil
+}
+
+// readPage reads one page, collecting the packets it completes
+func (o *oggReader) readPage() error {
+	// Capture pattern, version, header type, granule position, serial
+	// number, sequence number, checksum and segment count
+	var header [27]byte
+	if _, err := io.ReadFull(o.r, header[:]); err != nil {
+		return err
+	}
+	if string(header[:4]) != "OggS" {
+		return errors.New("invalid ogg page")
+	}
+
+	segments := make([]byte, header[26])
+	if _, err := io.ReadFull(o.r, segments); err != nil {
+		return unexpectedEOF(err)
+	}
+	for _, size := range segments {
+		segment := make([]byte, size)
+		if _, err := io.ReadFull(o.r, segment); err != nil {
+			return unexpectedEOF(err)
+		}
+		o.partial = append(o.partial, segment...)
+		// A segment shorter than 255 bytes ends its packet
+		if size < 255 {
+			o.packets = append(o.packets, o.partial)
+			o.partial = nil
+		}
+	}
+	return nil
+}
+
+// unexpectedEOF reports a stream that ends in the middle of a page
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,184 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"layeh.com/gopus"
+)
+
+// errPacketDuration means an Opus source uses packets Discord can't take as
+// they are; it has to be transcoded instead
+var errPacketDuration = errors.New("opus packet isn't a single 20ms frame")
+
+// opusPacketDuration returns how much audio an Opus packet holds, read from
+// its TOC byte (RFC 6716, section 3.1)
+func opusPacketDuration(packet []byte) time.Duration {
+	if len(packet) == 0 {
+		return 0
+	}
+
+	var frame time.Duration
+	switch config := packet[0] >> 3; {
+	case config < 12: // SILK
+		frame = [...]time.Duration{10, 20, 40, 60}[config%4] * time.Millisecond
+	case config < 16: // Hybrid
+		frame = [...]time.Duration{10, 20}[config%2] * time.Millisecond
+	default: // CELT
+		frame = [...]time.Duration{2500, 5000, 10000, 20000}[config%4] * time.Microsecond
+	}
+
+	frames := 1
+	switch packet[0] & 3 {
+	case 1, 2:
+		frames = 2
+	case 3:
+		if len(packet) < 2 {
+			return 0
+		}
+		frames = int(packet[1] & 0x3f)
+	}
+	return frame * time.Duration(frames)
+}
+
+// playPassthrough sends the Opus packets of an Opus file to the connection as
+// they are, starting start into it, so nothing is decoded or re-encoded. If
+// the file uses packets Discord can't take, it returns errPacketDuration and
+// the position playback got to, so the rest can be transcoded.
+func (vi *VoiceInstance) playPassthrough(filePath string, start time.Duration, bitrate int, vc *discordgo.VoiceConnection, sender *frameSender, stop chan bool) (time.Duration, error) {
+	// Let ffmpeg demux the file into Ogg without touching the packets
+	stdout, cleanup, err := vi.startFFmpeg([]string{
+		"-ss", fmt.Sprintf("%.3f", start.Seconds()), // Seek before demuxing
+		"-i", filePath, // Input file
+		"-map", "0:a:0", // First audio stream only
+		"-c:a", "copy", // Keep the Opus packets as they are
+		"-loglevel", "warning", // Only show warnings and errors
+		"-f", "ogg", // Ogg is simple to split into packets
+		"pipe:1", // Output to stdout
+	})
+	if err != nil {
+		return start, err
+	}
+	defer cleanup()
+
+	packets := newOggReader(stdout)
+	// Every Ogg Opus stream starts with the OpusHead and OpusTags packets
+	for n := 0; n < 2; n++ {
+		if _, err := packets.ReadPacket(); err != nil {
+			return start, fmt.Errorf("error reading audio data: %v", err)
+		}
+	}
+	return vi.sendPackets(vc, sender, packets, bitrate, false, 1, start, stop)
+}
+
+// playDCA sends the frames of a DCA file to the connection, starting start
+// into it. If transcode is set they are re-encoded at bitrate, as are files
+// encoded at another volume than the one live playback uses.
+func (vi *VoiceInstance) playDCA(filePath string, start time.Duration, bitrate int, transcode bool, vc *discordgo.VoiceConnection, sender *frameSender, stop chan bool) error {
+	frames, err := openDCA(filePath, start)
+	if err != nil {
+		return err
+	}
+	defer frames.Close()
+	gain := playbackGain / frames.gain
+	_, err = vi.sendPackets(vc, sender, frames, bitrate, transcode || gain != 1, gain, start, stop)
+	return err
+}
+
+// packetReader yields Opus packets one at a time
+type packetReader interface {
+	ReadPacket() ([]byte, error)
+}
+
+// sendPackets sends Opus packets to the connection until they run out or
+// playback is stopped, returning the position it got to. Packets go out as
+// they are unless transcode is set, in which case their volume is scaled by
+// gain; frames that speech is mixed into or that karaoke removes the vocals
+// from are always re-encoded at bitrate. A packet that isn't a single 20ms
+// frame ends it with errPacketDuration.
+func (vi *VoiceInstance) sendPackets(vc *discordgo.VoiceConnection, sender *frameSender, packets packetReader, bitrate int, transcode bool, gain float64, start time.Duration, stop chan bool) (time.Duration, error) {
+	coder := &transcoder{bitrate: bitrate, gain: gain}
+	position := start
+	for {
+		if vi.holdFrame(vc, sender, position, stop) {
+			return position, nil
+		}
+
+		packet, err := packets.ReadPacket()
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return position, nil
+		}
+		if err != nil {
+			return position, fmt.Errorf("error reading audio data: %v", err)
+		}
+		if opusPacketDuration(packet) != frameDuration {
+			return position, errPacketDuration
+		}
+		if stopped, err := vi.holdVoice(vc, sender, stop); stopped || err != nil {
+			return position, err
+		}
+
+		// Re-encode at the guild's bitrate, without the vocals for karaoke,
+		// and talk over the music if a message is being spoken
+		if transcode || vi.karaokeOn() || vi.speechPending() {
+			if packet, err = coder.transcode(vi, packet); err != nil {
+				return position, err
+			}
+		}
+
+		if !sender.Send(packet) {
+			return position, errors.New("voice connection closed")
+		}
+		position += frameDuration
+	}
+}
+
+// transcoder re-encodes Opus frames, removing vocals for karaoke and mixing in pending speech
+type transcoder struct {
+	bitrate int
+	gain    float64 // Volume change applied to every frame
+	decoder *gopus.Decoder
+	encoder *gopus.Encoder
+}
+
+// transcode decodes a packet, removes the vocals if karaoke is on, mixes
+// pending speech into it and encodes it again
+func (m *transcoder) transcode(vi *VoiceInstance, packet []byte) ([]byte, error) {
+	if m.decoder == nil {
+		decoder, err := gopus.NewDecoder(48000, channels)
+		if err != nil {
+			return nil, fmt.Errorf("error creating opus decoder: %v", err)
+		}
+		encoder, err := gopus.NewEncoder(48000, channels, gopus.Audio)
+		if err != nil {
+			return nil, fmt.Errorf("error creating opus encoder: %v", err)
+		}
+		encoder.SetBitrate(m.bitrate)
+		m.decoder, m.encoder = decoder, encoder
+	}
+
+	pcm, err := m.decoder.Decode(packet, frameSize, false)
+	if err != nil {
+		return nil, fmt.Errorf("decoding error: %v", err)
+	}
+	frame := make([]int16, frameSize*channels)
+	copy(frame, pcm)
+
+	if m.gain != 1 {
+		for idx, sample := range frame {
+			frame[idx] = clampSample(int32(float64(sample) * m.gain))
+		}
+	}
+	if vi.karaokeOn() {
+		removeVocals(frame)
+	}
+	vi.mixSpeech(frame)
+	opus, err := m.encoder.Encode(frame, frameSize, maxOpusBytes)
+	if err != nil {
+		return nil, fmt.Errorf("encoding error: %v", err)
+	}
+	return opus, nil
+}
@@ -0,0 +1,98 @@
+package audio
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Discord expects 48 kHz stereo
+const (
+	outputSampleRate = 48000
+	outputChannels   = 2
+)
+
+// SourceFormat describes the audio stream of a file
+type SourceFormat struct {
+	Codec         string // e.g. "opus" or "mp3"
+	SampleRate    int
+	Channels      int
+	ChannelLayout string
+}
+
+// ProbeSource reads the codec, sample rate and channel layout of a file's first audio stream using ffprobe
+func ProbeSource(filePath string) (*SourceFormat, error) {
+	output, err := exec.Command("ffprobe",
+		"-v", "error", // Only print errors
+		"-select_streams", "a:0", // First audio stream
+		"-show_entries", "stream=codec_name,sample_rate,channels,channel_layout",
+		"-of", "json", // Machine readable output
+		filePath).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("ffprobe failed: %v\nOutput: %s", err, string(exitErr.Stderr))
+		}
+		return nil, fmt.Errorf("ffprobe failed: %v", err)
+	}
+
+	var probe struct {
+		Streams []struct {
+			CodecName     string `json:"codec_name"`
+			SampleRate    string `json:"sample_rate"`
+			Channels      int    `json:"channels"`
+			ChannelLayout string `json:"channel_layout"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal(output, &probe); err != nil {
+		return nil, fmt.Errorf("failed to decode ffprobe output: %v", err)
+	}
+	if len(probe.Streams) == 0 {
+		return nil, fmt.Errorf("no audio stream in %s", filePath)
+	}
+
+	stream := probe.Streams[0]
+	sampleRate, err := strconv.Atoi(stream.SampleRate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sample rate %q: %v", stream.SampleRate, err)
+	}
+	return &SourceFormat{
+		Codec:         stream.CodecName,
+		SampleRate:    sampleRate,
+		Channels:      stream.Channels,
+		ChannelLayout: stream.ChannelLayout,
+	}, nil
+}
+
+// String formats the source format for logging
+func (f *SourceFormat) String() string {
+	layout := f.ChannelLayout
+	if layout == "" {
+		layout = fmt.Sprintf("%d channels", f.Channels)
+	}
+	return fmt.Sprintf("%d Hz %s", f.SampleRate, layout)
+}
+
+// conversionFilters returns the ffmpeg filters that turn the source into
+// 48 kHz stereo. Nil sources get no extra filters and rely on ffmpeg's defaults.
+func conversionFilters(src *SourceFormat) string {
+	if src == nil {
+		return ""
+	}
+
+	var filters []string
+	switch {
+	case src.Channels == 1:
+		// Put mono sources (podcasts, voice recordings) in the middle at full level
+		filters = append(filters, "pan=stereo|c0=c0|c1=c0")
+	case src.Channels > outputChannels:
+		// Downmix surround sources with the standard matrix, keeping the centre channel
+		filters = append(filters, "aformat=channel_layouts=stereo")
+	}
+	if src.SampleRate != outputSampleRate {
+		// Resample with a longer filter so 44.1 kHz sources don't alias
+		filters = append(filters, fmt.Sprintf("aresample=%d:filter_size=64:cutoff=0.97", outputSampleRate))
+	}
+	return strings.Join(filters, ",")
+}
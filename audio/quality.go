@@ -0,0 +1,201 @@
+package audio
+
+import (
+	"bufio"
+	"context"
+	"expvar"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"layeh.com/gopus"
+)
+
+// QualityProfile describes the encoder settings and filters used for a track
+type QualityProfile struct {
+	Bitrate     int               // Opus bitrate in bits per second
+	Application gopus.Application // Opus encoder mode
+	Filters     string            // ffmpeg audio filter chain
+	Gain        float64           // Volume the filters apply, which Opus packets sent as they are would skip
+	Reduced     bool              // Whether this is the reduced profile used under CPU pressure
+}
+
+// playbackGain is the volume tracks are played at, leaving headroom for speech mixed into them
+const playbackGain = 0.5
+
+var (
+	// normalQuality is used while the host has CPU to spare
+	normalQuality = QualityProfile{
+		Bitrate:     96000,
+		Application: gopus.Audio,
+		Filters:     fmt.Sprintf("volume=%g,aresample=async=1000", playbackGain),
+		Gain:        playbackGain,
+	}
+	// reducedQuality lowers the bitrate, drops the resampler and encodes in
+	// the CELT only low delay mode, which skips the speech codec and the
+	// analysis choosing between the two, to save CPU
+	reducedQuality = QualityProfile{
+		Bitrate:     48000,
+		Application: gopus.RestrictedLowDelay,
+		Filters:     fmt.Sprintf("volume=%g", playbackGain),
+		Gain:        playbackGain,
+		Reduced:     true,
+	}
+)
+
+var (
+	cpuUsageVar          = expvar.NewFloat("cpu_usage")
+	qualityReducedVar    = expvar.NewInt("audio_quality_reduced")
+	qualityReductionsVar = expvar.NewInt("audio_quality_reductions")
+	reducedTracksVar     = expvar.NewInt("audio_quality_reduced_tracks")
+)
+
+// QualityGovernor watches host CPU usage and switches new tracks to a cheaper
+// profile while load stays above the threshold
+type QualityGovernor struct {
+	Threshold float64       // CPU usage fraction (0-1) considered saturated
+	Interval  time.Duration // How often CPU usage is sampled
+	Sustain   int           // Consecutive samples required to change profile
+
+	mu      sync.Mutex
+	reduced bool
+	streak  int
+}
+
+// NewQualityGovernor creates a governor with the given saturation threshold
+func NewQualityGovernor(threshold float64) *QualityGovernor {
+	return &QualityGovernor{
+		Threshold: threshold,
+		Interval:  10 * time.Second,
+		Sustain:   3,
+	}
+}
+
+// withBitrate returns the profile encoding at bitrate instead, unless the
+// profile was reduced below it. Zero keeps the profile's own bitrate.
+func (p QualityProfile) withBitrate(bitrate int) QualityProfile {
+	if bitrate > 0 && !(p.Reduced && p.Bitrate < bitrate) {
+		p.Bitrate = bitrate
+	}
+	return p
+}
+
+// Profile returns the quality profile to use for a new track
+func (q *QualityGovernor) Profile() QualityProfile {
+	if q == nil {
+		return normalQuality
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.reduced {
+		reducedTracksVar.Add(1)
+		return reducedQuality
+	}
+	return normalQuality
+}
+
+// Run samples CPU usage until ctx is canceled
+func (q *QualityGovernor) Run(ctx context.Context) {
+	prevIdle, prevTotal, err := readCPUTimes()
+	if err != nil {
+		log.Printf("CPU monitoring disabled: %v", err)
+		return
+	}
+
+	ticker := time.NewTicker(q.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		idle, total, err := readCPUTimes()
+		if err != nil {
+			log.Printf("Error reading CPU usage: %v", err)
+			continue
+		}
+		if total == prevTotal {
+			continue
+		}
+
+		usage := 1 - float64(idle-prevIdle)/float64(total-prevTotal)
+		prevIdle, prevTotal = idle, total
+		cpuUsageVar.Set(usage)
+		q.observe(usage)
+	}
+}
+
+// observe records a CPU sample and switches profiles after sustained load changes
+func (q *QualityGovernor) observe(usage float64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// Use some hysteresis so the profile doesn't flap around the threshold
+	var crossing bool
+	if q.reduced {
+		crossing = usage < q.Threshold-0.15
+	} else {
+		crossing = usage > q.Threshold
+	}
+
+	if !crossing {
+		q.streak = 0
+		return
+	}
+
+	q.streak++
+	if q.streak < q.Sustain {
+		return
+	}
+
+	q.streak = 0
+	q.reduced = !q.reduced
+	if q.reduced {
+		qualityReducedVar.Set(1)
+		qualityReductionsVar.Add(1)
+		log.Printf("CPU usage at %.0f%%, temporarily reducing audio quality for new tracks (%d kbps, no resampling)",
+			usage*100, reducedQuality.Bitrate/1000)
+	} else {
+		qualityReducedVar.Set(0)
+		log.Printf("CPU usage back to %.0f%%, restoring normal audio quality for new tracks", usage*100)
+	}
+}
+
+// readCPUTimes returns the idle and total jiffies from /proc/stat
+func readCPUTimes() (idle, total uint64, err error) {
+	f, err := os.Open("/proc/stat")
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() {
+		return 0, 0, fmt.Errorf("empty /proc/stat")
+	}
+
+	fields := strings.Fields(scanner.Text())
+	if len(fields) < 5 || fields[0] != "cpu" {
+		return 0, 0, fmt.Errorf("unexpected /proc/stat format")
+	}
+
+	for idx, field := range fields[1:] {
+		value, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid /proc/stat value %q", field)
+		}
+		total += value
+		// idle and iowait
+		if idx == 3 || idx == 4 {
+			idle += value
+		}
+	}
+	return idle, total, nil
+}
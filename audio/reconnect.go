@@ -0,0 +1,96 @@
+package audio
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// voiceRecoveryWait is how long to wait for a dropped voice connection to
+	// become ready again, first on its own and then after each reconnect
+	voiceRecoveryWait = 10 * time.Second
+	// voiceReconnectAttempts is how often the player rejoins the channel itself
+	// before giving up on the track
+	voiceReconnectAttempts = 3
+)
+
+// errVoiceLost is returned when a dropped voice connection couldn't be re-established
+var errVoiceLost = errors.New("voice connection lost and couldn't be re-established")
+
+// voiceReady reports whether vc can carry audio
+func voiceReady(vc *discordgo.VoiceConnection) bool {
+	vc.RLock()
+	defer vc.RUnlock()
+	return vc.Ready && vc.OpusSend != nil
+}
+
+// waitVoiceReady waits up to timeout for vc to become ready. It returns false
+// if it didn't or stop was signaled in the meantime.
+func waitVoiceReady(vc *discordgo.VoiceConnection, stop <-chan bool, timeout time.Duration) (ready, stopped bool) {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	deadline := time.After(timeout)
+
+	for {
+		if voiceReady(vc) {
+			return true, false
+		}
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return false, true
+		case <-deadline:
+			return false, false
+		}
+	}
+}
+
+// recoverVoice brings a dropped voice connection back so the current track can
+// continue from where it stopped. discordgo reconnects by itself after voice
+// server and region changes, so that is waited for first; if the connection
+// stays down, e.g. after a UDP failure discordgo doesn't notice, the channel is
+// rejoined. It returns stopped if playback was stopped while waiting.
+func (vi *VoiceInstance) recoverVoice(vc *discordgo.VoiceConnection, stop <-chan bool) (stopped bool, err error) {
+	log.Printf("Voice connection in guild %s dropped, waiting for it to recover", vi.GuildID)
+
+	ready, stopped := waitVoiceReady(vc, stop, voiceRecoveryWait)
+	if ready || stopped {
+		return stopped, nil
+	}
+
+	for attempt := 1; attempt <= voiceReconnectAttempts; attempt++ {
+		// Don't rejoin if the bot left or was removed in the meantime
+		vi.Mu.Lock()
+		s, channelID, current := vi.session, vi.ChannelID, vi.Connection
+		vi.Mu.Unlock()
+		if current != vc || channelID == "" || s == nil {
+			return true, nil
+		}
+
+		log.Printf("Reconnecting to voice channel %s in guild %s (attempt %d/%d)", channelID, vi.GuildID, attempt, voiceReconnectAttempts)
+		vc.Close()
+		rejoined, err := s.ChannelVoiceJoin(vi.GuildID, channelID, false, true)
+		if err != nil {
+			log.Printf("Failed to reconnect to voice in guild %s: %v", vi.GuildID, err)
+		} else if rejoined != vc {
+			// The session replaced the connection, so the player's sender is stale
+			return false, errVoiceLost
+		}
+
+		ready, stopped := waitVoiceReady(vc, stop, voiceRecoveryWait)
+		if ready || stopped {
+			if ready {
+				log.Printf("Voice connection in guild %s recovered", vi.GuildID)
+				// Rejoining a stage makes the bot a listener again
+				if err := speakOnStage(s, vi.GuildID, channelID); err != nil {
+					log.Printf("Voice in guild %s recovered but stays muted: %v", vi.GuildID, err)
+				}
+			}
+			return stopped, nil
+		}
+	}
+	return false, errVoiceLost
+}
@@ -0,0 +1,118 @@
+package audio
+
+import (
+	"errors"
+	"log"
+	"time"
+)
+
+// Remote is a playback backend that resolves and streams tracks on another
+// server, such as a Lavalink node, instead of running yt-dlp and ffmpeg locally
+type Remote interface {
+	// Connect joins a voice channel and hands the voice session to the backend
+	Connect(guildID, channelID string) error
+	// Disconnect leaves the guild's voice channel and stops its player
+	Disconnect(guildID string) error
+	// Play streams url from start and blocks until it ends or stop is signaled
+	Play(guildID, url string, start time.Duration, stop <-chan bool) error
+	// Pause pauses or resumes the guild's player
+	Pause(guildID string, paused bool) error
+	// Position returns how far into the current track the guild's player is
+	Position(guildID string) time.Duration
+}
+
+// IsRemote reports whether the instance plays through a remote backend
+func (vi *VoiceInstance) IsRemote() bool {
+	return vi.remote != nil
+}
+
+// Connected reports whether the bot is in a voice channel in this guild
+func (vi *VoiceInstance) Connected() bool {
+	vi.Mu.Lock()
+	defer vi.Mu.Unlock()
+	return vi.Connection != nil || vi.remoteJoined
+}
+
+// Ready reports whether the voice connection can carry audio yet
+func (vi *VoiceInstance) Ready() bool {
+	vi.Mu.Lock()
+	defer vi.Mu.Unlock()
+
+	if vi.remote != nil {
+		return vi.remoteJoined
+	}
+	return vi.Connection != nil && vi.Connection.Ready
+}
+
+// joinRemote joins a voice channel through the remote backend. The caller must hold vi.Mu.
+func (vi *VoiceInstance) joinRemote(channelID string) error {
+	if vi.remoteJoined && vi.ChannelID == channelID {
+		log.Printf("Already connected to voice channel %s", channelID)
+		return nil
+	}
+
+	if vi.StopChan == nil {
+		vi.StopChan = make(chan bool, 1)
+	}
+
+	if err := vi.remote.Connect(vi.GuildID, channelID); err != nil {
+		return err
+	}
+	vi.ChannelID = channelID
+	vi.remoteJoined = true
+	return nil
+}
+
+// leaveRemote stops the remote player and leaves the voice channel. The caller must hold vi.Mu.
+func (vi *VoiceInstance) leaveRemote() error {
+	if !vi.remoteJoined {
+		return nil
+	}
+
+	log.Printf("Disconnecting from voice channel %s in guild %s", vi.ChannelID, vi.GuildID)
+
+	// Wake the player so Play returns
+	if vi.StopChan != nil {
+		close(vi.StopChan)
+		vi.StopChan = make(chan bool, 1)
+	}
+
+	err := vi.remote.Disconnect(vi.GuildID)
+
+	vi.remoteJoined = false
+	vi.ChannelID = ""
+	vi.IsPlaying = false
+	vi.Paused = false
+	vi.Current = nil
+	if len(vi.Queue) > 0 {
+		vi.Queue = nil
+		vi.queueChanged()
+	}
+	return err
+}
+
+// pauseRemote forwards a pause or resume to the remote backend, if there is one
+func (vi *VoiceInstance) pauseRemote(paused bool) {
+	if vi.remote == nil {
+		return
+	}
+	if err := vi.remote.Pause(vi.GuildID, paused); err != nil {
+		log.Printf("Failed to pause remote player in guild %s: %v", vi.GuildID, err)
+	}
+}
+
+// PlayRemote streams url through the remote backend, starting start into it.
+// It blocks until the track has finished playing.
+func (vi *VoiceInstance) PlayRemote(url string, start time.Duration) error {
+	vi.Mu.Lock()
+	if !vi.remoteJoined {
+		vi.Mu.Unlock()
+		return errors.New("not connected to a voice channel")
+	}
+	vi.IsPlaying = true
+	vi.Paused = false
+	stop := vi.StopChan
+	vi.Mu.Unlock()
+
+	return vi.remote.Play(vi.GuildID, url, start, stop)
+}
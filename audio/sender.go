@@ -0,0 +1,138 @@
+package audio
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// senderQueueSize is the number of Opus frames buffered per connection (~1s of audio)
+const senderQueueSize = 50
+
+// senderStallTimeout is how long the connection may go without taking a
+// frame before it counts as stalled. Tests shorten it.
+var senderStallTimeout = time.Second
+
+// frameSender writes Opus frames to a voice connection from its own goroutine.
+// A stalled voice socket only blocks this goroutine; the player sees a bounded
+// queue and drops the oldest frames instead of blocking its decode loop.
+type frameSender struct {
+	vc       *discordgo.VoiceConnection
+	frames   chan []byte
+	done     chan struct{}
+	once     sync.Once
+	lastSent atomic.Int64 // When the connection last took a frame, in Unix nanoseconds
+	mu       sync.Mutex
+	dropped  int
+}
+
+// newFrameSender creates a sender for vc and starts its writer goroutine
+func newFrameSender(vc *discordgo.VoiceConnection) *frameSender {
+	fs := &frameSender{
+		vc:     vc,
+		frames: make(chan []byte, senderQueueSize),
+		done:   make(chan struct{}),
+	}
+	fs.lastSent.Store(time.Now().UnixNano())
+	go fs.run()
+	return fs
+}
+
+// run forwards queued frames to the connection until the sender is closed
+func (fs *frameSender) run() {
+	for {
+		select {
+		case frame := <-fs.frames:
+			select {
+			case fs.vc.OpusSend <- frame:
+				fs.lastSent.Store(time.Now().UnixNano())
+			case <-fs.done:
+				return
+			}
+		case <-fs.done:
+			return
+		}
+	}
+}
+
+// Send queues a frame for the connection. A full queue is normal: the
+// connection takes a frame every frameDuration, which paces the player. Once
+// the connection has taken nothing for senderStallTimeout, Send stops
+// waiting for it and drops the oldest queued frame every frameDuration
+// instead, so the player keeps time without blocking on the stalled socket.
+// It returns false once the sender has been closed.
+func (fs *frameSender) Send(frame []byte) bool {
+	// Checked first: a select picks at random when the queue also has room
+	select {
+	case <-fs.done:
+		return false
+	default:
+	}
+	select {
+	case fs.frames <- frame:
+		return true
+	case <-fs.done:
+		return false
+	default:
+	}
+
+	// Wait for the connection until it counts as stalled, then only as long
+	// as the frame would have played
+	wait := frameDuration
+	if idle := time.Since(time.Unix(0, fs.lastSent.Load())); idle < senderStallTimeout {
+		wait = max(senderStallTimeout-idle, frameDuration)
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case fs.frames <- frame:
+		return true
+	case <-fs.done:
+		return false
+	case <-timer.C:
+	}
+
+	// The connection is stalled: drop the oldest frame so audio resumes
+	// from close to the current position once it recovers
+	select {
+	case <-fs.frames:
+		fs.mu.Lock()
+		fs.dropped++
+		dropped := fs.dropped
+		fs.mu.Unlock()
+		if dropped%senderQueueSize == 1 {
+			log.Printf("Warning: voice connection stalled, %d frames dropped so far", dropped)
+		}
+	default:
+	}
+
+	select {
+	case fs.frames <- frame:
+		return true
+	case <-fs.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Flush discards all queued frames, e.g. when playback is stopped
+func (fs *frameSender) Flush() {
+	for {
+		select {
+		case <-fs.frames:
+		default:
+			return
+		}
+	}
+}
+
+// Close stops the writer goroutine. It is safe to call more than once.
+func (fs *frameSender) Close() {
+	fs.once.Do(func() {
+		close(fs.done)
+	})
+}
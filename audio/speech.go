@@ -0,0 +1,219 @@
+package audio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"syscall"
+
+	"github.com/bwmarrin/discordgo"
+	"layeh.com/gopus"
+)
+
+// speechDuck is how loud music stays while speech is mixed over it
+const speechDuck = 0.3
+
+// Speech frames match the player's: 20ms of 48 kHz stereo PCM
+const (
+	speechFrameSize = 960
+	speechChannels  = 2
+)
+
+var (
+	// ErrSpeaking is returned by Say while another message is being spoken
+	ErrSpeaking = errors.New("already speaking")
+	// ErrSpeechRemote is returned by Say when a remote backend plays the audio
+	ErrSpeechRemote = errors.New("speech can't be mixed into remote playback")
+)
+
+// speech is a spoken message being decoded to PCM by ffmpeg
+type speech struct {
+	cmd        *exec.Cmd
+	pcm        *bufio.Reader
+	standalone bool // Played on its own instead of mixed into a track
+	done       chan struct{}
+	once       sync.Once
+}
+
+// startSpeech starts decoding filePath to 48 kHz stereo PCM
+func startSpeech(filePath string) (*speech, error) {
+	cmd := exec.Command("ffmpeg",
+		"-i", filePath,
+		"-f", "s16le",
+		"-ar", "48000",
+		"-ac", "2",
+		"-loglevel", "warning",
+		"pipe:1")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("error creating stdout pipe: %v", err)
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("error starting ffmpeg: %v", err)
+	}
+
+	return &speech{
+		cmd:  cmd,
+		pcm:  bufio.NewReaderSize(stdout, 16384),
+		done: make(chan struct{}),
+	}, nil
+}
+
+// read fills frame with the next samples, padding with silence at the end.
+// It returns false once the speech is over.
+func (sp *speech) read(frame []int16) bool {
+	err := binary.Read(sp.pcm, binary.LittleEndian, frame)
+	if err == io.ErrUnexpectedEOF {
+		// binary.Read leaves a partial frame undefined, so finish on silence
+		for idx := range frame {
+			frame[idx] = 0
+		}
+		return false
+	}
+	return err == nil
+}
+
+// finish stops the decoder and wakes up whoever waits for the speech
+func (sp *speech) finish() {
+	sp.once.Do(func() {
+		if sp.cmd.Process != nil {
+			syscall.Kill(-sp.cmd.Process.Pid, syscall.SIGKILL)
+		}
+		sp.cmd.Wait()
+		close(sp.done)
+	})
+}
+
+// Say speaks the audio file filePath in the voice channel and blocks until it
+// has been spoken. Over a playing track the speech is mixed in while the music
+// is ducked; otherwise it plays on its own and the next track waits for it.
+func (vi *VoiceInstance) Say(filePath string) error {
+	if vi.IsRemote() {
+		return ErrSpeechRemote
+	}
+
+	sp, err := startSpeech(filePath)
+	if err != nil {
+		return err
+	}
+
+	vi.Mu.Lock()
+	if vi.Connection == nil || vi.sender == nil {
+		vi.Mu.Unlock()
+		sp.finish()
+		return errors.New("not connected to a voice channel")
+	}
+	if vi.speech != nil {
+		vi.Mu.Unlock()
+		sp.finish()
+		return ErrSpeaking
+	}
+	sp.standalone = !vi.mixing || vi.Paused
+	vi.speech = sp
+	vc, sender := vi.Connection, vi.sender
+	vi.Mu.Unlock()
+
+	if sp.standalone {
+		return vi.speakAlone(sp, vc, sender)
+	}
+	<-sp.done
+	return nil
+}
+
+// speakAlone plays speech while no track is playing
+func (vi *VoiceInstance) speakAlone(sp *speech, vc *discordgo.VoiceConnection, sender *frameSender) error {
+	defer vi.endSpeech(sp)
+
+	encoder, err := gopus.NewEncoder(48000, speechChannels, gopus.Voip)
+	if err != nil {
+		return fmt.Errorf("error creating opus encoder: %v", err)
+	}
+
+	vc.Speaking(true)
+	defer vc.Speaking(false)
+
+	for {
+		frame := make([]int16, speechFrameSize*speechChannels)
+		more := sp.read(frame)
+		opus, err := encoder.Encode(frame, speechFrameSize, speechFrameSize*4)
+		if err != nil {
+			return fmt.Errorf("encoding error: %v", err)
+		}
+		if !sender.Send(opus) {
+			return errors.New("voice connection closed")
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+// mixSpeech mixes the next frame of pending speech into a music frame,
+// ducking the music underneath it
+func (vi *VoiceInstance) mixSpeech(frame []int16) {
+	vi.Mu.Lock()
+	sp := vi.speech
+	vi.Mu.Unlock()
+	if sp == nil || sp.standalone {
+		return
+	}
+
+	voice := make([]int16, len(frame))
+	more := sp.read(voice)
+	for idx := range frame {
+		mixed := int32(float64(frame[idx])*speechDuck) + int32(voice[idx])
+		if mixed > 32767 {
+			mixed = 32767
+		} else if mixed < -32768 {
+			mixed = -32768
+		}
+		frame[idx] = int16(mixed)
+	}
+	if !more {
+		vi.endSpeech(sp)
+	}
+}
+
+// speechPending reports whether speech is waiting to be mixed into the track
+func (vi *VoiceInstance) speechPending() bool {
+	vi.Mu.Lock()
+	defer vi.Mu.Unlock()
+	return vi.speech != nil && !vi.speech.standalone
+}
+
+// waitForSpeech blocks until speech playing on its own has finished, so a
+// new track doesn't talk over it
+func (vi *VoiceInstance) waitForSpeech() {
+	vi.Mu.Lock()
+	sp := vi.speech
+	vi.Mu.Unlock()
+	if sp != nil && sp.standalone {
+		<-sp.done
+	}
+}
+
+// stopMixing ends speech mixed into a track when the track stops
+func (vi *VoiceInstance) stopMixing() {
+	vi.Mu.Lock()
+	vi.mixing = false
+	sp := vi.speech
+	vi.Mu.Unlock()
+	if sp != nil && !sp.standalone {
+		vi.endSpeech(sp)
+	}
+}
+
+// endSpeech forgets finished speech and releases its decoder
+func (vi *VoiceInstance) endSpeech(sp *speech) {
+	vi.Mu.Lock()
+	if vi.speech == sp {
+		vi.speech = nil
+	}
+	vi.Mu.Unlock()
+	sp.finish()
+}
@@ -0,0 +1,38 @@
+package spotify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// IsExplicit reports whether Spotify flags a track as explicit
+func (c *Client) IsExplicit(trackID string) (bool, error) {
+	track, err := c.GetTrackInfo(trackID)
+	if err != nil {
+		return false, err
+	}
+	return track.Explicit, nil
+}
+
+// MatchExplicit looks up the Spotify track best matching a title, e.g. a
+// YouTube video's, and reports whether it is flagged explicit. It only counts
+// as a match if the Spotify track's name appears in the title, so unrelated
+// search results never mark a clean title as explicit.
+func (c *Client) MatchExplicit(title string) (bool, error) {
+	result, err := c.SpotifyClient.Search(context.Background(), title, spotify.SearchTypeTrack, spotify.Limit(1))
+	if err != nil {
+		return false, fmt.Errorf("failed to search Spotify: %v", err)
+	}
+	if result.Tracks == nil || len(result.Tracks.Tracks) == 0 {
+		return false, nil
+	}
+
+	track := result.Tracks.Tracks[0]
+	if !strings.Contains(strings.ToLower(title), strings.ToLower(track.Name)) {
+		return false, nil
+	}
+	return track.Explicit, nil
+}
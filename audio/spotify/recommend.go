@@ -0,0 +1,58 @@
+package spotify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// Recommendations returns up to limit tracks Spotify recommends for the track
+// that best matches query, formatted as "Artist - Title"
+func (c *Client) Recommendations(query string, limit int) ([]string, error) {
+	ctx := context.Background()
+
+	result, err := c.SpotifyClient.Search(ctx, query, spotify.SearchTypeTrack, spotify.Limit(1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search Spotify: %v", err)
+	}
+	if result.Tracks == nil || len(result.Tracks.Tracks) == 0 {
+		return nil, fmt.Errorf("no Spotify track matches %q", query)
+	}
+
+	seeds := spotify.Seeds{Tracks: []spotify.ID{result.Tracks.Tracks[0].ID}}
+	return c.recommend(ctx, seeds, limit)
+}
+
+// TrackRecommendations returns up to limit tracks Spotify recommends for a
+// known Spotify track, seeded with the track and its main artist
+func (c *Client) TrackRecommendations(trackID string, limit int) ([]string, error) {
+	track, err := c.GetTrackInfo(trackID)
+	if err != nil {
+		return nil, err
+	}
+
+	seeds := spotify.Seeds{Tracks: []spotify.ID{track.ID}}
+	if len(track.Artists) > 0 {
+		seeds.Artists = []spotify.ID{track.Artists[0].ID}
+	}
+	return c.recommend(context.Background(), seeds, limit)
+}
+
+// recommend asks Spotify for recommendations and formats them as "Artist - Title"
+func (c *Client) recommend(ctx context.Context, seeds spotify.Seeds, limit int) ([]string, error) {
+	recs, err := c.SpotifyClient.GetRecommendations(ctx, seeds, nil, spotify.Limit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recommendations: %v", err)
+	}
+
+	names := make([]string, 0, len(recs.Tracks))
+	for _, track := range recs.Tracks {
+		if len(track.Artists) == 0 {
+			names = append(names, track.Name)
+			continue
+		}
+		names = append(names, fmt.Sprintf("%s - %s", track.Artists[0].Name, track.Name))
+	}
+	return names, nil
+}
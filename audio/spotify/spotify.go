@@ -4,14 +4,15 @@ import (
 	"context"
 	"fmt"
 	"net/url"
-	"os"
 	"regexp"
 
 	"discordbot/audio/youtube"
+	"discordbot/config"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/zmb3/spotify/v2"
 	spotifyauth "github.com/zmb3/spotify/v2/auth"
+	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
 )
 
@@ -19,16 +20,19 @@ import (
 type Client struct {
 	SpotifyClient *spotify.Client
 	YouTubeClient *youtube.Client
+	tokens        oauth2.TokenSource
 }
 
-// NewClient creates a new Spotify client
+// NewClient creates a new Spotify client. It doesn't contact Spotify: a token
+// is fetched on first use and refreshed when it expires, so an unreachable
+// Spotify API only fails the requests made while it is down.
 func NewClient(ytClient *youtube.Client) (*Client, error) {
 	// Get Spotify credentials from environment
-	clientID := os.Getenv("SPOTIFY_ID")
-	clientSecret := os.Getenv("SPOTIFY_SECRET")
+	clientID := config.String("SPOTIFY_ID", "")
+	clientSecret := config.String("SPOTIFY_SECRET", "")
 
 	if clientID == "" || clientSecret == "" {
-		return nil, fmt.Errorf("SPOTIFY_ID and SPOTIFY_SECRET must be set in .env file")
+		return nil, fmt.Errorf("SPOTIFY_ID and SPOTIFY_SECRET must be set")
 	}
 
 	// Set up the Spotify client
@@ -38,20 +42,25 @@ func NewClient(ytClient *youtube.Client) (*Client, error) {
 		TokenURL:     spotifyauth.TokenURL,
 	}
 
-	token, err := config.Token(context.Background())
-	if err != nil {
-		return nil, fmt.Errorf("couldn't get Spotify token: %v", err)
-	}
-
-	httpClient := spotifyauth.New().Client(context.Background(), token)
-	client := spotify.New(httpClient)
+	tokens := config.TokenSource(context.Background())
+	client := spotify.New(oauth2.NewClient(context.Background(), tokens))
 
 	return &Client{
 		SpotifyClient: client,
 		YouTubeClient: ytClient,
+		tokens:        tokens,
 	}, nil
 }
 
+// Authenticate fetches a token ahead of the first request, so credential
+// problems show up at startup instead of on a user's request
+func (c *Client) Authenticate() error {
+	if _, err := c.tokens.Token(); err != nil {
+		return fmt.Errorf("couldn't get Spotify token: %v", err)
+	}
+	return nil
+}
+
 // GetTrackID extracts the track ID from a Spotify URL
 func (c *Client) GetTrackID(url string) (string, error) {
 	// Regular expressions to match Spotify URL patterns
@@ -0,0 +1,180 @@
+package spotify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"discordbot/audio/catalog"
+	"discordbot/config"
+
+	"github.com/zmb3/spotify/v2"
+	spotifyauth "github.com/zmb3/spotify/v2/auth"
+	"golang.org/x/oauth2"
+)
+
+var (
+	// ErrPlaylistNotFound is returned when a user has no playlist with the requested name
+	ErrPlaylistNotFound = errors.New("no Spotify playlist with that name")
+	// ErrLinkRevoked is returned when Spotify no longer accepts a user's
+	// token, usually because they removed the bot's access
+	ErrLinkRevoked = errors.New("Spotify access was revoked")
+)
+
+// userScopes are the permissions asked for when a user links their account:
+// reading their Liked Songs and the playlists they own or follow
+var userScopes = []string{
+	spotifyauth.ScopeUserLibraryRead,
+	spotifyauth.ScopePlaylistReadPrivate,
+	spotifyauth.ScopePlaylistReadCollaborative,
+}
+
+// UserAuth links users' Spotify accounts with the OAuth2 authorization code flow
+type UserAuth struct {
+	auth *spotifyauth.Authenticator
+}
+
+// NewUserAuth creates an authenticator for the app in SPOTIFY_ID and
+// SPOTIFY_SECRET. redirectURL must be registered with the app.
+func NewUserAuth(redirectURL string) (*UserAuth, error) {
+	clientID := config.String("SPOTIFY_ID", "")
+	clientSecret := config.String("SPOTIFY_SECRET", "")
+	if clientID == "" || clientSecret == "" {
+		return nil, fmt.Errorf("SPOTIFY_ID and SPOTIFY_SECRET must be set")
+	}
+
+	return &UserAuth{auth: spotifyauth.New(
+		spotifyauth.WithClientID(clientID),
+		spotifyauth.WithClientSecret(clientSecret),
+		spotifyauth.WithRedirectURL(redirectURL),
+		spotifyauth.WithScopes(userScopes...),
+	)}, nil
+}
+
+// AuthURL returns the Spotify page where a user grants access. state comes
+// back to the redirect URL and ties the callback to the user.
+func (a *UserAuth) AuthURL(state string) string {
+	return a.auth.AuthURL(state)
+}
+
+// Exchange trades the code Spotify sent to the redirect URL for a token
+func (a *UserAuth) Exchange(ctx context.Context, code string) (*oauth2.Token, error) {
+	token, err := a.auth.Exchange(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to link Spotify account: %v", err)
+	}
+	return token, nil
+}
+
+// UserClient reads the library of one linked Spotify account
+type UserClient struct {
+	client *spotify.Client
+}
+
+// Client returns a client acting as the user token belongs to. The token is
+// refreshed when it expires; Token returns the current one so it can be saved.
+func (a *UserAuth) Client(ctx context.Context, token *oauth2.Token) *UserClient {
+	return &UserClient{client: spotify.New(a.auth.Client(ctx, token))}
+}
+
+// Token returns the user's current token, which may have been refreshed
+func (c *UserClient) Token() (*oauth2.Token, error) {
+	return c.client.Token()
+}
+
+// DisplayName returns the name of the linked Spotify account
+func (c *UserClient) DisplayName(ctx context.Context) (string, error) {
+	user, err := c.client.CurrentUser(ctx)
+	if err != nil {
+		return "", userError(err)
+	}
+	if user.DisplayName != "" {
+		return user.DisplayName, nil
+	}
+	return user.ID, nil
+}
+
+// LikedSongs returns up to limit of the user's Liked Songs, most recently liked first
+func (c *UserClient) LikedSongs(ctx context.Context, limit int) ([]catalog.Song, error) {
+	var songs []catalog.Song
+	page, err := c.client.CurrentUsersTracks(ctx, spotify.Limit(min(limit, 50)))
+	for err == nil {
+		for _, saved := range page.Tracks {
+			songs = append(songs, song(saved.FullTrack))
+			if len(songs) >= limit {
+				return songs, nil
+			}
+		}
+		err = c.client.NextPage(ctx, page)
+	}
+	if err != spotify.ErrNoMorePages {
+		return nil, userError(err)
+	}
+	return songs, nil
+}
+
+// Playlist returns up to limit songs of the user's playlist whose name
+// matches, ignoring case, along with the playlist's exact name
+func (c *UserClient) Playlist(ctx context.Context, name string, limit int) (string, []catalog.Song, error) {
+	var found *spotify.SimplePlaylist
+	page, err := c.client.CurrentUsersPlaylists(ctx, spotify.Limit(50))
+	for err == nil && found == nil {
+		for idx := range page.Playlists {
+			if strings.EqualFold(strings.TrimSpace(page.Playlists[idx].Name), strings.TrimSpace(name)) {
+				found = &page.Playlists[idx]
+				break
+			}
+		}
+		if found == nil {
+			err = c.client.NextPage(ctx, page)
+		}
+	}
+	if found == nil {
+		if err == spotify.ErrNoMorePages {
+			return "", nil, ErrPlaylistNotFound
+		}
+		return "", nil, userError(err)
+	}
+
+	var songs []catalog.Song
+	items, err := c.client.GetPlaylistItems(ctx, found.ID, spotify.Limit(min(limit, 100)))
+	for err == nil {
+		for _, item := range items.Items {
+			// Episodes and local files have no track to match
+			if item.Track.Track == nil || item.IsLocal {
+				continue
+			}
+			songs = append(songs, song(*item.Track.Track))
+			if len(songs) >= limit {
+				return found.Name, songs, nil
+			}
+		}
+		err = c.client.NextPage(ctx, items)
+	}
+	if err != spotify.ErrNoMorePages {
+		return "", nil, userError(err)
+	}
+	return found.Name, songs, nil
+}
+
+// song converts a Spotify track into a song to look for on YouTube
+func song(track spotify.FullTrack) catalog.Song {
+	s := catalog.Song{
+		Title:    track.Name,
+		Duration: track.TimeDuration(),
+	}
+	if len(track.Artists) > 0 {
+		s.Artist = track.Artists[0].Name
+	}
+	return s
+}
+
+// userError reports a refused token refresh as ErrLinkRevoked
+func userError(err error) error {
+	var retrieve *oauth2.RetrieveError
+	if errors.As(err, &retrieve) {
+		return ErrLinkRevoked
+	}
+	return fmt.Errorf("failed to read Spotify library: %v", err)
+}
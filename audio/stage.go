@@ -0,0 +1,28 @@
+package audio
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// speakOnStage makes the bot a speaker if channelID is a stage channel.
+// Everyone joins a stage as a listener, so without this nobody would hear
+// the bot. Becoming a speaker directly takes the Mute Members permission.
+func speakOnStage(s *discordgo.Session, guildID, channelID string) error {
+	if s == nil || s.State == nil {
+		return nil
+	}
+	channel, err := s.State.Channel(channelID)
+	if err != nil || channel.Type != discordgo.ChannelTypeGuildStageVoice {
+		return nil
+	}
+
+	endpoint := discordgo.EndpointGuild(guildID) + "/voice-states/@me"
+	state := map[string]interface{}{"channel_id": channelID, "suppress": false}
+	if _, err := s.RequestWithBucketID(http.MethodPatch, endpoint, state, endpoint); err != nil {
+		return fmt.Errorf("failed to become a speaker on the stage: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package audio
+
+import "time"
+
+// Track represents a queued item along with who requested it
+type Track struct {
+	URL         string
+	Title       string
+	Duration    time.Duration
+	RequesterID string
+	Requester   string
+	AddedAt     time.Time
+	Live        bool   // Streamed as it airs instead of downloaded
+	Loops       int    // How many more times the track plays after this time
+	Plugin      string // Plugin that resolves the track's audio; empty for built-in sources
+}
+
+// DisplayName returns the track title, falling back to the URL
+func (t *Track) DisplayName() string {
+	if t.Title != "" {
+		return t.Title
+	}
+	return t.URL
+}
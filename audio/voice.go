@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"os"
 	"os/exec"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -18,22 +20,65 @@ import (
 
 // VoiceInstance represents a voice connection to a Discord guild
 type VoiceInstance struct {
-	GuildID    string
-	ChannelID  string
-	Connection *discordgo.VoiceConnection
-	IsPlaying  bool
-	Repeat     bool
-	Autoplay   bool
-	CurrentURL string
-	Queue      []string
-	Mu         sync.Mutex
-	StopChan   chan bool
+	GuildID       string
+	ChannelID     string
+	TextChannelID string // Channel that receives playback messages
+	Connection    *discordgo.VoiceConnection
+	IsPlaying     bool
+	Paused        bool
+	Repeat        bool
+	Autoplay      bool
+	Karaoke       bool // Remove center-panned vocals from the music
+	Bitrate       int  // Opus bitrate in bits per second chosen for the guild; 0 uses the default
+	Current       *Track
+	Queue         []*Track
+	History       []*Track // Recently played tracks, oldest first
+	Mu            sync.Mutex
+	StopChan      chan bool
+	resume        chan struct{} // Closed when a paused track resumes
+	sender        *frameSender
+	ffmpeg        *os.Process   // The running player's ffmpeg, if any
+	position      time.Duration // How far into the current track playback is
+	remote        Remote        // Plays through a remote backend instead of ffmpeg if set
+	remoteJoined  bool
+	session       *discordgo.Session // Used to rejoin after the voice connection drops
+	speech        *speech            // Message being spoken, if any
+	mixing        bool               // Whether a track's decode loop is mixing in speech
+	quality       *QualityGovernor
+	passthrough   bool // Send Opus sources without transcoding them
+	onQueueChange func(guildID string, queue []*Track)
+}
+
+const (
+	// frameSize is the number of samples per channel in one Opus frame
+	frameSize = 960
+	// channels is the number of audio channels sent to Discord
+	channels = 2
+	// maxOpusBytes caps the size of one encoded frame
+	maxOpusBytes = frameSize * 4
+	// frameDuration is how much audio one frame holds at 48 kHz
+	frameDuration = 20 * time.Millisecond
+)
+
+// frameEncoder turns PCM frames into Opus; *gopus.Encoder implements it
+type frameEncoder interface {
+	Encode(pcm []int16, frameSize, maxDataBytes int) ([]byte, error)
 }
 
 // VoiceManager manages voice connections
 type VoiceManager struct {
 	Instances map[string]*VoiceInstance
 	Mu        sync.Mutex
+	Quality   *QualityGovernor // Optional; lowers quality under CPU pressure
+	Remote    Remote           // Optional; streams through e.g. Lavalink instead of ffmpeg
+
+	// Passthrough sends the packets of Opus sources to Discord as they are
+	// instead of decoding and re-encoding them
+	Passthrough bool
+
+	// OnQueueChange is called with a copy of a guild's queue whenever it changes.
+	// It runs while the instance is locked and must not block.
+	OnQueueChange func(guildID string, queue []*Track)
 }
 
 // NewVoiceManager creates a new voice manager
@@ -74,6 +119,13 @@ func (vm *VoiceManager) Cleanup() {
 	}
 }
 
+// Remove forgets the voice instance of a guild, e.g. after the bot left the guild
+func (vm *VoiceManager) Remove(guildID string) {
+	vm.Mu.Lock()
+	defer vm.Mu.Unlock()
+	delete(vm.Instances, guildID)
+}
+
 // GetVoiceInstance gets or creates a voice instance for a guild
 func (vm *VoiceManager) GetVoiceInstance(guildID string) *VoiceInstance {
 	vm.Mu.Lock()
@@ -84,8 +136,12 @@ func (vm *VoiceManager) GetVoiceInstance(guildID string) *VoiceInstance {
 	}
 
 	instance := &VoiceInstance{
-		GuildID:  guildID,
-		StopChan: make(chan bool),
+		GuildID:       guildID,
+		StopChan:      make(chan bool, 1),
+		quality:       vm.Quality,
+		passthrough:   vm.Passthrough,
+		remote:        vm.Remote,
+		onQueueChange: vm.OnQueueChange,
 	}
 	vm.Instances[guildID] = instance
 	return instance
@@ -98,6 +154,13 @@ func (vi *VoiceInstance) Join(s *discordgo.Session, channelID string) error {
 
 	log.Printf("Attempting to join voice channel %s in guild %s", channelID, vi.GuildID)
 
+	if vi.remote != nil {
+		if err := vi.joinRemote(channelID); err != nil {
+			return err
+		}
+		return speakOnStage(s, vi.GuildID, channelID)
+	}
+
 	// If we're already connected to this channel, do nothing
 	if vi.Connection != nil && vi.ChannelID == channelID {
 		log.Printf("Already connected to voice channel %s", channelID)
@@ -113,6 +176,7 @@ func (vi *VoiceInstance) Join(s *discordgo.Session, channelID string) error {
 	if vi.Connection != nil {
 		log.Printf("Leaving current voice channel %s", vi.ChannelID)
 		// Don't use vi.Leave() here to avoid deadlock
+		vi.closeSender()
 		if err := vi.Connection.Disconnect(); err != nil {
 			log.Printf("Error disconnecting from current channel: %v", err)
 		}
@@ -129,7 +193,9 @@ func (vi *VoiceInstance) Join(s *discordgo.Session, channelID string) error {
 
 	// Initialize voice connection properties
 	vi.Connection = vc
+	vi.session = s
 	vi.ChannelID = channelID
+	vi.sender = newFrameSender(vc)
 
 	// Wait for voice connection to be ready
 	timeout := time.After(5 * time.Second)
@@ -141,11 +207,12 @@ func (vi *VoiceInstance) Join(s *discordgo.Session, channelID string) error {
 		case <-ticker.C:
 			if vc.Ready {
 				log.Printf("Successfully connected to voice channel %s", channelID)
-				return nil
+				return speakOnStage(s, vi.GuildID, channelID)
 			}
 		case <-timeout:
 			log.Printf("Timed out waiting for voice connection to be ready")
 			// Clean up the failed connection
+			vi.closeSender()
 			if err := vc.Disconnect(); err != nil {
 				log.Printf("Error cleaning up failed voice connection: %v", err)
 			}
@@ -161,6 +228,10 @@ func (vi *VoiceInstance) Leave() error {
 	vi.Mu.Lock()
 	defer vi.Mu.Unlock()
 
+	if vi.remote != nil {
+		return vi.leaveRemote()
+	}
+
 	if vi.Connection == nil {
 		return nil // Already disconnected
 	}
@@ -186,6 +257,9 @@ func (vi *VoiceInstance) Leave() error {
 		vi.StopChan = make(chan bool, 1)
 	}
 
+	// Stop the frame writer before tearing down the connection
+	vi.closeSender()
+
 	// Disconnect from voice with a timeout
 	done := make(chan struct{})
 	var err error
@@ -210,37 +284,325 @@ func (vi *VoiceInstance) Leave() error {
 	vi.Connection = nil
 	vi.ChannelID = ""
 	vi.IsPlaying = false
-	vi.CurrentURL = ""
-	vi.Queue = nil
+	vi.Paused = false
+	vi.Current = nil
+	if len(vi.Queue) > 0 {
+		vi.Queue = nil
+		vi.queueChanged()
+	}
 
 	log.Printf("Successfully left voice channel in guild %s", vi.GuildID)
 	return nil
 }
 
-// AddToQueue adds a URL to the queue
-func (vi *VoiceInstance) AddToQueue(url string) {
+// Release tears down a voice connection Discord has already closed, e.g. after
+// the bot was kicked from the channel or removed from the guild. Unlike Leave it
+// doesn't wait for the player to notice: ffmpeg is killed right away and the
+// queue is dropped, so the caller should save the session first.
+func (vi *VoiceInstance) Release() {
+	vi.Mu.Lock()
+	defer vi.Mu.Unlock()
+
+	if vi.remote != nil {
+		if err := vi.leaveRemote(); err != nil {
+			log.Printf("Error releasing remote player in guild %s: %v", vi.GuildID, err)
+		}
+		return
+	}
+
+	if vi.Connection == nil {
+		return
+	}
+
+	log.Printf("Releasing voice connection in guild %s", vi.GuildID)
+
+	// Wake the player wherever it is waiting and stop decoding immediately
+	if vi.StopChan != nil {
+		close(vi.StopChan)
+		vi.StopChan = make(chan bool, 1)
+	}
+	if vi.ffmpeg != nil {
+		syscall.Kill(-vi.ffmpeg.Pid, syscall.SIGKILL)
+	}
+	vi.closeSender()
+
+	// The gateway already dropped us, so only close the local sockets
+	go vi.Connection.Close()
+
+	vi.Connection = nil
+	vi.ChannelID = ""
+	vi.IsPlaying = false
+	vi.Paused = false
+	vi.Current = nil
+	if len(vi.Queue) > 0 {
+		vi.Queue = nil
+		vi.queueChanged()
+	}
+}
+
+// closeSender stops the frame writer of the current connection. The caller must hold vi.Mu.
+func (vi *VoiceInstance) closeSender() {
+	if vi.sender != nil {
+		vi.sender.Close()
+		vi.sender = nil
+	}
+}
+
+// queueChanged reports the current queue to the change hook. The caller must hold vi.Mu.
+func (vi *VoiceInstance) queueChanged() {
+	if vi.onQueueChange != nil {
+		vi.onQueueChange(vi.GuildID, append([]*Track(nil), vi.Queue...))
+	}
+}
+
+// AddToQueue adds a track to the queue
+func (vi *VoiceInstance) AddToQueue(track *Track) {
+	vi.Mu.Lock()
+	defer vi.Mu.Unlock()
+	vi.Queue = append(vi.Queue, track)
+	vi.queueChanged()
+}
+
+// InsertIntoQueue inserts tracks at index in the queue. The index is clamped
+// to the queue bounds, so 0 means "play next" and len(queue) means "play last".
+func (vi *VoiceInstance) InsertIntoQueue(index int, tracks ...*Track) {
 	vi.Mu.Lock()
 	defer vi.Mu.Unlock()
-	vi.Queue = append(vi.Queue, url)
+
+	if index < 0 {
+		index = 0
+	}
+	if index > len(vi.Queue) {
+		index = len(vi.Queue)
+	}
+
+	queue := make([]*Track, 0, len(vi.Queue)+len(tracks))
+	queue = append(queue, vi.Queue[:index]...)
+	queue = append(queue, tracks...)
+	queue = append(queue, vi.Queue[index:]...)
+	vi.Queue = queue
+	vi.queueChanged()
+}
+
+// RemoveFromQueue removes every queued track for which match returns true
+// and returns the removed tracks
+func (vi *VoiceInstance) RemoveFromQueue(match func(*Track) bool) []*Track {
+	vi.Mu.Lock()
+	defer vi.Mu.Unlock()
+
+	var removed []*Track
+	kept := vi.Queue[:0]
+	for _, track := range vi.Queue {
+		if match(track) {
+			removed = append(removed, track)
+		} else {
+			kept = append(kept, track)
+		}
+	}
+	vi.Queue = kept
+	if len(removed) > 0 {
+		vi.queueChanged()
+	}
+	return removed
+}
+
+// Skip stops the current track so playback moves on to the next one.
+// It returns false if nothing is playing.
+func (vi *VoiceInstance) Skip() bool {
+	vi.Mu.Lock()
+	defer vi.Mu.Unlock()
+
+	if !vi.IsPlaying || vi.StopChan == nil {
+		return false
+	}
+
+	select {
+	case vi.StopChan <- true:
+	default:
+		// A stop signal is already pending
+	}
+	return true
+}
+
+// Pause holds the current track at its position until Resume is called.
+// It returns false if nothing is playing or playback is already paused.
+func (vi *VoiceInstance) Pause() bool {
+	vi.Mu.Lock()
+	if !vi.IsPlaying || vi.Paused {
+		vi.Mu.Unlock()
+		return false
+	}
+	vi.Paused = true
+	vi.resume = make(chan struct{})
+	vi.Mu.Unlock()
+
+	vi.pauseRemote(true)
+	return true
+}
+
+// Resume continues a paused track. It returns false if playback isn't paused.
+func (vi *VoiceInstance) Resume() bool {
+	vi.Mu.Lock()
+	if !vi.Paused {
+		vi.Mu.Unlock()
+		return false
+	}
+	vi.Paused = false
+	close(vi.resume)
+	vi.Mu.Unlock()
+
+	vi.pauseRemote(false)
+	return true
+}
+
+// MoveInQueue moves the queued track at index from to index to.
+// It returns false if either index is out of range.
+func (vi *VoiceInstance) MoveInQueue(from, to int) bool {
+	vi.Mu.Lock()
+	defer vi.Mu.Unlock()
+
+	if from < 0 || from >= len(vi.Queue) || to < 0 || to >= len(vi.Queue) {
+		return false
+	}
+
+	track := vi.Queue[from]
+	vi.Queue = append(vi.Queue[:from], vi.Queue[from+1:]...)
+	vi.Queue = append(vi.Queue[:to], append([]*Track{track}, vi.Queue[to:]...)...)
+	vi.queueChanged()
+	return true
+}
+
+// Move switches the bot to another voice channel without interrupting playback.
+// The voice connection is kept, so the current track carries on in the new channel.
+func (vi *VoiceInstance) Move(channelID string) error {
+	vi.Mu.Lock()
+	defer vi.Mu.Unlock()
+
+	if vi.remote != nil {
+		return vi.joinRemote(channelID)
+	}
+	if vi.Connection == nil {
+		return errors.New("not connected to a voice channel")
+	}
+	if vi.ChannelID == channelID {
+		return nil
+	}
+
+	log.Printf("Moving from voice channel %s to %s in guild %s", vi.ChannelID, channelID, vi.GuildID)
+	if err := vi.Connection.ChangeChannel(channelID, false, true); err != nil {
+		return fmt.Errorf("failed to move to voice channel: %v", err)
+	}
+	vi.ChannelID = channelID
+	return speakOnStage(vi.session, vi.GuildID, channelID)
+}
+
+// ReverseQueue inverts the order of the queued tracks and returns how many there are
+func (vi *VoiceInstance) ReverseQueue() int {
+	vi.Mu.Lock()
+	defer vi.Mu.Unlock()
+
+	for left, right := 0, len(vi.Queue)-1; left < right; left, right = left+1, right-1 {
+		vi.Queue[left], vi.Queue[right] = vi.Queue[right], vi.Queue[left]
+	}
+	if len(vi.Queue) > 1 {
+		vi.queueChanged()
+	}
+	return len(vi.Queue)
+}
+
+// RemoveAt removes the queued track at index and returns it
+func (vi *VoiceInstance) RemoveAt(index int) (*Track, bool) {
+	vi.Mu.Lock()
+	defer vi.Mu.Unlock()
+
+	if index < 0 || index >= len(vi.Queue) {
+		return nil, false
+	}
+
+	track := vi.Queue[index]
+	vi.Queue = append(vi.Queue[:index], vi.Queue[index+1:]...)
+	vi.queueChanged()
+	return track, true
 }
 
 // GetNextFromQueue gets the next item from the queue
-func (vi *VoiceInstance) GetNextFromQueue() (string, bool) {
+func (vi *VoiceInstance) GetNextFromQueue() (*Track, bool) {
 	vi.Mu.Lock()
 	defer vi.Mu.Unlock()
 
 	if len(vi.Queue) == 0 {
-		return "", false
+		return nil, false
 	}
 
-	url := vi.Queue[0]
+	track := vi.Queue[0]
 	vi.Queue = vi.Queue[1:]
-	vi.CurrentURL = url
-	return url, true
+	vi.Current = track
+	vi.queueChanged()
+	return track, true
 }
 
-// PlayAudio plays audio from a file using ffmpeg to convert and play the audio
+// maxHistory is how many recently played tracks a voice instance remembers
+const maxHistory = 20
+
+// AddToHistory records a played track, forgetting the oldest beyond maxHistory
+func (vi *VoiceInstance) AddToHistory(track *Track) {
+	vi.Mu.Lock()
+	defer vi.Mu.Unlock()
+
+	vi.History = append(vi.History, track)
+	if len(vi.History) > maxHistory {
+		vi.History = vi.History[len(vi.History)-maxHistory:]
+	}
+}
+
+// RecentHistory returns a copy of the recently played tracks, oldest first
+func (vi *VoiceInstance) RecentHistory() []*Track {
+	vi.Mu.Lock()
+	defer vi.Mu.Unlock()
+
+	return append([]*Track(nil), vi.History...)
+}
+
+// ForgetRequester drops the tracks requested by a user from every guild's history
+func (vm *VoiceManager) ForgetRequester(userID string) {
+	vm.Mu.Lock()
+	defer vm.Mu.Unlock()
+
+	for _, vi := range vm.Instances {
+		vi.Mu.Lock()
+		kept := vi.History[:0]
+		for _, track := range vi.History {
+			if track.RequesterID != userID {
+				kept = append(kept, track)
+			}
+		}
+		vi.History = kept
+		vi.Mu.Unlock()
+	}
+}
+
+// Position returns how far into the current track playback is
+func (vi *VoiceInstance) Position() time.Duration {
+	if vi.remote != nil {
+		return vi.remote.Position(vi.GuildID)
+	}
+
+	vi.Mu.Lock()
+	defer vi.Mu.Unlock()
+	return vi.position
+}
+
+// PlayAudio plays audio from a file using ffmpeg to convert and play the audio.
+// It blocks until the file has finished playing.
 func (vi *VoiceInstance) PlayAudio(filePath string) error {
+	return vi.PlayAudioFrom(filePath, 0)
+}
+
+// PlayAudioFrom plays audio from a file like PlayAudio, starting start into it
+func (vi *VoiceInstance) PlayAudioFrom(filePath string, start time.Duration) error {
+	// Let a message spoken between tracks finish first
+	vi.waitForSpeech()
+
 	vi.Mu.Lock()
 
 	if vi.Connection == nil {
@@ -249,146 +611,236 @@ func (vi *VoiceInstance) PlayAudio(filePath string) error {
 	}
 
 	vi.IsPlaying = true
+	vi.Paused = false
+	vi.position = start
+	vi.mixing = true
+	vc := vi.Connection
+	sender := vi.sender
+	stop := vi.StopChan
+	custom := vi.Bitrate
 	vi.Mu.Unlock()
+	defer vi.stopMixing()
 
-	go func() {
-		defer func() {
-			vi.Mu.Lock()
-			vi.IsPlaying = false
-			vi.Mu.Unlock()
-		}()
-
-		// Set speaking state
-		err := vi.Connection.Speaking(true)
-		if err != nil {
-			log.Printf("Error setting speaking state: %v", err)
-			return
+	// Set speaking state
+	err := vc.Speaking(true)
+	if err != nil {
+		return fmt.Errorf("error setting speaking state: %v", err)
+	}
+	defer vc.Speaking(false)
+
+	// Pick encoder settings and filters based on current host load and the guild's bitrate
+	profile := vi.quality.Profile().withBitrate(custom)
+	if profile.Reduced {
+		log.Printf("Playing with reduced quality profile in guild %s due to CPU load", vi.GuildID)
+	}
+
+	// Pre-encoded files are simply read frame by frame, and only re-encoded
+	// if the guild chose its own bitrate or they were cached at another volume
+	if strings.HasSuffix(filePath, dcaExt) {
+		return vi.playDCA(filePath, start, profile.Bitrate, custom > 0, vc, sender, stop)
+	}
+
+	src, err := ProbeSource(filePath)
+	if err != nil {
+		log.Printf("Failed to probe %s, using default conversion: %v", filePath, err)
+	}
+
+	// Opus sources go out as they are, skipping the decoder and encoder, as
+	// long as that doesn't skip a volume change every other track gets too
+	if vi.passthrough && custom == 0 && profile.Gain == 1 && !vi.karaokeOn() && src != nil && src.Codec == "opus" {
+		position, err := vi.playPassthrough(filePath, start, profile.Bitrate, vc, sender, stop)
+		if !errors.Is(err, errPacketDuration) {
+			return err
 		}
-		defer vi.Connection.Speaking(false)
-
-		// Create a command to convert the audio to raw PCM and send to stdout
-		cmd := exec.Command("ffmpeg",
-			"-i", filePath, // Input file
-			"-f", "s16le", // Output format (signed 16-bit little-endian)
-			"-ar", "48000", // Audio sample rate (48kHz)
-			"-ac", "2", // Audio channels (stereo)
-			"-loglevel", "warning", // Only show warnings and errors
-			"-af", "volume=0.5,aresample=async=1000", // Adjust volume and resample
-			"-acodec", "pcm_s16le", // Force PCM signed 16-bit little-endian codec
-			"-ar", "48000", // Force 48kHz sample rate
-			"-ac", "2", // Force stereo
-			"-f", "s16le", // Force output format
-			"-fflags", "nobuffer", // Reduce input buffering
-			"-flags", "low_delay", // Reduce latency
-			"-probesize", "32", // Reduce probe size
-			"-analyzeduration", "0", // Don't analyze the entire file
-			"pipe:1") // Output to stdout
-
-		// Create a buffer for reading audio data (60ms of stereo audio at 48kHz = 11520 bytes)
-		// Using a larger buffer to reduce the number of reads
-
-		// Get the command's stdout pipe
-		stdout, err := cmd.StdoutPipe()
-		if err != nil {
-			log.Printf("Error creating stdout pipe: %v", err)
-			return
+		log.Printf("Can't pass %s through (%v), transcoding from %s", filePath, err, position)
+		start = position
+	}
+
+	// Convert the source to 48 kHz stereo explicitly instead of assuming it already is
+	filters := vi.karaokeFilters(profile.Filters)
+	if src != nil {
+		if conversion := conversionFilters(src); conversion != "" {
+			log.Printf("Converting %s source in guild %s", src, vi.GuildID)
+			filters = conversion + "," + filters
 		}
+	}
 
-		buffer := bufio.NewReaderSize(stdout, 16384)
+	_, err = vi.decode([]string{
+		"-ss", fmt.Sprintf("%.3f", start.Seconds()), // Seek before decoding
+		"-i", filePath, // Input file
+	}, filters, profile, vc, sender, start, stop)
+	return err
+}
 
-		// Set process group ID to allow killing child processes
-		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+// decode runs ffmpeg on the given input arguments and streams the converted
+// audio, encoded with the profile's settings, to the connection from start
+// until the input ends or playback is stopped. ended reports whether the
+// input ran out.
+func (vi *VoiceInstance) decode(input []string, filters string, profile QualityProfile, vc *discordgo.VoiceConnection, sender *frameSender, start time.Duration, stop chan bool) (ended bool, err error) {
+	// Create a command to convert the audio to raw PCM and send to stdout
+	args := append(append([]string{}, input...),
+		"-f", "s16le", // Output format (signed 16-bit little-endian)
+		"-ar", "48000", // Audio sample rate (48kHz)
+		"-ac", "2", // Audio channels (stereo)
+		"-loglevel", "warning", // Only show warnings and errors
+		"-af", filters, // Convert the source format, adjust volume and resample
+		"-acodec", "pcm_s16le", // Force PCM signed 16-bit little-endian codec
+		"-ar", "48000", // Force 48kHz sample rate
+		"-ac", "2", // Force stereo
+		"-f", "s16le", // Force output format
+		"-fflags", "nobuffer", // Reduce input buffering
+		"-flags", "low_delay", // Reduce latency
+		"-probesize", "32", // Reduce probe size
+		"-analyzeduration", "0", // Don't analyze the entire file
+		"pipe:1") // Output to stdout
+	stdout, cleanup, err := vi.startFFmpeg(args)
+	if err != nil {
+		return false, err
+	}
+	defer cleanup()
+	source := &endReader{r: bufio.NewReaderSize(stdout, 16384)}
 
-		// Start the command
-		err = cmd.Start()
-		if err != nil {
-			log.Printf("Error starting ffmpeg: %v", err)
-			return
+	encoder, err := gopus.NewEncoder(48000, channels, profile.Application)
+	if err != nil {
+		return false, fmt.Errorf("error creating opus encoder: %v", err)
+	}
+	encoder.SetBitrate(profile.Bitrate)
+
+	err = vi.streamFrames(vc, sender, source, encoder, start, stop)
+	return source.ended, err
+}
+
+// startFFmpeg runs ffmpeg with args in its own process group and returns its
+// output. Release kills it if the connection goes away, and cleanup kills it
+// once the caller is done.
+func (vi *VoiceInstance) startFFmpeg(args []string) (stdout io.Reader, cleanup func(), err error) {
+	cmd := exec.Command("ffmpeg", args...)
+
+	// Get the command's stdout pipe
+	stdout, err = cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating stdout pipe: %v", err)
+	}
+
+	// Set process group ID to allow killing child processes
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	// Start the command
+	err = cmd.Start()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error starting ffmpeg: %v", err)
+	}
+
+	// Let Release kill the pipeline if the connection goes away mid-track
+	vi.Mu.Lock()
+	vi.ffmpeg = cmd.Process
+	vi.Mu.Unlock()
+
+	// Make sure to clean up the ffmpeg process
+	cleanup = func() {
+		vi.Mu.Lock()
+		vi.ffmpeg = nil
+		vi.Mu.Unlock()
+
+		if cmd.Process != nil {
+			// Kill the entire process group
+			syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
 		}
+		cmd.Wait()
+	}
+	return stdout, cleanup, nil
+}
 
-		// Make sure to clean up the ffmpeg process
-		defer func() {
-			if cmd.Process != nil {
-				// Kill the entire process group
-				syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
-			}
-		}()
-
-		const FRAME_SIZE = 960
-		const CHANNELS = 2
-		const MAX_BYTES = FRAME_SIZE * 4
-		const FRAME_RATE int = 48000
-		encoder, err := gopus.NewEncoder(FRAME_RATE, CHANNELS, gopus.Audio)
-
-		for {
-			ab := make([]int16, FRAME_SIZE*CHANNELS)
-			err := binary.Read(buffer, binary.LittleEndian, &ab)
-			if err == io.EOF || err == io.ErrUnexpectedEOF {
-				return
-			}
-			if err != nil {
-				fmt.Println("Err buffer: %v", err)
-				return
-			}
-			if !vi.Connection.Ready || vi.Connection.OpusSend == nil {
-				fmt.Printf("Discordgo not ready for opus packets. %+v : %+v", vi.Connection.Ready, vi.Connection.OpusSend)
-				return
-			}
+// endReader remembers whether the reader it wraps has run out
+type endReader struct {
+	r     io.Reader
+	ended bool
+}
 
-			opus, err := encoder.Encode(ab, FRAME_SIZE, MAX_BYTES)
-			if err != nil {
-				fmt.Println("Encoding error,", err)
-				return
-			}
+// Read reads from the wrapped reader, noting when it reaches the end
+func (e *endReader) Read(p []byte) (int, error) {
+	n, err := e.r.Read(p)
+	if err == io.EOF {
+		e.ended = true
+	}
+	return n, err
+}
 
-			select {
-			case vi.Connection.OpusSend <- opus:
-				// Frame sent successfully
-			case <-time.After(1000 * time.Millisecond):
-				// Skip frame if we can't send it in time
-				log.Println("Warning: Frame send timeout, dropping frame")
-			}
+// streamFrames reads 20ms frames of 48 kHz stereo PCM from source, encodes
+// them and hands them to sender until the source ends or playback is stopped.
+// A partial frame at the end of the source is dropped.
+func (vi *VoiceInstance) streamFrames(vc *discordgo.VoiceConnection, sender *frameSender, source io.Reader, encoder frameEncoder, start time.Duration, stop chan bool) error {
+	frames := 0
+	for {
+		if vi.holdFrame(vc, sender, start+time.Duration(frames)*frameDuration, stop) {
+			return nil
+		}
 
+		ab := make([]int16, frameSize*channels)
+		err := binary.Read(source, binary.LittleEndian, &ab)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading audio data: %v", err)
+		}
+		if stopped, err := vi.holdVoice(vc, sender, stop); stopped || err != nil {
+			return err
 		}
 
-		return
-		/*
-			// Create a ticker for consistent timing (60ms = ~16.67fps)
-			ticker := time.NewTicker(60 * time.Millisecond)
-			defer ticker.Stop()
-
-			for {
-				select {
-				case <-ticker.C:
-					// Read raw PCM data with timeout
-					n, err := stdout.Read(buffer)
-					if err == io.EOF {
-						// End of file, we're done
-						return
-					} else if err != nil {
-						log.Printf("Error reading audio data: %v", err)
-						return
-					}
-
-					// Only send if we have data
-					if n > 0 {
-						// Copy the buffer to ensure we don't modify it while it's being sent
-						frame := make([]byte, n)
-						copy(frame, buffer[:n])
-
-						// Send the frame with a timeout
-						select {
-						case vi.Connection.OpusSend <- frame:
-							// Frame sent successfully
-						case <-time.After(100 * time.Millisecond):
-							// Skip frame if we can't send it in time
-							log.Println("Warning: Frame send timeout, dropping frame")
-						}
-					}
-				}
-			}
-		*/
-	}()
+		// Talk over the music if a message is being spoken
+		vi.mixSpeech(ab)
 
-	return nil
+		opus, err := encoder.Encode(ab, frameSize, maxOpusBytes)
+		if err != nil {
+			return fmt.Errorf("encoding error: %v", err)
+		}
+
+		// Hand the frame to the connection's writer; a stalled socket drops
+		// frames there instead of blocking this decode loop
+		if !sender.Send(opus) {
+			return errors.New("voice connection closed")
+		}
+		frames++
+	}
+}
+
+// holdFrame records the position of the next frame and holds the track in
+// place while paused. It returns true if the track was skipped or the bot
+// left the channel.
+func (vi *VoiceInstance) holdFrame(vc *discordgo.VoiceConnection, sender *frameSender, position time.Duration, stop chan bool) (stopped bool) {
+	// Stop early if the track was skipped or the bot left the channel
+	select {
+	case <-stop:
+		sender.Flush()
+		return true
+	default:
+	}
+
+	// Hold the track in place while paused; skipping still works
+	vi.Mu.Lock()
+	paused, resume := vi.Paused, vi.resume
+	vi.position = position
+	vi.Mu.Unlock()
+	if paused {
+		sender.Flush()
+		vc.Speaking(false)
+		select {
+		case <-resume:
+			vc.Speaking(true)
+		case <-stop:
+			return true
+		}
+	}
+	return false
+}
+
+// holdVoice holds the track while a dropped connection recovers. ffmpeg
+// waits on the pipe meanwhile, so playback continues where it left off.
+func (vi *VoiceInstance) holdVoice(vc *discordgo.VoiceConnection, sender *frameSender, stop chan bool) (stopped bool, err error) {
+	if voiceReady(vc) {
+		return false, nil
+	}
+	sender.Flush()
+	return vi.recoverVoice(vc, stop)
 }
@@ -0,0 +1,194 @@
+package audio
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStreamFramesSendsEveryFrameInOrder(t *testing.T) {
+	vi := newTestInstance()
+	defer vi.sender.Close()
+	sink := newOpusSink(vi.Connection, 0)
+	defer sink.Close()
+
+	start := 3 * time.Second
+	if err := await(t, stream(vi, pcmSource(5, 0), &indexEncoder{}, start)); err != nil {
+		t.Fatalf("streamFrames: %v", err)
+	}
+
+	frames := sink.WaitFrames(t, 5)
+	for n, frame := range frames {
+		if got := frameIndex(frame); got != n {
+			t.Errorf("frame %d carries index %d", n, got)
+		}
+	}
+	if got, want := vi.Position(), start+5*frameDuration; got != want {
+		t.Errorf("position %s, want %s", got, want)
+	}
+}
+
+func TestStreamFramesDropsPartialFrame(t *testing.T) {
+	vi := newTestInstance()
+	defer vi.sender.Close()
+	sink := newOpusSink(vi.Connection, 0)
+	defer sink.Close()
+
+	encoder := &indexEncoder{}
+	if err := await(t, stream(vi, pcmSource(3, 100), encoder, 0)); err != nil {
+		t.Fatalf("streamFrames: %v", err)
+	}
+
+	sink.WaitFrames(t, 3)
+	if len(encoder.sizes) != 3 {
+		t.Fatalf("encoded %d frames, want 3", len(encoder.sizes))
+	}
+	for n, size := range encoder.sizes {
+		if size != frameSize*channels {
+			t.Errorf("frame %d had %d samples, want %d", n, size, frameSize*channels)
+		}
+	}
+}
+
+func TestStreamFramesStopsOnSkip(t *testing.T) {
+	vi := newTestInstance()
+	defer vi.sender.Close()
+	sink := newOpusSink(vi.Connection, time.Millisecond)
+	defer sink.Close()
+
+	result := stream(vi, &endlessPCM{}, &indexEncoder{}, 0)
+	sink.WaitFrames(t, 10)
+	if !vi.Skip() {
+		t.Fatal("Skip returned false while playing")
+	}
+	if err := await(t, result); err != nil {
+		t.Fatalf("streamFrames: %v", err)
+	}
+}
+
+func TestStreamFramesHoldsWhilePaused(t *testing.T) {
+	vi := newTestInstance()
+	defer vi.sender.Close()
+	sink := newOpusSink(vi.Connection, time.Millisecond)
+	defer sink.Close()
+
+	encoder := &indexEncoder{}
+	result := stream(vi, &endlessPCM{}, encoder, 0)
+	sink.WaitFrames(t, 10)
+
+	if !vi.Pause() {
+		t.Fatal("Pause returned false while playing")
+	}
+	// The frame being encoded when Pause was called may still go out
+	time.Sleep(20 * time.Millisecond)
+	paused := encoder.Count()
+	position := vi.Position()
+	time.Sleep(50 * time.Millisecond)
+	if got := encoder.Count(); got != paused {
+		t.Errorf("encoded %d frames while paused", got-paused)
+	}
+	if got := vi.Position(); got != position {
+		t.Errorf("position moved from %s to %s while paused", position, got)
+	}
+
+	if !vi.Resume() {
+		t.Fatal("Resume returned false while paused")
+	}
+	waitFor(t, "playback to continue", func() bool { return encoder.Count() > paused+5 })
+
+	vi.Skip()
+	if err := await(t, result); err != nil {
+		t.Fatalf("streamFrames: %v", err)
+	}
+}
+
+func TestStreamFramesSkipWhilePaused(t *testing.T) {
+	vi := newTestInstance()
+	defer vi.sender.Close()
+	sink := newOpusSink(vi.Connection, time.Millisecond)
+	defer sink.Close()
+
+	result := stream(vi, &endlessPCM{}, &indexEncoder{}, 0)
+	sink.WaitFrames(t, 5)
+	vi.Pause()
+	vi.Skip()
+	if err := await(t, result); err != nil {
+		t.Fatalf("streamFrames: %v", err)
+	}
+}
+
+func TestStreamFramesFailsOnClosedConnection(t *testing.T) {
+	vi := newTestInstance()
+	vi.sender.Close()
+
+	if err := await(t, stream(vi, pcmSource(5, 0), &indexEncoder{}, 0)); err == nil {
+		t.Fatal("streamFrames succeeded on a closed sender")
+	}
+}
+
+func TestFrameSenderDropsOldestFrameWhenStalled(t *testing.T) {
+	defer func(timeout time.Duration) { senderStallTimeout = timeout }(senderStallTimeout)
+	senderStallTimeout = 10 * time.Millisecond
+
+	vc := fakeConnection()
+	sender := newFrameSender(vc)
+	defer sender.Close()
+
+	// Nothing reads from the connection yet: one frame waits in the writer,
+	// senderQueueSize fill the queue and the last one times out
+	total := senderQueueSize + 2
+	sender.Send([]byte{0})
+	waitFor(t, "the writer to take the first frame", func() bool { return len(sender.frames) == 0 })
+	for n := 1; n < total; n++ {
+		if !sender.Send([]byte{byte(n)}) {
+			t.Fatalf("Send %d reported a closed sender", n)
+		}
+	}
+	if sender.dropped != 1 {
+		t.Fatalf("dropped %d frames, want 1", sender.dropped)
+	}
+
+	var got []int
+	for len(got) < total-1 {
+		select {
+		case frame := <-vc.OpusSend:
+			got = append(got, int(frame[0]))
+		case <-time.After(time.Second):
+			t.Fatalf("received %d frames, want %d", len(got), total-1)
+		}
+	}
+	// Frame 0 was already with the writer, so frame 1 is the oldest queued one
+	if got[0] != 0 || got[1] != 2 || got[len(got)-1] != total-1 {
+		t.Errorf("received frames %v, want 0 followed by 2 to %d", got, total-1)
+	}
+}
+
+func TestFrameSenderKeepsTimeWhenStalled(t *testing.T) {
+	defer func(timeout time.Duration) { senderStallTimeout = timeout }(senderStallTimeout)
+	senderStallTimeout = 200 * time.Millisecond
+
+	vc := fakeConnection()
+	sender := newFrameSender(vc)
+	defer sender.Close()
+
+	// Nothing reads from the connection, so it stalls once the queue is full
+	for n := 0; n <= senderQueueSize+1; n++ {
+		sender.Send([]byte{byte(n)})
+	}
+	if sender.dropped != 1 {
+		t.Fatalf("dropped %d frames, want 1", sender.dropped)
+	}
+
+	// Later frames only wait as long as they would have played
+	started := time.Now()
+	for n := 0; n < 5; n++ {
+		if !sender.Send([]byte{byte(n)}) {
+			t.Fatalf("Send %d reported a closed sender", n)
+		}
+	}
+	if elapsed := time.Since(started); elapsed >= senderStallTimeout {
+		t.Errorf("5 frames on a stalled connection took %s, want less than %s", elapsed, senderStallTimeout)
+	}
+	if sender.dropped != 6 {
+		t.Errorf("dropped %d frames, want 6", sender.dropped)
+	}
+}
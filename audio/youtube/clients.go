@@ -0,0 +1,127 @@
+package youtube
+
+import (
+	"log"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"discordbot/config"
+)
+
+// yt-dlp messages that mean YouTube took the request for a bot, which another
+// player client often gets past
+var botCheckMarkers = []string{
+	"sign in to confirm you're not a bot",
+	"sign in to confirm you’re not a bot",
+}
+
+// defaultPlayerClients are tried in order when YT_PLAYER_CLIENTS is unset.
+// The bot check rarely hits all of them at once.
+var defaultPlayerClients = []string{"web", "ios", "tv_embedded"}
+
+// PlayerClients holds the yt-dlp extractor settings for YouTube and rotates
+// between player clients when YouTube blocks one
+type PlayerClients struct {
+	mu          sync.Mutex
+	names       []string
+	preferred   int // index of the client that last worked
+	poToken     string
+	visitorData string
+}
+
+// LoadPlayerClients reads the comma separated YT_PLAYER_CLIENTS (e.g.
+// "default,mweb,tv", defaulting to web, ios and tv_embedded) along with
+// YT_PO_TOKEN and YT_VISITOR_DATA
+func LoadPlayerClients() *PlayerClients {
+	clients := &PlayerClients{}
+	clients.Reload()
+	return clients
+}
+
+// Reload reads the player clients and tokens again, e.g. after the
+// configuration changed
+func (p *PlayerClients) Reload() {
+	names := config.List("YT_PLAYER_CLIENTS")
+	if len(names) == 0 {
+		names = defaultPlayerClients
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.names = names
+	p.preferred = 0
+	p.poToken = config.String("YT_PO_TOKEN", "")
+	p.visitorData = config.String("YT_VISITOR_DATA", "")
+}
+
+// order returns the player clients to try, starting with the one that last worked
+func (p *PlayerClients) order() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append(append([]string{}, p.names[p.preferred:]...), p.names[:p.preferred]...)
+}
+
+// prefer makes name the first client tried from now on
+func (p *PlayerClients) prefer(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, n := range p.names {
+		if n == name {
+			p.preferred = i
+			return
+		}
+	}
+}
+
+// extractorArgs returns the --extractor-args value for client
+func (p *PlayerClients) extractorArgs(client string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	parts := []string{"player_client=" + client}
+	if p.poToken != "" {
+		parts = append(parts, "po_token="+p.poToken)
+	}
+	if p.visitorData != "" {
+		parts = append(parts, "visitor_data="+p.visitorData)
+	}
+	return "youtube:" + strings.Join(parts, ";")
+}
+
+// run runs yt-dlp with args on target, retrying with the next player client
+// while YouTube answers with a bot check. It returns yt-dlp's output and,
+// on failure, the diagnostics of the last attempt.
+func (p *PlayerClients) run(args []string, target string, combined bool) (output, diagnostics []byte, err error) {
+	clients := p.order()
+	for n, client := range clients {
+		full := append([]string{}, args...)
+		full = append(full, "--extractor-args", p.extractorArgs(client))
+		full = append(full, target)
+
+		cmd := exec.Command("yt-dlp", full...)
+		if combined {
+			output, err = cmd.CombinedOutput()
+			diagnostics = output
+		} else {
+			output, err = cmd.Output()
+			diagnostics = nil
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				diagnostics = exitErr.Stderr
+			}
+		}
+		if err == nil {
+			if n > 0 {
+				p.prefer(client)
+			}
+			return output, nil, nil
+		}
+		if !containsAny(diagnostics, botCheckMarkers) {
+			break
+		}
+		if n < len(clients)-1 {
+			log.Printf("YouTube asked player client %q to sign in, trying %q", client, clients[n+1])
+		}
+	}
+	return nil, diagnostics, err
+}
@@ -0,0 +1,298 @@
+package youtube
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"discordbot/config"
+)
+
+// A cookie jar YouTube rejected is left out of the rotation for
+// flagCooldownMin, twice as long each time it is rejected again in a row, up
+// to flagCooldownMax. Bot checks often pass within minutes while a
+// terminated account never comes back.
+const (
+	flagCooldownMin = 15 * time.Minute
+	flagCooldownMax = 24 * time.Hour
+)
+
+var (
+	// ErrAgeRestricted is returned for videos that need a signed-in, age-verified account
+	ErrAgeRestricted = errors.New("video is age-restricted and no working account is configured")
+
+	// ErrRegionLocked is returned for videos that aren't available where the bot runs
+	ErrRegionLocked = errors.New("video is not available in this region and no working account is configured")
+
+	// ErrMembersOnly is returned for videos only channel members or Premium subscribers can watch
+	ErrMembersOnly = errors.New("video is only available to channel members or Premium subscribers")
+
+	// ErrUnavailable is returned for private, removed and otherwise unwatchable videos
+	ErrUnavailable = errors.New("video is private, removed or otherwise unavailable")
+)
+
+// yt-dlp messages that mean YouTube no longer accepts a jar's account
+var flaggedMarkers = append([]string{
+	"cookies are no longer valid",
+	"account has been terminated",
+	"this account is unavailable",
+}, botCheckMarkers...)
+
+// yt-dlp messages for age-restricted videos
+var ageMarkers = []string{
+	"sign in to confirm your age",
+	"age-restricted",
+	"inappropriate for some users",
+}
+
+// yt-dlp messages for region-locked videos
+var regionMarkers = []string{
+	"not made this video available in your country",
+	"not available in your country",
+	"blocked it in your country",
+}
+
+// yt-dlp messages for videos behind a membership or Premium
+var membersMarkers = []string{
+	"members-only content",
+	"available to this channel's members",
+	"only available to music premium members",
+	"only available for premium",
+}
+
+// yt-dlp messages for videos nobody can watch. Region locks also say
+// "video unavailable", so they're checked first.
+var unavailableMarkers = []string{
+	"private video",
+	"this video is private",
+	"video unavailable",
+	"removed by the uploader",
+	"has been removed",
+	"no longer available",
+	"content isn't available",
+	"does not exist",
+}
+
+// CookieJars rotates between the Netscape cookie files yt-dlp signs in with,
+// leaving out jars whose accounts YouTube has recently rejected
+type CookieJars struct {
+	mu      sync.Mutex
+	paths   []string
+	flagged map[string]jarBackoff
+}
+
+// jarBackoff is when a rejected jar rejoins the rotation and how often in a
+// row YouTube has rejected it
+type jarBackoff struct {
+	until    time.Time
+	rejected int
+}
+
+// LoadCookieJars reads the cookie files from YT_COOKIE_FILE and the comma
+// separated YT_COOKIE_FILES, keeping only the ones that look usable
+func LoadCookieJars() *CookieJars {
+	return &CookieJars{paths: cookieFiles(), flagged: make(map[string]jarBackoff)}
+}
+
+// Reload reads the cookie files again, e.g. after the configuration changed.
+// Jars that are still configured stay flagged.
+func (j *CookieJars) Reload() {
+	paths := cookieFiles()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.paths = paths
+}
+
+// cookieFiles returns the usable cookie files named by YT_COOKIE_FILE and YT_COOKIE_FILES
+func cookieFiles() []string {
+	var candidates []string
+	if path := config.String("YT_COOKIE_FILE", ""); path != "" {
+		candidates = append(candidates, path)
+	}
+	candidates = append(candidates, config.List("YT_COOKIE_FILES")...)
+
+	var paths []string
+	seen := make(map[string]bool)
+	for _, path := range candidates {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		if err := validateCookieFile(path); err != nil {
+			log.Printf("Warning: Skipping cookie file %s: %v", path, err)
+			continue
+		}
+		paths = append(paths, path)
+	}
+	if len(candidates) > 0 {
+		log.Printf("Loaded %d of %d YouTube cookie files", len(paths), len(seen))
+	}
+	return paths
+}
+
+// validateCookieFile checks that path is a Netscape cookie file holding at
+// least one unexpired YouTube cookie
+func validateCookieFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	now := time.Now().Unix()
+	cookies, expired := 0, 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// Browsers export HttpOnly cookies with this prefix on an otherwise normal line
+		line := strings.TrimPrefix(scanner.Text(), "#HttpOnly_")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return fmt.Errorf("not a Netscape cookie file")
+		}
+		if !strings.HasSuffix(fields[0], "youtube.com") {
+			continue
+		}
+		cookies++
+		// An expiry of 0 marks a session cookie
+		if expires, err := strconv.ParseInt(fields[4], 10, 64); err == nil && expires != 0 && expires < now {
+			expired++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	switch {
+	case cookies == 0:
+		return fmt.Errorf("no youtube.com cookies")
+	case expired == cookies:
+		return fmt.Errorf("all youtube.com cookies have expired")
+	}
+	return nil
+}
+
+// Available returns the jars that haven't been flagged recently, in order
+func (j *CookieJars) Available() []string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var available []string
+	for _, path := range j.paths {
+		if backoff, ok := j.flagged[path]; ok && time.Now().Before(backoff.until) {
+			continue
+		}
+		available = append(available, path)
+	}
+	return available
+}
+
+// Flag takes a jar out of the rotation after YouTube rejected it, for longer
+// the more often it was rejected in a row
+func (j *CookieJars) Flag(path string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	backoff := j.flagged[path]
+	cooldown := flagCooldownMax
+	if backoff.rejected < 16 {
+		cooldown = min(flagCooldownMin<<backoff.rejected, flagCooldownMax)
+	}
+	backoff.rejected++
+	backoff.until = time.Now().Add(cooldown)
+	j.flagged[path] = backoff
+	log.Printf("Warning: YouTube rejected cookie file %s, leaving it out for %s", path, cooldown)
+}
+
+// Succeeded resets a jar's back-off once YouTube accepted it again
+func (j *CookieJars) Succeeded(path string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	delete(j.flagged, path)
+}
+
+// Counts returns how many jars are configured and how many of them are working
+func (j *CookieJars) Counts() (working, total int) {
+	working = len(j.Available())
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return working, len(j.paths)
+}
+
+// ytdlp runs yt-dlp with args on target, moving on to the next cookie jar
+// when YouTube rejects one even after trying every player client. With
+// combined set the output includes stderr.
+func (c *Client) ytdlp(args []string, target string, combined bool) ([]byte, error) {
+	jars := c.Cookies.Available()
+	if len(jars) == 0 {
+		jars = []string{""}
+	}
+
+	var lastErr error
+	for _, jar := range jars {
+		withJar := append([]string{}, args...)
+		if jar != "" {
+			withJar = append(withJar, "--cookies", jar)
+		}
+
+		output, diagnostics, err := c.Clients.run(withJar, target, combined)
+		if err == nil {
+			if jar != "" {
+				c.Cookies.Succeeded(jar)
+			}
+			return output, nil
+		}
+
+		lastErr = classifyError(err, diagnostics)
+		if jar == "" {
+			break
+		}
+		if containsAny(diagnostics, flaggedMarkers) {
+			c.Cookies.Flag(jar)
+			continue
+		}
+		// The account may simply not be age-verified, so another one could still work
+		if errors.Is(lastErr, ErrAgeRestricted) {
+			continue
+		}
+		break
+	}
+	return nil, lastErr
+}
+
+// classifyError turns a failed yt-dlp run into a restriction error where one
+// applies, and otherwise into an error carrying yt-dlp's output
+func classifyError(err error, diagnostics []byte) error {
+	switch {
+	case containsAny(diagnostics, ageMarkers):
+		return ErrAgeRestricted
+	case containsAny(diagnostics, regionMarkers):
+		return ErrRegionLocked
+	case containsAny(diagnostics, membersMarkers):
+		return ErrMembersOnly
+	case containsAny(diagnostics, unavailableMarkers):
+		return ErrUnavailable
+	case len(diagnostics) > 0:
+		return fmt.Errorf("yt-dlp failed: %v\nOutput: %s", err, string(diagnostics))
+	}
+	return fmt.Errorf("yt-dlp failed: %v", err)
+}
+
+// containsAny reports whether output mentions any of the markers, ignoring case
+func containsAny(output []byte, markers []string) bool {
+	text := strings.ToLower(string(output))
+	for _, marker := range markers {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
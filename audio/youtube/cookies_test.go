@@ -0,0 +1,69 @@
+package youtube
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCookieJarsBackOff(t *testing.T) {
+	jars := &CookieJars{paths: []string{"a.txt", "b.txt"}, flagged: make(map[string]jarBackoff)}
+
+	jars.Flag("a.txt")
+	if got, want := jars.Available(), []string{"b.txt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Available got %q, want %q", got, want)
+	}
+	if got := time.Until(jars.flagged["a.txt"].until); got > flagCooldownMin || got < flagCooldownMin-time.Minute {
+		t.Errorf("first cooldown got %s, want %s", got, flagCooldownMin)
+	}
+
+	jars.Flag("a.txt")
+	if got := time.Until(jars.flagged["a.txt"].until); got < 2*flagCooldownMin-time.Minute {
+		t.Errorf("second cooldown got %s, want %s", got, 2*flagCooldownMin)
+	}
+	for n := 0; n < 100; n++ {
+		jars.Flag("a.txt")
+	}
+	if got := time.Until(jars.flagged["a.txt"].until); got > flagCooldownMax {
+		t.Errorf("cooldown got %s, want at most %s", got, flagCooldownMax)
+	}
+
+	// A jar that works again starts over
+	jars.Succeeded("a.txt")
+	if got, want := jars.Available(), []string{"a.txt", "b.txt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Available after success got %q, want %q", got, want)
+	}
+	jars.Flag("a.txt")
+	if got := time.Until(jars.flagged["a.txt"].until); got > flagCooldownMin {
+		t.Errorf("cooldown after success got %s, want %s", got, flagCooldownMin)
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	failed := errors.New("exit status 1")
+	tests := []struct {
+		output string
+		want   error
+	}{
+		{"ERROR: [youtube] abc: Private video. Sign in if you've been granted access to this video", ErrUnavailable},
+		{"ERROR: [youtube] abc: Video unavailable. This video is private", ErrUnavailable},
+		{"ERROR: [youtube] abc: This video has been removed by the uploader", ErrUnavailable},
+		{"ERROR: [youtube] abc: Video unavailable. This content isn't available.", ErrUnavailable},
+		{"ERROR: [youtube] abc: Sign in to confirm your age. This video may be inappropriate for some users.", ErrAgeRestricted},
+		{"ERROR: [youtube] abc: Video unavailable. The uploader has not made this video available in your country", ErrRegionLocked},
+		{"ERROR: [youtube] abc: Join this channel to get access to members-only content like this video", ErrMembersOnly},
+	}
+	for _, test := range tests {
+		if got := classifyError(failed, []byte(test.output)); !errors.Is(got, test.want) {
+			t.Errorf("classifyError(%q) got %v, want %v", test.output, got, test.want)
+		}
+	}
+
+	transient := classifyError(failed, []byte("ERROR: unable to download video data: HTTP Error 503"))
+	for _, restriction := range []error{ErrUnavailable, ErrAgeRestricted, ErrRegionLocked, ErrMembersOnly} {
+		if errors.Is(transient, restriction) {
+			t.Errorf("HTTP 503 classified as %v", restriction)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package youtube
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Entry is a lightweight search or playlist result
+type Entry struct {
+	ID       string
+	Title    string
+	URL      string
+	Duration time.Duration
+}
+
+// flatPlaylist is the subset of yt-dlp's flat playlist JSON used by the bot
+type flatPlaylist struct {
+	Entries []struct {
+		ID       string  `json:"id"`
+		Title    string  `json:"title"`
+		URL      string  `json:"url"`
+		Duration float64 `json:"duration"`
+	} `json:"entries"`
+}
+
+// IsPlaylistURL reports whether url points to a YouTube playlist
+func IsPlaylistURL(url string) bool {
+	return strings.Contains(url, "list=")
+}
+
+// Search returns up to limit videos matching query
+func (c *Client) Search(query string, limit int) ([]Entry, error) {
+	if limit <= 0 {
+		limit = 1
+	}
+	return c.flatEntries(fmt.Sprintf("ytsearch%d:%s", limit, query), 0, 0)
+}
+
+// PlaylistEntries returns up to limit entries of a playlist without downloading them.
+// A limit of 0 returns the whole playlist.
+func (c *Client) PlaylistEntries(url string, limit int) ([]Entry, error) {
+	return c.flatEntries(url, 0, limit)
+}
+
+// PlaylistRange returns the entries of a playlist from position start to end,
+// counting from 1 and including both. An end of 0 runs to the end of the playlist.
+func (c *Client) PlaylistRange(url string, start, end int) ([]Entry, error) {
+	return c.flatEntries(url, start, end)
+}
+
+// flatEntries lists the entries of a playlist or search using yt-dlp,
+// optionally only those from position start to end
+func (c *Client) flatEntries(target string, start, end int) ([]Entry, error) {
+	args := []string{
+		"--flat-playlist",    // Don't resolve every entry
+		"--dump-single-json", // Print the playlist as one JSON document
+		"--no-warnings",      // Suppress warnings
+		"--yes-playlist",     // Treat watch?v=...&list=... URLs as playlists
+	}
+	if start > 1 {
+		args = append(args, "--playlist-start", fmt.Sprint(start))
+	}
+	if end > 0 {
+		args = append(args, "--playlist-end", fmt.Sprint(end))
+	}
+	output, err := c.ytdlp(args, target, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var playlist flatPlaylist
+	if err := json.Unmarshal(output, &playlist); err != nil {
+		return nil, fmt.Errorf("failed to decode playlist: %v", err)
+	}
+
+	entries := make([]Entry, 0, len(playlist.Entries))
+	for _, e := range playlist.Entries {
+		if e.ID == "" {
+			continue
+		}
+		entries = append(entries, Entry{
+			ID:       e.ID,
+			Title:    e.Title,
+			URL:      "https://www.youtube.com/watch?v=" + e.ID,
+			Duration: time.Duration(e.Duration * float64(time.Second)),
+		})
+	}
+	return entries, nil
+}
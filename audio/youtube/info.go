@@ -0,0 +1,77 @@
+package youtube
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ytdlpInfo is the subset of yt-dlp's JSON metadata used by the bot
+type ytdlpInfo struct {
+	ID           string  `json:"id"`
+	Title        string  `json:"title"`
+	Uploader     string  `json:"uploader"`
+	UploaderID   string  `json:"uploader_id"`
+	ChannelID    string  `json:"channel_id"`
+	WebpageURL   string  `json:"webpage_url"`
+	Thumbnail    string  `json:"thumbnail"`
+	Duration     float64 `json:"duration"`
+	IsLive       bool    `json:"is_live"`
+	UploadDate   string  `json:"upload_date"` // YYYYMMDD
+	ViewCount    int64   `json:"view_count"`
+	Availability string  `json:"availability"`
+	URL          string  `json:"url"` // Of the selected format; only set with -f
+	Chapters     []struct {
+		StartTime float64 `json:"start_time"`
+		Title     string  `json:"title"`
+	} `json:"chapters"`
+}
+
+// Chapter is a section of a video marked by its uploader
+type Chapter struct {
+	Start time.Duration
+	Title string
+}
+
+// GetVideoInfo fetches metadata for a video without downloading it
+func (c *Client) GetVideoInfo(url string) (*VideoInfo, error) {
+	args := []string{
+		"--dump-json",     // Print metadata as JSON
+		"--skip-download", // Don't download the media
+		"--no-playlist",   // Only the video itself
+		"--no-warnings",   // Suppress warnings
+	}
+	output, err := c.ytdlp(args, url, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var info ytdlpInfo
+	if err := json.Unmarshal(output, &info); err != nil {
+		return nil, fmt.Errorf("failed to decode video info: %v", err)
+	}
+
+	// The upload date is optional; extractors that don't know it leave it empty
+	uploaded, _ := time.Parse("20060102", info.UploadDate)
+
+	chapters := make([]Chapter, len(info.Chapters))
+	for n, chapter := range info.Chapters {
+		chapters[n] = Chapter{Start: time.Duration(chapter.StartTime * float64(time.Second)), Title: chapter.Title}
+	}
+
+	return &VideoInfo{
+		ID:           info.ID,
+		Title:        info.Title,
+		Author:       info.Uploader,
+		ChannelID:    info.ChannelID,
+		UploaderID:   info.UploaderID,
+		Webpage:      info.WebpageURL,
+		Thumbnail:    info.Thumbnail,
+		Duration:     time.Duration(info.Duration * float64(time.Second)),
+		IsLive:       info.IsLive,
+		Uploaded:     uploaded,
+		Views:        info.ViewCount,
+		Availability: info.Availability,
+		Chapters:     chapters,
+	}, nil
+}
@@ -0,0 +1,38 @@
+package youtube
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrNotLive is returned by LiveStreamURL for a video that isn't airing
+var ErrNotLive = errors.New("the video is not live")
+
+// LiveStreamURL returns the manifest URL of the best audio format of a video
+// that is live right now
+func (c *Client) LiveStreamURL(url string) (string, error) {
+	args := []string{
+		"--dump-json",          // Print metadata as JSON
+		"--skip-download",      // Don't download the media
+		"--no-playlist",        // Only the video itself
+		"--no-warnings",        // Suppress warnings
+		"-f", "bestaudio/best", // Live streams often only offer combined formats
+	}
+	output, err := c.ytdlp(args, url, false)
+	if err != nil {
+		return "", err
+	}
+
+	var info ytdlpInfo
+	if err := json.Unmarshal(output, &info); err != nil {
+		return "", fmt.Errorf("failed to decode video info: %v", err)
+	}
+	if !info.IsLive {
+		return "", ErrNotLive
+	}
+	if info.URL == "" {
+		return "", errors.New("yt-dlp returned no stream URL")
+	}
+	return info.URL, nil
+}
@@ -0,0 +1,69 @@
+package youtube
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// oembedEndpoint answers basic video metadata without an API key or yt-dlp
+const oembedEndpoint = "https://www.youtube.com/oembed"
+
+// oembedClient bounds how long a metadata fallback may hold up a command
+var oembedClient = &http.Client{Timeout: 10 * time.Second}
+
+// oembedResponse is the part of YouTube's oEmbed answer the bot uses
+type oembedResponse struct {
+	Title        string `json:"title"`
+	AuthorName   string `json:"author_name"`
+	AuthorURL    string `json:"author_url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+}
+
+// OEmbed looks up a video's title, uploader and thumbnail through YouTube's
+// oEmbed endpoint. It's a fallback for when yt-dlp can't get the metadata:
+// the duration, chapters and the rest are left unknown.
+func (c *Client) OEmbed(videoURL string) (*VideoInfo, error) {
+	videoID, err := c.GetVideoID(videoURL)
+	if err != nil {
+		return nil, err
+	}
+	webpage := "https://www.youtube.com/watch?v=" + videoID
+
+	resp, err := oembedClient.Get(oembedEndpoint + "?format=json&url=" + url.QueryEscape(webpage))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query oEmbed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oEmbed answered %s", resp.Status)
+	}
+
+	var embed oembedResponse
+	if err := json.NewDecoder(resp.Body).Decode(&embed); err != nil {
+		return nil, fmt.Errorf("failed to decode oEmbed response: %v", err)
+	}
+	if embed.Title == "" {
+		return nil, fmt.Errorf("oEmbed has no title for %s", videoID)
+	}
+
+	// Channel links are either /@handle or /channel/UCxxxx
+	info := &VideoInfo{
+		ID:        videoID,
+		Title:     embed.Title,
+		Author:    embed.AuthorName,
+		Webpage:   webpage,
+		Thumbnail: embed.ThumbnailURL,
+	}
+	if path, ok := strings.CutPrefix(embed.AuthorURL, "https://www.youtube.com/"); ok {
+		if strings.HasPrefix(path, "@") {
+			info.UploaderID = path
+		} else if channelID, ok := strings.CutPrefix(path, "channel/"); ok {
+			info.ChannelID = channelID
+		}
+	}
+	return info, nil
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 	"sync"
@@ -18,6 +19,8 @@ import (
 // Client handles YouTube audio downloads and streaming
 type Client struct {
 	CacheDir  string
+	Cookies   *CookieJars
+	Clients   *PlayerClients
 	mu        sync.Mutex
 	lastError error
 }
@@ -29,19 +32,33 @@ func NewClient(cacheDir string) *Client {
 	}
 	return &Client{
 		CacheDir: cacheDir,
+		Cookies:  LoadCookieJars(),
+		Clients:  LoadPlayerClients(),
 	}
 }
 
 // VideoInfo represents basic video information
 type VideoInfo struct {
-	ID      string
-	Title   string
-	Author  string
-	Webpage string
+	ID           string
+	Title        string
+	Author       string
+	ChannelID    string // e.g. "UCxxxx"; empty if unknown
+	UploaderID   string // e.g. "@handle"; empty if unknown
+	Webpage      string
+	Thumbnail    string
+	Duration     time.Duration
+	IsLive       bool
+	Uploaded     time.Time // Zero if unknown
+	Views        int64
+	Availability string    // e.g. "public", "unlisted" or "needs_auth"; empty if unknown
+	Chapters     []Chapter // In order; empty if the video has none
 }
 
 // GetVideoID extracts the video ID from a YouTube URL
 func (c *Client) GetVideoID(url string) (string, error) {
+	// Fragments such as #t=90 aren't part of the ID
+	url = strings.SplitN(url, "#", 2)[0]
+
 	// Handle youtu.be links
 	if strings.Contains(url, "youtu.be/") {
 		parts := strings.Split(url, "youtu.be/")
@@ -78,8 +95,9 @@ func (c *Client) GetVideoID(url string) (string, error) {
 	return "", fmt.Errorf("unrecognized YouTube URL format")
 }
 
-// DownloadAudio downloads audio from YouTube using yt-dlp
-func (c *Client) DownloadAudio(videoID string) (string, error) {
+// DownloadAudio downloads audio from YouTube using yt-dlp. A positive
+// rateLimit caps the download at that many bytes per second.
+func (c *Client) DownloadAudio(videoID string, rateLimit int64) (string, error) {
 	if c.CacheDir == "" {
 		c.CacheDir = "/tmp/discordbot/cache"
 	}
@@ -92,15 +110,14 @@ func (c *Client) DownloadAudio(videoID string) (string, error) {
 
 	outputPath := filepath.Join(c.CacheDir, fmt.Sprintf("%s.%%(ext)s", videoID))
 
-	// Get cookie file path from environment
-	cookieFile := os.Getenv("YT_COOKIE_FILE")
-
 	// Create base command
 	args := []string{
-		"-x",                    // Extract audio
-		"--audio-format", "mp3", // Convert to MP3
+		"-f", "bestaudio[acodec=opus]/bestaudio", // Prefer Opus, which plays without transcoding
+		"-x",                     // Extract audio
+		"--audio-format", "opus", // Keep Opus as it is and convert anything else to it
 		"-o", outputPath, // Output path
 		"--no-playlist",          // Don't download playlists
+		"--match-filter", "!is_live", // Never record live streams; they're played as they air
 		"--no-warnings",          // Suppress warnings
 		"--quiet",                // Quiet mode
 		"--no-cache-dir",         // Don't use cache
@@ -111,29 +128,17 @@ func (c *Client) DownloadAudio(videoID string) (string, error) {
 		"--ffmpeg-location", "/home/ec2-user/discordbot/ffmpeg-n6.1-latest-linux64-gpl-6.1/bin", // Use system ffmpeg
 	}
 
-	// Add cookie file if specified
-	if cookieFile != "" {
-		if _, err := os.Stat(cookieFile); err == nil {
-			args = append(args, "--cookies", cookieFile)
-		} else {
-			log.Printf("Warning: Cookie file not found at %s", cookieFile)
-		}
+	if rateLimit > 0 {
+		args = append(args, "--limit-rate", strconv.FormatInt(rateLimit, 10))
 	}
 
-	// Add the video URL
-	args = append(args, "https://youtube.com/watch?v="+videoID)
-
-	// Create command with arguments
-	cmd := exec.Command("yt-dlp", args...)
-
-	// Run the command and capture combined output (stdout + stderr)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return "", fmt.Errorf("yt-dlp failed: %v\nOutput: %s", err, string(output))
+	// Run yt-dlp, rotating cookie jars if YouTube rejects one
+	if _, err := c.ytdlp(args, "https://youtube.com/watch?v="+videoID, true); err != nil {
+		return "", err
 	}
 
-	// The actual output file will have the .mp3 extension
-	actualFile := filepath.Join(c.CacheDir, fmt.Sprintf("%s.mp3", videoID))
+	// The actual output file will have the .opus extension
+	actualFile := filepath.Join(c.CacheDir, fmt.Sprintf("%s.opus", videoID))
 	if _, err := os.Stat(actualFile); os.IsNotExist(err) {
 		return "", fmt.Errorf("output file not found: %s", actualFile)
 	}
@@ -161,7 +166,7 @@ func (c *Client) Play(vc *discordgo.VoiceConnection, url string) error {
 
 	// Download the audio file
 	log.Printf("Starting audio download for video ID: %s", videoID)
-	audioFile, err := c.DownloadAudio(videoID)
+	audioFile, err := c.DownloadAudio(videoID, 0)
 	if err != nil {
 		err = fmt.Errorf("error downloading audio: %v", err)
 		log.Printf("DownloadAudio error: %v", err)
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"log"
+)
+
+// evictCache trims the audio cache back to its size limit
+func evictCache() {
+	evicted, err := audioCache.Evict()
+	if err != nil {
+		log.Printf("Failed to evict cached audio: %v", err)
+		return
+	}
+	for _, entry := range evicted {
+		log.Printf("Evicted %s from the cache (%d plays, score %.2f)", entry.VideoID, entry.Plays, entry.Score)
+	}
+}
@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"discordbot/storage"
+)
+
+const collection = "audit_log"
+
+// maxEntries is how many commands are kept per guild; older ones are dropped
+const maxEntries = 500
+
+// Entry is one command someone ran
+type Entry struct {
+	Time     time.Time `json:"time"`
+	UserID   string    `json:"user_id"`
+	Username string    `json:"username"`
+	Command  string    `json:"command"`
+	// Error is what the command answered if it failed, or empty if it succeeded
+	Error string `json:"error,omitempty"`
+}
+
+// Log keeps the recent commands of each guild in the persistent store
+type Log struct {
+	store storage.Store
+	mu    sync.Mutex
+}
+
+// NewLog creates a new audit log
+func NewLog(store storage.Store) *Log {
+	return &Log{store: store}
+}
+
+// load returns the recorded commands of a guild, oldest first
+func (l *Log) load(guildID string) ([]Entry, error) {
+	var entries []Entry
+	err := l.store.Get(collection, guildID, &entries)
+	if err == storage.ErrNotFound {
+		return nil, nil
+	}
+	return entries, err
+}
+
+// Record appends a command to a guild's log
+func (l *Log) Record(guildID string, entry Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries, err := l.load(guildID)
+	if err != nil {
+		return fmt.Errorf("error loading audit log: %v", err)
+	}
+
+	entries = append(entries, entry)
+	if len(entries) > maxEntries {
+		entries = entries[len(entries)-maxEntries:]
+	}
+	return l.store.Put(collection, guildID, entries)
+}
+
+// Recent returns up to limit of a guild's latest commands, newest first.
+// If userID isn't empty only that user's commands are returned.
+func (l *Log) Recent(guildID, userID string, limit int) ([]Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries, err := l.load(guildID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading audit log: %v", err)
+	}
+
+	var recent []Entry
+	for idx := len(entries) - 1; idx >= 0 && len(recent) < limit; idx-- {
+		if userID == "" || entries[idx].UserID == userID {
+			recent = append(recent, entries[idx])
+		}
+	}
+	return recent, nil
+}
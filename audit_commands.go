@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"discordbot/audit"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Bounds and default of the /auditlog limit option
+var (
+	auditMinLimit     = 1.0
+	auditMaxLimit     = 50.0
+	auditDefaultLimit = 20
+)
+
+// unauditedCommands aren't worth recording since they change nothing
+var unauditedCommands = map[string]bool{
+	"ping":     true,
+	"auditlog": true,
+}
+
+// commandErrors holds the error each running command answered with, keyed by
+// interaction ID, until the command is recorded in the audit log
+var commandErrors sync.Map
+
+// noteCommandError remembers that the interaction's command failed with content
+func noteCommandError(i *discordgo.InteractionCreate, content string) {
+	commandErrors.Store(i.ID, content)
+}
+
+// auditCommand records a finished slash command and its outcome in the guild's audit log
+func auditCommand(i *discordgo.InteractionCreate) {
+	data := i.ApplicationCommandData()
+	recordCommand(i, data.Name, formatCommand(data.Name, data.Options))
+}
+
+// recordCommand records a finished command named name, written as command,
+// and its outcome in the guild's audit log. Components that act like a
+// command, such as picks from a menu, are recorded through it too.
+func recordCommand(i *discordgo.InteractionCreate, name, command string) {
+	failure, _ := commandErrors.LoadAndDelete(i.ID)
+	if readOnly || unauditedCommands[name] {
+		return
+	}
+
+	entry := audit.Entry{
+		Time:     time.Now(),
+		UserID:   i.Member.User.ID,
+		Username: i.Member.User.Username,
+		Command:  command,
+	}
+	if failure != nil {
+		entry.Error = failure.(string)
+	}
+	if err := auditLog.Record(i.GuildID, entry); err != nil {
+		log.Printf("Failed to record command in the audit log of guild %s: %v", i.GuildID, err)
+	}
+}
+
+// formatCommand writes a command the way it was typed, e.g. "/queue add query:lofi"
+func formatCommand(name string, options []*discordgo.ApplicationCommandInteractionDataOption) string {
+	var b strings.Builder
+	b.WriteString("/" + name)
+	writeOptions(&b, options)
+	return b.String()
+}
+
+// writeOptions appends subcommands and name:value pairs for options to b
+func writeOptions(b *strings.Builder, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	for _, option := range options {
+		switch option.Type {
+		case discordgo.ApplicationCommandOptionSubCommand, discordgo.ApplicationCommandOptionSubCommandGroup:
+			b.WriteString(" " + option.Name)
+			writeOptions(b, option.Options)
+		case discordgo.ApplicationCommandOptionUser:
+			fmt.Fprintf(b, " %s:<@%v>", option.Name, option.Value)
+		case discordgo.ApplicationCommandOptionChannel:
+			fmt.Fprintf(b, " %s:<#%v>", option.Name, option.Value)
+		case discordgo.ApplicationCommandOptionRole:
+			fmt.Fprintf(b, " %s:<@&%v>", option.Name, option.Value)
+		default:
+			fmt.Fprintf(b, " %s:%v", option.Name, option.Value)
+		}
+	}
+}
+
+// handleAuditLog shows admins the guild's most recent commands, optionally of one user
+func handleAuditLog(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !isAdmin(i) {
+		errorResponse(s, i, tr(i, "auditlog.admin_only"))
+		return
+	}
+
+	limit := auditDefaultLimit
+	var userID string
+	for _, option := range i.ApplicationCommandData().Options {
+		switch option.Name {
+		case "limit":
+			limit = int(option.IntValue())
+		case "user":
+			userID = option.UserValue(nil).ID
+		}
+	}
+
+	entries, err := auditLog.Recent(i.GuildID, userID, limit)
+	if err != nil {
+		log.Printf("Failed to load the audit log of guild %s: %v", i.GuildID, err)
+		errorResponse(s, i, tr(i, "auditlog.failed"))
+		return
+	}
+	if len(entries) == 0 {
+		editResponse(s, i, tr(i, "auditlog.empty"))
+		return
+	}
+
+	var msg strings.Builder
+	msg.WriteString(tr(i, "auditlog.header", len(entries)) + "\n")
+	for _, entry := range entries {
+		msg.WriteString(tr(i, "auditlog.entry", entry.Time.Unix(), entry.UserID, entry.Command))
+		if entry.Error != "" {
+			msg.WriteString(tr(i, "auditlog.entry_failed", entry.Error))
+		}
+		msg.WriteString("\n")
+	}
+	editResponse(s, i, truncateMessage(msg.String()))
+}
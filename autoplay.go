@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"discordbot/audio"
+	"discordbot/config"
+	"discordbot/recommend"
+)
+
+// autoplayRequester is shown as the requester of tracks picked by autoplay
+const autoplayRequester = "Autoplay"
+
+// defaultAutoplayAvoidWindow is how long autoplayed tracks are skipped unless AUTOPLAY_AVOID_HOURS says otherwise
+const defaultAutoplayAvoidWindow = 3 * time.Hour
+
+// autoplayAvoidWindow is how long a track autoplay picked stays out of its
+// candidates, as a time.Duration. It changes when the configuration is reloaded.
+var autoplayAvoidWindow atomic.Int64
+
+// autoplayed remembers when autoplay last picked each video, per guild and video ID
+var autoplayed = struct {
+	sync.Mutex
+	guilds map[string]map[string]autoplayedTrack
+}{guilds: make(map[string]map[string]autoplayedTrack)}
+
+// autoplayedTrack is a track autoplay picked and when it did
+type autoplayedTrack struct {
+	track *audio.Track
+	at    time.Time
+}
+
+// recommenders holds the autoplay engines available to guilds, keyed by engine name
+var recommenders map[string]recommend.Recommender
+
+// newRecommenders sets up the autoplay engines the bot is configured for.
+// Spotify needs working Spotify credentials and Last.fm needs LASTFM_API_KEY.
+func newRecommenders() map[string]recommend.Recommender {
+	engines := map[string]recommend.Recommender{
+		recommend.EngineSeed:    recommend.NewSeeded(youtubeClient),
+		recommend.EngineYouTube: recommend.NewYouTube(youtubeClient),
+	}
+	if spotifyClient != nil {
+		engines[recommend.EngineSpotify] = recommend.NewSpotify(spotifyClient, youtubeClient)
+	}
+	if apiKey := config.String("LASTFM_API_KEY", ""); apiKey != "" {
+		engines[recommend.EngineLastFM] = recommend.NewLastFM(apiKey, youtubeClient)
+	} else {
+		log.Printf("LASTFM_API_KEY not set, Last.fm autoplay will be disabled")
+	}
+	return engines
+}
+
+// setupAutoplayAvoidance reads how long autoplayed tracks are kept out of autoplay's picks
+func setupAutoplayAvoidance() {
+	hours := config.Int("AUTOPLAY_AVOID_HOURS", int(defaultAutoplayAvoidWindow/time.Hour), func(hours int) bool { return hours >= 0 })
+	autoplayAvoidWindow.Store(int64(time.Duration(hours) * time.Hour))
+}
+
+// availableEngines lists the names of the configured autoplay engines
+func availableEngines() []string {
+	names := make([]string, 0, len(recommenders))
+	for name := range recommenders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// autoplayAttempts is how many picks autoplay tries when the guild's
+// blocklist or content filter rejects them
+const autoplayAttempts = 3
+
+// nextAutoplayTrack picks the track to play when the queue runs empty in
+// autoplay mode. Picks go through the guild's blocklist and content filter
+// like requested tracks; rejected ones are remembered, so the next pick
+// avoids them.
+func nextAutoplayTrack(vi *audio.VoiceInstance, current *audio.Track) (*audio.Track, error) {
+	var err error
+	for attempt := 0; attempt < autoplayAttempts; attempt++ {
+		var pick *audio.Track
+		if pick, err = pickAutoplayTrack(vi, current); err != nil {
+			return nil, err
+		}
+		if err = checkAllowed(vi.GuildID, []*audio.Track{pick}); err == nil {
+			return pick, nil
+		}
+		log.Printf("Autoplay skipped %s in guild %s: %v", pick.URL, vi.GuildID, err)
+		// Repeating the current track is the last resort, so there's nothing else to try
+		if pick == current {
+			break
+		}
+	}
+	return nil, err
+}
+
+// pickAutoplayTrack asks the guild's autoplay engine for a track. Guilds
+// without an engine or seed get videos related to what just played.
+func pickAutoplayTrack(vi *audio.VoiceInstance, current *audio.Track) (*audio.Track, error) {
+	guild := settingsStore.Get(vi.GuildID)
+
+	engine := guild.AutoplayEngine
+	if engine == "" {
+		if guild.AutoplaySeed == "" {
+			return relatedAutoplayTrack(vi, current)
+		}
+		engine = recommend.EngineSeed
+	}
+
+	recommender, ok := recommenders[engine]
+	if !ok {
+		return nil, fmt.Errorf("the %s autoplay engine isn't available on this bot", engine)
+	}
+
+	seeds, err := autoplaySeeds(guild.AutoplaySeed)
+	if err != nil {
+		return nil, err
+	}
+
+	pick, err := recommender.NextTrack(autoplayHistory(vi), seeds)
+	if err != nil {
+		return nil, err
+	}
+	rememberAutoplay(vi.GuildID, pick)
+	pick.Requester = autoplayRequester
+	pick.AddedAt = time.Now()
+	return pick, nil
+}
+
+// relatedAutoplayTrack picks a track related to what just played: a Spotify
+// recommendation if it came from Spotify, else a video from its YouTube mix.
+// The current track keeps going if nothing related can be found.
+func relatedAutoplayTrack(vi *audio.VoiceInstance, current *audio.Track) (*audio.Track, error) {
+	history := autoplayHistory(vi)
+
+	recommender := recommenders[recommend.EngineYouTube]
+	if engine, ok := recommenders[recommend.EngineSpotify].(*recommend.Spotify); ok && engine.SeedTrackID(history, nil) != "" {
+		recommender = engine
+	}
+
+	pick, err := recommender.NextTrack(history, nil)
+	if err != nil {
+		log.Printf("No related track for autoplay in guild %s, repeating the current one: %v", vi.GuildID, err)
+		return current, nil
+	}
+	rememberAutoplay(vi.GuildID, pick)
+	pick.Requester = autoplayRequester
+	pick.AddedAt = time.Now()
+	return pick, nil
+}
+
+// autoplayHistory returns the history recommenders should avoid: the tracks autoplay
+// picked within the avoidance window followed by the guild's recently played tracks.
+// Autoplayed tracks come first so recommenders still relate to what played last.
+func autoplayHistory(vi *audio.VoiceInstance) []*audio.Track {
+	cutoff := time.Now().Add(-time.Duration(autoplayAvoidWindow.Load()))
+
+	autoplayed.Lock()
+	var avoided []*audio.Track
+	for videoID, entry := range autoplayed.guilds[vi.GuildID] {
+		if entry.at.Before(cutoff) {
+			delete(autoplayed.guilds[vi.GuildID], videoID)
+			continue
+		}
+		avoided = append(avoided, entry.track)
+	}
+	autoplayed.Unlock()
+
+	return append(avoided, vi.RecentHistory()...)
+}
+
+// rememberAutoplay records that autoplay picked a track in a guild
+func rememberAutoplay(guildID string, track *audio.Track) {
+	if autoplayAvoidWindow.Load() == 0 {
+		return
+	}
+	key := track.URL
+	if videoID, err := downloader.GetVideoID(track.URL); err == nil && videoID != "" {
+		key = videoID
+	}
+
+	autoplayed.Lock()
+	defer autoplayed.Unlock()
+	if autoplayed.guilds[guildID] == nil {
+		autoplayed.guilds[guildID] = make(map[string]autoplayedTrack)
+	}
+	// Keep a copy so later changes to the queued track don't leak in
+	autoplayed.guilds[guildID][key] = autoplayedTrack{
+		track: &audio.Track{URL: track.URL, Title: track.Title},
+		at:    time.Now(),
+	}
+}
+
+// autoplaySeeds expands a guild's autoplay seed into the seed terms and URLs
+// recommenders understand. Playlist references become their tracks' URLs.
+func autoplaySeeds(seed string) ([]string, error) {
+	if seed == "" {
+		return nil, nil
+	}
+
+	ownerID, name, ok := parsePlaylistRef(seed)
+	if !ok {
+		return []string{seed}, nil
+	}
+
+	pl, err := playlistStore.Get(ownerID, name)
+	if err != nil {
+		return nil, fmt.Errorf("error loading seed playlist %q: %v", name, err)
+	}
+	seeds := make([]string, len(pl.Entries))
+	for idx, entry := range pl.Entries {
+		seeds[idx] = entry.URL
+	}
+	return seeds, nil
+}
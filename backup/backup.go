@@ -0,0 +1,131 @@
+// Package backup writes a guild's configuration to a signed JSON file and
+// reads it back, so admins can move a setup to another guild or restore it
+// after an accidental reset
+package backup
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"discordbot/playlist"
+	"discordbot/settings"
+	"discordbot/storage"
+)
+
+const (
+	collection = "backups"
+	keyName    = "signing_key"
+
+	// Version is the backup format written by this build
+	Version = 1
+)
+
+var (
+	// ErrInvalidSignature is returned for files that weren't written by this
+	// bot or were edited afterwards
+	ErrInvalidSignature = errors.New("backup signature does not match")
+	// ErrUnsupportedVersion is returned for files written by a newer format
+	ErrUnsupportedVersion = errors.New("backup was written by a newer version of the bot")
+)
+
+// Backup is the configuration of one guild
+type Backup struct {
+	Version   int            `json:"version"`
+	GuildID   string         `json:"guild_id"`
+	CreatedAt time.Time      `json:"created_at"`
+	Settings  settings.Guild `json:"settings"`
+	// Aliases are the guild-shared aliases, by name
+	Aliases map[string]string `json:"aliases,omitempty"`
+	// RadioStations are the guild's own stations; an empty URL hides a preset
+	RadioStations map[string]string `json:"radio_stations,omitempty"`
+	// Playlists are the playlists of the guild's members, by user ID
+	Playlists map[string][]*playlist.Playlist `json:"playlists,omitempty"`
+}
+
+// file is the exported JSON: the backup and the signature of its compact encoding,
+// so reindenting the file doesn't invalidate it
+type file struct {
+	Backup    json.RawMessage `json:"backup"`
+	Signature string          `json:"signature"`
+}
+
+// Signer signs and verifies backups with a key kept in the store
+type Signer struct {
+	key []byte
+}
+
+// NewSigner loads the signing key from the store, generating one the first
+// time. Backups only verify on deployments sharing the same store.
+func NewSigner(store storage.Store) (*Signer, error) {
+	var encoded string
+	err := store.Get(collection, keyName, &encoded)
+	if err == storage.ErrNotFound {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("error generating backup signing key: %v", err)
+		}
+		encoded = hex.EncodeToString(b)
+		err = store.Put(collection, keyName, encoded)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error loading backup signing key: %v", err)
+	}
+	key, err := hex.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding backup signing key: %v", err)
+	}
+	return &Signer{key: key}, nil
+}
+
+// sign returns the hex HMAC-SHA256 of data
+func (s *Signer) sign(data []byte) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Encode returns the signed file for backup
+func (s *Signer) Encode(backup Backup) ([]byte, error) {
+	backup.Version = Version
+	data, err := json.Marshal(backup)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding backup: %v", err)
+	}
+	return json.MarshalIndent(file{Backup: data, Signature: s.sign(data)}, "", "  ")
+}
+
+// Decode verifies a file written by Encode and returns its backup
+func (s *Signer) Decode(data []byte) (Backup, error) {
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return Backup{}, fmt.Errorf("error parsing backup: %v", err)
+	}
+	signature, err := hex.DecodeString(f.Signature)
+	if err != nil || len(f.Backup) == 0 {
+		return Backup{}, ErrInvalidSignature
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, f.Backup); err != nil {
+		return Backup{}, fmt.Errorf("error parsing backup: %v", err)
+	}
+	expected, _ := hex.DecodeString(s.sign(compact.Bytes()))
+	if !hmac.Equal(signature, expected) {
+		return Backup{}, ErrInvalidSignature
+	}
+
+	var backup Backup
+	if err := json.Unmarshal(f.Backup, &backup); err != nil {
+		return Backup{}, fmt.Errorf("error parsing backup: %v", err)
+	}
+	if backup.Version > Version {
+		return Backup{}, ErrUnsupportedVersion
+	}
+	return backup, nil
+}
@@ -0,0 +1,176 @@
+package backup
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"discordbot/playlist"
+	"discordbot/settings"
+	"discordbot/storage"
+)
+
+// newTestSigner returns a signer keyed in a fresh store, and the store
+func newTestSigner(t *testing.T) (*Signer, storage.Store) {
+	t.Helper()
+	store, err := storage.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	signer, err := NewSigner(store)
+	if err != nil {
+		t.Fatalf("NewSigner: %v", err)
+	}
+	return signer, store
+}
+
+func testBackup() Backup {
+	return Backup{
+		GuildID:   "123",
+		CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Settings: settings.Guild{
+			MaxQueueSize:  50,
+			BlockedVideos: []string{"dQw4w9WgXcQ"},
+			Language:      "de",
+		},
+		Aliases:       map[string]string{"chill": "lofi hip hop"},
+		RadioStations: map[string]string{"jazz": ""},
+		Playlists: map[string][]*playlist.Playlist{
+			"456": {{Name: "Road trip", OwnerID: "456", Entries: []playlist.Entry{{URL: "https://youtu.be/dQw4w9WgXcQ"}}}},
+		},
+	}
+}
+
+func TestSignerRoundTrip(t *testing.T) {
+	signer, _ := newTestSigner(t)
+	data, err := signer.Encode(testBackup())
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	got, err := signer.Decode(data)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if got.Version != Version {
+		t.Errorf("version %d, want %d", got.Version, Version)
+	}
+	if got.GuildID != "123" || got.Settings.MaxQueueSize != 50 || got.Settings.Language != "de" {
+		t.Errorf("settings not restored: %+v", got)
+	}
+	if len(got.Settings.BlockedVideos) != 1 || got.Aliases["chill"] != "lofi hip hop" {
+		t.Errorf("blocklist or aliases not restored: %+v", got)
+	}
+	if url, ok := got.RadioStations["jazz"]; !ok || url != "" {
+		t.Errorf("hidden preset not restored: %v", got.RadioStations)
+	}
+	if list := got.Playlists["456"]; len(list) != 1 || list[0].Name != "Road trip" || len(list[0].Entries) != 1 {
+		t.Errorf("playlists not restored: %v", got.Playlists)
+	}
+}
+
+func TestSignerKeepsKey(t *testing.T) {
+	signer, store := newTestSigner(t)
+	data, err := signer.Encode(testBackup())
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	// A restarted bot loads the same key from the store
+	reloaded, err := NewSigner(store)
+	if err != nil {
+		t.Fatalf("NewSigner: %v", err)
+	}
+	if _, err := reloaded.Decode(data); err != nil {
+		t.Errorf("Decode with reloaded key: %v", err)
+	}
+
+	other, _ := newTestSigner(t)
+	if _, err := other.Decode(data); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Decode with another key: %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestSignerAcceptsReformatting(t *testing.T) {
+	signer, _ := newTestSigner(t)
+	data, err := signer.Encode(testBackup())
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	if _, err := signer.Decode(compact.Bytes()); err != nil {
+		t.Errorf("Decode of compacted file: %v", err)
+	}
+}
+
+func TestSignerRejectsTampering(t *testing.T) {
+	signer, _ := newTestSigner(t)
+	data, err := signer.Encode(testBackup())
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(f map[string]json.RawMessage)
+	}{
+		{"changed setting", func(f map[string]json.RawMessage) {
+			f["backup"] = bytes.Replace(f["backup"], []byte(`"max_queue_size":50`), []byte(`"max_queue_size":5000`), 1)
+		}},
+		{"changed guild", func(f map[string]json.RawMessage) {
+			f["backup"] = bytes.Replace(f["backup"], []byte(`"guild_id":"123"`), []byte(`"guild_id":"999"`), 1)
+		}},
+		{"changed signature", func(f map[string]json.RawMessage) {
+			f["signature"] = json.RawMessage(`"` + strings.Repeat("0", 64) + `"`)
+		}},
+		{"missing signature", func(f map[string]json.RawMessage) {
+			delete(f, "signature")
+		}},
+		{"missing backup", func(f map[string]json.RawMessage) {
+			delete(f, "backup")
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var f map[string]json.RawMessage
+			if err := json.Unmarshal(data, &f); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			var compact bytes.Buffer
+			json.Compact(&compact, f["backup"])
+			f["backup"] = compact.Bytes()
+			test.tamper(f)
+			tampered, err := json.Marshal(f)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if _, err := signer.Decode(tampered); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Decode: %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+}
+
+func TestSignerRejectsNewerVersion(t *testing.T) {
+	signer, _ := newTestSigner(t)
+	backup := testBackup()
+	backup.Version = Version + 1
+	data, err := json.Marshal(backup)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	file, err := json.Marshal(map[string]interface{}{"backup": json.RawMessage(data), "signature": signer.sign(data)})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if _, err := signer.Decode(file); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("Decode: %v, want ErrUnsupportedVersion", err)
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"discordbot/aliases"
+	"discordbot/backup"
+	"discordbot/i18n"
+	"discordbot/playlist"
+	"discordbot/radio"
+	"discordbot/settings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxBackupBytes caps the size of a file accepted by /settings import
+const maxBackupBytes = 512 << 10
+
+// backupSigner signs exported guild backups. It stays nil if the signing key
+// can't be loaded, which disables /settings export and import.
+var backupSigner *backup.Signer
+
+// setupBackups loads the key guild backups are signed with
+func setupBackups() {
+	signer, err := backup.NewSigner(store)
+	if err != nil {
+		log.Printf("Settings backups disabled: %v", err)
+		return
+	}
+	backupSigner = signer
+}
+
+// handleSettingsExport attaches a signed backup of the guild's settings,
+// shared aliases, radio stations and its members' playlists. Webhook URLs
+// are left out, since anyone holding them can post to the webhook.
+func handleSettingsExport(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if backupSigner == nil {
+		errorResponse(s, i, tr(i, "backup.unavailable"))
+		return
+	}
+
+	guildAliases, err := aliasStore.Guild(i.GuildID)
+	if err != nil {
+		errorResponse(s, i, tr(i, "error", err))
+		return
+	}
+	stations, err := radioStore.Own(i.GuildID)
+	if err != nil {
+		errorResponse(s, i, tr(i, "error", err))
+		return
+	}
+
+	playlists, err := memberPlaylists(s, i.GuildID)
+	if err != nil {
+		errorResponse(s, i, tr(i, "error", err))
+		return
+	}
+
+	guild := settingsStore.Get(i.GuildID)
+	guild.Webhooks = nil
+	data, err := backupSigner.Encode(backup.Backup{
+		GuildID:       i.GuildID,
+		CreatedAt:     time.Now().UTC(),
+		Settings:      guild,
+		Aliases:       guildAliases,
+		RadioStations: stations,
+		Playlists:     playlists,
+	})
+	if err != nil {
+		errorResponse(s, i, tr(i, "error", err))
+		return
+	}
+
+	content := tr(i, "backup.exported")
+	name := fmt.Sprintf("settings-%s.json", i.GuildID)
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &content,
+		Files:   []*discordgo.File{{Name: name, ContentType: "application/json", Reader: bytes.NewReader(data)}},
+	})
+	if err != nil {
+		log.Printf("Failed to update interaction: %v", err)
+	}
+}
+
+// memberPlaylists returns the playlists of the guild's members, by user ID.
+// Members who opted out with /privacy are left out.
+func memberPlaylists(s *discordgo.Session, guildID string) (map[string][]*playlist.Playlist, error) {
+	owners, err := playlistStore.Owners()
+	if err != nil {
+		return nil, err
+	}
+	playlists := make(map[string][]*playlist.Playlist)
+	for _, userID := range owners {
+		if !isMember(s, guildID, userID) || privacyStore.OptedOut(userID) {
+			continue
+		}
+		list, err := playlistStore.List(userID)
+		if err != nil {
+			return nil, err
+		}
+		if len(list) > 0 {
+			playlists[userID] = list
+		}
+	}
+	return playlists, nil
+}
+
+// isMember reports whether a user is a member of the guild, asking Discord
+// if the state doesn't know
+func isMember(s *discordgo.Session, guildID, userID string) bool {
+	if _, err := s.State.Member(guildID, userID); err == nil {
+		return true
+	}
+	_, err := s.GuildMember(guildID, userID)
+	return err == nil
+}
+
+// validateBackup checks everything a backup would write, so an import either
+// applies completely or not at all
+func validateBackup(restored backup.Backup) error {
+	g := restored.Settings
+	switch {
+	case g.MaxTrackSeconds < 0, g.MaxQueueSize < 0, g.MaxPerUser < 0,
+		g.EnqueuesPerMinute < 0, g.CommandCooldownSeconds < 0,
+		g.RecentPlayHours < 0, g.DownloadRateKB < 0:
+		return errors.New("a limit is negative")
+	case g.Bitrate != 0 && (g.Bitrate < minGuildBitrate || g.Bitrate > maxGuildBitrate):
+		return fmt.Errorf("bitrate %d kbps is out of range", g.Bitrate)
+	case g.Language != "" && !i18n.Supported(g.Language):
+		return fmt.Errorf("unknown language %q", g.Language)
+	case g.KioskCleanup != "" && g.KioskCleanup != kioskCleanupChatter && g.KioskCleanup != kioskCleanupNone:
+		return fmt.Errorf("unknown kiosk cleanup %q", g.KioskCleanup)
+	}
+	if g.AutoplayEngine != "" {
+		if _, ok := recommenders[g.AutoplayEngine]; !ok {
+			return fmt.Errorf("autoplay engine %q isn't available", g.AutoplayEngine)
+		}
+	}
+
+	for name, target := range restored.Aliases {
+		if aliases.Normalize(name) == "" || strings.TrimSpace(target) == "" {
+			return fmt.Errorf("alias %q is empty", name)
+		}
+	}
+	for name, url := range restored.RadioStations {
+		if radio.Normalize(name) == "" {
+			return errors.New("a radio station has no name")
+		}
+		if url != "" && !isLink(url) {
+			return fmt.Errorf("radio station %s has no valid URL", name)
+		}
+	}
+	for userID, list := range restored.Playlists {
+		for _, p := range list {
+			if p == nil || strings.TrimSpace(p.Name) == "" {
+				return fmt.Errorf("a playlist of user %s has no name", userID)
+			}
+			for _, entry := range p.Entries {
+				if !isLink(entry.URL) {
+					return fmt.Errorf("playlist %s has an entry without a valid URL", p.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// handleSettingsImport replaces the guild's configuration with a backup
+// written by /settings export
+func handleSettingsImport(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	if backupSigner == nil {
+		errorResponse(s, i, tr(i, "backup.unavailable"))
+		return
+	}
+
+	var attachment *discordgo.MessageAttachment
+	for _, option := range options {
+		if option.Name == "file" {
+			attachment = i.ApplicationCommandData().Resolved.Attachments[option.Value.(string)]
+		}
+	}
+	if attachment == nil {
+		errorResponse(s, i, tr(i, "backup.no_file"))
+		return
+	}
+	if attachment.Size > maxBackupBytes {
+		errorResponse(s, i, tr(i, "backup.too_big", maxBackupBytes>>10))
+		return
+	}
+
+	data, err := fetchAttachment(attachment.URL, maxBackupBytes, i.GuildID)
+	if err != nil {
+		errorResponse(s, i, tr(i, "error", err))
+		return
+	}
+	restored, err := backupSigner.Decode(data)
+	if errors.Is(err, backup.ErrInvalidSignature) {
+		errorResponse(s, i, tr(i, "backup.invalid_signature"))
+		return
+	}
+	if err == nil {
+		err = validateBackup(restored)
+	}
+	if err != nil {
+		errorResponse(s, i, tr(i, "backup.invalid", err))
+		return
+	}
+
+	// Channels belong to the guild the backup was taken in, and its members'
+	// playlists to its members. Roles aren't stored: DJs are recognized by
+	// the name of their role, which works in any guild.
+	imported := restored.Settings
+	if restored.GuildID != i.GuildID {
+		imported.CommandChannels = nil
+		imported.AnnounceChannelID = ""
+		imported.KioskChannelID = ""
+		restored.Playlists = nil
+	}
+
+	if _, err := settingsStore.Update(i.GuildID, func(g *settings.Guild) {
+		// Exports leave the webhooks out, so keep the ones set up here
+		imported.Webhooks = g.Webhooks
+		*g = imported
+	}); err != nil {
+		errorResponse(s, i, tr(i, "error", err))
+		return
+	}
+	if err := aliasStore.ReplaceGuild(i.GuildID, restored.Aliases); err != nil {
+		errorResponse(s, i, tr(i, "error", err))
+		return
+	}
+	if err := radioStore.Replace(i.GuildID, restored.RadioStations); err != nil {
+		errorResponse(s, i, tr(i, "error", err))
+		return
+	}
+	// Playlists members still have are kept, so only deleted ones come back
+	for userID, list := range restored.Playlists {
+		if _, err := playlistStore.Restore(userID, list); err != nil {
+			errorResponse(s, i, tr(i, "error", err))
+			return
+		}
+	}
+
+	created := restored.CreatedAt.Format("2006-01-02 15:04 MST")
+	if restored.GuildID != i.GuildID {
+		editResponse(s, i, tr(i, "backup.imported_other", created))
+		return
+	}
+	editResponse(s, i, tr(i, "backup.imported", created))
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"discordbot/bandwidth"
+	"discordbot/config"
+)
+
+// downloadRateMin is the smallest /settings downloads value; MinValue takes a pointer
+var downloadRateMin = 0.0
+
+// setupDownloadRate reads the bot's total download rate limit
+func setupDownloadRate() {
+	kb := config.Int("DOWNLOAD_RATE_LIMIT_KB", 0, func(kb int) bool { return kb >= 0 })
+	bandwidth.Default.SetTotal(int64(kb) << 10)
+}
+
+// startDownload registers a download for a guild and returns the bytes per
+// second it may use: its share of the bot's limit, lowered to the guild's
+// own limit. 0 means unlimited. done must be called once it's over.
+func startDownload(guildID string) (rate int64, done func()) {
+	var guildRate int64
+	if guildID != "" {
+		guildRate = int64(settingsStore.Get(guildID).DownloadRateKB) << 10
+	}
+	return bandwidth.Default.Start(guildRate)
+}
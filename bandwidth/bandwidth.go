@@ -0,0 +1,83 @@
+// Package bandwidth shares the bot's download rate limit between the
+// downloads running at the same time, whether yt-dlp runs them or the bot
+// reads them itself
+package bandwidth
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// Limiter splits a total rate in bytes per second evenly between the
+// downloads running at the same time
+type Limiter struct {
+	total   atomic.Int64 // 0 means unlimited
+	running atomic.Int64
+}
+
+// Default is the limiter for the bot's downloads
+var Default = &Limiter{}
+
+// SetTotal changes the total rate in bytes per second; 0 means unlimited.
+// Downloads already running keep the rate they started with.
+func (l *Limiter) SetTotal(rate int64) {
+	l.total.Store(rate)
+}
+
+// Total returns the total rate in bytes per second; 0 means unlimited
+func (l *Limiter) Total() int64 {
+	return l.total.Load()
+}
+
+// Start registers a download and returns the rate it may use: an even share
+// of the total between it and the downloads already running, lowered to
+// limit if that is positive and smaller. 0 means unlimited. done must be
+// called once the download is over.
+func (l *Limiter) Start(limit int64) (rate int64, done func()) {
+	running := l.running.Add(1)
+	rate = l.total.Load()
+	if rate > 0 {
+		rate /= running
+		rate = max(rate, 1)
+	}
+	if limit > 0 && (rate == 0 || limit < rate) {
+		rate = limit
+	}
+	return rate, func() { l.running.Add(-1) }
+}
+
+// Running returns how many downloads are registered
+func (l *Limiter) Running() int {
+	return int(l.running.Load())
+}
+
+// throttledReader reads no faster than rate bytes per second
+type throttledReader struct {
+	r     io.Reader
+	rate  int64
+	start time.Time
+	read  int64
+}
+
+// Reader limits reads from r to rate bytes per second; a rate of 0 returns r as is
+func Reader(r io.Reader, rate int64) io.Reader {
+	if rate <= 0 {
+		return r
+	}
+	return &throttledReader{r: r, rate: rate, start: time.Now()}
+}
+
+// Read reads from the underlying reader, sleeping first if reading has run ahead of the rate
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if due := t.start.Add(time.Duration(t.read * int64(time.Second) / t.rate)); time.Until(due) > 0 {
+		time.Sleep(time.Until(due))
+	}
+	// Keep each read to about a tenth of a second's worth so the rate stays smooth
+	if chunk := max(t.rate/10, 1); int64(len(p)) > chunk {
+		p = p[:chunk]
+	}
+	n, err := t.r.Read(p)
+	t.read += int64(n)
+	return n, err
+}
@@ -0,0 +1,74 @@
+package bandwidth
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestStartSharesTotal(t *testing.T) {
+	l := &Limiter{}
+	l.SetTotal(1000)
+
+	first, doneFirst := l.Start(0)
+	if first != 1000 {
+		t.Errorf("first download rate got %d, want 1000", first)
+	}
+	second, doneSecond := l.Start(0)
+	if second != 500 {
+		t.Errorf("second download rate got %d, want 500", second)
+	}
+	if got := l.Running(); got != 2 {
+		t.Errorf("Running got %d, want 2", got)
+	}
+
+	doneSecond()
+	doneFirst()
+	if got := l.Running(); got != 0 {
+		t.Errorf("Running after done got %d, want 0", got)
+	}
+}
+
+func TestStartLimit(t *testing.T) {
+	tests := []struct {
+		total, limit, want int64
+	}{
+		{0, 0, 0},
+		{0, 300, 300},
+		{1000, 300, 300},
+		{1000, 3000, 1000},
+	}
+	for _, test := range tests {
+		l := &Limiter{}
+		l.SetTotal(test.total)
+		rate, done := l.Start(test.limit)
+		done()
+		if rate != test.want {
+			t.Errorf("total %d, limit %d: got %d, want %d", test.total, test.limit, rate, test.want)
+		}
+	}
+}
+
+func TestReaderUnlimited(t *testing.T) {
+	r := bytes.NewReader(nil)
+	if got := Reader(r, 0); got != io.Reader(r) {
+		t.Errorf("Reader with rate 0 got %T, want the reader itself", got)
+	}
+}
+
+func TestReaderPaces(t *testing.T) {
+	data := bytes.Repeat([]byte("x"), 3000)
+	began := time.Now()
+	read, err := io.ReadAll(Reader(bytes.NewReader(data), 10000))
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if !bytes.Equal(read, data) {
+		t.Errorf("read %d bytes, want the %d written", len(read), len(data))
+	}
+	// 3000 bytes at 10000 bytes per second take at least 0.2s after the first read
+	if elapsed := time.Since(began); elapsed < 200*time.Millisecond {
+		t.Errorf("reading took %v, want at least 200ms", elapsed)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"sync"
+
+	"discordbot/audio"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// defaultBitrate is the bitrate Discord gives new voice channels
+const defaultBitrate = 64000
+
+const (
+	// minGuildBitrate and maxGuildBitrate bound the bitrate in kbps a guild may choose
+	minGuildBitrate = 64
+	maxGuildBitrate = 384
+)
+
+// bitrateWarnings remembers the voice channel each guild was warned about in its
+// current voice session, so the warning isn't repeated for every track
+var bitrateWarnings = struct {
+	sync.Mutex
+	channels map[string]string
+}{channels: make(map[string]string)}
+
+// warnLowBitrate tells the guild when music plays into a default-bitrate voice
+// channel, since users tend to blame the bot for the muffled sound, and suggests
+// a higher-bitrate channel the bot can play in if there is one
+func warnLowBitrate(s *discordgo.Session, vi *audio.VoiceInstance, textChannelID string) {
+	vi.Mu.Lock()
+	voiceChannelID := vi.ChannelID
+	vi.Mu.Unlock()
+	if voiceChannelID == "" {
+		return
+	}
+
+	bitrateWarnings.Lock()
+	warned := bitrateWarnings.channels[vi.GuildID] == voiceChannelID
+	bitrateWarnings.channels[vi.GuildID] = voiceChannelID
+	bitrateWarnings.Unlock()
+	if warned {
+		return
+	}
+
+	channel, err := s.State.Channel(voiceChannelID)
+	if err != nil || channel.Bitrate == 0 || channel.Bitrate > defaultBitrate {
+		return
+	}
+
+	if better := betterVoiceChannel(s, channel); better != nil {
+		notifier.Send(textChannelID, trGuild(vi.GuildID, "bitrate.low_suggest", channel.Bitrate/1000, better.ID, better.Bitrate/1000))
+	} else {
+		notifier.Send(textChannelID, trGuild(vi.GuildID, "bitrate.low", channel.Bitrate/1000))
+	}
+}
+
+// forgetBitrateWarning lets the next voice session in a guild warn again
+func forgetBitrateWarning(guildID string) {
+	bitrateWarnings.Lock()
+	delete(bitrateWarnings.channels, guildID)
+	bitrateWarnings.Unlock()
+}
+
+// betterVoiceChannel returns the guild's highest-bitrate voice channel above
+// current's bitrate that the bot can play in, or nil if there is none
+func betterVoiceChannel(s *discordgo.Session, current *discordgo.Channel) *discordgo.Channel {
+	guild, err := s.State.Guild(current.GuildID)
+	if err != nil {
+		return nil
+	}
+
+	var best *discordgo.Channel
+	for _, channel := range guild.Channels {
+		if channel.Type != discordgo.ChannelTypeGuildVoice || channel.Bitrate <= current.Bitrate {
+			continue
+		}
+		if best != nil && channel.Bitrate <= best.Bitrate {
+			continue
+		}
+		if canPlayIn(s, channel.ID) {
+			best = channel
+		}
+	}
+	return best
+}
+
+// canPlayIn reports whether the bot has every permission it needs in a voice channel
+func canPlayIn(s *discordgo.Session, channelID string) bool {
+	perms, err := s.State.UserChannelPermissions(s.State.User.ID, channelID)
+	if err != nil {
+		return false
+	}
+	if perms&discordgo.PermissionAdministrator != 0 {
+		return true
+	}
+	for _, p := range voicePermissions {
+		if perms&p.Permission == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// guildBitrate returns the bitrate in bits per second a guild chose for its
+// music, lowered to its voice channel's bitrate, or 0 for the default
+func guildBitrate(s *discordgo.Session, vi *audio.VoiceInstance) int {
+	kbps := settingsStore.Get(vi.GuildID).Bitrate
+	if kbps == 0 {
+		return 0
+	}
+	bitrate := kbps * 1000
+
+	vi.Mu.Lock()
+	voiceChannelID := vi.ChannelID
+	vi.Mu.Unlock()
+	// Anything above the channel's bitrate is thrown away by Discord anyway
+	if channel, err := s.State.Channel(voiceChannelID); err == nil && channel.Bitrate > 0 && channel.Bitrate < bitrate {
+		bitrate = channel.Bitrate
+	}
+	return bitrate
+}
+
+// voiceChannelBitrate returns the bitrate of the voice channel the bot is in
+// for a guild, or 0 if it isn't connected
+func voiceChannelBitrate(s *discordgo.Session, guildID string) int {
+	voiceManager.Mu.Lock()
+	vi := voiceManager.Instances[guildID]
+	voiceManager.Mu.Unlock()
+	if vi == nil {
+		return 0
+	}
+
+	vi.Mu.Lock()
+	voiceChannelID := vi.ChannelID
+	vi.Mu.Unlock()
+	if voiceChannelID == "" {
+		return 0
+	}
+	channel, err := s.State.Channel(voiceChannelID)
+	if err != nil {
+		return 0
+	}
+	return channel.Bitrate
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"log"
+	neturl "net/url"
+	"strings"
+
+	"discordbot/audio"
+	"discordbot/audio/youtube"
+	"discordbot/settings"
+)
+
+// Blocklist entry kinds accepted by /blocklist
+const (
+	blockVideo   = "video"
+	blockChannel = "channel"
+	blockDomain  = "domain"
+)
+
+// checkBlocklist rejects tracks that match the guild's blocklist.
+// The returned error is meant to be shown to the user.
+func checkBlocklist(guildID string, tracks []*audio.Track) error {
+	guild := settingsStore.Get(guildID)
+	if len(guild.BlockedVideos) == 0 && len(guild.BlockedChannels) == 0 && len(guild.BlockedDomains) == 0 {
+		return nil
+	}
+
+	for _, track := range tracks {
+		if domain := urlHost(track.URL); domain != "" && matchesDomain(domain, guild.BlockedDomains) {
+			return errors.New(trGuild(guildID, "blocklist.blocked_domain", track.DisplayName(), domain))
+		}
+		if !isYouTubeURL(track.URL) {
+			continue
+		}
+		videoID, err := downloader.GetVideoID(track.URL)
+		if err == nil && contains(guild.BlockedVideos, videoID) {
+			return errors.New(trGuild(guildID, "blocklist.blocked_video", track.DisplayName()))
+		}
+	}
+
+	// Channels need the videos' metadata, so only look it up if any are
+	// blocked, several videos at a time for playlists
+	if len(guild.BlockedChannels) == 0 {
+		return nil
+	}
+	infos := make([]*youtube.VideoInfo, len(tracks))
+	forEachTrack(tracks, func(n int, track *audio.Track) {
+		if !isYouTubeURL(track.URL) {
+			return
+		}
+		info, err := videos.GetVideoInfo(track.URL)
+		if err != nil {
+			// Don't block the request if metadata is unavailable; playback will report real errors
+			log.Printf("Failed to get uploader of %s for the blocklist: %v", track.URL, err)
+			return
+		}
+		infos[n] = info
+	})
+	for n, info := range infos {
+		if info == nil {
+			continue
+		}
+		for _, channel := range []string{info.ChannelID, info.UploaderID, info.Author} {
+			if channel != "" && contains(guild.BlockedChannels, strings.ToLower(channel)) {
+				return errors.New(trGuild(guildID, "blocklist.blocked_channel", tracks[n].DisplayName(), info.Author))
+			}
+		}
+	}
+	return nil
+}
+
+// normalizeBlockEntry turns user input into the form a blocklist entry is stored
+// and matched in: video IDs from links, channel IDs or @handles from channel
+// links and host names from URLs
+func normalizeBlockEntry(kind, value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	switch kind {
+	case blockVideo:
+		if isYouTubeURL(value) {
+			videoID, err := downloader.GetVideoID(value)
+			return videoID, err == nil && videoID != ""
+		}
+		return value, value != "" && !strings.ContainsAny(value, "/ ")
+	case blockChannel:
+		if u, err := neturl.Parse(value); err == nil && u.Host != "" {
+			// https://www.youtube.com/channel/UCxxxx or https://www.youtube.com/@handle
+			segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+			value = segments[len(segments)-1]
+		}
+		return strings.ToLower(value), value != ""
+	case blockDomain:
+		if host := urlHost(value); host != "" {
+			value = host
+		}
+		value = strings.TrimPrefix(strings.ToLower(value), "www.")
+		return value, strings.Contains(value, ".") && !strings.ContainsAny(value, "/ ")
+	}
+	return "", false
+}
+
+// blockEntries returns a pointer to the guild's blocklist entries of a kind
+func blockEntries(g *settings.Guild, kind string) *[]string {
+	switch kind {
+	case blockVideo:
+		return &g.BlockedVideos
+	case blockChannel:
+		return &g.BlockedChannels
+	default:
+		return &g.BlockedDomains
+	}
+}
+
+// urlHost returns the lowercase host name of a URL, or empty if it isn't one
+func urlHost(raw string) string {
+	u, err := neturl.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// matchesDomain reports whether host is one of the domains or a subdomain of one
+func matchesDomain(host string, domains []string) bool {
+	for _, domain := range domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// contains reports whether list holds value
+func contains(list []string, value string) bool {
+	for _, entry := range list {
+		if entry == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"strings"
+
+	"discordbot/settings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// handleBlocklist handles the /blocklist command and its subcommands
+func handleBlocklist(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !isAdmin(i) {
+		errorResponse(s, i, tr(i, "blocklist.admin_only"))
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		editResponse(s, i, tr(i, "command.choose_subcommand"))
+		return
+	}
+
+	subcommand := options[0]
+	var kind, value string
+	for _, option := range subcommand.Options {
+		switch option.Name {
+		case "kind":
+			kind = option.StringValue()
+		case "value":
+			value = option.StringValue()
+		}
+	}
+
+	switch subcommand.Name {
+	case "add", "remove":
+		entry, ok := normalizeBlockEntry(kind, value)
+		if !ok {
+			errorResponse(s, i, tr(i, "blocklist.invalid", value))
+			return
+		}
+
+		var changed bool
+		_, err := settingsStore.Update(i.GuildID, func(g *settings.Guild) {
+			entries := blockEntries(g, kind)
+			if subcommand.Name == "add" {
+				if !contains(*entries, entry) {
+					*entries = append(*entries, entry)
+					changed = true
+				}
+				return
+			}
+			// Build a new slice so readers of the previous settings are unaffected
+			var kept []string
+			for _, existing := range *entries {
+				if existing != entry {
+					kept = append(kept, existing)
+				}
+			}
+			changed = len(kept) != len(*entries)
+			*entries = kept
+		})
+		if err != nil {
+			errorResponse(s, i, tr(i, "error", err))
+			return
+		}
+
+		switch {
+		case subcommand.Name == "add" && !changed:
+			editResponse(s, i, tr(i, "blocklist.already", entry))
+		case subcommand.Name == "add":
+			editResponse(s, i, tr(i, "blocklist.added", entry))
+		case !changed:
+			editResponse(s, i, tr(i, "blocklist.not_found", entry))
+		default:
+			editResponse(s, i, tr(i, "blocklist.removed", entry))
+		}
+
+	case "list":
+		guild := settingsStore.Get(i.GuildID)
+		if len(guild.BlockedVideos) == 0 && len(guild.BlockedChannels) == 0 && len(guild.BlockedDomains) == 0 {
+			editResponse(s, i, tr(i, "blocklist.empty"))
+			return
+		}
+
+		var msg strings.Builder
+		for _, section := range []struct {
+			key     string
+			entries []string
+		}{
+			{"blocklist.videos", guild.BlockedVideos},
+			{"blocklist.channels", guild.BlockedChannels},
+			{"blocklist.domains", guild.BlockedDomains},
+		} {
+			if len(section.entries) > 0 {
+				msg.WriteString(tr(i, section.key, "`"+strings.Join(section.entries, "`, `")+"`") + "\n")
+			}
+		}
+		editResponse(s, i, truncateMessage(msg.String()))
+	}
+}
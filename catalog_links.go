@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"discordbot/audio"
+	"discordbot/audio/catalog"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// maxCatalogTracks caps how many tracks one Deezer or Apple Music album or playlist queues
+	maxCatalogTracks = 25
+	// catalogSearches is how many YouTube searches run at once while matching a catalog link
+	// or a Spotify library
+	catalogSearches = 4
+)
+
+// resolveCatalogLink turns a Deezer or Apple Music link into the best YouTube
+// match for each of its tracks. ok is false for links of other services.
+func resolveCatalogLink(i *discordgo.InteractionCreate, link string) (tracks []*audio.Track, ok bool, err error) {
+	songs, ok, err := catalog.Resolve(link, maxCatalogTracks)
+	if !ok || err != nil {
+		return nil, ok, err
+	}
+
+	tracks = matchOnYouTube(i, songs)
+	if len(tracks) == 0 {
+		return nil, true, fmt.Errorf("no YouTube matches found for %s", link)
+	}
+	return tracks, true, nil
+}
+
+// matchOnYouTube returns the best YouTube match for each song that has one,
+// in the songs' order
+func matchOnYouTube(i *discordgo.InteractionCreate, songs []catalog.Song) []*audio.Track {
+	// Search concurrently but keep the catalog's order
+	matches := make([]*audio.Track, len(songs))
+	slots := make(chan struct{}, catalogSearches)
+	var wg sync.WaitGroup
+	for n, song := range songs {
+		wg.Add(1)
+		go func(n int, song catalog.Song) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			results, err := searcher.SearchTracks(song.Query(), 1)
+			if err != nil || len(results) == 0 {
+				log.Printf("No YouTube match for %q: %v", song.Query(), err)
+				return
+			}
+			track := newTrack(i, results[0].URL)
+			track.Title = results[0].Title
+			track.Duration = results[0].Duration
+			matches[n] = track
+		}(n, song)
+	}
+	wg.Wait()
+
+	var tracks []*audio.Track
+	for _, track := range matches {
+		if track != nil {
+			tracks = append(tracks, track)
+		}
+	}
+	return tracks
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"discordbot/audio"
+	"discordbot/audio/youtube"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// currentChapters returns the playing track with its YouTube chapters.
+// Failures are reported on the interaction and ok is false.
+func currentChapters(s *discordgo.Session, i *discordgo.InteractionCreate, vi *audio.VoiceInstance) (current *audio.Track, chapters []youtube.Chapter, ok bool) {
+	vi.Mu.Lock()
+	current = vi.Current
+	if !vi.IsPlaying {
+		current = nil
+	}
+	vi.Mu.Unlock()
+	if current == nil {
+		errorResponse(s, i, tr(i, "chapters.nothing_playing"))
+		return nil, nil, false
+	}
+	if !isYouTubeURL(current.URL) {
+		errorResponse(s, i, tr(i, "chapters.none"))
+		return nil, nil, false
+	}
+
+	info, err := videos.GetVideoInfo(current.URL)
+	if err != nil {
+		errorResponse(s, i, tr(i, "error", err))
+		return nil, nil, false
+	}
+	if len(info.Chapters) == 0 {
+		errorResponse(s, i, tr(i, "chapters.none"))
+		return nil, nil, false
+	}
+	return current, info.Chapters, true
+}
+
+// chapterAt returns the index of the chapter playing at position
+func chapterAt(chapters []youtube.Chapter, position time.Duration) int {
+	current := 0
+	for n, chapter := range chapters {
+		if chapter.Start <= position {
+			current = n
+		}
+	}
+	return current
+}
+
+// handleChapters lists the chapters of the playing video, marking the current one
+func handleChapters(s *discordgo.Session, i *discordgo.InteractionCreate, vi *audio.VoiceInstance) {
+	current, chapters, ok := currentChapters(s, i, vi)
+	if !ok {
+		return
+	}
+
+	playing := chapterAt(chapters, vi.Position())
+	var msg strings.Builder
+	msg.WriteString(tr(i, "chapters.header", current.DisplayName()) + "\n")
+	for n, chapter := range chapters {
+		marker := "▫️"
+		if n == playing {
+			marker = "▶️"
+		}
+		msg.WriteString(tr(i, "chapters.entry", marker, formatDuration(chapter.Start), chapter.Title) + "\n")
+	}
+	editResponse(s, i, truncateMessage(msg.String()))
+}
+
+// handleNextChapter seeks the playing video to the start of its next chapter
+func handleNextChapter(s *discordgo.Session, i *discordgo.InteractionCreate, vi *audio.VoiceInstance) {
+	_, chapters, ok := currentChapters(s, i, vi)
+	if !ok {
+		return
+	}
+
+	next := chapterAt(chapters, vi.Position()) + 1
+	if next >= len(chapters) {
+		errorResponse(s, i, tr(i, "chapters.last"))
+		return
+	}
+	if _, ok := seekTo(vi, chapters[next].Start); !ok {
+		errorResponse(s, i, tr(i, "chapters.nothing_playing"))
+		return
+	}
+	editResponse(s, i, tr(i, "chapters.skipped", chapters[next].Title, formatDuration(chapters[next].Start)))
+}
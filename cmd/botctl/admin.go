@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"discordbot/admin"
+	"discordbot/config"
+)
+
+// adminCommand runs a command against the running bot's admin socket and
+// reports whether args named one
+func adminCommand(args []string) bool {
+	var run func(*admin.Client)
+	switch {
+	case len(args) == 1 && args[0] == "guilds":
+		run = listGuilds
+	case len(args) == 2 && args[0] == "queue":
+		run = func(c *admin.Client) { showQueue(c, args[1]) }
+	case len(args) == 2 && args[0] == "disconnect":
+		run = func(c *admin.Client) {
+			if err := c.Disconnect(args[1]); err != nil {
+				log.Fatalf("Error disconnecting: %v", err)
+			}
+			fmt.Printf("Left voice in %s\n", args[1])
+		}
+	case len(args) == 2 && args[0] == "cache" && args[1] == "flush":
+		run = func(c *admin.Client) {
+			result, err := c.FlushCache()
+			if err != nil {
+				log.Fatalf("Error flushing the cache: %v", err)
+			}
+			fmt.Printf("%d entries removed, %s freed\n", result.Removed, formatSize(result.Bytes))
+		}
+	case len(args) == 2 && args[0] == "config" && args[1] == "reload":
+		run = func(c *admin.Client) {
+			if err := c.ReloadConfig(); err != nil {
+				log.Fatalf("Error reloading the configuration: %v", err)
+			}
+			fmt.Println("Configuration reloaded")
+		}
+	default:
+		return false
+	}
+
+	path := config.String("ADMIN_SOCKET", "")
+	if path == "" {
+		log.Fatal("ADMIN_SOCKET is not set, so the running bot can't be reached")
+	}
+	client, err := admin.NewClient(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+	run(client)
+	return true
+}
+
+// listGuilds prints the servers the bot is in, playing ones first
+func listGuilds(c *admin.Client) {
+	guilds, err := c.Guilds()
+	if err != nil {
+		log.Fatalf("Error listing servers: %v", err)
+	}
+
+	connected := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "GUILD ID\tNAME\tVOICE\tSTATE\tQUEUE\tNOW PLAYING")
+	for _, g := range guilds {
+		state, nowPlaying := "idle", ""
+		switch {
+		case !g.Connected:
+			state = "-"
+		case g.Paused:
+			state = "paused"
+		case g.Playing:
+			state = "playing"
+		}
+		if g.Connected {
+			connected++
+		}
+		if g.NowPlaying != nil {
+			nowPlaying = g.NowPlaying.Title
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", g.ID, g.Name, g.ChannelID, state, g.QueueLength, nowPlaying)
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d servers, %d in voice\n", len(guilds), connected)
+}
+
+// showQueue prints a server's player state and queue
+func showQueue(c *admin.Client, guildID string) {
+	state, err := c.Queue(guildID)
+	if err != nil {
+		log.Fatalf("Error reading the queue: %v", err)
+	}
+
+	if !state.Connected {
+		fmt.Println("Not in voice")
+	} else {
+		fmt.Printf("In voice channel %s, playing: %t, paused: %t, repeat: %t, autoplay: %t\n",
+			state.ChannelID, state.Playing, state.Paused, state.Repeat, state.Autoplay)
+	}
+	if state.NowPlaying != nil {
+		fmt.Printf("Now playing: %s <%s>\n", state.NowPlaying.Title, state.NowPlaying.URL)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tTITLE\tDURATION\tREQUESTER\tURL")
+	for idx, track := range state.Queue {
+		fmt.Fprintf(w, "%d\t%s\t%.0fs\t%s\t%s\n", idx+1, track.Title, track.Duration, track.Requester, track.URL)
+	}
+	w.Flush()
+	fmt.Printf("\n%d queued\n", len(state.Queue))
+}
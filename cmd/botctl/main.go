@@ -0,0 +1,161 @@
+// Command botctl is the operator CLI for inspecting a bot deployment's local
+// state. It reads the same environment and storage as the bot.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"discordbot/audio/cache"
+	"discordbot/config"
+	"discordbot/playcounts"
+	"discordbot/storage"
+)
+
+const usage = `Usage: botctl <command>
+
+Commands:
+  cache list    List cached tracks with their play counts and eviction scores
+  cache evict   Evict the lowest scored tracks until the cache fits CACHE_MAX_MB
+  storage import <dir>
+                Copy the data a file store kept in <dir> into the configured
+                store, e.g. when switching STORAGE_DRIVER to postgres
+
+Commands for the running bot, through its ADMIN_SOCKET:
+  guilds        List the servers the bot is in and what they are playing
+  queue <guild> Show a server's player state and queue
+  disconnect <guild>
+                Make the bot leave voice in a server, dropping its queue
+  cache flush   Remove every cached track that isn't playing
+  config reload Reload the configuration, like sending SIGHUP
+`
+
+func main() {
+	// The .env file is optional here, the environment may already be set
+	if err := config.Load(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	// These talk to the running bot instead of its storage
+	if adminCommand(os.Args[1:]) {
+		return
+	}
+
+	if len(os.Args) < 3 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	store, err := storage.Open()
+	if err != nil {
+		log.Fatalf("Error opening storage: %v", err)
+	}
+
+	switch os.Args[1] {
+	case "cache":
+		cacheCommand(store)
+	case "storage":
+		if os.Args[2] != "import" || len(os.Args) != 4 {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(2)
+		}
+		importStore(store, os.Args[3])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// cacheCommand runs the cache subcommand named in the arguments
+func cacheCommand(store storage.Store) {
+	counts := playcounts.NewStore(store)
+	audioCache := cache.New(cache.DefaultDir(), cache.DefaultMaxBytes(), counts.Plays)
+
+	switch os.Args[2] {
+	case "list":
+		listCache(audioCache)
+	case "evict":
+		evicted, err := audioCache.Evict()
+		if err != nil {
+			log.Fatalf("Error evicting cache entries: %v", err)
+		}
+		for _, entry := range evicted {
+			fmt.Printf("Evicted %s (%s, score %.2f)\n", entry.VideoID, formatSize(entry.Size), entry.Score)
+		}
+		fmt.Printf("%d entries evicted\n", len(evicted))
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// listCache prints the cache entries, highest score first
+func listCache(audioCache *cache.Cache) {
+	entries, err := audioCache.Entries()
+	if err != nil {
+		log.Fatalf("Error listing cache: %v", err)
+	}
+
+	var total int64
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VIDEO ID\tSIZE\tLAST USED\tPLAYS\tSCORE")
+	for _, entry := range entries {
+		total += entry.Size
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%.2f\n",
+			entry.VideoID, formatSize(entry.Size), entry.LastUsed.Format(time.DateTime), entry.Plays, entry.Score)
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d entries, %s of %s in %s\n",
+		len(entries), formatSize(total), formatSize(audioCache.MaxBytes()), audioCache.Dir)
+}
+
+// importStore copies every value a file store kept in dir into store
+func importStore(store storage.Store, dir string) {
+	if _, err := os.Stat(dir); err != nil {
+		log.Fatalf("Error opening %s: %v", dir, err)
+	}
+	src, err := storage.NewFileStore(dir)
+	if err != nil {
+		log.Fatalf("Error opening %s: %v", dir, err)
+	}
+	collections, err := src.Collections()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	total := 0
+	for _, collection := range collections {
+		keys, err := src.Keys(collection)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, key := range keys {
+			var value json.RawMessage
+			if err := src.Get(collection, key, &value); err != nil {
+				log.Fatal(err)
+			}
+			if err := store.Put(collection, key, value); err != nil {
+				log.Fatal(err)
+			}
+		}
+		fmt.Printf("%s: %d values\n", collection, len(keys))
+		total += len(keys)
+	}
+	fmt.Printf("%d values imported from %s\n", total, dir)
+}
+
+// formatSize formats a byte count in MiB
+func formatSize(bytes int64) string {
+	return fmt.Sprintf("%.1f MiB", float64(bytes)/(1<<20))
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strings"
+
+	"discordbot/settings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// anyChannelCommands configure the bot or concern only their author, so they
+// work outside the guild's command channels too
+var anyChannelCommands = map[string]bool{
+	"settings":  true,
+	"privacy":   true,
+	"diagnose":  true,
+	"auditlog":  true,
+	"blocklist": true,
+}
+
+// checkCommandChannel makes sure music commands are run in one of the guild's
+// command channels, if it set any. Otherwise the author is pointed to them
+// privately and false is returned. Threads count as their parent channel.
+func checkCommandChannel(s *discordgo.Session, i *discordgo.InteractionCreate, path string) bool {
+	if anyChannelCommands[commandName(path)] {
+		return true
+	}
+	allowed := settingsStore.Get(i.GuildID).CommandChannels
+	if len(allowed) == 0 || contains(allowed, i.ChannelID) {
+		return true
+	}
+	if channel, err := s.State.Channel(i.ChannelID); err == nil && channel.IsThread() && contains(allowed, channel.ParentID) {
+		return true
+	}
+
+	msg := tr(i, "commandchannel.redirect", formatChannels(allowed))
+	usage.CommandError(commandName(path))
+	noteCommandError(i, msg)
+	respondEphemeral(s, i, msg)
+	return false
+}
+
+// formatChannels mentions each channel, separated by commas
+func formatChannels(channelIDs []string) string {
+	mentions := make([]string, len(channelIDs))
+	for idx, channelID := range channelIDs {
+		mentions[idx] = "<#" + channelID + ">"
+	}
+	return strings.Join(mentions, ", ")
+}
+
+// handleCommandChannelSettings shows or changes the channels music commands are allowed in
+func handleCommandChannelSettings(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	guild, err := settingsStore.Update(i.GuildID, func(g *settings.Guild) {
+		for _, option := range options {
+			switch option.Name {
+			case "add":
+				if channelID := option.ChannelValue(nil).ID; !contains(g.CommandChannels, channelID) {
+					g.CommandChannels = append(g.CommandChannels, channelID)
+				}
+			case "remove":
+				// Build a new slice so readers of the previous settings are unaffected
+				channelID := option.ChannelValue(nil).ID
+				var kept []string
+				for _, existing := range g.CommandChannels {
+					if existing != channelID {
+						kept = append(kept, existing)
+					}
+				}
+				g.CommandChannels = kept
+			case "clear":
+				if option.BoolValue() {
+					g.CommandChannels = nil
+				}
+			}
+		}
+	})
+	if err != nil {
+		errorResponse(s, i, tr(i, "error", err))
+		return
+	}
+
+	if len(guild.CommandChannels) == 0 {
+		editResponse(s, i, tr(i, "commandchannel.anywhere"))
+		return
+	}
+	editResponse(s, i, tr(i, "commandchannel.list", formatChannels(guild.CommandChannels)))
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// syncCommands makes the registered commands match desired, creating, editing
+// and deleting only what changed. Unchanged commands are left alone so clients
+// don't have to wait for them to propagate again. An empty guildID syncs the
+// global commands.
+func syncCommands(s *discordgo.Session, guildID string, desired []*discordgo.ApplicationCommand) error {
+	appID := s.State.User.ID
+	existing, err := s.ApplicationCommands(appID, guildID)
+	if err != nil {
+		return fmt.Errorf("error fetching registered commands: %v", err)
+	}
+
+	registered := make(map[string]*discordgo.ApplicationCommand, len(existing))
+	for _, cmd := range existing {
+		registered[cmd.Name] = cmd
+	}
+
+	var created, edited, unchanged, deleted int
+	for _, cmd := range desired {
+		current, ok := registered[cmd.Name]
+		delete(registered, cmd.Name)
+
+		switch {
+		case !ok:
+			if _, err := s.ApplicationCommandCreate(appID, guildID, cmd); err != nil {
+				log.Printf("Cannot create '%v' command: %v", cmd.Name, err)
+				continue
+			}
+			log.Printf("Created command: %s", cmd.Name)
+			created++
+		case commandSignature(current) != commandSignature(cmd):
+			if _, err := s.ApplicationCommandEdit(appID, guildID, current.ID, cmd); err != nil {
+				log.Printf("Cannot edit '%v' command: %v", cmd.Name, err)
+				continue
+			}
+			log.Printf("Updated command: %s", cmd.Name)
+			edited++
+		default:
+			unchanged++
+		}
+	}
+
+	// Whatever is left is no longer wanted, e.g. a command that was removed or renamed
+	for _, cmd := range registered {
+		if err := s.ApplicationCommandDelete(appID, guildID, cmd.ID); err != nil {
+			log.Printf("Cannot delete '%v' command: %v", cmd.Name, err)
+			continue
+		}
+		log.Printf("Deleted command: %s", cmd.Name)
+		deleted++
+	}
+
+	log.Printf("Commands synced: %d created, %d updated, %d deleted, %d unchanged", created, edited, deleted, unchanged)
+	return nil
+}
+
+// commandSignature returns a canonical form of the parts of a command we
+// define, so a desired command can be compared with what Discord returns
+func commandSignature(cmd *discordgo.ApplicationCommand) string {
+	normalized := discordgo.ApplicationCommand{
+		Type:                     cmd.Type,
+		Name:                     cmd.Name,
+		NameLocalizations:        normalizeLocalizations(cmd.NameLocalizations),
+		DefaultMemberPermissions: cmd.DefaultMemberPermissions,
+		DMPermission:             cmd.DMPermission,
+		NSFW:                     cmd.NSFW,
+		Description:              cmd.Description,
+		DescriptionLocalizations: normalizeLocalizations(cmd.DescriptionLocalizations),
+		Options:                  normalizeOptions(cmd.Options),
+	}
+
+	// Fill in the defaults Discord reports for fields we leave unset
+	if normalized.Type == 0 {
+		normalized.Type = discordgo.ChatApplicationCommand
+	}
+	if normalized.DMPermission == nil {
+		allowed := true
+		normalized.DMPermission = &allowed
+	}
+	if normalized.NSFW == nil {
+		nsfw := false
+		normalized.NSFW = &nsfw
+	}
+
+	data, err := json.Marshal(normalized)
+	if err != nil {
+		// Treat unencodable commands as changed
+		return ""
+	}
+	return string(data)
+}
+
+// normalizeLocalizations treats missing and empty localizations the same
+func normalizeLocalizations(localizations *map[discordgo.Locale]string) *map[discordgo.Locale]string {
+	if localizations == nil || len(*localizations) == 0 {
+		return nil
+	}
+	return localizations
+}
+
+// normalizeOptions copies options with empty lists set consistently
+func normalizeOptions(options []*discordgo.ApplicationCommandOption) []*discordgo.ApplicationCommandOption {
+	normalized := make([]*discordgo.ApplicationCommandOption, len(options))
+	for idx, option := range options {
+		copied := *option
+		if len(copied.NameLocalizations) == 0 {
+			copied.NameLocalizations = nil
+		}
+		if len(copied.DescriptionLocalizations) == 0 {
+			copied.DescriptionLocalizations = nil
+		}
+		if len(copied.ChannelTypes) == 0 {
+			copied.ChannelTypes = nil
+		}
+		copied.Options = normalizeOptions(option.Options)
+		copied.Choices = make([]*discordgo.ApplicationCommandOptionChoice, len(option.Choices))
+		for c, choice := range option.Choices {
+			copiedChoice := *choice
+			if len(copiedChoice.NameLocalizations) == 0 {
+				copiedChoice.NameLocalizations = nil
+			}
+			copied.Choices[c] = &copiedChoice
+		}
+		normalized[idx] = &copied
+	}
+	return normalized
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/joho/godotenv"
+)
+
+// Secrets are the variables that can also be read from a file named by
+// <NAME>_FILE, as Docker and Kubernetes mount secrets
+var Secrets = []string{
+	"DISCORD_TOKEN",
+	"DISCORD_CLIENT_SECRET",
+	"SPOTIFY_ID",
+	"SPOTIFY_SECRET",
+	"SPOTIFY_TOKEN_KEY",
+	"LASTFM_API_KEY",
+	"YT_PO_TOKEN",
+	"GOOGLE_TTS_API_KEY",
+	"AZURE_SPEECH_KEY",
+	"LAVALINK_PASSWORD",
+	"DATABASE_URL",
+}
+
+var state struct {
+	sync.Mutex
+	environment map[string]bool // Settings the process was started with, which always win
+	reloaders   []func()
+}
+
+// Load reads the optional .env file in the working directory, the optional
+// YAML or TOML config file and then the secret files, setting the variables
+// they hold. Variables already set in the environment take precedence over
+// the .env file, which takes precedence over the config file, so the bot can
+// be configured by environment alone. Invalid values are reported as errors.
+func Load() error {
+	state.Lock()
+	state.environment = make(map[string]bool)
+	for _, entry := range os.Environ() {
+		state.environment[strings.SplitN(entry, "=", 2)[0]] = true
+	}
+	state.Unlock()
+
+	if err := godotenv.Load(); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error reading .env file: %v", err)
+	}
+
+	path, err := configFile()
+	if err != nil {
+		return err
+	}
+	if path != "" {
+		values, err := readFile(path)
+		if err != nil {
+			return err
+		}
+		for name, value := range values {
+			if _, set := os.LookupEnv(name); !set {
+				os.Setenv(name, value)
+			}
+		}
+		log.Printf("Loaded configuration from %s", path)
+	}
+
+	for _, name := range Secrets {
+		path := os.Getenv(name + "_FILE")
+		if path == "" || os.Getenv(name) != "" {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("error reading %s_FILE: %v", name, err)
+		}
+		// Editors and secret tooling often leave a trailing newline
+		os.Setenv(name, strings.TrimRight(string(data), "\r\n"))
+	}
+	return validate()
+}
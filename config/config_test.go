@@ -0,0 +1,160 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testSettings are the settings the tests configure
+var testSettings = []string{"CONFIG_FILE", "DATA_DIR", "HTTP_ADDR", "PLUGINS_DIR", "CACHE_MAX_MB", "SHUTDOWN_GRACE_SECONDS", "AUTOPLAY_AVOID_HOURS", "YT_PO_TOKEN", "YT_PO_TOKEN_FILE"}
+
+// setupDir runs the test in an empty working directory with none of the
+// test settings in the environment, restoring both when it ends
+func setupDir(t *testing.T) string {
+	t.Helper()
+	for _, name := range testSettings {
+		value, set := os.LookupEnv(name)
+		os.Unsetenv(name)
+		t.Cleanup(func() {
+			if set {
+				os.Setenv(name, value)
+			} else {
+				os.Unsetenv(name)
+			}
+		})
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd: %v", err)
+	}
+	dir := t.TempDir()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	return dir
+}
+
+// writeFile writes a file in the working directory
+func writeFile(t *testing.T, dir, name, data string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	dir := setupDir(t)
+	os.Setenv("DATA_DIR", "/from/env")
+	writeFile(t, dir, ".env", "DATA_DIR=/from/dotenv\nHTTP_ADDR=:8080\n")
+	writeFile(t, dir, "config.yaml", "data_dir: /from/file\nhttp_addr: :9090\nplugins_dir: /plugins\n")
+
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	tests := []struct {
+		name, want string
+	}{
+		{"DATA_DIR", "/from/env"},
+		{"HTTP_ADDR", ":8080"},
+		{"PLUGINS_DIR", "/plugins"},
+	}
+	for _, test := range tests {
+		if got := String(test.name, ""); got != test.want {
+			t.Errorf("%s got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestLoadSecretFile(t *testing.T) {
+	dir := setupDir(t)
+	writeFile(t, dir, "po_token", "mweb.gvs+token\n")
+	os.Setenv("YT_PO_TOKEN_FILE", filepath.Join(dir, "po_token"))
+
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := String("YT_PO_TOKEN", ""); got != "mweb.gvs+token" {
+		t.Errorf("YT_PO_TOKEN got %q, want %q", got, "mweb.gvs+token")
+	}
+}
+
+func TestLoadRejectsInvalidFile(t *testing.T) {
+	dir := setupDir(t)
+	writeFile(t, dir, "config.yaml", "cache_max_mb: lots\n")
+	if err := Load(); err == nil {
+		t.Errorf("Load of invalid CACHE_MAX_MB: got no error")
+	}
+}
+
+func TestReload(t *testing.T) {
+	dir := setupDir(t)
+	os.Setenv("AUTOPLAY_AVOID_HOURS", "5")
+	writeFile(t, dir, "config.yaml", "cache_max_mb: 100\nshutdown_grace_seconds: 10\nautoplay_avoid_hours: 7\n")
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	reloaded := false
+	OnReload(func() { reloaded = true })
+	writeFile(t, dir, "config.yaml", "cache_max_mb: 200\nshutdown_grace_seconds: 20\nautoplay_avoid_hours: 9\n")
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+
+	if !reloaded {
+		t.Errorf("OnReload function not called")
+	}
+	if got := Int("CACHE_MAX_MB", 0, nil); got != 200 {
+		t.Errorf("reloadable CACHE_MAX_MB got %d, want 200", got)
+	}
+	if got := Int("SHUTDOWN_GRACE_SECONDS", 0, nil); got != 10 {
+		t.Errorf("SHUTDOWN_GRACE_SECONDS needing a restart got %d, want 10", got)
+	}
+	if got := Int("AUTOPLAY_AVOID_HOURS", 0, nil); got != 5 {
+		t.Errorf("AUTOPLAY_AVOID_HOURS from the environment got %d, want 5", got)
+	}
+}
+
+func TestReloadKeepsSettingsOnError(t *testing.T) {
+	dir := setupDir(t)
+	writeFile(t, dir, "config.yaml", "cache_max_mb: 100\n")
+	if err := Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	writeFile(t, dir, "config.yaml", "cache_max_mb: lots\n")
+	if err := Reload(); err == nil {
+		t.Errorf("Reload of invalid CACHE_MAX_MB: got no error")
+	}
+	if got := Int("CACHE_MAX_MB", 0, nil); got != 100 {
+		t.Errorf("CACHE_MAX_MB got %d, want 100", got)
+	}
+}
+
+func TestAccessors(t *testing.T) {
+	setupDir(t)
+	os.Setenv("HTTP_ADDR", "  :8080 ")
+	if got := String("HTTP_ADDR", ":80"); got != ":8080" {
+		t.Errorf("String got %q, want %q", got, ":8080")
+	}
+	if got := String("DATA_DIR", "/data"); got != "/data" {
+		t.Errorf("String of unset setting got %q, want %q", got, "/data")
+	}
+
+	os.Setenv("PLUGINS_DIR", "a, b,,c ")
+	if got, want := List("PLUGINS_DIR"), []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("List got %q, want %q", got, want)
+	}
+	if got := List("DATA_DIR"); got != nil {
+		t.Errorf("List of unset setting got %q, want nil", got)
+	}
+
+	os.Setenv("CACHE_MAX_MB", "-1")
+	if got := Int("CACHE_MAX_MB", 2048, func(mb int) bool { return mb > 0 }); got != 2048 {
+		t.Errorf("Int of rejected value got %d, want 2048", got)
+	}
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultFiles are the config files looked for in the working directory
+// when CONFIG_FILE isn't set
+var defaultFiles = []string{"config.yaml", "config.yml", "config.toml"}
+
+// configFile returns the path of the config file to read, or "" if there is none
+func configFile() (string, error) {
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("error reading CONFIG_FILE: %v", err)
+		}
+		return path, nil
+	}
+	for _, path := range defaultFiles {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", nil
+}
+
+// readFile reads a YAML or TOML config file into setting values. Keys are
+// setting names in any case, and nested tables join their keys with
+// underscores, so "cache: {max_mb: 512}" sets CACHE_MAX_MB. Lists become
+// comma separated values.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %v", err)
+	}
+
+	var tree map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		return nil, fmt.Errorf("config file %s must end in .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %v", path, err)
+	}
+
+	values := make(map[string]string)
+	flatten("", tree, values)
+
+	var problems []error
+	for name, value := range values {
+		setting, ok := lookup(name)
+		if !ok {
+			problems = append(problems, fmt.Errorf("unknown setting %s", name))
+			continue
+		}
+		if err := setting.check(value); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	if err := joinProblems("error in "+path, problems); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// flatten turns a parsed config tree into setting values
+func flatten(prefix string, tree map[string]any, values map[string]string) {
+	for key, value := range tree {
+		name := strings.ToUpper(key)
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+		switch value := value.(type) {
+		case map[string]any:
+			flatten(name, value, values)
+		case []any:
+			items := make([]string, len(value))
+			for n, item := range value {
+				items[n] = fmt.Sprint(item)
+			}
+			values[name] = strings.Join(items, ",")
+		case nil:
+			// An empty key leaves the setting unset
+		default:
+			values[name] = fmt.Sprint(value)
+		}
+	}
+}
+
+// joinProblems combines problems into one error listing them, in a stable order
+func joinProblems(context string, problems []error) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	lines := make([]string, len(problems))
+	for n, problem := range problems {
+		lines[n] = problem.Error()
+	}
+	sort.Strings(lines)
+	return fmt.Errorf("%s: %s", context, strings.Join(lines, "; "))
+}
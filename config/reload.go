@@ -0,0 +1,89 @@
+package config
+
+import (
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+)
+
+// OnReload registers fn to run after Reload applied new settings, so it can
+// read its reloadable settings again
+func OnReload(fn func()) {
+	state.Lock()
+	defer state.Unlock()
+	state.reloaders = append(state.reloaders, fn)
+}
+
+// Reload reads the .env and config files again and applies the reloadable
+// settings they hold. Settings from the process environment keep their
+// values. Other changed settings are logged since they need a restart. If a
+// file can't be read or holds invalid values, nothing changes.
+func Reload() error {
+	dotenv, err := godotenv.Read()
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	path, err := configFile()
+	if err != nil {
+		return err
+	}
+	file := map[string]string{}
+	if path != "" {
+		if file, err = readFile(path); err != nil {
+			return err
+		}
+	}
+	for name, value := range dotenv {
+		if setting, ok := lookup(name); ok {
+			if err := setting.check(value); err != nil {
+				return err
+			}
+		}
+	}
+
+	state.Lock()
+	environment := state.environment
+	reloaders := append([]func(){}, state.reloaders...)
+	state.Unlock()
+
+	changed := 0
+	for _, setting := range Settings {
+		if environment[setting.Name] || readFromSecretFile(setting.Name) {
+			continue
+		}
+		value, ok := dotenv[setting.Name]
+		if !ok {
+			value = file[setting.Name]
+		}
+		if value == os.Getenv(setting.Name) {
+			continue
+		}
+		if !setting.Reloadable {
+			log.Printf("%s changed; restart the bot to apply it", setting.Name)
+			continue
+		}
+		if value == "" {
+			os.Unsetenv(setting.Name)
+		} else {
+			os.Setenv(setting.Name, value)
+		}
+		changed++
+	}
+
+	for _, fn := range reloaders {
+		fn()
+	}
+	log.Printf("Configuration reloaded, %d settings changed", changed)
+	return nil
+}
+
+// readFromSecretFile reports whether a secret's value came from its <NAME>_FILE
+func readFromSecretFile(name string) bool {
+	for _, secret := range Secrets {
+		if secret == name {
+			return os.Getenv(name+"_FILE") != ""
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Kind is the type of value a setting holds
+type Kind int
+
+// Kinds of setting values
+const (
+	KindString Kind = iota
+	KindInt
+	KindFloat
+	KindBool
+)
+
+// Setting describes a variable the bot is configured with
+type Setting struct {
+	Name string
+	Kind Kind
+	// Reloadable settings take effect on SIGHUP; the rest need a restart
+	Reloadable bool
+}
+
+// Settings lists every variable the bot reads. Config files may only set these
+// (and the <NAME>_FILE variants of Secrets).
+var Settings = []Setting{
+	{Name: "DISCORD_TOKEN"},
+	{Name: "DISCORD_CLIENT_ID"},
+	{Name: "DISCORD_CLIENT_SECRET"},
+	{Name: "DASHBOARD_URL"},
+	{Name: "DEV_GUILD_ID"},
+	{Name: "SPOTIFY_ID"},
+	{Name: "SPOTIFY_SECRET"},
+	{Name: "SPOTIFY_REDIRECT_URL"},
+	{Name: "SPOTIFY_TOKEN_KEY"},
+	{Name: "LASTFM_API_KEY"},
+	{Name: "INVIDIOUS_URL", Reloadable: true},
+	{Name: "RADIO_PRESETS_FILE"},
+	{Name: "PLUGINS_DIR"},
+	{Name: "PLUGIN_ENV"},
+	{Name: "LAVALINK_ADDRESS"},
+	{Name: "LAVALINK_PASSWORD"},
+	{Name: "LAVALINK_SECURE", Kind: KindBool},
+	{Name: "STORAGE_DRIVER"},
+	{Name: "DATABASE_URL"},
+	{Name: "DATA_DIR"},
+	{Name: "READ_ONLY", Kind: KindBool},
+	{Name: "CACHE_DIR"},
+	{Name: "CACHE_MAX_MB", Kind: KindInt, Reloadable: true},
+	{Name: "CPU_QUALITY_THRESHOLD", Kind: KindFloat},
+	{Name: "OPUS_PASSTHROUGH", Kind: KindBool},
+	{Name: "DOWNLOAD_WORKERS", Kind: KindInt},
+	{Name: "DOWNLOAD_MAX_CONCURRENT", Kind: KindInt},
+	{Name: "DOWNLOAD_RATE_LIMIT_KB", Kind: KindInt, Reloadable: true},
+	{Name: "AUTOPLAY_AVOID_HOURS", Kind: KindInt, Reloadable: true},
+	{Name: "TTS_ENGINE"},
+	{Name: "PIPER_MODEL"},
+	{Name: "GOOGLE_TTS_API_KEY"},
+	{Name: "GOOGLE_TTS_VOICE"},
+	{Name: "AZURE_SPEECH_KEY"},
+	{Name: "AZURE_SPEECH_REGION"},
+	{Name: "AZURE_SPEECH_VOICE"},
+	{Name: "TELEMETRY_ENDPOINT"},
+	{Name: "TELEMETRY_INTERVAL_MINUTES", Kind: KindInt},
+	{Name: "WEBHOOK_URLS"},
+	{Name: "HTTP_ADDR"},
+	{Name: "ADMIN_SOCKET"},
+	{Name: "KIOSK_ENABLED", Kind: KindBool},
+	{Name: "SHUTDOWN_GRACE_SECONDS", Kind: KindInt},
+	{Name: "YT_COOKIE_FILE", Reloadable: true},
+	{Name: "YT_COOKIE_FILES", Reloadable: true},
+	{Name: "YT_PLAYER_CLIENTS", Reloadable: true},
+	{Name: "YT_PO_TOKEN", Reloadable: true},
+	{Name: "YT_VISITOR_DATA", Reloadable: true},
+}
+
+// lookup returns the setting called name
+func lookup(name string) (Setting, bool) {
+	for _, setting := range Settings {
+		if setting.Name == name {
+			return setting, true
+		}
+	}
+	for _, secret := range Secrets {
+		if name == secret+"_FILE" {
+			return Setting{Name: name}, true
+		}
+	}
+	return Setting{}, false
+}
+
+// check reports whether value is valid for the setting
+func (s Setting) check(value string) error {
+	var err error
+	switch s.Kind {
+	case KindInt:
+		_, err = strconv.Atoi(value)
+	case KindFloat:
+		_, err = strconv.ParseFloat(value, 64)
+	case KindBool:
+		_, err = strconv.ParseBool(value)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s %q", s.Name, value)
+	}
+	return nil
+}
+
+// validate checks every configured setting holds a value of its kind
+func validate() error {
+	var problems []error
+	for _, setting := range Settings {
+		if value := os.Getenv(setting.Name); value != "" {
+			if err := setting.check(value); err != nil {
+				problems = append(problems, err)
+			}
+		}
+	}
+	return joinProblems("invalid configuration", problems)
+}
+
+// String returns the setting name with surrounding spaces removed, or def if it's unset
+func String(name, def string) string {
+	if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+		return value
+	}
+	return def
+}
+
+// List returns the comma separated values of the setting name, leaving out empty ones
+func List(name string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(name), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// Int returns the integer setting name, or def if it's unset or valid rejects it
+func Int(name string, def int, valid func(int) bool) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	if parsed, err := strconv.Atoi(value); err == nil && (valid == nil || valid(parsed)) {
+		return parsed
+	}
+	log.Printf("Warning: invalid %s %q, using %d", name, value, def)
+	return def
+}
+
+// Float returns the number setting name, or def if it's unset or invalid
+func Float(name string, def float64) float64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+		return parsed
+	}
+	log.Printf("Warning: invalid %s %q, using %g", name, value, def)
+	return def
+}
+
+// Bool returns the boolean setting name, or def if it's unset or invalid
+func Bool(name string, def bool) bool {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	if parsed, err := strconv.ParseBool(value); err == nil {
+		return parsed
+	}
+	log.Printf("Warning: invalid %s %q, using %t", name, value, def)
+	return def
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+
+	"discordbot/audio"
+)
+
+// checkContentFilter rejects tracks Spotify flags as explicit or whose titles
+// contain one of the guild's filtered keywords, if the guild enabled either.
+// The returned error is meant to be shown to the user.
+func checkContentFilter(guildID string, tracks []*audio.Track) error {
+	guild := settingsStore.Get(guildID)
+	if !guild.ExplicitFilter && len(guild.FilteredKeywords) == 0 {
+		return nil
+	}
+
+	// Don't block the request if metadata is unavailable; playback will report real errors
+	fillTracksInfo(tracks)
+	for _, track := range tracks {
+		if keyword := matchKeyword(track.Title, guild.FilteredKeywords); keyword != "" {
+			return errors.New(trGuild(guildID, "filter.keyword", track.DisplayName()))
+		}
+		if guild.ExplicitFilter && isExplicit(track) {
+			return errors.New(trGuild(guildID, "filter.explicit", track.DisplayName()))
+		}
+	}
+	return nil
+}
+
+// isExplicit reports whether Spotify flags a track as explicit. Spotify links
+// are checked directly; other tracks are matched to a Spotify track by title.
+func isExplicit(track *audio.Track) bool {
+	if spotifyClient == nil {
+		return false
+	}
+
+	var explicit bool
+	var err error
+	if trackID, idErr := spotifyClient.GetTrackID(track.URL); idErr == nil {
+		explicit, err = spotifyClient.IsExplicit(trackID)
+	} else if track.Title != "" {
+		explicit, err = spotifyClient.MatchExplicit(track.Title)
+	}
+	if err != nil {
+		log.Printf("Failed to check whether %s is explicit: %v", track.URL, err)
+		return false
+	}
+	return explicit
+}
+
+// matchKeyword returns the first keyword that appears in title as a whole word, or empty if none does.
+// Words are delimited by anything but letters and digits in any script, since
+// \b only knows ASCII letters and would match "café" inside "cafés".
+func matchKeyword(title string, keywords []string) string {
+	if title == "" {
+		return ""
+	}
+	for _, keyword := range keywords {
+		pattern := `(?i)(^|[^\p{L}\p{N}])` + regexp.QuoteMeta(keyword) + `($|[^\p{L}\p{N}])`
+		if matched, _ := regexp.MatchString(pattern, title); matched {
+			return keyword
+		}
+	}
+	return ""
+}
+
+// normalizeKeyword trims and lowercases a filtered keyword
+func normalizeKeyword(keyword string) string {
+	return strings.ToLower(strings.TrimSpace(keyword))
+}
@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestMatchKeyword(t *testing.T) {
+	tests := []struct {
+		title    string
+		keywords []string
+		want     string
+	}{
+		{"Explicit Song (Official Video)", []string{"explicit"}, "explicit"},
+		{"Inexplicit remix", []string{"explicit"}, ""},
+		{"Song [EXPLICIT]", []string{"explicit"}, "explicit"},
+		{"über alles", []string{"über"}, "über"},
+		{"Ärger im Paradies", []string{"ärger"}, "ärger"},
+		{"Les cafés de Paris", []string{"café"}, ""},
+		{"Un café à Paris", []string{"café"}, "café"},
+		{"Песня о войне", []string{"войне"}, "войне"},
+		{"Песня о войнесе", []string{"войне"}, ""},
+		{"Track 2", []string{"2"}, "2"},
+		{"Track 22", []string{"2"}, ""},
+		{"a.b.c", []string{"a.b"}, "a.b"},
+		{"", []string{"explicit"}, ""},
+		{"clean, explicit and live", []string{"live", "explicit"}, "live"},
+	}
+	for _, test := range tests {
+		if got := matchKeyword(test.title, test.keywords); got != test.want {
+			t.Errorf("matchKeyword(%q, %q) got %q, want %q", test.title, test.keywords, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package control
+
+import (
+	"errors"
+	"time"
+
+	"discordbot/audio"
+)
+
+// Errors returned by a Player when a control doesn't apply to the current state
+var (
+	ErrNotConnected = errors.New("the bot isn't in a voice channel in that server")
+	ErrNotPlaying   = errors.New("nothing is playing")
+	ErrNotPaused    = errors.New("playback isn't paused")
+	ErrBadPosition  = errors.New("no track at that position")
+)
+
+// Track is a playing or queued track as shown to API clients
+type Track struct {
+	URL       string  `json:"url"`
+	Title     string  `json:"title"`
+	Duration  float64 `json:"duration_seconds,omitempty"`
+	Requester string  `json:"requester,omitempty"`
+}
+
+// State is a snapshot of a guild's player
+type State struct {
+	GuildID    string  `json:"guild_id"`
+	ChannelID  string  `json:"channel_id,omitempty"`
+	Connected  bool    `json:"connected"`
+	Playing    bool    `json:"playing"`
+	Paused     bool    `json:"paused"`
+	Repeat     bool    `json:"repeat"`
+	Autoplay   bool    `json:"autoplay"`
+	NowPlaying *Track  `json:"now_playing,omitempty"`
+	Queue      []Track `json:"queue"`
+}
+
+// Player controls playback in a guild on behalf of clients outside Discord.
+// Queue positions start at 1, like in the /queue command.
+type Player interface {
+	State(guildID string) State
+	Enqueue(guildID, url, requester string) (Track, error)
+	Skip(guildID string) error
+	Pause(guildID string) error
+	Resume(guildID string) error
+	Move(guildID string, from, to int) error
+	Remove(guildID string, position int) error
+}
+
+// TrackFrom converts a queue entry for API clients
+func TrackFrom(t *audio.Track) Track {
+	return Track{
+		URL:       t.URL,
+		Title:     t.DisplayName(),
+		Duration:  t.Duration.Round(time.Second).Seconds(),
+		Requester: t.Requester,
+	}
+}
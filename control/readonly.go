@@ -0,0 +1,46 @@
+package control
+
+import "errors"
+
+// ErrReadOnly is returned by a read-only player for every control
+var ErrReadOnly = errors.New("this bot instance is read-only")
+
+// readOnlyPlayer shows the wrapped player's state but rejects all controls
+type readOnlyPlayer struct {
+	Player
+}
+
+// ReadOnly wraps player so clients can see its state but not change it
+func ReadOnly(player Player) Player {
+	return readOnlyPlayer{Player: player}
+}
+
+// Enqueue always fails with ErrReadOnly
+func (readOnlyPlayer) Enqueue(guildID, url, requester string) (Track, error) {
+	return Track{}, ErrReadOnly
+}
+
+// Skip always fails with ErrReadOnly
+func (readOnlyPlayer) Skip(guildID string) error {
+	return ErrReadOnly
+}
+
+// Pause always fails with ErrReadOnly
+func (readOnlyPlayer) Pause(guildID string) error {
+	return ErrReadOnly
+}
+
+// Resume always fails with ErrReadOnly
+func (readOnlyPlayer) Resume(guildID string) error {
+	return ErrReadOnly
+}
+
+// Move always fails with ErrReadOnly
+func (readOnlyPlayer) Move(guildID string, from, to int) error {
+	return ErrReadOnly
+}
+
+// Remove always fails with ErrReadOnly
+func (readOnlyPlayer) Remove(guildID string, position int) error {
+	return ErrReadOnly
+}
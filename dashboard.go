@@ -0,0 +1,52 @@
+package main
+
+import (
+	"log"
+	"net/http"
+
+	"discordbot/config"
+	"discordbot/control"
+	"discordbot/dashboard"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// dashboardGuilds answers the dashboard's membership and permission questions from the bot's session
+type dashboardGuilds struct {
+	s *discordgo.Session
+}
+
+// HasGuild reports whether the bot is in a guild
+func (g dashboardGuilds) HasGuild(guildID string) bool {
+	_, err := g.s.State.Guild(guildID)
+	return err == nil
+}
+
+// CanControl reports whether a user may control playback in a guild,
+// using the same DJ rules as slash commands
+func (g dashboardGuilds) CanControl(guildID, userID string) bool {
+	member, err := g.s.State.Member(guildID, userID)
+	if err != nil {
+		member, err = g.s.GuildMember(guildID, userID)
+		if err != nil {
+			return false
+		}
+	}
+	return memberIsDJ(g.s, guildID, member)
+}
+
+// registerDashboard mounts the web dashboard on mux if Discord OAuth2
+// credentials and the dashboard's public URL are configured
+func registerDashboard(s *discordgo.Session, mux *http.ServeMux, player control.Player) {
+	cfg := dashboard.Config{
+		ClientID:     config.String("DISCORD_CLIENT_ID", ""),
+		ClientSecret: config.String("DISCORD_CLIENT_SECRET", ""),
+		BaseURL:      config.String("DASHBOARD_URL", ""),
+	}
+	if cfg.ClientID == "" || cfg.ClientSecret == "" || cfg.BaseURL == "" {
+		return
+	}
+
+	dashboard.New(cfg, player, dashboardGuilds{s: s}).Register(mux)
+	log.Printf("Web dashboard enabled at %s/dashboard/", cfg.BaseURL)
+}
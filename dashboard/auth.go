@@ -0,0 +1,215 @@
+package dashboard
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	discordAPI = "https://discord.com/api/v10"
+
+	sessionCookie = "dashboard_session"
+	stateCookie   = "dashboard_oauth_state"
+
+	// sessionTTL is how long a dashboard login lasts. The user's guild list
+	// is fetched at login, so joining a server requires logging in again.
+	sessionTTL = 12 * time.Hour
+)
+
+// discordEndpoint is Discord's OAuth2 endpoint
+var discordEndpoint = oauth2.Endpoint{
+	AuthURL:   "https://discord.com/oauth2/authorize",
+	TokenURL:  "https://discord.com/api/oauth2/token",
+	AuthStyle: oauth2.AuthStyleInParams,
+}
+
+// User is the Discord account logged into the dashboard
+type User struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+// session is a logged in dashboard user along with the guilds they are a member of
+type session struct {
+	User    User
+	Guilds  map[string]string // guild ID to name
+	Expires time.Time
+}
+
+// sessions keeps the active dashboard logins in memory, keyed by cookie value
+type sessions struct {
+	mu   sync.Mutex
+	byID map[string]*session
+}
+
+// create stores a new session and returns its ID
+func (ss *sessions) create(sess *session) (string, error) {
+	id, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	// Forget expired logins while we're here
+	now := time.Now()
+	for key, s := range ss.byID {
+		if now.After(s.Expires) {
+			delete(ss.byID, key)
+		}
+	}
+	ss.byID[id] = sess
+	return id, nil
+}
+
+// get returns the session for a request, or nil if the user isn't logged in
+func (ss *sessions) get(r *http.Request) *session {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return nil
+	}
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	sess, ok := ss.byID[cookie.Value]
+	if !ok || time.Now().After(sess.Expires) {
+		return nil
+	}
+	return sess
+}
+
+// delete ends the session of a request
+func (ss *sessions) delete(r *http.Request) {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return
+	}
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	delete(ss.byID, cookie.Value)
+}
+
+// randomToken returns a random hex string for session IDs and OAuth state
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating token: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// setCookie sets a dashboard cookie that scripts can't read
+func (d *Server) setCookie(w http.ResponseWriter, name, value string, maxAge time.Duration) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/dashboard",
+		MaxAge:   int(maxAge / time.Second),
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(d.baseURL, "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// handleLogin redirects to Discord to authorize the dashboard
+func (d *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	state, err := randomToken()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	d.setCookie(w, stateCookie, state, 10*time.Minute)
+	http.Redirect(w, r, d.oauth.AuthCodeURL(state), http.StatusFound)
+}
+
+// handleCallback completes the OAuth2 flow and starts a dashboard session
+func (d *Server) handleCallback(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(stateCookie)
+	if err != nil || cookie.Value == "" || cookie.Value != r.URL.Query().Get("state") {
+		http.Error(w, "invalid OAuth state, please log in again", http.StatusBadRequest)
+		return
+	}
+	d.setCookie(w, stateCookie, "", -1)
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	token, err := d.oauth.Exchange(ctx, r.URL.Query().Get("code"))
+	if err != nil {
+		http.Error(w, "Discord login failed", http.StatusBadGateway)
+		return
+	}
+	client := d.oauth.Client(ctx, token)
+
+	var user struct {
+		ID         string `json:"id"`
+		Username   string `json:"username"`
+		GlobalName string `json:"global_name"`
+	}
+	if err := getJSON(client, discordAPI+"/users/@me", &user); err != nil {
+		http.Error(w, "error loading your Discord account", http.StatusBadGateway)
+		return
+	}
+
+	var guilds []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := getJSON(client, discordAPI+"/users/@me/guilds", &guilds); err != nil {
+		http.Error(w, "error loading your Discord servers", http.StatusBadGateway)
+		return
+	}
+
+	sess := &session{
+		User:    User{ID: user.ID, Username: user.Username},
+		Guilds:  make(map[string]string, len(guilds)),
+		Expires: time.Now().Add(sessionTTL),
+	}
+	if user.GlobalName != "" {
+		sess.User.Username = user.GlobalName
+	}
+	for _, g := range guilds {
+		sess.Guilds[g.ID] = g.Name
+	}
+
+	id, err := d.sessions.create(sess)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	d.setCookie(w, sessionCookie, id, sessionTTL)
+	http.Redirect(w, r, "/dashboard/", http.StatusFound)
+}
+
+// handleLogout ends the dashboard session
+func (d *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	d.sessions.delete(r)
+	d.setCookie(w, sessionCookie, "", -1)
+	http.Redirect(w, r, "/dashboard/", http.StatusSeeOther)
+}
+
+// getJSON fetches url with an authorized client and decodes the response into v
+func getJSON(client *http.Client, url string, v interface{}) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
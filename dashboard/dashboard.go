@@ -0,0 +1,211 @@
+package dashboard
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"discordbot/control"
+
+	"golang.org/x/oauth2"
+)
+
+//go:embed static
+var static embed.FS
+
+// Config holds the Discord application credentials used for dashboard logins
+type Config struct {
+	ClientID     string
+	ClientSecret string
+	BaseURL      string // Public URL the bot's HTTP server is reachable at, e.g. https://bot.example.com
+}
+
+// Guilds tells the dashboard which servers the bot is in and who may control playback there
+type Guilds interface {
+	HasGuild(guildID string) bool
+	CanControl(guildID, userID string) bool
+}
+
+// Server serves the web dashboard and the internal API it uses
+type Server struct {
+	player   control.Player
+	guilds   Guilds
+	oauth    *oauth2.Config
+	baseURL  string
+	sessions *sessions
+}
+
+// New creates a dashboard controlling player
+func New(cfg Config, player control.Player, guilds Guilds) *Server {
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	return &Server{
+		player: player,
+		guilds: guilds,
+		oauth: &oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			Endpoint:     discordEndpoint,
+			RedirectURL:  baseURL + "/dashboard/callback",
+			Scopes:       []string{"identify", "guilds"},
+		},
+		baseURL:  baseURL,
+		sessions: &sessions{byID: make(map[string]*session)},
+	}
+}
+
+// Register mounts the dashboard under /dashboard/ on mux
+func (d *Server) Register(mux *http.ServeMux) {
+	files, err := fs.Sub(static, "static")
+	if err != nil {
+		log.Fatalf("Error loading dashboard files: %v", err)
+	}
+
+	mux.Handle("GET /dashboard/", http.StripPrefix("/dashboard/", http.FileServerFS(files)))
+	mux.HandleFunc("GET /dashboard/login", d.handleLogin)
+	mux.HandleFunc("GET /dashboard/callback", d.handleCallback)
+	mux.HandleFunc("POST /dashboard/logout", d.handleLogout)
+
+	mux.HandleFunc("GET /dashboard/api/me", d.handleMe)
+	mux.HandleFunc("GET /dashboard/api/guilds/{guild}", d.handleState)
+	mux.HandleFunc("POST /dashboard/api/guilds/{guild}/{action}", d.handleControl)
+}
+
+// guildInfo is a server listed in the dashboard
+type guildInfo struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	CanControl bool   `json:"can_control"`
+}
+
+// handleMe returns the logged in user and the servers they share with the bot
+func (d *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	sess := d.sessions.get(r)
+	if sess == nil {
+		writeError(w, http.StatusUnauthorized, "not logged in")
+		return
+	}
+
+	guilds := []guildInfo{}
+	for id, name := range sess.Guilds {
+		if d.guilds.HasGuild(id) {
+			guilds = append(guilds, guildInfo{ID: id, Name: name, CanControl: d.guilds.CanControl(id, sess.User.ID)})
+		}
+	}
+	sort.Slice(guilds, func(a, b int) bool {
+		return strings.ToLower(guilds[a].Name) < strings.ToLower(guilds[b].Name)
+	})
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"user":   sess.User,
+		"guilds": guilds,
+	})
+}
+
+// authorize returns the session and guild of a guild API request,
+// writing an error response if the user may not see that guild
+func (d *Server) authorize(w http.ResponseWriter, r *http.Request) (*session, string, bool) {
+	sess := d.sessions.get(r)
+	if sess == nil {
+		writeError(w, http.StatusUnauthorized, "not logged in")
+		return nil, "", false
+	}
+
+	guildID := r.PathValue("guild")
+	if _, member := sess.Guilds[guildID]; !member || !d.guilds.HasGuild(guildID) {
+		writeError(w, http.StatusForbidden, "you don't share that server with the bot")
+		return nil, "", false
+	}
+	return sess, guildID, true
+}
+
+// handleState returns the player state of a guild
+func (d *Server) handleState(w http.ResponseWriter, r *http.Request) {
+	sess, guildID, ok := d.authorize(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"state":       d.player.State(guildID),
+		"can_control": d.guilds.CanControl(guildID, sess.User.ID),
+	})
+}
+
+// controlRequest is the body of move and remove requests
+type controlRequest struct {
+	Position int `json:"position"`
+	To       int `json:"to"`
+}
+
+// handleControl runs a player control for a guild
+func (d *Server) handleControl(w http.ResponseWriter, r *http.Request) {
+	// Requiring JSON means browsers preflight cross-site requests, which we never allow
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		writeError(w, http.StatusUnsupportedMediaType, "requests must be JSON")
+		return
+	}
+
+	sess, guildID, ok := d.authorize(w, r)
+	if !ok {
+		return
+	}
+	if !d.guilds.CanControl(guildID, sess.User.ID) {
+		writeError(w, http.StatusForbidden, "you need the DJ role or Manage Server permission to control playback")
+		return
+	}
+
+	var req controlRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+
+	var err error
+	switch r.PathValue("action") {
+	case "skip":
+		err = d.player.Skip(guildID)
+	case "pause":
+		err = d.player.Pause(guildID)
+	case "resume":
+		err = d.player.Resume(guildID)
+	case "move":
+		err = d.player.Move(guildID, req.Position, req.To)
+	case "remove":
+		err = d.player.Remove(guildID, req.Position)
+	default:
+		writeError(w, http.StatusNotFound, "unknown action")
+		return
+	}
+
+	switch {
+	case errors.Is(err, control.ErrBadPosition):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case err != nil:
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		log.Printf("Dashboard: %s ran %s in guild %s", sess.User.Username, r.PathValue("action"), guildID)
+		writeJSON(w, http.StatusOK, d.player.State(guildID))
+	}
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error writing dashboard response: %v", err)
+	}
+}
+
+// writeError writes an API error as JSON
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
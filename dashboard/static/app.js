@@ -0,0 +1,149 @@
+// Dashboard client: polls the player state of the selected server and
+// sends controls to the bot's internal API.
+const refreshInterval = 3000;
+
+const $ = (id) => document.getElementById(id);
+
+let guildID = "";
+let canControl = false;
+
+async function api(path, options = {}) {
+	const resp = await fetch("/dashboard/api" + path, options);
+	const body = await resp.json();
+	if (!resp.ok) {
+		const err = new Error(body.error || resp.statusText);
+		err.status = resp.status;
+		throw err;
+	}
+	return body;
+}
+
+function control(action, body = {}) {
+	$("error").textContent = "";
+	return api(`/guilds/${guildID}/${action}`, {
+		method: "POST",
+		headers: { "Content-Type": "application/json" },
+		body: JSON.stringify(body),
+	})
+		.then(render)
+		.catch((err) => ($("error").textContent = err.message));
+}
+
+function formatDuration(seconds) {
+	if (!seconds) {
+		return "";
+	}
+	const m = Math.floor(seconds / 60);
+	const s = String(Math.floor(seconds % 60)).padStart(2, "0");
+	return `${m}:${s}`;
+}
+
+function trackLabel(track) {
+	const duration = formatDuration(track.duration_seconds);
+	return duration ? `${track.title} (${duration})` : track.title;
+}
+
+function render(state) {
+	$("current").textContent = state.now_playing ? trackLabel(state.now_playing) : "Nothing is playing";
+
+	const flags = [];
+	if (!state.connected) flags.push("Not in a voice channel");
+	if (state.paused) flags.push("Paused");
+	if (state.repeat) flags.push("Repeat on");
+	if (state.autoplay) flags.push("Autoplay on");
+	$("flags").textContent = flags.join(" · ");
+
+	$("pause").disabled = !canControl || !state.playing || state.paused;
+	$("resume").disabled = !canControl || !state.paused;
+	$("skip").disabled = !canControl || !state.playing;
+
+	const queue = $("queue");
+	queue.replaceChildren();
+	state.queue.forEach((track, idx) => {
+		const position = idx + 1;
+		const item = document.createElement("li");
+
+		const title = document.createElement("span");
+		title.className = "title";
+		title.textContent = trackLabel(track);
+		item.append(title);
+
+		if (track.requester) {
+			const requester = document.createElement("span");
+			requester.className = "requester";
+			requester.textContent = track.requester;
+			item.append(requester);
+		}
+
+		if (canControl) {
+			const up = button("↑", () => control("move", { position, to: position - 1 }));
+			up.disabled = position === 1;
+			const down = button("↓", () => control("move", { position, to: position + 1 }));
+			down.disabled = position === state.queue.length;
+			item.append(up, down, button("✕", () => control("remove", { position })));
+		}
+		queue.append(item);
+	});
+	$("queue-empty").hidden = state.queue.length > 0;
+}
+
+function button(label, onClick) {
+	const b = document.createElement("button");
+	b.textContent = label;
+	b.addEventListener("click", onClick);
+	return b;
+}
+
+async function refresh() {
+	if (!guildID) {
+		return;
+	}
+	try {
+		const body = await api(`/guilds/${guildID}`);
+		canControl = body.can_control;
+		render(body.state);
+	} catch (err) {
+		$("error").textContent = err.message;
+	}
+}
+
+async function start() {
+	let me;
+	try {
+		me = await api("/me");
+	} catch (err) {
+		if (err.status === 401) {
+			$("login").hidden = false;
+			return;
+		}
+		throw err;
+	}
+
+	$("account").hidden = false;
+	$("username").textContent = me.user.username;
+	$("player").hidden = false;
+
+	const select = $("guild");
+	for (const guild of me.guilds) {
+		select.append(new Option(guild.name, guild.id));
+	}
+	if (me.guilds.length === 0) {
+		$("no-guilds").hidden = false;
+		$("now-playing").hidden = true;
+		return;
+	}
+
+	guildID = select.value;
+	select.addEventListener("change", () => {
+		guildID = select.value;
+		refresh();
+	});
+	document.querySelectorAll(".controls button").forEach((b) => {
+		b.addEventListener("click", () => control(b.dataset.action));
+	});
+
+	refresh();
+	setInterval(refresh, refreshInterval);
+}
+
+start();
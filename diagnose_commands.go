@@ -0,0 +1,109 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// requiredPermission is a channel permission the bot needs, with the message key explaining why
+type requiredPermission struct {
+	Name       string
+	Permission int64
+	ReasonKey  string
+}
+
+// Permissions the bot needs in the text channel it announces in
+var textPermissions = []requiredPermission{
+	{"View Channel", discordgo.PermissionViewChannel, "diagnose.reason.view"},
+	{"Send Messages", discordgo.PermissionSendMessages, "diagnose.reason.send"},
+	{"Embed Links", discordgo.PermissionEmbedLinks, "diagnose.reason.embed"},
+	{"Manage Messages", discordgo.PermissionManageMessages, "diagnose.reason.manage"},
+}
+
+// Permissions the bot needs in the voice channel it plays in
+var voicePermissions = []requiredPermission{
+	{"View Channel", discordgo.PermissionViewChannel, "diagnose.reason.view"},
+	{"Connect", discordgo.PermissionVoiceConnect, "diagnose.reason.connect"},
+	{"Speak", discordgo.PermissionVoiceSpeak, "diagnose.reason.speak"},
+}
+
+// handleDiagnose reports which permissions the bot is missing in the current
+// text channel and in the voice channel it would play in
+func handleDiagnose(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	var msg strings.Builder
+	missing, err := checkChannelPermissions(s, i, &msg, i.ChannelID, textPermissions)
+	unchecked := err != nil
+
+	// Check the caller's voice channel, or the bot's if the caller isn't in one
+	voiceChannelID := ""
+	if vs, err := findUserVoiceState(s, i.GuildID, i.Member.User.ID); err == nil {
+		voiceChannelID = vs.ChannelID
+	} else if vi := voiceManager.GetVoiceInstance(i.GuildID); vi.ChannelID != "" {
+		voiceChannelID = vi.ChannelID
+	}
+
+	msg.WriteString("\n")
+	if voiceChannelID != "" {
+		voiceMissing, err := checkChannelPermissions(s, i, &msg, voiceChannelID, channelPermissions(s, voiceChannelID))
+		missing += voiceMissing
+		unchecked = unchecked || err != nil
+	} else {
+		msg.WriteString(tr(i, "diagnose.no_voice") + "\n")
+	}
+
+	msg.WriteString("\n")
+	switch {
+	case missing > 0:
+		msg.WriteString(tr(i, "diagnose.missing", missing))
+	case unchecked:
+		msg.WriteString(tr(i, "diagnose.unchecked"))
+	default:
+		msg.WriteString(tr(i, "diagnose.all_good"))
+	}
+	editResponse(s, i, msg.String())
+}
+
+// checkChannelPermissions writes a checklist of the bot's permissions in a
+// channel to msg and returns how many are missing, or an error if they
+// couldn't be read
+func checkChannelPermissions(s *discordgo.Session, i *discordgo.InteractionCreate, msg *strings.Builder, channelID string, required []requiredPermission) (int, error) {
+	msg.WriteString(tr(i, "diagnose.channel_header", channelID) + "\n")
+
+	perms, err := s.State.UserChannelPermissions(s.State.User.ID, channelID)
+	if err != nil {
+		msg.WriteString(tr(i, "diagnose.unknown", err) + "\n")
+		return 0, err
+	}
+
+	missing := 0
+	for _, p := range required {
+		// Administrator implies every permission
+		if perms&p.Permission != 0 || perms&discordgo.PermissionAdministrator != 0 {
+			msg.WriteString("✅ " + p.Name + "\n")
+			continue
+		}
+		missing++
+		msg.WriteString("❌ " + p.Name + " — " + tr(i, p.ReasonKey) + "\n")
+	}
+	return missing, nil
+}
+
+// missingPermissions returns the names of the required permissions the bot lacks in a channel
+func missingPermissions(s *discordgo.Session, channelID string, required []requiredPermission) ([]string, error) {
+	perms, err := s.State.UserChannelPermissions(s.State.User.ID, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if perms&discordgo.PermissionAdministrator != 0 {
+		return nil, nil
+	}
+
+	var missing []string
+	for _, p := range required {
+		if perms&p.Permission == 0 {
+			missing = append(missing, p.Name)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"discordbot/audio"
+	"discordbot/config"
+	"discordbot/events"
+)
+
+// prefetchDepth is how many upcoming queue entries are downloaded ahead of playback
+const prefetchDepth = 10
+
+// urgentDownloads is how many downloads playback waits on may run beyond
+// DOWNLOAD_WORKERS unless DOWNLOAD_MAX_CONCURRENT says otherwise
+const urgentDownloads = 2
+
+const (
+	// maxDownloadAttempts caps how often a single track's download is tried
+	maxDownloadAttempts = 3
+
+	// downloadBackoff is the wait before the second attempt; it doubles after each one
+	downloadBackoff = 2 * time.Second
+)
+
+// downloads fetches YouTube audio into the cache through a bounded pool of
+// workers. It is set up by setupDownloads.
+var downloads *downloadPool
+
+// download is a single fetch into the cache that any number of callers can wait on
+type download struct {
+	guildID string // the guild that requested it first, whose rate limit applies
+	done    chan struct{}
+	urgent  chan struct{} // closed once playback is waiting on this download
+	promote sync.Once
+	file    string
+	err     error
+}
+
+// downloadPool runs at most cap(slots) background downloads at a time and
+// never downloads the same video twice concurrently: every guild requesting a
+// video waits on the same download, and other processes sharing the cache
+// directory wait on its lock. Downloads playback is waiting on skip the line,
+// but no more than cap(active) downloads of any kind run at once.
+type downloadPool struct {
+	mu         sync.Mutex
+	downloader audio.Downloader
+	slots      chan struct{}
+	active     chan struct{}
+	inflight   map[string]*download
+}
+
+// newDownloadPool creates a pool with the given number of background workers
+// and overall download cap, fetching through downloader
+func newDownloadPool(workers, maxActive int, downloader audio.Downloader) *downloadPool {
+	return &downloadPool{
+		downloader: downloader,
+		slots:      make(chan struct{}, workers),
+		active:     make(chan struct{}, maxActive),
+		inflight:   make(map[string]*download),
+	}
+}
+
+// setupDownloads sizes the pool from DOWNLOAD_WORKERS and DOWNLOAD_MAX_CONCURRENT
+// and starts downloading upcoming tracks whenever a queue changes
+func setupDownloads() {
+	positive := func(n int) bool { return n > 0 }
+	workers := config.Int("DOWNLOAD_WORKERS", 3, positive)
+	// Leave room for tracks playback is waiting on while the workers are busy
+	maxActive := config.Int("DOWNLOAD_MAX_CONCURRENT", workers+urgentDownloads, positive)
+	downloads = newDownloadPool(workers, maxActive, downloader)
+	setupDownloadRate()
+
+	eventBus.Subscribe(func(e events.Event) {
+		if e.Type == events.QueueUpdate {
+			go prefetchQueue(e.GuildID, e.Queue)
+		}
+	})
+}
+
+// prefetchQueue starts downloading the first few YouTube tracks of a guild's queue
+func prefetchQueue(guildID string, queue []*audio.Track) {
+	// Lavalink nodes fetch their own audio
+	voiceManager.Mu.Lock()
+	vi := voiceManager.Instances[guildID]
+	voiceManager.Mu.Unlock()
+	if vi == nil || vi.IsRemote() {
+		return
+	}
+
+	if len(queue) > prefetchDepth {
+		queue = queue[:prefetchDepth]
+	}
+	for _, track := range queue {
+		if !isYouTubeURL(track.URL) || track.Live {
+			continue
+		}
+		if videoID, err := downloader.GetVideoID(track.URL); err == nil {
+			downloads.start(videoID, guildID)
+		}
+	}
+}
+
+// Fetch returns the cached file for a video, downloading it first for guildID
+// if needed. A background download of the same video is joined and moved to the front.
+func (p *downloadPool) Fetch(videoID, guildID string) (string, error) {
+	cached, d := p.start(videoID, guildID)
+	if d == nil {
+		log.Printf("Playing %s from the cache", videoID)
+		return cached, nil
+	}
+	d.promote.Do(func() { close(d.urgent) })
+	<-d.done
+	return d.file, d.err
+}
+
+// start begins downloading a video for guildID in the background unless it's
+// already cached or being downloaded. It returns the cached file or the download.
+func (p *downloadPool) start(videoID, guildID string) (string, *download) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if d, ok := p.inflight[videoID]; ok {
+		return "", d
+	}
+	if cached, ok := audioCache.Lookup(videoID); ok {
+		return cached, nil
+	}
+
+	d := &download{guildID: guildID, done: make(chan struct{}), urgent: make(chan struct{})}
+	p.inflight[videoID] = d
+	go p.run(videoID, d)
+	return "", d
+}
+
+// run waits for a free worker, or for playback to need the file, and for
+// room under the download cap, then downloads it
+func (p *downloadPool) run(videoID string, d *download) {
+	select {
+	case p.slots <- struct{}{}:
+		defer func() { <-p.slots }()
+	case <-d.urgent:
+	}
+	p.active <- struct{}{}
+	defer func() { <-p.active }()
+
+	rate, done := startDownload(d.guildID)
+	d.file, d.err = p.fetchShared(videoID, rate)
+	done()
+	if d.err != nil {
+		log.Printf("Failed to download %s: %v", videoID, d.err)
+	}
+
+	p.mu.Lock()
+	delete(p.inflight, videoID)
+	p.mu.Unlock()
+	close(d.done)
+
+	if d.err == nil {
+		go evictCache()
+	}
+}
+
+// fetchShared downloads a video into the cache while holding its download
+// lock, so bot processes sharing the cache directory never run yt-dlp for the
+// same video at once. If another process cached it meanwhile, its file is used.
+func (p *downloadPool) fetchShared(videoID string, rateLimit int64) (string, error) {
+	unlock, err := audioCache.LockDownload(videoID)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	if cached, ok := audioCache.Lookup(videoID); ok {
+		log.Printf("Using %s cached by another process", videoID)
+		return cached, nil
+	}
+	file, err := p.downloadWithRetry(videoID, rateLimit)
+	if err != nil {
+		return "", err
+	}
+	return encodeForCache(videoID, file)
+}
+
+// encodeForCache stores a downloaded file in the cache as pre-encoded Opus
+// frames, so playing it needs neither yt-dlp nor ffmpeg
+func encodeForCache(videoID, downloaded string) (string, error) {
+	cached := audioCache.Path(videoID)
+	if err := audio.EncodeDCA(downloaded, cached); err != nil {
+		return "", fmt.Errorf("error encoding %s for the cache: %v", videoID, err)
+	}
+	if err := os.Remove(downloaded); err != nil {
+		log.Printf("Failed to remove download of %s: %v", videoID, err)
+	}
+	return cached, nil
+}
+
+// downloadFailure is the consolidated error for a track whose every attempt failed
+type downloadFailure struct {
+	attempts []error
+}
+
+// Error lists the reason each attempt failed
+func (f *downloadFailure) Error() string {
+	return fmt.Sprintf("download failed after %d attempts: %s", len(f.attempts), f.Summary("; "))
+}
+
+// Unwrap exposes the individual attempts to errors.Is and errors.As
+func (f *downloadFailure) Unwrap() []error {
+	return f.attempts
+}
+
+// Summary returns a short reason for each attempt, joined by sep
+func (f *downloadFailure) Summary(sep string) string {
+	reasons := make([]string, len(f.attempts))
+	for n, err := range f.attempts {
+		reasons[n] = fmt.Sprintf("%d. %s", n+1, errorReason(err))
+	}
+	return strings.Join(reasons, sep)
+}
+
+// downloadWithRetry downloads a video at up to rateLimit bytes per second,
+// backing off exponentially between attempts. Restrictions no retry can get
+// past end it early.
+func (p *downloadPool) downloadWithRetry(videoID string, rateLimit int64) (string, error) {
+	failure := &downloadFailure{}
+	wait := downloadBackoff
+	for attempt := 1; attempt <= maxDownloadAttempts; attempt++ {
+		file, err := p.downloader.DownloadAudio(videoID, rateLimit)
+		if err == nil {
+			return file, nil
+		}
+		if restrictionName(err) != "" {
+			return "", err
+		}
+		failure.attempts = append(failure.attempts, err)
+		if attempt < maxDownloadAttempts {
+			log.Printf("Download of %s failed (attempt %d of %d), retrying in %s: %v", videoID, attempt, maxDownloadAttempts, wait, err)
+			time.Sleep(wait)
+			wait *= 2
+		}
+	}
+	return "", failure
+}
+
+// errorReason picks the line of a yt-dlp error that says what went wrong,
+// leaving out the rest of its output
+func errorReason(err error) string {
+	lines := strings.Split(strings.TrimSpace(err.Error()), "\n")
+	reason := lines[0]
+	for _, line := range lines {
+		if strings.HasPrefix(line, "ERROR:") {
+			reason = strings.TrimSpace(strings.TrimPrefix(line, "ERROR:"))
+		}
+	}
+	if runes := []rune(reason); len(runes) > 200 {
+		reason = string(runes[:200]) + "…"
+	}
+	return reason
+}
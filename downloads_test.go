@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"discordbot/audio/youtube"
+)
+
+// failingDownloader fails every download with err and counts the attempts
+type failingDownloader struct {
+	err      error
+	attempts int
+}
+
+func (d *failingDownloader) GetVideoID(url string) (string, error) {
+	return url, nil
+}
+
+func (d *failingDownloader) DownloadAudio(videoID string, rateLimit int64) (string, error) {
+	d.attempts++
+	return "", d.err
+}
+
+func TestDownloadWithRetryStopsOnRestrictions(t *testing.T) {
+	for _, err := range []error{youtube.ErrUnavailable, youtube.ErrAgeRestricted, youtube.ErrRegionLocked, youtube.ErrMembersOnly} {
+		downloader := &failingDownloader{err: err}
+		pool := newDownloadPool(1, 1, downloader)
+		if _, got := pool.downloadWithRetry("abc", 0); !errors.Is(got, err) {
+			t.Errorf("downloadWithRetry got %v, want %v", got, err)
+		}
+		if downloader.attempts != 1 {
+			t.Errorf("%v: tried %d times, want 1", err, downloader.attempts)
+		}
+	}
+}
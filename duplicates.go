@@ -0,0 +1,224 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"discordbot/audio"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// confirmationTTL is how long a "Queue anyway" button stays valid
+const confirmationTTL = 5 * time.Minute
+
+// Component custom ID prefixes for the duplicate confirmation buttons
+const (
+	duplicateConfirmPrefix = "dup-confirm:"
+	duplicateCancelPrefix  = "dup-cancel:"
+)
+
+// pendingEnqueue is a request waiting for the requester to confirm a duplicate
+type pendingEnqueue struct {
+	GuildID     string
+	ChannelID   string
+	RequesterID string
+	Tracks      []*audio.Track
+	Position    string
+	Expires     time.Time
+}
+
+// pendingEnqueues holds requests waiting for confirmation, keyed by token
+var pendingEnqueues = struct {
+	sync.Mutex
+	requests map[string]*pendingEnqueue
+}{requests: make(map[string]*pendingEnqueue)}
+
+// trackKey returns the identity used to compare tracks, preferring the YouTube video ID
+func trackKey(url string) string {
+	if isYouTubeURL(url) {
+		if videoID, err := downloader.GetVideoID(url); err == nil {
+			return "youtube:" + videoID
+		}
+	}
+	return strings.TrimSpace(url)
+}
+
+// findDuplicate returns the first track in tracks that is already playing or queued
+func findDuplicate(vi *audio.VoiceInstance, tracks []*audio.Track) *audio.Track {
+	vi.Mu.Lock()
+	queued := make(map[string]bool, len(vi.Queue)+1)
+	if vi.Current != nil && vi.IsPlaying {
+		queued[trackKey(vi.Current.URL)] = true
+	}
+	for _, track := range vi.Queue {
+		queued[trackKey(track.URL)] = true
+	}
+	vi.Mu.Unlock()
+
+	for _, track := range tracks {
+		if queued[trackKey(track.URL)] {
+			return track
+		}
+	}
+	return nil
+}
+
+// findRecentPlay returns the first track in tracks that the guild played within
+// its configured window, and when it was last played
+func findRecentPlay(vi *audio.VoiceInstance, tracks []*audio.Track) (*audio.Track, time.Time) {
+	hours := settingsStore.Get(vi.GuildID).RecentPlayHours
+	if hours <= 0 {
+		return nil, time.Time{}
+	}
+
+	plays, err := statsRecorder.RecentPlays(vi.GuildID, time.Now().Add(-time.Duration(hours)*time.Hour))
+	if err != nil {
+		log.Printf("Failed to check recent plays: %v", err)
+		return nil, time.Time{}
+	}
+
+	// Plays are oldest first, so later plays overwrite earlier ones
+	lastPlayed := make(map[string]time.Time, len(plays))
+	for _, play := range plays {
+		lastPlayed[trackKey(play.URL)] = play.StartedAt
+	}
+
+	for _, track := range tracks {
+		if playedAt, ok := lastPlayed[trackKey(track.URL)]; ok {
+			return track, playedAt
+		}
+	}
+	return nil, time.Time{}
+}
+
+// formatAgo formats how long ago something happened in minutes or whole hours
+func formatAgo(i *discordgo.InteractionCreate, d time.Duration) string {
+	if d < time.Hour {
+		minutes := int(d / time.Minute)
+		if minutes < 1 {
+			minutes = 1
+		}
+		return tr(i, "time.minutes", minutes)
+	}
+	return tr(i, "time.hours", int(d/time.Hour))
+}
+
+// newConfirmationToken returns a random token identifying a pending request
+func newConfirmationToken() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// confirmDuplicate shows the requester prompt, e.g. a duplicate warning, and offers a "Queue anyway" button
+func confirmDuplicate(s *discordgo.Session, i *discordgo.InteractionCreate, tracks []*audio.Track, position, prompt string) {
+	token := newConfirmationToken()
+
+	pendingEnqueues.Lock()
+	// Drop expired requests while we're here
+	for key, pending := range pendingEnqueues.requests {
+		if time.Now().After(pending.Expires) {
+			delete(pendingEnqueues.requests, key)
+		}
+	}
+	pendingEnqueues.requests[token] = &pendingEnqueue{
+		GuildID:     i.GuildID,
+		ChannelID:   i.ChannelID,
+		RequesterID: i.Member.User.ID,
+		Tracks:      tracks,
+		Position:    position,
+		Expires:     time.Now().Add(confirmationTTL),
+	}
+	pendingEnqueues.Unlock()
+
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    tr(i, "duplicate.queue_anyway"),
+					Style:    discordgo.PrimaryButton,
+					CustomID: duplicateConfirmPrefix + token,
+				},
+				discordgo.Button{
+					Label:    tr(i, "duplicate.cancel"),
+					Style:    discordgo.SecondaryButton,
+					CustomID: duplicateCancelPrefix + token,
+				},
+			},
+		},
+	}
+
+	_, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content:    &prompt,
+		Components: &components,
+	})
+	if err != nil {
+		log.Printf("Failed to send duplicate confirmation: %v", err)
+	}
+}
+
+// handleComponent handles button presses and menu picks
+func handleComponent(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	customID := i.MessageComponentData().CustomID
+
+	switch {
+	case strings.HasPrefix(customID, duplicateConfirmPrefix):
+		handleDuplicateButton(s, i, strings.TrimPrefix(customID, duplicateConfirmPrefix), true)
+	case strings.HasPrefix(customID, duplicateCancelPrefix):
+		handleDuplicateButton(s, i, strings.TrimPrefix(customID, duplicateCancelPrefix), false)
+	case strings.HasPrefix(customID, trendingSelectPrefix):
+		handleTrendingSelect(s, i, strings.TrimPrefix(customID, trendingSelectPrefix))
+	default:
+		log.Printf("Ignoring unknown component: %s", customID)
+	}
+}
+
+// handleDuplicateButton queues or discards a request waiting for duplicate confirmation
+func handleDuplicateButton(s *discordgo.Session, i *discordgo.InteractionCreate, token string, confirmed bool) {
+	pendingEnqueues.Lock()
+	pending, ok := pendingEnqueues.requests[token]
+	if ok && pending.RequesterID == i.Member.User.ID {
+		delete(pendingEnqueues.requests, token)
+	}
+	pendingEnqueues.Unlock()
+
+	if ok && pending.RequesterID != i.Member.User.ID {
+		respondEphemeral(s, i, tr(i, "duplicate.not_requester"))
+		return
+	}
+
+	var content string
+	switch {
+	case !ok || time.Now().After(pending.Expires):
+		content = tr(i, "duplicate.expired")
+	case !confirmed:
+		content = tr(i, "duplicate.cancelled")
+	default:
+		vi := voiceManager.GetVoiceInstance(pending.GuildID)
+		if err := checkLimits(vi, pending.RequesterID, pending.Tracks); err != nil {
+			content = tr(i, "error", err)
+		} else {
+			content = addTracks(s, pending.ChannelID, vi, pending.Tracks, pending.Position)
+		}
+	}
+
+	// Replace the prompt and remove its buttons
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    content,
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to update duplicate confirmation: %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package events
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"discordbot/audio"
+)
+
+// Type identifies a kind of player event
+type Type string
+
+const (
+	TrackStart  Type = "track_start"
+	TrackEnd    Type = "track_end"
+	QueueUpdate Type = "queue_update"
+	Paused      Type = "paused"
+	Resumed     Type = "resumed"
+	PlayerError Type = "error"
+)
+
+// Event is something that happened in a guild's player
+type Event struct {
+	Type      Type           `json:"type"`
+	GuildID   string         `json:"guild_id"`
+	ChannelID string         `json:"channel_id,omitempty"` // Voice channel the bot is playing in
+	Track     *audio.Track   `json:"track,omitempty"`
+	Queue     []*audio.Track `json:"queue,omitempty"` // The whole queue after a QueueUpdate
+	Error     string         `json:"error,omitempty"` // Message shown to the guild for a PlayerError
+	Time      time.Time      `json:"time"`
+}
+
+// maxPending is how many events may wait for a subscriber before new ones
+// are dropped, so a stuck subscriber can't grow its queue forever
+const maxPending = 1024
+
+// Bus delivers player events to subscribers
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers []*subscriber
+}
+
+// subscriber runs a handler for events one at a time, in the order they were published
+type subscriber struct {
+	fn      func(Event)
+	mu      sync.Mutex
+	pending []Event
+	wake    chan struct{}
+}
+
+// NewBus creates an empty event bus
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers fn to be called for every published event. Each
+// subscriber gets events in the order they were published, one at a time.
+func (b *Bus) Subscribe(fn func(Event)) {
+	sub := &subscriber{fn: fn, wake: make(chan struct{}, 1)}
+	go sub.run()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, sub)
+}
+
+// Publish queues an event for all subscribers. It never waits for a
+// handler, so a slow subscriber never delays playback.
+func (b *Bus) Publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	b.mu.RLock()
+	subscribers := b.subscribers
+	b.mu.RUnlock()
+
+	for _, sub := range subscribers {
+		sub.push(e)
+	}
+}
+
+// push adds an event to the subscriber's queue and wakes its goroutine
+func (s *subscriber) push(e Event) {
+	s.mu.Lock()
+	if len(s.pending) >= maxPending {
+		s.mu.Unlock()
+		log.Printf("Event handler is %d events behind, dropping %s for guild %s", maxPending, e.Type, e.GuildID)
+		return
+	}
+	s.pending = append(s.pending, e)
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run handles the subscriber's queued events as they arrive
+func (s *subscriber) run() {
+	for range s.wake {
+		s.mu.Lock()
+		pending := s.pending
+		s.pending = nil
+		s.mu.Unlock()
+
+		for _, e := range pending {
+			s.handle(e)
+		}
+	}
+}
+
+// handle calls the handler for one event, recovering from a panic in it
+func (s *subscriber) handle(e Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Recovered from panic in event handler: %v", r)
+		}
+	}()
+	s.fn(e)
+}
@@ -0,0 +1,73 @@
+package events
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestPublishKeepsOrder(t *testing.T) {
+	bus := NewBus()
+	got := make(chan string, 100)
+	bus.Subscribe(func(e Event) {
+		// A slow handler must still see events in order
+		time.Sleep(time.Millisecond)
+		got <- e.GuildID
+	})
+
+	for n := 0; n < 20; n++ {
+		bus.Publish(Event{Type: QueueUpdate, GuildID: strconv.Itoa(n)})
+	}
+	for n := 0; n < 20; n++ {
+		select {
+		case id := <-got:
+			if id != strconv.Itoa(n) {
+				t.Fatalf("event %d got guild %s, want %d", n, id, n)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %d not delivered", n)
+		}
+	}
+}
+
+func TestPublishSurvivesPanic(t *testing.T) {
+	bus := NewBus()
+	got := make(chan Type, 2)
+	bus.Subscribe(func(e Event) {
+		if e.Type == PlayerError {
+			panic("handler failed")
+		}
+		got <- e.Type
+	})
+
+	bus.Publish(Event{Type: PlayerError})
+	bus.Publish(Event{Type: TrackStart})
+	select {
+	case typ := <-got:
+		if typ != TrackStart {
+			t.Errorf("got %s, want %s", typ, TrackStart)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("event after a panic not delivered")
+	}
+}
+
+func TestPublishDoesNotWait(t *testing.T) {
+	bus := NewBus()
+	block := make(chan struct{})
+	defer close(block)
+	bus.Subscribe(func(e Event) { <-block })
+
+	done := make(chan struct{})
+	go func() {
+		for n := 0; n < maxPending+10; n++ {
+			bus.Publish(Event{Type: QueueUpdate})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Errorf("Publish waited for a blocked handler")
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"discordbot/events"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// inviteTTL is how long generated voice channel invites stay valid
+const inviteTTL = 24 * time.Hour
+
+// invites caches voice channel invites used in cross-posted announcements
+var invites = struct {
+	sync.Mutex
+	links   map[string]string
+	expires map[string]time.Time
+}{
+	links:   make(map[string]string),
+	expires: make(map[string]time.Time),
+}
+
+// joinLink returns an invite to a voice channel, falling back to a plain
+// channel link if the bot may not create invites
+func joinLink(s *discordgo.Session, guildID, channelID string) string {
+	invites.Lock()
+	defer invites.Unlock()
+
+	if link, ok := invites.links[channelID]; ok && time.Now().Before(invites.expires[channelID]) {
+		return link
+	}
+
+	invite, err := s.ChannelInviteCreate(channelID, discordgo.Invite{
+		MaxAge: int(inviteTTL / time.Second),
+	})
+	if err != nil {
+		log.Printf("Failed to create invite for channel %s: %v", channelID, err)
+		return fmt.Sprintf("https://discord.com/channels/%s/%s", guildID, channelID)
+	}
+
+	link := "https://discord.gg/" + invite.Code
+	invites.links[channelID] = link
+	// Refresh a little early so we never hand out an expired invite
+	invites.expires[channelID] = time.Now().Add(inviteTTL - time.Hour)
+	return link
+}
+
+// startCrossPosting relays track changes of public sessions to the guilds following them
+func startCrossPosting(s *discordgo.Session) {
+	eventBus.Subscribe(func(e events.Event) {
+		if e.Type != events.TrackStart || e.Track == nil {
+			return
+		}
+		if !settingsStore.Get(e.GuildID).PublicSession {
+			return
+		}
+
+		followers, err := followStore.Followers(e.GuildID)
+		if err != nil {
+			log.Printf("Failed to load followers of guild %s: %v", e.GuildID, err)
+			return
+		}
+		if len(followers) == 0 {
+			return
+		}
+
+		guildName := e.GuildID
+		if guild, err := s.State.Guild(e.GuildID); err == nil {
+			guildName = guild.Name
+		}
+
+		var link string
+		if e.ChannelID != "" {
+			link = joinLink(s, e.GuildID, e.ChannelID)
+		}
+
+		// Every follower gets the announcement in its own language
+		for _, follower := range followers {
+			content := trGuild(follower.GuildID, "follow.now_playing", guildName, e.Track.DisplayName())
+			if link != "" {
+				content += "\n" + trGuild(follower.GuildID, "follow.join", link)
+			}
+			notifier.Send(follower.ChannelID, content)
+		}
+	})
+}
+
+// handleFollow handles the /follow command and its subcommands
+func handleFollow(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !isAdmin(i) {
+		errorResponse(s, i, tr(i, "follow.admin_only"))
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		editResponse(s, i, tr(i, "command.choose_subcommand"))
+		return
+	}
+
+	subcommand := options[0]
+	var sourceID string
+	var channel *discordgo.Channel
+	for _, option := range subcommand.Options {
+		switch option.Name {
+		case "server":
+			sourceID = strings.TrimSpace(option.StringValue())
+		case "channel":
+			channel = option.ChannelValue(s)
+		}
+	}
+
+	switch subcommand.Name {
+	case "start":
+		if sourceID == i.GuildID {
+			errorResponse(s, i, tr(i, "follow.self"))
+			return
+		}
+		source, err := s.State.Guild(sourceID)
+		if err != nil {
+			errorResponse(s, i, tr(i, "follow.unknown_server"))
+			return
+		}
+		if !settingsStore.Get(sourceID).PublicSession {
+			errorResponse(s, i, tr(i, "follow.not_public", source.Name))
+			return
+		}
+
+		channelID := i.ChannelID
+		if channel != nil {
+			channelID = channel.ID
+		}
+		if err := followStore.Follow(sourceID, i.GuildID, channelID); err != nil {
+			errorResponse(s, i, tr(i, "follow.save_failed", err))
+			return
+		}
+		editResponse(s, i, tr(i, "follow.started", source.Name, channelID))
+
+	case "stop":
+		removed, err := followStore.Unfollow(sourceID, i.GuildID)
+		if err != nil {
+			errorResponse(s, i, tr(i, "follow.remove_failed", err))
+			return
+		}
+		if !removed {
+			editResponse(s, i, tr(i, "follow.not_following"))
+			return
+		}
+		editResponse(s, i, tr(i, "follow.stopped"))
+
+	case "list":
+		following, err := followStore.Following(i.GuildID)
+		if err != nil {
+			errorResponse(s, i, tr(i, "follow.list_failed", err))
+			return
+		}
+		if len(following) == 0 {
+			editResponse(s, i, tr(i, "follow.none"))
+			return
+		}
+
+		var msg strings.Builder
+		msg.WriteString(tr(i, "follow.header") + "\n")
+		for sourceID, channelID := range following {
+			name := sourceID
+			if guild, err := s.State.Guild(sourceID); err == nil {
+				name = guild.Name
+			}
+			fmt.Fprintf(&msg, "• %s (`%s`) → <#%s>\n", name, sourceID, channelID)
+		}
+		editResponse(s, i, msg.String())
+	}
+}
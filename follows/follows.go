@@ -0,0 +1,110 @@
+package follows
+
+import (
+	"fmt"
+	"sync"
+
+	"discordbot/storage"
+)
+
+const collection = "follows"
+
+// Follower is a guild channel that receives another guild's track announcements
+type Follower struct {
+	GuildID   string `json:"guild_id"`
+	ChannelID string `json:"channel_id"`
+}
+
+// Store keeps which guilds follow which public sessions, keyed by the followed guild
+type Store struct {
+	store storage.Store
+	mu    sync.Mutex
+}
+
+// NewStore creates a new follow store
+func NewStore(store storage.Store) *Store {
+	return &Store{store: store}
+}
+
+// load returns the followers of a guild. The caller must hold s.mu.
+func (s *Store) load(sourceID string) ([]Follower, error) {
+	var followers []Follower
+	err := s.store.Get(collection, sourceID, &followers)
+	if err != nil && err != storage.ErrNotFound {
+		return nil, fmt.Errorf("error loading followers: %v", err)
+	}
+	return followers, nil
+}
+
+// Follow makes followerGuildID receive announcements from sourceID in channelID,
+// replacing any previous channel for that pair
+func (s *Store) Follow(sourceID, followerGuildID, channelID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	followers, err := s.load(sourceID)
+	if err != nil {
+		return err
+	}
+
+	for idx, f := range followers {
+		if f.GuildID == followerGuildID {
+			followers[idx].ChannelID = channelID
+			return s.store.Put(collection, sourceID, followers)
+		}
+	}
+
+	followers = append(followers, Follower{GuildID: followerGuildID, ChannelID: channelID})
+	return s.store.Put(collection, sourceID, followers)
+}
+
+// Unfollow stops followerGuildID from receiving announcements from sourceID
+func (s *Store) Unfollow(sourceID, followerGuildID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	followers, err := s.load(sourceID)
+	if err != nil {
+		return false, err
+	}
+
+	for idx, f := range followers {
+		if f.GuildID == followerGuildID {
+			followers = append(followers[:idx], followers[idx+1:]...)
+			return true, s.store.Put(collection, sourceID, followers)
+		}
+	}
+	return false, nil
+}
+
+// Followers returns the channels following a guild's session
+func (s *Store) Followers(sourceID string) ([]Follower, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(sourceID)
+}
+
+// Following returns the guilds followed by followerGuildID, mapped to the announcement channel
+func (s *Store) Following(followerGuildID string) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sources, err := s.store.Keys(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	following := make(map[string]string)
+	for _, sourceID := range sources {
+		followers, err := s.load(sourceID)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range followers {
+			if f.GuildID == followerGuildID {
+				following[sourceID] = f.ChannelID
+			}
+		}
+	}
+	return following, nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+
+	"discordbot/health"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// gatewayGrace is how long the gateway may be disconnected before the bot
+// is considered dead; discordgo reconnects on its own within that time
+const gatewayGrace = 5 * time.Minute
+
+// minCacheFreeBytes is the free disk space below which new downloads may fail
+const minCacheFreeBytes = 256 << 20
+
+// healthChecks holds the checks served on /healthz and /readyz
+var healthChecks = health.NewRegistry()
+
+// gateway tracks the state of the Discord gateway connection
+var gateway = struct {
+	sync.Mutex
+	connected bool
+	since     time.Time
+}{since: time.Now()}
+
+// trackGateway records gateway connects and disconnects for the health checks
+func trackGateway(s *discordgo.Session) {
+	s.AddHandler(func(s *discordgo.Session, _ *discordgo.Connect) {
+		gateway.Lock()
+		gateway.connected, gateway.since = true, time.Now()
+		gateway.Unlock()
+	})
+	s.AddHandler(func(s *discordgo.Session, _ *discordgo.Disconnect) {
+		gateway.Lock()
+		gateway.connected, gateway.since = false, time.Now()
+		gateway.Unlock()
+	})
+}
+
+// registerHealthChecks sets up the liveness and readiness checks
+func registerHealthChecks(s *discordgo.Session) {
+	trackGateway(s)
+
+	healthChecks.AddLiveness("discord_gateway", func() health.Result {
+		gateway.Lock()
+		connected, since := gateway.connected, gateway.since
+		gateway.Unlock()
+
+		if connected {
+			return health.Result{OK: true, Detail: fmt.Sprintf("connected, latency %s", s.HeartbeatLatency().Round(time.Millisecond))}
+		}
+		down := time.Since(since).Round(time.Second)
+		return health.Result{OK: down < gatewayGrace, Detail: fmt.Sprintf("disconnected for %s", down)}
+	})
+
+	healthChecks.AddReadiness("discord_ready", func() health.Result {
+		s.RLock()
+		ready := s.DataReady
+		s.RUnlock()
+		if !ready {
+			return health.Result{Detail: "waiting for the gateway READY event"}
+		}
+		return health.Result{OK: true}
+	})
+
+	// A read-only mirror never downloads or plays anything
+	if readOnly {
+		return
+	}
+
+	// With Lavalink the node does the downloading and decoding
+	if lavalinkNode != nil {
+		healthChecks.AddReadiness("lavalink", func() health.Result {
+			if !lavalinkNode.Connected() {
+				return health.Result{Detail: "not connected to the node"}
+			}
+			return health.Result{OK: true}
+		})
+		return
+	}
+	healthChecks.AddReadiness("yt-dlp", binaryCheck("yt-dlp"))
+	healthChecks.AddReadiness("ffmpeg", binaryCheck("ffmpeg"))
+	healthChecks.AddReadiness("ffprobe", binaryCheck("ffprobe"))
+	healthChecks.AddReadiness("cache_disk", cacheDiskCheck)
+}
+
+// binaryCheck reports whether an external program the bot runs is installed
+func binaryCheck(name string) health.Check {
+	return func() health.Result {
+		path, err := exec.LookPath(name)
+		if err != nil {
+			return health.Result{Detail: err.Error()}
+		}
+		return health.Result{OK: true, Detail: path}
+	}
+}
+
+// cacheDiskCheck reports whether the audio cache directory is writable and has room for downloads
+func cacheDiskCheck() health.Result {
+	if err := os.MkdirAll(audioCache.Dir, 0755); err != nil {
+		return health.Result{Detail: err.Error()}
+	}
+	probe, err := os.CreateTemp(audioCache.Dir, ".healthcheck-*")
+	if err != nil {
+		return health.Result{Detail: fmt.Sprintf("cache directory not writable: %v", err)}
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(audioCache.Dir, &fs); err != nil {
+		return health.Result{Detail: err.Error()}
+	}
+	free := int64(fs.Bavail) * int64(fs.Bsize)
+	used, _ := audioCache.Size()
+
+	detail := fmt.Sprintf("%d MiB cached of %d MiB, %d MiB free", used>>20, audioCache.MaxBytes()>>20, free>>20)
+	return health.Result{OK: free >= minCacheFreeBytes, Detail: detail}
+}
@@ -0,0 +1,119 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Result is the outcome of a single check
+type Result struct {
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Check reports the state of one dependency
+type Check func() Result
+
+// Report is the JSON body served by the health endpoints
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Registry holds the liveness and readiness checks of the bot. Readiness
+// includes every liveness check, so a live but degraded bot stays up while
+// being taken out of rotation.
+type Registry struct {
+	mu        sync.Mutex
+	liveness  map[string]Check
+	readiness map[string]Check
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		liveness:  make(map[string]Check),
+		readiness: make(map[string]Check),
+	}
+}
+
+// AddLiveness registers a check that decides whether the process should be restarted
+func (r *Registry) AddLiveness(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.liveness[name] = check
+}
+
+// AddReadiness registers a check that decides whether the bot can serve requests
+func (r *Registry) AddReadiness(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.readiness[name] = check
+}
+
+// Live runs the liveness checks
+func (r *Registry) Live() Report {
+	return r.run(false)
+}
+
+// Ready runs the liveness and readiness checks
+func (r *Registry) Ready() Report {
+	return r.run(true)
+}
+
+// run executes the selected checks and summarizes them
+func (r *Registry) run(readiness bool) Report {
+	r.mu.Lock()
+	checks := make(map[string]Check, len(r.liveness)+len(r.readiness))
+	for name, check := range r.liveness {
+		checks[name] = check
+	}
+	if readiness {
+		for name, check := range r.readiness {
+			checks[name] = check
+		}
+	}
+	r.mu.Unlock()
+
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	report := Report{Status: "ok", Checks: make(map[string]Result, len(checks))}
+	for _, name := range names {
+		result := checks[name]()
+		report.Checks[name] = result
+		if !result.OK {
+			report.Status = "fail"
+		}
+	}
+	return report
+}
+
+// LivenessHandler serves the liveness report, with 503 if any check fails
+func (r *Registry) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		writeReport(w, r.Live())
+	})
+}
+
+// ReadinessHandler serves the readiness report, with 503 if any check fails
+func (r *Registry) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		writeReport(w, r.Ready())
+	})
+}
+
+// writeReport writes a report as JSON
+func writeReport(w http.ResponseWriter, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"expvar"
+	"log"
+	"net/http"
+	"time"
+
+	"discordbot/api"
+	"discordbot/config"
+	"discordbot/control"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// startHTTPServer serves the health endpoints, the metrics, the REST API and,
+// if configured, the web dashboard on HTTP_ADDR. Nothing is served if HTTP_ADDR is unset.
+func startHTTPServer(s *discordgo.Session) {
+	registerHealthChecks(s)
+
+	addr := config.String("HTTP_ADDR", "")
+	if addr == "" {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", healthChecks.LivenessHandler())
+	mux.Handle("/readyz", healthChecks.ReadinessHandler())
+	mux.Handle("/debug/vars", expvar.Handler())
+	var player control.Player = botPlayer{s: s}
+	if readOnly {
+		player = control.ReadOnly(player)
+	}
+	api.New(player, apiTokens, eventBus).Register(mux)
+	registerDashboard(s, mux, player)
+	registerSpotifyCallback(mux)
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		log.Printf("Serving HTTP on %s", addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("HTTP server failed: %v", err)
+		}
+	}()
+}
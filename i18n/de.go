@@ -0,0 +1,687 @@
+package i18n
+
+// german is the German catalog. Missing keys fall back to English.
+var german = Catalog{
+	"error":                     "❌ %v",
+	"command.failed":            "Fehler: Der Befehl konnte nicht verarbeitet werden. Bitte versuche es erneut.",
+	"command.choose_subcommand": "Bitte wähle einen Unterbefehl",
+	"ping.pong":                 "Pong!",
+	"time.minutes":              "%d Minuten",
+	"time.hours":                "%d Stunden",
+	"time.seconds":              "%d s",
+
+	"voice.user_not_connected": "❌ Du musst zuerst einem Sprachkanal beitreten!",
+	"voice.bot_not_connected":  "❌ Ich bin in keinem Sprachkanal!",
+	"voice.join_failed":        "❌ Fehler beim Betreten des Sprachkanals: %v",
+	"voice.connect_failed":     "❌ Verbindung zum Sprachkanal fehlgeschlagen. Bitte versuche es erneut.",
+	"voice.leave_failed":       "❌ Fehler beim Verlassen des Sprachkanals: %v",
+	"voice.joined":             "Sprachkanal betreten!",
+	"voice.left":               "Sprachkanal verlassen!",
+	"voice.followed":           "🚶 Dem DJ nach <#%s> gefolgt",
+	"voice.not_same_channel":   "❌ Tritt <#%s> bei, um die Wiedergabe auf diesem Server zu steuern",
+	"voice.released":           "⏹️ Ich wurde aus dem Sprachkanal entfernt, daher wurde die Wiedergabe gestoppt. Hol mich mit /join zurück.",
+
+	"voice.missing_permissions": "❌ Ich kann in <#%s> nicht spielen. Bitte einen Server-Admin, mir dort diese Berechtigungen zu geben:",
+	"voice.channel_full":        "❌ <#%s> ist voll. Macht Platz, erhöht das Nutzerlimit oder gebt mir die Berechtigung „Mitglieder verschieben“.",
+
+	"bitrate.low":         "ℹ️ Dieser Sprachkanal ist auf %d kbps begrenzt, Discords Standard, daher klingt Musik dumpf. Wer Kanäle verwalten darf, kann die Bitrate in den Kanaleinstellungen erhöhen.",
+	"bitrate.low_suggest": "ℹ️ Dieser Sprachkanal ist auf %d kbps begrenzt, Discords Standard, daher klingt Musik dumpf. <#%s> erlaubt %d kbps, wechselt am besten dorthin.",
+
+	"queue.empty":           "Die Warteschlange ist leer",
+	"queue.header":          "Aktuelle Warteschlange:",
+	"queue.entry":           "%d. %s (gewünscht von %s)",
+	"queue.track_count":     "%d Titel",
+	"queue.playing_now":     "Wird jetzt gespielt: %s",
+	"queue.playing_next":    "Wird als Nächstes gespielt: %s",
+	"queue.added":           "Zur Warteschlange hinzugefügt: %s",
+	"queue.exported":        "📤 %d Titel exportiert",
+	"queue.import_no_file":  "❌ Hänge eine Warteschlangen-Datei zum Importieren an",
+	"queue.import_too_big":  "❌ Warteschlangen-Dateien dürfen höchstens %d KB groß sein",
+	"queue.import_invalid":  "❌ Diese Datei ist kein Warteschlangen-Export: %v",
+	"queue.import_empty":    "❌ Diese Datei enthält keine Titel",
+	"queue.import_too_many": "❌ Eine Warteschlangen-Datei darf höchstens %d Titel enthalten",
+	"queue.added_eta":       "Zur Warteschlange hinzugefügt: %s (Position %d, läuft %s)",
+	"queue.total":           "Gesamt: %s",
+	"queue.total_unknown":   "Gesamt: unbekannt, manche Titel haben keine Länge",
+	"queue.eta_minutes":     "in ca. %d Min.",
+	"queue.eta_soon":        "in weniger als einer Minute",
+	"queue.eta_unknown":     "Startzeit unbekannt",
+
+	"repeat.enabled":         "Wiederholung aktiviert",
+	"repeat.disabled":        "Wiederholung deaktiviert",
+	"replay.nothing_playing": "Es läuft nichts, das wiederholt werden könnte",
+	"replay.restarted":       "🔁 %s wird neu gestartet",
+	"replay.queued":          "🔁 %s wird nach dem Ende noch einmal gespielt",
+
+	"repeat.times":         "🔂 %s läuft noch %d-mal, dann geht es mit der Warteschlange weiter",
+	"repeat.times_cleared": "%s wird nicht mehr wiederholt",
+
+	"karaoke.enabled":  "🎤 Karaoke-Modus aktiviert: Gesang wird aus der Musik entfernt",
+	"karaoke.disabled": "Karaoke-Modus deaktiviert",
+	"karaoke.remote":   "❌ Der Karaoke-Modus ist bei Wiedergabe über Lavalink nicht verfügbar",
+
+	"chapters.nothing_playing": "Es läuft nichts",
+	"chapters.none":            "Der laufende Titel hat keine Kapitel",
+	"chapters.header":          "📑 Kapitel von %s:",
+	"chapters.entry":           "%s `%s` %s",
+	"chapters.last":            "Das ist bereits das letzte Kapitel",
+	"chapters.skipped":         "⏭️ Weiter zu %s (%s)",
+
+	"autoplay.enabled":  "Autoplay aktiviert",
+	"autoplay.disabled": "Autoplay deaktiviert",
+	"autoplay.failed":   "❌ Autoplay hat keinen Titel gefunden: %v",
+
+	"player.downloading":         "Wird heruntergeladen: %s",
+	"player.connecting_live":     "📡 Verbinde mit Livestream: %s",
+	"player.now_playing":         "🎵 Läuft gerade: %s",
+	"player.now_playing_from":    "🎵 Läuft gerade: %s (ab %s)",
+	"player.now_playing_live":    "🔴 Läuft live: %s",
+	"player.now_playing_radio":   "🔴 Läuft live: %s\n🎵 %s",
+	"player.finished":            "✅ Fertig gespielt: %s",
+	"player.invalid_youtube_url": "❌ Ungültige YouTube-URL",
+	"player.download_failed":     "❌ Fehler beim Herunterladen: %v",
+	"player.download_gave_up":    "❌ Dieser Titel konnte nach %d Versuchen nicht heruntergeladen werden und wird übersprungen:\n%s",
+	"player.age_restricted":      "🔞 Dieses Video ist altersbeschränkt und es ist kein funktionierendes YouTube-Konto eingerichtet",
+	"player.region_locked":       "🌍 Dieses Video ist in der Region des Bots nicht verfügbar",
+	"player.members_only":        "🔒 Dieses Video ist nur für Kanalmitglieder oder Premium-Abonnenten verfügbar",
+	"player.unavailable":         "🚫 Dieses Video ist privat, entfernt oder anderweitig nicht verfügbar",
+	"player.skipped":             "⏭️ Überspringe: %v",
+	"player.play_failed":         "❌ Fehler bei der Wiedergabe: %v",
+	"player.spotify_unavailable": "❌ Spotify wird nicht unterstützt",
+	"player.spotify_unsupported": "❌ Spotify wird noch nicht unterstützt",
+	"player.unsupported_url":     "❌ Nicht unterstützte URL. Bitte gib eine YouTube- oder Spotify-URL an.",
+
+	"shutdown.notice":  "⚠️ Der Bot startet in %s neu; die Wiedergabe geht danach an derselben Stelle weiter.",
+	"shutdown.resumed": "▶️ Nach dem Neustart zurück, die Wiedergabe geht weiter.",
+
+	"cleanup.members_failed": "❌ Fehler beim Lesen der Mitglieder des Sprachkanals: %v",
+	"cleanup.nothing":        "Alle mit Titeln in der Warteschlange sind noch da, nichts aufzuräumen",
+	"cleanup.removed":        "🧹 %d Titel von %d Nutzer(n) entfernt, die den Sprachkanal verlassen haben",
+
+	"removeuser.dj_only": "❌ Nur DJs können die Titel anderer Mitglieder entfernen",
+	"removeuser.nothing": "<@%s> hat keine Titel in der Warteschlange",
+	"removeuser.removed": "🧹 %d Titel von <@%s> aus der Warteschlange entfernt",
+
+	"move.dj_only":          "❌ Nur DJs können den Bot in einen anderen Kanal verschieben. Mit /summon holst du ihn in deinen.",
+	"move.others_listening": "❌ In <#%s> hören noch Leute zu. Komm dazu oder bitte einen DJ, den Bot zu verschieben.",
+	"move.already_there":    "Ich bin schon in <#%s>",
+	"move.moved":            "🔀 In <#%s> gewechselt",
+
+	"radio.title":     "📻 Radio %s",
+	"radio.dj_only":   "❌ Nur DJs können die Radiosender des Servers ändern",
+	"radio.not_found": "❌ Kein Radiosender namens `%s`. Mit `/radio list` siehst du alle.",
+	"radio.none":      "Dieser Server hat keine Radiosender. DJs können mit `/radio set` einen hinzufügen.",
+	"radio.header":    "📻 **Radiosender**",
+	"radio.bad_url":   "❌ Gib die Stream-URL des Senders an, beginnend mit http:// oder https://. YouTube-Links lassen sich mit /play einreihen.",
+	"radio.saved":     "📻 Radiosender `%s` gespeichert",
+	"radio.removed":   "Radiosender `%s` entfernt",
+
+	"trending.unavailable":   "❌ /trending ist bei diesem Bot abgeschaltet. Der Betreiber muss INVIDIOUS_URL setzen.",
+	"trending.bad_region":    "❌ %q ist kein zweistelliger Ländercode wie US oder DE",
+	"trending.failed":        "❌ Die angesagte Musik konnte nicht geladen werden, versuch es später noch einmal",
+	"trending.empty":         "Gerade ist keine Musik angesagt",
+	"trending.header":        "🔥 Angesagte Musik, wähle Titel zum Einreihen:",
+	"trending.header_region": "🔥 Angesagte Musik in %s, wähle Titel zum Einreihen:",
+	"trending.placeholder":   "Titel auswählen",
+	"trending.expired":       "❌ Dieses Menü ist abgelaufen, führe /trending noch einmal aus",
+	"trending.not_requester": "❌ Nur wer /trending ausgeführt hat, kann aus diesem Menü wählen",
+
+	"reverse.too_short": "Zum Umkehren müssen mindestens zwei Titel in der Warteschlange sein",
+	"reverse.done":      "🔃 Reihenfolge von %d Titeln in der Warteschlange umgekehrt",
+
+	"limits.queue_full":     "die Warteschlange ist voll (höchstens %d Titel auf diesem Server)",
+	"limits.queue_too_long": "die Warteschlange würde zu lang: %d Titel eingereiht, %d weitere erlaubt, du wolltest %d hinzufügen",
+	"limits.per_user":       "du kannst auf diesem Server höchstens %d Titel gleichzeitig einreihen (du hast %d, wolltest %d hinzufügen)",
+	"limits.track_too_long": "%s ist %s lang; die maximale Titellänge auf diesem Server ist %s",
+	"limits.age_restricted": "%s ist altersbeschränkt und es ist kein funktionierendes YouTube-Konto eingerichtet",
+	"limits.region_locked":  "%s ist in der Region des Bots nicht verfügbar",
+	"limits.members_only":   "%s ist nur für Kanalmitglieder oder Premium-Abonnenten verfügbar",
+	"limits.unavailable":    "%s ist privat, entfernt oder anderweitig nicht verfügbar",
+
+	"ratelimit.enqueues": "🐢 Langsam! Du kannst auf diesem Server %d-mal pro Minute Titel einreihen. Versuch es <t:%d:R> wieder.",
+	"ratelimit.cooldown": "🐢 Langsam! Du kannst %s <t:%d:R> wieder nutzen.",
+
+	"duplicate.blocked":       "❌ %s ist bereits in der Warteschlange",
+	"duplicate.confirm":       "⚠️ %s ist bereits in der Warteschlange. Trotzdem einreihen?",
+	"duplicate.recent":        "⚠️ %s lief bereits vor %s. Trotzdem einreihen?",
+	"duplicate.queue_anyway":  "Trotzdem einreihen",
+	"duplicate.cancel":        "Abbrechen",
+	"duplicate.cancelled":     "Abgebrochen.",
+	"duplicate.expired":       "Diese Anfrage ist abgelaufen. Bitte führe den Befehl erneut aus.",
+	"duplicate.not_requester": "Nur die Person, die den Titel gewünscht hat, kann ihn bestätigen",
+
+	"alias.dj_only":         "❌ Nur DJs können Server-Aliase verwalten",
+	"alias.save_failed":     "❌ Fehler beim Speichern des Alias: %v",
+	"alias.remove_failed":   "❌ Fehler beim Entfernen des Alias: %v",
+	"alias.saved":           "Alias `%s` → %s gespeichert",
+	"alias.not_found":       "Kein Alias namens `%s`",
+	"alias.removed":         "Alias `%s` entfernt",
+	"alias.none":            "Keine Aliase gespeichert. Mit `/alias add` legst du einen an.",
+	"alias.personal_header": "**Deine Aliase**",
+	"alias.guild_header":    "**Server-Aliase**",
+	"alias.playlist_target": "Playlist **%s**",
+
+	"playlist.gone":            "die Playlist %q existiert nicht mehr",
+	"playlist.empty":           "die Playlist %q ist leer",
+	"playlist.created":         "Playlist **%s** erstellt",
+	"playlist.nothing_playing": "Es läuft gerade nichts. Gib eine URL zum Hinzufügen an.",
+	"playlist.not_found":       "❌ Du hast keine Playlist namens %q",
+	"playlist.update_failed":   "❌ Fehler beim Aktualisieren der Playlist: %v",
+	"playlist.added":           "%s zu **%s** hinzugefügt (%d Titel)",
+	"playlist.list_failed":     "❌ Fehler beim Laden der Playlists: %v",
+	"playlist.none":            "Du hast keine Playlists. Mit `/playlist create` legst du eine an.",
+	"playlist.header":          "**Deine Playlists**",
+	"playlist.list_entry":      "• %s (%d Titel)",
+	"playlist.load_failed":     "❌ Fehler beim Laden der Playlist: %v",
+	"playlist.is_empty":        "**%s** ist leer",
+	"playlist.delete_failed":   "❌ Fehler beim Löschen der Playlist: %v",
+	"playlist.deleted":         "Playlist **%s** gelöscht",
+	"playlist.queue_empty":     "Die Warteschlange ist leer, es gibt nichts zu speichern",
+	"playlist.saved":           "%d Titel in der Playlist **%s** gespeichert. Mit `/playlist play %s` stellst du sie wieder her.",
+
+	"stats.load_failed":     "❌ Statistiken konnten nicht geladen werden",
+	"stats.none":            "Keine Titel gespielt (%s)",
+	"stats.header":          "📊 Musikstatistik (%s)",
+	"stats.user_header":     "📊 Musikstatistik für %s (%s)",
+	"stats.totals":          "Wiedergaben: %d, Hörzeit: %s",
+	"stats.top_tracks":      "**Meistgespielte Titel**",
+	"stats.track_entry":     "%d. %s (%d Wiedergaben)",
+	"stats.top_requesters":  "**Aktivste Wünschende**",
+	"stats.requester_entry": "%d. %s (%d Titel, %s)",
+	"stats.window.day":      "letzte 24 Stunden",
+	"stats.window.week":     "letzte 7 Tage",
+	"stats.window.month":    "letzte 30 Tage",
+	"stats.window.all":      "gesamter Zeitraum",
+
+	"runtime.title":         "⚙️ Bot-Laufzeit",
+	"runtime.uptime":        "Laufzeit",
+	"runtime.guilds":        "Server",
+	"runtime.voice":         "Sprachverbindungen",
+	"runtime.memory":        "Speicher",
+	"runtime.memory_value":  "%s Heap, %s gesamt",
+	"runtime.goroutines":    "Goroutinen",
+	"runtime.latency":       "Gateway-Latenz",
+	"runtime.cache":         "Audio-Cache",
+	"runtime.cookies":       "YouTube-Konten",
+	"runtime.cookies_none":  "keine",
+	"runtime.cookies_value": "%d von %d funktionieren",
+
+	"top.none":   "Es wurde noch nichts gespielt",
+	"top.header": "🏆 **Meistgespielte Titel auf allen Servern**",
+
+	"diagnose.channel_header": "**<#%s>**",
+	"diagnose.unknown":        "❓ Ich konnte meine Berechtigungen hier nicht lesen: %v",
+	"diagnose.no_voice":       "Tritt einem Sprachkanal bei, um auch meine Sprachberechtigungen zu prüfen.",
+	"diagnose.all_good":       "Alles in Ordnung. Falls ich trotzdem stumm bin, prüfe, ob ich serverweit stummgeschaltet bin.",
+	"diagnose.unchecked":      "Ich konnte nicht alle meine Berechtigungen lesen, es könnten also noch welche fehlen. Versuch es gleich noch einmal.",
+	"diagnose.missing":        "⚠️ %d Berechtigung(en) fehlen. Bitte einen Admin, sie meiner Rolle oder im Kanal zu geben.",
+	"diagnose.reason.view":    "ich kann den Kanal nicht sehen",
+	"diagnose.reason.send":    "ich kann keine Ansagen oder Fehler posten",
+	"diagnose.reason.embed":   "Links und Titelinfos bekommen keine Vorschau",
+	"diagnose.reason.manage":  "ich kann meine eigenen Nachrichten nicht aktualisieren oder aufräumen",
+	"diagnose.reason.connect": "ich kann dem Sprachkanal nicht beitreten",
+	"diagnose.reason.speak":   "ich kann beitreten, aber niemand hört etwas",
+
+	"diagnose.reason.stage_speaker": "ich kann mich auf der Stage nicht selbst zum Sprecher machen, also hört niemand etwas",
+
+	"follow.admin_only":     "❌ Du brauchst die Berechtigung „Server verwalten“, um gefolgte Sessions zu verwalten",
+	"follow.self":           "❌ Ein Server kann sich nicht selbst folgen",
+	"follow.unknown_server": "❌ Ich bin auf keinem Server mit dieser ID",
+	"follow.not_public":     "❌ **%s** hat seine Session nicht öffentlich gemacht",
+	"follow.save_failed":    "❌ Fehler beim Speichern: %v",
+	"follow.started":        "Du folgst jetzt **%s**. Titelwechsel werden in <#%s> gepostet.",
+	"follow.remove_failed":  "❌ Fehler beim Entfernen: %v",
+	"follow.not_following":  "Dieser Server folgt dieser Session nicht",
+	"follow.stopped":        "Der Session wird nicht mehr gefolgt",
+	"follow.list_failed":    "❌ Fehler beim Laden der gefolgten Sessions: %v",
+	"follow.none":           "Dieser Server folgt keinen Sessions",
+	"follow.header":         "**Gefolgte Sessions**",
+	"follow.now_playing":    "📻 Läuft gerade auf **%s**: %s",
+	"follow.join":           "Zur Session: %s",
+
+	"privacy.save_failed":   "❌ Fehler beim Speichern deiner Datenschutzeinstellung: %v",
+	"privacy.opted_out":     "🔒 Deine Höraktivität wird nicht mehr aufgezeichnet. Deine Wünsche funktionieren weiterhin.",
+	"privacy.forgotten":     "%d aufgezeichnete Wiedergabe(n) aus der Statistik entfernt.",
+	"privacy.forget_failed": "⚠️ Deine bisherigen Wiedergaben konnten nicht aus der Statistik entfernt werden. Bitte versuche es später erneut.",
+	"privacy.opted_in":      "Deine Höraktivität wird wieder aufgezeichnet",
+	"privacy.status_out":    "🔒 Du hast widersprochen. Verlauf und Statistik werden für dich nicht aufgezeichnet.",
+	"privacy.status_in":     "Deine Wiedergaben zählen für Verlauf und Statistik. Mit `/privacy optout` kannst du das abschalten.",
+
+	"lookup.invalid_url":  "❌ Bitte gib einen vollständigen Link an, der mit http:// oder https:// beginnt",
+	"lookup.failed":       "❌ Der Link konnte nicht gelesen werden: %v",
+	"lookup.duration":     "Dauer",
+	"lookup.uploader":     "Hochgeladen von",
+	"lookup.uploaded":     "Hochgeladen am",
+	"lookup.views":        "Aufrufe",
+	"lookup.availability": "Verfügbarkeit",
+	"lookup.cached":       "Im Cache",
+	"lookup.live":         "Livestream",
+	"lookup.too_long":     "(über dem Limit von %s auf diesem Server)",
+	"lookup.unknown":      "unbekannt",
+	"lookup.yes":          "ja",
+	"lookup.no":           "nein",
+
+	"lookup.availability.public":          "öffentlich",
+	"lookup.availability.unlisted":        "nicht gelistet",
+	"lookup.availability.private":         "privat",
+	"lookup.availability.needs_auth":      "Anmeldung nötig (z. B. Altersbeschränkung)",
+	"lookup.availability.premium_only":    "nur Premium",
+	"lookup.availability.subscriber_only": "nur Kanalmitglieder",
+
+	"tts.now_playing": "Jetzt läuft: %s",
+	"tts.said":        "🗣️ Nachricht gesprochen",
+	"tts.busy":        "❌ Der Bot sagt gerade schon etwas, versuch es gleich noch einmal",
+	"tts.too_long":    "❌ Nachrichten dürfen höchstens %d Zeichen lang sein",
+	"tts.remote":      "❌ Sprachausgabe ist nicht verfügbar, während ein Lavalink-Node abspielt",
+	"tts.unavailable": "❌ Sprachausgabe ist bei diesem Bot nicht eingerichtet",
+	"tts.failed":      "❌ Das konnte nicht gesagt werden: %v",
+
+	"sound.saved":        "🔊 Clip `%s` gespeichert. Spiel ihn mit `/sound play` ab.",
+	"sound.removed":      "🗑️ Clip `%s` entfernt",
+	"sound.played":       "🔊 `%s` abgespielt",
+	"sound.list":         "🔊 Clips: %s",
+	"sound.none":         "Dieser Server hat noch keine Clips. Admins können welche mit `/sound add` hochladen.",
+	"sound.not_found":    "❌ Es gibt keinen Clip namens `%s`",
+	"sound.invalid_name": "❌ Clipnamen dürfen nur Kleinbuchstaben, Ziffern, - und _ enthalten (bis zu 32 Zeichen)",
+	"sound.no_file":      "❌ Hänge eine Audiodatei zum Hochladen an",
+	"sound.too_big":      "❌ Clips dürfen höchstens %d MB groß sein",
+	"sound.full":         "❌ Dieser Server hat schon %d Clips, entferne zuerst einen",
+	"sound.save_failed":  "❌ Der Clip konnte nicht gespeichert werden: %v",
+	"sound.admin_only":   "❌ Du brauchst die Berechtigung „Server verwalten“, um das Soundboard zu ändern",
+	"sound.busy":         "❌ Gerade läuft schon etwas über der Musik, versuch es gleich noch einmal",
+	"sound.remote":       "❌ Das Soundboard ist nicht verfügbar, während ein Lavalink-Node abspielt",
+
+	"playmany.title":       "Mehrere Titel einreihen",
+	"playmany.label":       "Eine URL, ein Alias oder ein Suchbegriff pro Zeile",
+	"playmany.placeholder": "https://www.youtube.com/watch?v=...\nKünstler - Songtitel",
+	"playmany.empty":       "❌ Es gab nichts zum Einreihen",
+	"playmany.too_many":    "❌ Es können höchstens %d Zeilen auf einmal eingereiht werden",
+	"playmany.duplicate":   "schon in der Warteschlange",
+	"playmany.failed_line": "❌ %s: %v",
+	"playmany.summary":     "%d von %d Zeilen eingereiht",
+
+	"blocklist.admin_only":      "❌ Du brauchst die Berechtigung „Server verwalten“, um die Sperrliste zu ändern",
+	"blocklist.invalid":         "❌ %s ist kein gültiger Eintrag dieser Art",
+	"blocklist.added":           "🚫 `%s` gesperrt",
+	"blocklist.already":         "`%s` ist schon gesperrt",
+	"blocklist.removed":         "✅ `%s` entsperrt",
+	"blocklist.not_found":       "`%s` steht nicht auf der Sperrliste",
+	"blocklist.empty":           "Auf diesem Server ist nichts gesperrt",
+	"blocklist.videos":          "🚫 Videos: %s",
+	"blocklist.channels":        "🚫 Kanäle: %s",
+	"blocklist.domains":         "🚫 Domains: %s",
+	"blocklist.blocked_video":   "🚫 %s ist auf diesem Server gesperrt",
+	"blocklist.blocked_channel": "🚫 %s kann nicht abgespielt werden: Videos von %s sind auf diesem Server gesperrt",
+	"blocklist.blocked_domain":  "🚫 %s kann nicht abgespielt werden: Links zu %s sind auf diesem Server gesperrt",
+
+	"commandchannel.redirect": "🎵 Musikbefehle gehören auf diesem Server in %s",
+	"commandchannel.anywhere": "Musikbefehle funktionieren in jedem Kanal",
+	"commandchannel.list":     "Musikbefehle funktionieren nur in %s",
+
+	"auditlog.admin_only":   "❌ Du brauchst die Berechtigung „Server verwalten“, um das Protokoll zu lesen",
+	"auditlog.failed":       "❌ Das Protokoll konnte nicht geladen werden",
+	"auditlog.empty":        "Auf diesem Server wurden noch keine Befehle protokolliert",
+	"auditlog.header":       "📋 **Letzte %d Befehle:**",
+	"auditlog.entry":        "<t:%d:R> <@%s> `%s`",
+	"auditlog.entry_failed": " → %s",
+
+	"filter.explicit": "🔞 %s ist als explizit markiert und dieser Server erlaubt keine expliziten Titel",
+	"filter.keyword":  "🚫 %s kann nicht abgespielt werden: Der Titel enthält ein auf diesem Server gefiltertes Wort",
+
+	"kiosk.queued":       "🎶 <@%s> %s",
+	"kiosk.not_found":    "❌ <@%s> nichts gefunden für „%s“",
+	"kiosk.duplicate":    "❌ <@%s> **%s** ist schon in der Warteschlange",
+	"kiosk.not_in_voice": "❌ <@%s> tritt zuerst einem Sprachkanal bei und poste deinen Song dann noch einmal",
+	"kiosk.slow_down":    "⏳ <@%s> bitte ein Song nach dem anderen, warte ein paar Sekunden",
+
+	"kiosk.cleanup_all":     "Jede Nachricht dort wird gelöscht; Anfragen werden durch eine kurze Bestätigung ersetzt.",
+	"kiosk.cleanup_chatter": "Anfragen bleiben stehen und bekommen eine Reaktion und eine Antwort mit ihrer Position in der Warteschlange; andere Nachrichten werden gelöscht.",
+	"kiosk.cleanup_none":    "Nichts wird gelöscht; Anfragen bekommen eine Reaktion und eine Antwort mit ihrer Position in der Warteschlange.",
+
+	"settings.admin_only":          "❌ Du brauchst die Berechtigung „Server verwalten“, um Einstellungen zu ändern",
+	"settings.unlimited":           "unbegrenzt",
+	"settings.limits_updated":      "Limits aktualisiert.",
+	"settings.max_track_length":    "Maximale Titellänge: %s",
+	"settings.max_queue_size":      "Maximale Länge der Warteschlange: %s",
+	"settings.max_per_user":        "Maximal eingereihte Titel pro Nutzer: %s",
+	"settings.enqueues_per_minute": "Einreihungen pro Mitglied: %s",
+	"settings.per_minute":          "%d pro Minute",
+	"settings.command_cooldown":    "Wartezeit zwischen Wiedergabebefehlen: %s",
+	"settings.bitrate_default":     "🎚️ Musik wird mit der Standard-Bitrate kodiert",
+	"settings.bitrate":             "🎚️ Musik wird mit %d kbps kodiert",
+	"settings.bitrate_clamped":     "⚠️ Der Sprachkanal erlaubt nur %d kbps, deshalb wird das verwendet",
+	"settings.bitrate_range":       "Die Bitrate muss zwischen %d und %d kbps liegen, oder 0 für den Standard",
+	"settings.duplicates_blocked":  "Bereits eingereihte Titel werden jetzt abgelehnt",
+	"settings.duplicates_confirm":  "Bei doppelten Titeln wird jetzt nachgefragt",
+	"settings.recent_plays":        "Bei Titeln, die in den letzten %d Stunden liefen, wird nachgefragt",
+	"settings.recent_plays_off":    "Kürzlich gespielte Titel werden ohne Nachfrage eingereiht",
+	"settings.public_enabled":      "Die Session dieses Servers ist jetzt öffentlich. Andere Server können ihr mit `/follow start server:%s` folgen.",
+	"settings.public_disabled":     "Die Session dieses Servers ist nicht mehr öffentlich",
+	"settings.tts_on":              "Titel werden jetzt vor dem Abspielen vorgelesen",
+	"settings.tts_off":             "Titel werden nicht mehr vorgelesen",
+	"settings.same_channel_on":     "Nur Mitglieder im Sprachkanal des Bots können jetzt die Wiedergabe steuern. DJs sind ausgenommen.",
+	"settings.follow_dj_on":        "Der Bot folgt jetzt dem Mitglied, dessen Titel läuft, wenn es den Sprachkanal wechselt und sonst niemand zuhört",
+	"settings.follow_dj_off":       "Der Bot bleibt in seinem Sprachkanal, wenn Mitglieder den Kanal wechseln",
+
+	"settings.timeouts_updated": "Zeitlimits aktualisiert.",
+	"settings.idle_timeout":     "💤 Verlassen, nachdem so lange nichts lief: %s",
+	"settings.empty_timeout":    "🚪 Verlassen, nachdem alle den Kanal so lange verlassen haben: %s (die Wiedergabe pausiert sofort)",
+	"settings.timeout_off":      "nie",
+	"settings.timeout_default":  "%s (Standard)",
+	"settings.timeout_invalid":  "❌ `%s` ist kein Zeitlimit. Gib eine Anzahl Minuten, `off` oder `default` an.",
+
+	"inactivity.paused_empty": "⏸️ Alle haben den Sprachkanal verlassen, daher habe ich pausiert. Kommt zurück, um weiterzuhören.",
+	"inactivity.resumed":      "▶️ Willkommen zurück, die Wiedergabe geht weiter",
+	"inactivity.left_empty":   "👋 Sprachkanal verlassen, weil niemand zugehört hat",
+	"inactivity.left_idle":    "👋 Sprachkanal verlassen, weil nichts lief",
+
+	"presence.guilds": "Musik auf %d Servern",
+
+	"plugins.failed":        "❌ Das Plugin konnte das nicht erledigen: %v",
+	"plugins.done":          "✅ Erledigt",
+	"plugins.nothing_found": "Nichts gefunden für %s",
+
+	"webhooks.invalid":  "❌ Dieser Webhook kann nicht verwendet werden: %v",
+	"webhooks.too_many": "❌ Ein Server kann höchstens %d Webhooks haben. Entferne zuerst einen.",
+	"webhooks.none":     "Es sind keine Webhooks eingerichtet. Titelereignisse werden nirgends hin gesendet.",
+	"webhooks.list":     "Titelstarts, -enden und Fehler werden gesendet an:\n%s",
+
+	"backup.exported":          "Hier ist die Sicherung dieses Servers. Stelle sie mit /settings import wieder her; sie funktioniert nur mit diesem Bot. Webhooks sind nicht enthalten, da jeder mit ihrer URL an sie posten kann.",
+	"backup.imported":          "✅ Einstellungen, Aliase, Radiosender und gelöschte Playlists aus der Sicherung vom %s wiederhergestellt",
+	"backup.imported_other":    "✅ Einstellungen, Aliase und Radiosender aus der Sicherung vom %s wiederhergestellt. Sie stammt von einem anderen Server, daher wurden die Playlists seiner Mitglieder ausgelassen; lege Befehls-, Ankündigungs- und Kiosk-Kanäle neu fest.",
+	"backup.no_file":           "❌ Hänge eine Datei aus /settings export an",
+	"backup.too_big":           "❌ Sicherungsdateien dürfen höchstens %d KB groß sein",
+	"backup.invalid":           "❌ Das ist keine Sicherungsdatei: %v",
+	"backup.invalid_signature": "❌ Diese Sicherung stammt nicht von diesem Bot oder wurde nach dem Export verändert",
+	"backup.unavailable":       "❌ Sicherungen sind auf diesem Bot gerade nicht verfügbar",
+
+	"spotify.link":               "[Verknüpfe dein Spotify-Konto](%s), um deine Lieblingssongs mit `/play spotify:liked` abzuspielen und deine Playlists mit `/playlist import` zu übernehmen. Der Link gilt %d Minuten und nur für dich.",
+	"spotify.link_unavailable":   "❌ Das Verknüpfen von Spotify-Konten ist auf diesem Bot nicht eingerichtet",
+	"spotify.link_first":         "Verknüpfe zuerst dein Spotify-Konto mit `/spotify link`",
+	"spotify.link_revoked":       "❌ Spotify akzeptiert den Zugriff des Bots auf dein Konto nicht mehr. Verknüpfe es erneut mit `/spotify link`.",
+	"spotify.unlinked":           "Dein Spotify-Konto ist nicht mehr verknüpft und der Zugriff des Bots wurde gelöscht. Du kannst die App auch unter spotify.com/account/apps entfernen.",
+	"spotify.not_linked":         "Du hast kein Spotify-Konto verknüpft",
+	"spotify.status":             "Seit <t:%[2]d:D> mit dem Spotify-Konto **%[1]s** verknüpft",
+	"spotify.liked_songs":        "Lieblingssongs",
+	"spotify.playlist_not_found": "❌ Du hast keine Spotify-Playlist namens %q",
+	"spotify.library_empty":      "❌ %s enthält keine Songs",
+	"spotify.import_source":      "❌ Wähle spotify:liked oder spotify:<Playlist-Name> zum Importieren",
+	"spotify.import_no_matches":  "❌ Keiner der Songs aus %s wurde auf YouTube gefunden",
+	"spotify.imported":           "%d von %d Songs aus %s in der Playlist **%s** gespeichert. Mit `/playlist play %s` reihst du sie ein.",
+	"spotify.callback_linked":    "Dein Spotify-Konto ist verknüpft. Du kannst diese Seite schließen und zu Discord zurückkehren.",
+	"spotify.callback_denied":    "Der Bot hat keinen Zugriff auf dein Spotify-Konto erhalten. Führe /spotify link erneut aus, um es noch einmal zu versuchen.",
+	"spotify.callback_expired":   "Dieser Link ist abgelaufen oder wurde bereits verwendet. Führe /spotify link erneut aus.",
+	"spotify.callback_failed":    "Dein Spotify-Konto konnte nicht verknüpft werden. Bitte versuche es später erneut.",
+
+	"settings.download_rate":         "Jeder Download für diesen Server ist auf %d KB/s begrenzt",
+	"settings.download_rate_default": "Downloads für diesen Server haben keine eigene Begrenzung",
+	"settings.download_rate_bot":     "Der Bot begrenzt alle Downloads zusammen auf %d KB/s",
+
+	"settings.same_channel_off":    "Alle auf dem Server können die Wiedergabe wieder steuern",
+	"settings.kiosk_channel":       "In <#%s> gepostete Songs werden für alle im Sprachkanal eingereiht. Der Bot braucht dort die Berechtigung „Nachrichten verwalten“, um Anfragen aufzuräumen.",
+	"settings.kiosk_off":           "Es ist kein Kiosk-Kanal festgelegt",
+	"settings.kiosk_unavailable":   "❌ Kiosk-Kanäle sind bei diesem Bot abgeschaltet. Der Betreiber muss KIOSK_ENABLED setzen und den Message-Content-Intent aktivieren.",
+	"settings.nickname_on":         "Der Nickname des Bots zeigt jetzt, ob Musik läuft",
+	"settings.nickname_station":    "Der Nickname des Bots zeigt jetzt den Wiedergabestatus neben %s",
+	"settings.nickname_off":        "Der Nickname des Bots zeigt den Wiedergabestatus nicht mehr",
+	"settings.station_too_long":    "❌ Sendernamen dürfen höchstens %d Zeichen lang sein",
+	"settings.filter_updated":      "Inhaltsfilter aktualisiert.",
+	"settings.explicit_on":         "Explizite Titel: abgelehnt",
+	"settings.explicit_off":        "Explizite Titel: erlaubt",
+	"settings.explicit_no_spotify": "⚠️ Spotify ist bei diesem Bot nicht eingerichtet, daher können explizite Titel nicht erkannt werden",
+	"settings.keywords":            "Gefilterte Wörter: %s",
+	"settings.no_keywords":         "Gefilterte Wörter: keine",
+	"settings.engine_unavailable":  "❌ Die Engine %s ist auf diesem Bot nicht verfügbar. Verfügbare Engines: %s",
+	"settings.autoplay_updated":    "Autoplay-Einstellungen aktualisiert.",
+	"settings.autoplay_seed":       "Quelle: %s",
+	"settings.autoplay_no_seed":    "Quelle: keine",
+	"settings.autoplay_engine":     "Engine: %s",
+	"settings.autoplay_no_engine":  "Engine: keine, Autoplay spielt zum letzten Titel passende Videos",
+
+	"settings.announcements_updated":    "Ansagen-Einstellungen aktualisiert.",
+	"settings.announce_channel":         "Ansagekanal: <#%s>",
+	"settings.announce_request_channel": "Ansagekanal: der Kanal, in dem die Musik gewünscht wurde",
+	"settings.quiet_on":                 "Ruhemodus: an, nur Fehler werden gepostet",
+	"settings.quiet_off":                "Ruhemodus: aus",
+	"settings.language_set":             "Der Bot antwortet jetzt auf %s",
+	"settings.language_unsupported":     "❌ %s ist keine unterstützte Sprache",
+
+	"settings.api_token_created": "🔑 Neues REST-API-Token, wird nur einmal angezeigt. Ein bisheriges Token funktioniert nicht mehr.\n||`%s`||\nSende es als `Authorization: Bearer <token>` an `/api/v1/guilds/%s/...`.",
+	"settings.api_token_revoked": "Das REST-API-Token wurde widerrufen",
+	"settings.api_no_token":      "Dieser Server hat kein REST-API-Token",
+
+	// Command names and descriptions shown in Discord's command picker
+	"cmdname.join":         "beitreten",
+	"cmdname.leave":        "verlassen",
+	"cmdname.play":         "abspielen",
+	"cmdname.playmany":     "vieleabspielen",
+	"cmdname.queue":        "warteschlange",
+	"cmdname.repeat":       "wiederholen",
+	"cmdname.replay":       "nochmal",
+	"cmdname.karaoke":      "karaoke",
+	"cmdname.chapters":     "kapitel",
+	"cmdname.nextchapter":  "nächsteskapitel",
+	"cmdname.stats":        "statistik",
+	"cmdname.settings":     "einstellungen",
+	"cmdname.removeuser":   "nutzerentfernen",
+	"cmdname.summon":       "herbeirufen",
+	"cmdname.move":         "verschieben",
+	"cmdname.radio":        "radio",
+	"cmdname.trending":     "trends",
+	"cmdname.reverse":      "umkehren",
+	"cmdname.leavecleanup": "aufräumen",
+	"cmdname.privacy":      "datenschutz",
+	"cmdname.say":          "sagen",
+	"cmdname.sound":        "klang",
+	"cmdname.blocklist":    "sperrliste",
+	"cmdname.lookup":       "nachschlagen",
+	"cmdname.auditlog":     "protokoll",
+	"cmdname.spotify":      "spotify",
+
+	"cmd.ping":                                 "Antwortet mit Pong!",
+	"cmd.join":                                 "Deinem Sprachkanal beitreten",
+	"cmd.leave":                                "Den Sprachkanal verlassen",
+	"cmd.play":                                 "Eine YouTube- oder Spotify-URL abspielen",
+	"cmd.play.url":                             "Die URL oder der Alias zum Abspielen",
+	"cmd.play.position":                        "Wo der Titel in die Warteschlange soll",
+	"cmd.play.from":                            "Bei Playlists die Position des ersten einzureihenden Eintrags",
+	"cmd.play.to":                              "Bei Playlists die Position des letzten einzureihenden Eintrags",
+	"cmd.playmany":                             "Mehrere URLs oder Suchbegriffe einfügen, einen pro Zeile, um alle einzureihen",
+	"cmd.queue":                                "Warteschlange anzeigen, erweitern oder speichern",
+	"cmd.queue.show":                           "Die aktuelle Warteschlange anzeigen",
+	"cmd.queue.add":                            "Eine URL oder einen Alias einreihen",
+	"cmd.queue.add.url":                        "Die einzureihende URL",
+	"cmd.queue.add.from":                       "Bei Playlists die Position des ersten einzureihenden Eintrags",
+	"cmd.queue.add.to":                         "Bei Playlists die Position des letzten einzureihenden Eintrags",
+	"cmd.queue.save":                           "Aktuellen Titel und Warteschlange als Playlist speichern",
+	"cmd.queue.save.name":                      "Der Name der Playlist",
+	"cmd.queue.save.overwrite":                 "Eine vorhandene Playlist mit gleichem Namen ersetzen",
+	"cmd.queue.export":                         "Aktuellen Titel und Warteschlange als Datei anhängen",
+	"cmd.queue.export.format":                  "Das Dateiformat (standardmäßig JSON)",
+	"cmd.queue.import":                         "Die Titel einer exportierten Warteschlangen-Datei einreihen",
+	"cmd.queue.import.file":                    "Eine JSON- oder Textdatei aus /queue export",
+	"cmd.repeat":                               "Wiederholung ein- oder ausschalten oder den aktuellen Titel mehrmals wiederholen",
+	"cmd.repeat.times":                         "Den aktuellen Titel so oft zusätzlich spielen, bevor die Warteschlange weitergeht (0 bricht ab)",
+	"cmd.replay":                               "Den aktuellen Titel von vorne starten",
+	"cmd.replay.after":                         "Nach dem Ende noch einmal spielen, statt jetzt neu zu starten",
+	"cmd.karaoke":                              "Karaoke-Modus ein- oder ausschalten, der den Gesang aus der Musik entfernt",
+	"cmd.chapters":                             "Die Kapitel des laufenden Videos anzeigen",
+	"cmd.nextchapter":                          "Zum nächsten Kapitel des laufenden Videos springen",
+	"cmd.autoplay":                             "Autoplay ein- oder ausschalten",
+	"cmd.top":                                  "Die meistgespielten Titel aller Server anzeigen",
+	"cmd.top.limit":                            "Wie viele Titel angezeigt werden (standardmäßig 10)",
+	"cmd.diagnose":                             "Die Berechtigungen des Bots in diesem und deinem Sprachkanal prüfen",
+	"cmd.summon":                               "Den Bot in deinen Sprachkanal holen, ohne den Titel zu unterbrechen",
+	"cmd.move":                                 "Den Bot in einen anderen Sprachkanal verschieben, ohne den Titel zu unterbrechen (nur DJs)",
+	"cmd.move.channel":                         "Der Sprachkanal, in den der Bot wechseln soll",
+	"cmd.radio":                                "Einen Internetradiosender abspielen",
+	"cmd.radio.play":                           "Einen der Radiosender des Servers abspielen",
+	"cmd.radio.play.name":                      "Der Sender, z. B. lofi, jazz, classical oder news",
+	"cmd.radio.play.position":                  "Wo der Sender in die Warteschlange soll",
+	"cmd.radio.list":                           "Die Radiosender des Servers anzeigen",
+	"cmd.radio.set":                            "Einen Radiosender hinzufügen oder seinen Stream ändern (nur DJs)",
+	"cmd.radio.set.name":                       "Der Name des Senders",
+	"cmd.radio.set.url":                        "Die Stream-URL",
+	"cmd.radio.remove":                         "Einen Radiosender von diesem Server entfernen (nur DJs)",
+	"cmd.radio.remove.name":                    "Der Name des Senders",
+	"cmd.trending":                             "Titel aus der auf YouTube angesagten Musik zum Einreihen auswählen",
+	"cmd.trending.region":                      "Zweistelliger Ländercode wie DE (Standard: die Region der Invidious-Instanz)",
+	"cmd.removeuser":                           "Alle Titel eines Mitglieds aus der Warteschlange entfernen",
+	"cmd.removeuser.member":                    "Das Mitglied, dessen Titel entfernt werden sollen",
+	"cmd.reverse":                              "Die Reihenfolge der Titel in der Warteschlange umkehren",
+	"cmd.leavecleanup":                         "Titel von Personen entfernen, die den Sprachkanal verlassen haben",
+	"cmd.stats":                                "Hörstatistiken anzeigen",
+	"cmd.stats.music":                          "Meistgespielte Titel und aktivste Wünschende",
+	"cmd.stats.music.window":                   "Der auszuwertende Zeitraum",
+	"cmd.stats.music.user":                     "Nur Titel zählen, die diese Person gewünscht hat",
+	"cmd.stats.runtime":                        "Laufzeit, Ressourcenverbrauch und Werkzeugversionen des Bots",
+	"cmd.alias":                                "Kurznamen für Titel und Playlists verwalten",
+	"cmd.alias.add":                            "Einen Alias für /play speichern",
+	"cmd.alias.add.name":                       "Der Name des Alias",
+	"cmd.alias.add.target":                     "Die URL oder eine deiner Playlists, auf die der Alias zeigt",
+	"cmd.alias.add.shared":                     "Den Alias mit dem ganzen Server teilen (nur DJs)",
+	"cmd.alias.remove":                         "Einen Alias entfernen",
+	"cmd.alias.remove.name":                    "Der Name des Alias",
+	"cmd.alias.remove.shared":                  "Einen Server-Alias statt eines persönlichen entfernen (nur DJs)",
+	"cmd.alias.list":                           "Deine und die Server-Aliase auflisten",
+	"cmd.playlist":                             "Deine Playlists verwalten",
+	"cmd.playlist.create":                      "Eine neue Playlist erstellen",
+	"cmd.playlist.create.name":                 "Der Name der Playlist",
+	"cmd.playlist.add":                         "Eine URL oder den aktuellen Titel zu einer Playlist hinzufügen",
+	"cmd.playlist.add.name":                    "Der Name der Playlist",
+	"cmd.playlist.add.url":                     "Die hinzuzufügende URL (standardmäßig der aktuelle Titel)",
+	"cmd.playlist.list":                        "Deine Playlists oder den Inhalt einer Playlist anzeigen",
+	"cmd.playlist.list.name":                   "Die anzuzeigende Playlist",
+	"cmd.playlist.play":                        "Eine ganze Playlist einreihen",
+	"cmd.playlist.play.name":                   "Der Name der Playlist",
+	"cmd.playlist.delete":                      "Eine Playlist löschen",
+	"cmd.playlist.delete.name":                 "Der Name der Playlist",
+	"cmd.playlist.import":                      "Deine Spotify-Lieblingssongs oder eine deiner Spotify-Playlists als Playlist speichern",
+	"cmd.playlist.import.source":               "spotify:liked oder spotify:<Playlist-Name> (verknüpfe dein Konto mit /spotify link)",
+	"cmd.playlist.import.name":                 "Name der Playlist (standardmäßig der Name der Spotify-Playlist)",
+	"cmd.playlist.import.overwrite":            "Eine vorhandene Playlist mit gleichem Namen ersetzen",
+	"cmd.settings":                             "Den Bot für diesen Server einrichten",
+	"cmd.settings.limits":                      "Limits anzeigen oder ändern (0 bedeutet unbegrenzt)",
+	"cmd.settings.limits.max_track_minutes":    "Maximale Titellänge in Minuten",
+	"cmd.settings.limits.max_queue":            "Maximale Anzahl Titel in der Warteschlange",
+	"cmd.settings.limits.max_per_user":         "Maximal eingereihte Titel pro Nutzer",
+	"cmd.settings.limits.enqueues_per_minute":  "Wie oft ein Mitglied pro Minute Titel einreihen darf (DJs ausgenommen)",
+	"cmd.settings.limits.command_cooldown":     "Sekunden, die ein Mitglied zwischen /replay, /repeat, /say und Ähnlichem warten muss",
+	"cmd.settings.filter":                      "Explizite Titel oder Titel mit bestimmten Wörtern ablehnen",
+	"cmd.settings.filter.explicit":             "Titel ablehnen, die Spotify als explizit markiert",
+	"cmd.settings.filter.add_keyword":          "Titel mit diesem Wort im Namen ablehnen",
+	"cmd.settings.filter.remove_keyword":       "Titel mit diesem Wort im Namen nicht mehr ablehnen",
+	"cmd.settings.autoplay":                    "Festlegen, was Autoplay bei leerer Warteschlange spielt",
+	"cmd.settings.autoplay.seed":               "Eine deiner Playlists, eine YouTube-Playlist-URL oder ein Genre („off“ zum Löschen)",
+	"cmd.settings.autoplay.engine":             "Woher Autoplay seine Empfehlungen bezieht",
+	"cmd.settings.bitrate":                     "Die Audio-Bitrate anzeigen oder ändern, begrenzt auf die Bitrate des Sprachkanals",
+	"cmd.settings.bitrate.kbps":                "Bitrate in kbps von 64 bis 384 (0 nutzt den Standard)",
+	"cmd.settings.downloads":                   "Anzeigen oder ändern, wie schnell Titel für diesen Server heruntergeladen werden",
+	"cmd.settings.downloads.kb_per_second":     "Höchste Downloadgeschwindigkeit in KB/s (0 hebt die Begrenzung auf)",
+	"cmd.settings.duplicates":                  "Festlegen, wie wiederholte Titel behandelt werden",
+	"cmd.settings.duplicates.block":            "Doppelte Titel ablehnen statt nachzufragen",
+	"cmd.settings.duplicates.recent_hours":     "Vor Titeln nachfragen, die in so vielen Stunden schon liefen (0 schaltet es ab)",
+	"cmd.settings.commandchannel":              "Musikbefehle nur in bestimmten Textkanälen erlauben",
+	"cmd.settings.commandchannel.add":          "Musikbefehle in diesem Kanal erlauben",
+	"cmd.settings.commandchannel.remove":       "Musikbefehle in diesem Kanal nicht mehr erlauben",
+	"cmd.settings.commandchannel.clear":        "Musikbefehle wieder in allen Kanälen erlauben",
+	"cmd.settings.webhooks":                    "Titelstarts, -enden und Fehler als JSON an externe Dienste senden",
+	"cmd.settings.webhooks.add":                "HTTPS-URL, an die Ereignisse gesendet werden",
+	"cmd.settings.webhooks.remove":             "Keine Ereignisse mehr an diese URL senden",
+	"cmd.settings.webhooks.clear":              "Alle Webhooks entfernen",
+	"cmd.settings.export":                      "Eine signierte Sicherung der Einstellungen, Aliase und Radiosender dieses Servers herunterladen",
+	"cmd.settings.import":                      "Einstellungen, Aliase und Radiosender aus einer /settings-export-Datei wiederherstellen",
+	"cmd.settings.import.file":                 "Eine Sicherungsdatei aus /settings export",
+	"cmd.settings.samechannel":                 "Nur Mitglieder im Sprachkanal des Bots die Wiedergabe steuern lassen (DJs ausgenommen)",
+	"cmd.settings.samechannel.required":        "Ob Mitglieder im Sprachkanal des Bots sein müssen",
+	"cmd.settings.followdj":                    "Den Bot mitziehen lassen, wenn das Mitglied, dessen Titel läuft, den Sprachkanal wechselt",
+	"cmd.settings.followdj.enabled":            "Ob der Bot dem Mitglied folgt",
+	"cmd.settings.timeouts":                    "Festlegen oder anzeigen, wie lange der Bot untätig oder allein im Sprachkanal bleibt",
+	"cmd.settings.timeouts.idle":               "Minuten, die er bleibt, wenn nichts läuft, „off“ für immer oder „default“",
+	"cmd.settings.timeouts.empty":              "Minuten, die er in einem verlassenen Kanal wartet, „off“ für immer oder „default“",
+	"cmd.settings.tts":                         "Titel vor dem Abspielen im Sprachkanal vorlesen",
+	"cmd.settings.tts.announce":                "Ob Titel vorgelesen werden",
+	"cmd.settings.kiosk":                       "Alle Songs durch Posten ihres Namens in einem Kanal einreihen lassen",
+	"cmd.settings.kiosk.channel":               "Kanal, dessen Nachrichten als Songwünsche eingereiht werden",
+	"cmd.settings.kiosk.disable":               "Den Kiosk-Kanal abschalten",
+	"cmd.settings.kiosk.cleanup":               "Welche Nachrichten im Kiosk-Kanal gelöscht werden",
+	"cmd.settings.nickname":                    "Den Wiedergabestatus im Nickname des Bots anzeigen",
+	"cmd.settings.nickname.enabled":            "Ob der Nickname den Wiedergabestatus zeigt",
+	"cmd.settings.nickname.station":            "Name statt des Benutzernamens des Bots (\"off\" zum Entfernen)",
+	"cmd.settings.public":                      "Anderen Servern erlauben, den Ansagen dieses Servers zu folgen",
+	"cmd.settings.public.enabled":              "Ob die Session öffentlich ist",
+	"cmd.settings.language":                    "Die Sprache des Bots festlegen",
+	"cmd.settings.language.language":           "Die zu verwendende Sprache",
+	"cmd.settings.announcements":               "Festlegen, wo Titel angesagt werden, oder Ansagen abschalten",
+	"cmd.settings.announcements.channel":       "Kanal für „Läuft gerade“-Nachrichten",
+	"cmd.settings.announcements.quiet":         "Einzelne Titel gar nicht ansagen",
+	"cmd.settings.announcements.reset_channel": "Wieder in dem Kanal ansagen, in dem die Musik gewünscht wurde",
+	"cmd.settings.api":                         "Das REST-API-Token dieses Servers erstellen oder widerrufen",
+	"cmd.settings.api.action":                  "Was mit dem Token geschehen soll",
+	"cmd.follow":                               "Der öffentlichen Musik-Session eines anderen Servers folgen",
+	"cmd.follow.start":                         "Titelwechsel eines anderen Servers in einem Kanal posten",
+	"cmd.follow.start.server":                  "Die ID des Servers, dem gefolgt werden soll",
+	"cmd.follow.start.channel":                 "Wo Ansagen gepostet werden (standardmäßig dieser Kanal)",
+	"cmd.follow.stop":                          "Einer Session nicht mehr folgen",
+	"cmd.follow.stop.server":                   "Die ID des gefolgten Servers",
+	"cmd.follow.list":                          "Die Sessions auflisten, denen dieser Server folgt",
+	"cmd.privacy":                              "Festlegen, ob deine Höraktivität aufgezeichnet wird",
+	"cmd.privacy.optout":                       "Verlauf und Statistik nicht mehr aufzeichnen und Bisheriges löschen",
+	"cmd.privacy.optin":                        "Deine Höraktivität wieder aufzeichnen",
+	"cmd.privacy.status":                       "Anzeigen, ob deine Höraktivität aufgezeichnet wird",
+	"cmd.spotify":                              "Dein Spotify-Konto verknüpfen, um deine Lieblingssongs und Playlists abzuspielen",
+	"cmd.spotify.link":                         "Einen Link zum Verbinden deines Spotify-Kontos erhalten",
+	"cmd.spotify.unlink":                       "Dein Spotify-Konto trennen und den Zugriff löschen",
+	"cmd.spotify.status":                       "Anzeigen, welches Spotify-Konto verknüpft ist",
+	"cmd.say":                                  "Eine kurze Nachricht im Sprachkanal sprechen",
+	"cmd.say.text":                             "Was der Bot sagen soll",
+	"cmd.sound":                                "Kurze Clips aus dem Soundboard des Servers abspielen",
+	"cmd.sound.play":                           "Einen Clip abspielen, über der Musik, falls etwas läuft",
+	"cmd.sound.play.name":                      "Name des Clips",
+	"cmd.sound.list":                           "Die Clips des Servers auflisten",
+	"cmd.sound.add":                            "Einen Clip von bis zu 10 Sekunden hochladen (nur Admins)",
+	"cmd.sound.add.name":                       "Name, unter dem der Clip abgespielt wird (Buchstaben, Ziffern, - und _)",
+	"cmd.sound.add.file":                       "Die Audiodatei",
+	"cmd.sound.remove":                         "Einen Clip löschen (nur Admins)",
+	"cmd.sound.remove.name":                    "Name des Clips",
+	"cmd.blocklist":                            "Videos, Kanäle oder Domains auf diesem Server sperren",
+	"cmd.blocklist.add":                        "Ein Video, einen Kanal oder eine Domain sperren",
+	"cmd.blocklist.add.kind":                   "Was gesperrt wird",
+	"cmd.blocklist.add.value":                  "Videolink oder -ID, Kanallink, -ID oder @Handle, oder Domain",
+	"cmd.blocklist.remove":                     "Ein Video, einen Kanal oder eine Domain entsperren",
+	"cmd.blocklist.remove.kind":                "Was entsperrt wird",
+	"cmd.blocklist.remove.value":               "Der zu entfernende Eintrag",
+	"cmd.blocklist.list":                       "Die Sperrliste anzeigen",
+	"cmd.lookup":                               "Details zu einem Link anzeigen, ohne ihn einzureihen",
+	"cmd.lookup.url":                           "Der zu prüfende Link",
+	"cmd.auditlog":                             "Zeigen, wer zuletzt welche Befehle auf diesem Server genutzt hat (nur Admins)",
+	"cmd.auditlog.user":                        "Nur die Befehle dieses Mitglieds zeigen",
+	"cmd.auditlog.limit":                       "Wie viele Befehle gezeigt werden (Standard 20)",
+
+	"choice.queue.export.format.text":         "Text (eine URL pro Zeile)",
+	"choice.blocklist.add.kind.video":         "Video",
+	"choice.blocklist.add.kind.channel":       "Kanal",
+	"choice.blocklist.add.kind.domain":        "Domain",
+	"choice.blocklist.remove.kind.video":      "Video",
+	"choice.blocklist.remove.kind.channel":    "Kanal",
+	"choice.blocklist.remove.kind.domain":     "Domain",
+	"choice.play.position.end":                "Ende der Warteschlange",
+	"choice.play.position.next":               "Als Nächstes spielen",
+	"choice.play.position.now":                "Sofort spielen",
+	"choice.radio.play.position.end":          "Ende der Warteschlange",
+	"choice.radio.play.position.next":         "Als Nächstes spielen",
+	"choice.radio.play.position.now":          "Sofort spielen",
+	"choice.stats.music.window.day":           "Letzte 24 Stunden",
+	"choice.stats.music.window.week":          "Letzte 7 Tage",
+	"choice.stats.music.window.month":         "Letzte 30 Tage",
+	"choice.stats.music.window.all":           "Gesamter Zeitraum",
+	"choice.settings.autoplay.engine.seed":    "Nur Quelle",
+	"choice.settings.autoplay.engine.youtube": "Ähnliche YouTube-Videos",
+	"choice.settings.autoplay.engine.spotify": "Spotify-Empfehlungen",
+	"choice.settings.autoplay.engine.lastfm":  "Ähnliche Titel auf Last.fm",
+	"choice.settings.api.action.create":       "Neues Token erstellen",
+	"choice.settings.api.action.revoke":       "Token widerrufen",
+	"choice.settings.kiosk.cleanup.all":       "Alle, Anfragen werden durch eine Bestätigung ersetzt",
+	"choice.settings.kiosk.cleanup.chatter":   "Nur Nachrichten, die keine Songwünsche sind",
+	"choice.settings.kiosk.cleanup.none":      "Keine, Anfragen bekommen eine Reaktion",
+}
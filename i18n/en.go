@@ -0,0 +1,455 @@
+package i18n
+
+// english is the default catalog. Every key used by the bot must exist here.
+var english = Catalog{
+	"error":                     "❌ %v",
+	"command.failed":            "Error: Failed to process your command. Please try again.",
+	"command.choose_subcommand": "Please choose a subcommand",
+
+	"ping.pong": "Pong!",
+
+	"time.minutes": "%d minutes",
+	"time.hours":   "%d hours",
+	"time.seconds": "%ds",
+
+	"voice.user_not_connected": "❌ You need to be in a voice channel first!",
+	"voice.bot_not_connected":  "❌ I'm not in a voice channel!",
+	"voice.join_failed":        "❌ Error joining voice channel: %v",
+	"voice.connect_failed":     "❌ Failed to connect to voice channel. Please try again.",
+	"voice.leave_failed":       "❌ Error leaving voice channel: %v",
+	"voice.joined":             "Joined voice channel!",
+	"voice.left":               "Left voice channel!",
+	"voice.followed":           "🚶 Followed the DJ to <#%s>",
+	"voice.not_same_channel":   "❌ Join <#%s> to control playback on this server",
+	"voice.released":           "⏹️ I was disconnected from the voice channel, so playback stopped. Use /join to bring me back.",
+
+	"voice.missing_permissions": "❌ I can't play in <#%s>. Ask a server admin to give me these permissions there:",
+	"voice.channel_full":        "❌ <#%s> is full. Make room, raise its user limit or give me the Move Members permission.",
+
+	"bitrate.low":         "ℹ️ This voice channel is limited to %d kbps, Discord's default, so music will sound muffled. Anyone with Manage Channels can raise the bitrate in the channel settings.",
+	"bitrate.low_suggest": "ℹ️ This voice channel is limited to %d kbps, Discord's default, so music will sound muffled. <#%s> allows %d kbps, consider moving there.",
+
+	"queue.empty":           "The queue is empty",
+	"queue.header":          "Current queue:",
+	"queue.entry":           "%d. %s (requested by %s)",
+	"queue.track_count":     "%d tracks",
+	"queue.playing_now":     "Playing now: %s",
+	"queue.playing_next":    "Playing next: %s",
+	"queue.added":           "Added to queue: %s",
+	"queue.exported":        "📤 Exported %d tracks",
+	"queue.import_no_file":  "❌ Attach a queue file to import",
+	"queue.import_too_big":  "❌ Queue files can be at most %d KB",
+	"queue.import_invalid":  "❌ That file is not a queue export: %v",
+	"queue.import_empty":    "❌ That file has no tracks",
+	"queue.import_too_many": "❌ A queue file can have at most %d tracks",
+	"queue.added_eta":       "Added to queue: %s (position %d, playing %s)",
+	"queue.total":           "Total: %s",
+	"queue.total_unknown":   "Total: unknown, some tracks have no length",
+	"queue.eta_minutes":     "in ~%d min",
+	"queue.eta_soon":        "in under a minute",
+	"queue.eta_unknown":     "start time unknown",
+
+	"repeat.enabled":  "Repeat mode enabled",
+	"repeat.disabled": "Repeat mode disabled",
+
+	"repeat.times":         "🔂 %s will play %d more time(s), then the queue continues",
+	"repeat.times_cleared": "%s won't repeat anymore",
+
+	"replay.nothing_playing": "Nothing is playing to replay",
+	"replay.restarted":       "🔁 Restarting %s",
+	"replay.queued":          "🔁 %s will play again once it finishes",
+
+	"karaoke.enabled":  "🎤 Karaoke mode enabled: vocals are removed from the music",
+	"karaoke.disabled": "Karaoke mode disabled",
+	"karaoke.remote":   "❌ Karaoke mode isn't available while playing through Lavalink",
+
+	"chapters.nothing_playing": "Nothing is playing",
+	"chapters.none":            "The playing track has no chapters",
+	"chapters.header":          "📑 Chapters of %s:",
+	"chapters.entry":           "%s `%s` %s",
+	"chapters.last":            "This is already the last chapter",
+	"chapters.skipped":         "⏭️ Skipped to %s (%s)",
+
+	"autoplay.enabled":  "Autoplay mode enabled",
+	"autoplay.disabled": "Autoplay mode disabled",
+	"autoplay.failed":   "❌ Autoplay couldn't find a track: %v",
+
+	"player.downloading":         "Downloading: %s",
+	"player.connecting_live":     "📡 Connecting to live stream: %s",
+	"player.now_playing":         "🎵 Now playing: %s",
+	"player.now_playing_from":    "🎵 Now playing: %s (from %s)",
+	"player.now_playing_live":    "🔴 Now playing live: %s",
+	"player.now_playing_radio":   "🔴 Now playing live: %s\n🎵 %s",
+	"player.finished":            "✅ Finished playing: %s",
+	"player.invalid_youtube_url": "❌ Invalid YouTube URL",
+	"player.download_failed":     "❌ Error downloading audio: %v",
+	"player.download_gave_up":    "❌ Couldn't download this track after %d attempts, skipping it:\n%s",
+	"player.age_restricted":      "🔞 This video is age-restricted and no working YouTube account is configured",
+	"player.region_locked":       "🌍 This video isn't available in the bot's region",
+	"player.members_only":        "🔒 This video is only available to channel members or Premium subscribers",
+	"player.unavailable":         "🚫 This video is private, removed or otherwise unavailable",
+	"player.skipped":             "⏭️ Skipping: %v",
+	"player.play_failed":         "❌ Error playing audio: %v",
+	"player.spotify_unavailable": "❌ Spotify support is not available",
+	"player.spotify_unsupported": "❌ Spotify support is not yet implemented",
+	"player.unsupported_url":     "❌ Unsupported URL. Please provide a YouTube or Spotify URL.",
+
+	"shutdown.notice":  "⚠️ Bot restarting in %s; playback will pick up where it left off.",
+	"shutdown.resumed": "▶️ Back after a restart, resuming playback.",
+
+	"cleanup.members_failed": "❌ Error reading voice channel members: %v",
+	"cleanup.nothing":        "Everyone with queued tracks is still here, nothing to clean up",
+	"cleanup.removed":        "🧹 Removed %d track(s) from %d user(s) who left the voice channel",
+
+	"removeuser.dj_only": "❌ Only DJs can remove other members' tracks",
+	"removeuser.nothing": "<@%s> has no tracks in the queue",
+	"removeuser.removed": "🧹 Removed %d track(s) queued by <@%s>",
+
+	"move.dj_only":          "❌ Only DJs can move the bot to another channel. Use /summon to bring it to yours.",
+	"move.others_listening": "❌ People are still listening in <#%s>. Join them or ask a DJ to move the bot.",
+	"move.already_there":    "I'm already in <#%s>",
+	"move.moved":            "🔀 Moved to <#%s>",
+
+	"radio.title":     "📻 %s radio",
+	"radio.dj_only":   "❌ Only DJs can change the server's radio stations",
+	"radio.not_found": "❌ No radio station named `%s`. Use `/radio list` to see them.",
+	"radio.none":      "This server has no radio stations. DJs can add one with `/radio set`.",
+	"radio.header":    "📻 **Radio stations**",
+	"radio.bad_url":   "❌ Give the station's stream URL, starting with http:// or https://. YouTube links can be queued with /play.",
+	"radio.saved":     "📻 Saved radio station `%s`",
+	"radio.removed":   "Removed radio station `%s`",
+
+	"trending.unavailable":   "❌ /trending is turned off on this bot. The operator needs to set INVIDIOUS_URL.",
+	"trending.bad_region":    "❌ %q isn't a two-letter country code like US or DE",
+	"trending.failed":        "❌ Couldn't load the trending music, try again later",
+	"trending.empty":         "Nothing is trending in music right now",
+	"trending.header":        "🔥 Trending music, pick tracks to queue:",
+	"trending.header_region": "🔥 Trending music in %s, pick tracks to queue:",
+	"trending.placeholder":   "Choose tracks",
+	"trending.expired":       "❌ This menu has expired, run /trending again",
+	"trending.not_requester": "❌ Only the person who ran /trending can pick from this menu",
+
+	"reverse.too_short": "There need to be at least two queued tracks to reverse",
+	"reverse.done":      "🔃 Reversed the order of %d queued tracks",
+
+	"limits.queue_full":     "the queue is full (maximum %d tracks on this server)",
+	"limits.queue_too_long": "that would make the queue too long: %d tracks queued, %d more allowed, you tried to add %d",
+	"limits.per_user":       "you can have at most %d pending tracks on this server (you have %d, tried to add %d)",
+	"limits.track_too_long": "%s is %s long; the maximum track length on this server is %s",
+	"limits.age_restricted": "%s is age-restricted and no working YouTube account is configured",
+	"limits.region_locked":  "%s isn't available in the bot's region",
+	"limits.members_only":   "%s is only available to channel members or Premium subscribers",
+	"limits.unavailable":    "%s is private, removed or otherwise unavailable",
+
+	"ratelimit.enqueues": "🐢 Slow down! You can queue tracks %d times per minute on this server. Try again <t:%d:R>.",
+	"ratelimit.cooldown": "🐢 Slow down! You can use %s again <t:%d:R>.",
+
+	"duplicate.blocked":       "❌ %s is already in the queue",
+	"duplicate.confirm":       "⚠️ %s is already in the queue. Queue it anyway?",
+	"duplicate.recent":        "⚠️ %s was played %s ago. Queue it anyway?",
+	"duplicate.queue_anyway":  "Queue anyway",
+	"duplicate.cancel":        "Cancel",
+	"duplicate.cancelled":     "Cancelled.",
+	"duplicate.expired":       "This request has expired. Please run the command again.",
+	"duplicate.not_requester": "Only the person who requested this track can confirm it",
+
+	"alias.dj_only":         "❌ Only DJs can manage shared aliases",
+	"alias.save_failed":     "❌ Error saving alias: %v",
+	"alias.remove_failed":   "❌ Error removing alias: %v",
+	"alias.saved":           "Saved alias `%s` → %s",
+	"alias.not_found":       "No alias named `%s`",
+	"alias.removed":         "Removed alias `%s`",
+	"alias.none":            "No aliases saved. Use `/alias add` to create one.",
+	"alias.personal_header": "**Your aliases**",
+	"alias.guild_header":    "**Server aliases**",
+	"alias.playlist_target": "playlist **%s**",
+
+	"playlist.gone":            "playlist %q no longer exists",
+	"playlist.empty":           "playlist %q is empty",
+	"playlist.created":         "Created playlist **%s**",
+	"playlist.nothing_playing": "Nothing is playing. Provide a URL to add.",
+	"playlist.not_found":       "❌ You don't have a playlist named %q",
+	"playlist.update_failed":   "❌ Error updating playlist: %v",
+	"playlist.added":           "Added %s to **%s** (%d tracks)",
+	"playlist.list_failed":     "❌ Error loading playlists: %v",
+	"playlist.none":            "You don't have any playlists. Use `/playlist create` to make one.",
+	"playlist.header":          "**Your playlists**",
+	"playlist.list_entry":      "• %s (%d tracks)",
+	"playlist.load_failed":     "❌ Error loading playlist: %v",
+	"playlist.is_empty":        "**%s** is empty",
+	"playlist.delete_failed":   "❌ Error deleting playlist: %v",
+	"playlist.deleted":         "Deleted playlist **%s**",
+	"playlist.queue_empty":     "The queue is empty, there is nothing to save",
+	"playlist.saved":           "Saved %d tracks to playlist **%s**. Use `/playlist play %s` to restore it.",
+
+	"stats.load_failed":     "❌ Failed to load statistics",
+	"stats.none":            "No tracks played (%s)",
+	"stats.header":          "📊 Music stats (%s)",
+	"stats.user_header":     "📊 Music stats for %s (%s)",
+	"stats.totals":          "Plays: %d, listening time: %s",
+	"stats.top_tracks":      "**Most played tracks**",
+	"stats.track_entry":     "%d. %s (%d plays)",
+	"stats.top_requesters":  "**Most active requesters**",
+	"stats.requester_entry": "%d. %s (%d tracks, %s)",
+	"stats.window.day":      "last 24 hours",
+	"stats.window.week":     "last 7 days",
+	"stats.window.month":    "last 30 days",
+	"stats.window.all":      "all time",
+
+	"runtime.title":         "⚙️ Bot runtime",
+	"runtime.uptime":        "Uptime",
+	"runtime.guilds":        "Servers",
+	"runtime.voice":         "Voice connections",
+	"runtime.memory":        "Memory",
+	"runtime.memory_value":  "%s heap, %s total",
+	"runtime.goroutines":    "Goroutines",
+	"runtime.latency":       "Gateway latency",
+	"runtime.cache":         "Audio cache",
+	"runtime.cookies":       "YouTube accounts",
+	"runtime.cookies_none":  "none",
+	"runtime.cookies_value": "%d of %d working",
+
+	"top.none":   "Nothing has been played yet",
+	"top.header": "🏆 **Most played tracks across all servers**",
+
+	"diagnose.channel_header": "**<#%s>**",
+	"diagnose.unknown":        "❓ Couldn't read my permissions here: %v",
+	"diagnose.no_voice":       "Join a voice channel to check my voice permissions too.",
+	"diagnose.all_good":       "Everything looks good. If I'm still silent, check that I'm not server muted.",
+	"diagnose.unchecked":      "I couldn't read all of my permissions, so some may still be missing. Try again in a moment.",
+	"diagnose.missing":        "⚠️ %d permission(s) missing. Ask an admin to grant them to my role or in the channel settings.",
+	"diagnose.reason.view":    "I can't see the channel",
+	"diagnose.reason.send":    "I can't post now playing messages or errors",
+	"diagnose.reason.embed":   "track links and info won't show previews",
+	"diagnose.reason.manage":  "I can't update or clean up my own messages",
+	"diagnose.reason.connect": "I can't join the voice channel",
+	"diagnose.reason.speak":   "I can join but nobody will hear anything",
+
+	"diagnose.reason.stage_speaker": "I can't make myself a speaker on the stage, so nobody will hear anything",
+
+	"follow.admin_only":     "❌ You need the Manage Server permission to manage followed sessions",
+	"follow.self":           "❌ A server can't follow itself",
+	"follow.unknown_server": "❌ I'm not in a server with that ID",
+	"follow.not_public":     "❌ **%s** hasn't made its session public",
+	"follow.save_failed":    "❌ Error saving follow: %v",
+	"follow.started":        "Now following **%s**. Track changes will be posted in <#%s>.",
+	"follow.remove_failed":  "❌ Error removing follow: %v",
+	"follow.not_following":  "This server isn't following that session",
+	"follow.stopped":        "Stopped following that session",
+	"follow.list_failed":    "❌ Error loading followed sessions: %v",
+	"follow.none":           "This server isn't following any sessions",
+	"follow.header":         "**Followed sessions**",
+	"follow.now_playing":    "📻 Now playing in **%s**: %s",
+	"follow.join":           "Join the session: %s",
+
+	"privacy.save_failed":   "❌ Error saving your privacy setting: %v",
+	"privacy.opted_out":     "🔒 Your listening activity is no longer recorded. Your requests still work as before.",
+	"privacy.forgotten":     "Removed %d recorded play(s) from the statistics.",
+	"privacy.forget_failed": "⚠️ Your earlier plays couldn't be removed from the statistics. Please try again later.",
+	"privacy.opted_in":      "Your listening activity is recorded again",
+	"privacy.status_out":    "🔒 You opted out. Your history and stats aren't recorded.",
+	"privacy.status_in":     "Your plays count towards history and stats. Use `/privacy optout` to stop that.",
+
+	"lookup.invalid_url":  "❌ Please give a full link starting with http:// or https://",
+	"lookup.failed":       "❌ Couldn't read that link: %v",
+	"lookup.duration":     "Duration",
+	"lookup.uploader":     "Uploader",
+	"lookup.uploaded":     "Uploaded",
+	"lookup.views":        "Views",
+	"lookup.availability": "Availability",
+	"lookup.cached":       "Cached",
+	"lookup.live":         "Live stream",
+	"lookup.too_long":     "(over this server's %s limit)",
+	"lookup.unknown":      "unknown",
+	"lookup.yes":          "yes",
+	"lookup.no":           "no",
+
+	"lookup.availability.public":          "public",
+	"lookup.availability.unlisted":        "unlisted",
+	"lookup.availability.private":         "private",
+	"lookup.availability.needs_auth":      "needs sign-in (e.g. age-restricted)",
+	"lookup.availability.premium_only":    "Premium only",
+	"lookup.availability.subscriber_only": "members only",
+
+	"tts.now_playing": "Now playing: %s",
+	"tts.said":        "🗣️ Message spoken",
+	"tts.busy":        "❌ The bot is already saying something, try again in a moment",
+	"tts.too_long":    "❌ Messages can be at most %d characters long",
+	"tts.remote":      "❌ Speech isn't available while playback runs on a Lavalink node",
+	"tts.unavailable": "❌ Text-to-speech isn't set up on this bot",
+	"tts.failed":      "❌ Couldn't say that: %v",
+
+	"sound.saved":        "🔊 Saved the clip `%s`. Play it with `/sound play`.",
+	"sound.removed":      "🗑️ Removed the clip `%s`",
+	"sound.played":       "🔊 Played `%s`",
+	"sound.list":         "🔊 Clips: %s",
+	"sound.none":         "This server has no clips yet. Admins can upload some with `/sound add`.",
+	"sound.not_found":    "❌ There's no clip called `%s`",
+	"sound.invalid_name": "❌ Clip names can only use lowercase letters, digits, - and _ (up to 32 characters)",
+	"sound.no_file":      "❌ Attach an audio file to upload",
+	"sound.too_big":      "❌ Clips can be at most %d MB",
+	"sound.full":         "❌ This server already has %d clips, remove one first",
+	"sound.save_failed":  "❌ Couldn't save the clip: %v",
+	"sound.admin_only":   "❌ You need the Manage Server permission to change the soundboard",
+	"sound.busy":         "❌ Something else is playing over the music right now, try again in a moment",
+	"sound.remote":       "❌ The soundboard isn't available while playback runs on a Lavalink node",
+
+	"playmany.title":       "Queue several tracks",
+	"playmany.label":       "One URL, alias or search term per line",
+	"playmany.placeholder": "https://www.youtube.com/watch?v=...\nartist - song title",
+	"playmany.empty":       "❌ There was nothing to queue",
+	"playmany.too_many":    "❌ At most %d lines can be queued at once",
+	"playmany.duplicate":   "already queued",
+	"playmany.failed_line": "❌ %s: %v",
+	"playmany.summary":     "Queued %d of %d lines",
+
+	"blocklist.admin_only":      "❌ You need the Manage Server permission to change the blocklist",
+	"blocklist.invalid":         "❌ %s isn't a valid entry of that kind",
+	"blocklist.added":           "🚫 Blocked `%s`",
+	"blocklist.already":         "`%s` is already blocked",
+	"blocklist.removed":         "✅ Unblocked `%s`",
+	"blocklist.not_found":       "`%s` isn't on the blocklist",
+	"blocklist.empty":           "Nothing is blocked on this server",
+	"blocklist.videos":          "🚫 Videos: %s",
+	"blocklist.channels":        "🚫 Uploader channels: %s",
+	"blocklist.domains":         "🚫 Domains: %s",
+	"blocklist.blocked_video":   "🚫 %s is blocked on this server",
+	"blocklist.blocked_channel": "🚫 %s can't be played: videos by %s are blocked on this server",
+	"blocklist.blocked_domain":  "🚫 %s can't be played: links to %s are blocked on this server",
+
+	"commandchannel.redirect": "🎵 Music commands go in %s on this server",
+	"commandchannel.anywhere": "Music commands work in every channel",
+	"commandchannel.list":     "Music commands only work in %s",
+
+	"auditlog.admin_only":   "❌ You need the Manage Server permission to read the audit log",
+	"auditlog.failed":       "❌ Couldn't load the audit log",
+	"auditlog.empty":        "No commands have been recorded on this server yet",
+	"auditlog.header":       "📋 **Last %d commands:**",
+	"auditlog.entry":        "<t:%d:R> <@%s> `%s`",
+	"auditlog.entry_failed": " → %s",
+
+	"filter.explicit": "🔞 %s is marked explicit and this server doesn't allow explicit tracks",
+	"filter.keyword":  "🚫 %s can't be played: its title contains a word filtered on this server",
+
+	"kiosk.queued":       "🎶 <@%s> %s",
+	"kiosk.not_found":    "❌ <@%s> nothing found for \"%s\"",
+	"kiosk.duplicate":    "❌ <@%s> **%s** is already queued",
+	"kiosk.not_in_voice": "❌ <@%s> join a voice channel first, then post your song again",
+	"kiosk.slow_down":    "⏳ <@%s> one song at a time, please wait a few seconds",
+
+	"kiosk.cleanup_all":     "Every message there is deleted; requests are replaced by a short confirmation.",
+	"kiosk.cleanup_chatter": "Requests stay and get a reaction and a reply with their queue position; other messages are deleted.",
+	"kiosk.cleanup_none":    "Nothing is deleted; requests get a reaction and a reply with their queue position.",
+
+	"settings.admin_only":          "❌ You need the Manage Server permission to change settings",
+	"settings.unlimited":           "unlimited",
+	"settings.limits_updated":      "Limits updated.",
+	"settings.max_track_length":    "Maximum track length: %s",
+	"settings.max_queue_size":      "Maximum queue size: %s",
+	"settings.max_per_user":        "Maximum pending tracks per user: %s",
+	"settings.enqueues_per_minute": "Enqueues per member: %s",
+	"settings.per_minute":          "%d per minute",
+	"settings.command_cooldown":    "Cooldown between playback commands: %s",
+	"settings.bitrate_default":     "🎚️ Music is encoded at the default bitrate",
+	"settings.bitrate":             "🎚️ Music is encoded at %d kbps",
+	"settings.bitrate_clamped":     "⚠️ The voice channel only allows %d kbps, so that is used instead",
+	"settings.bitrate_range":       "The bitrate must be between %d and %d kbps, or 0 for the default",
+	"settings.duplicates_blocked":  "Tracks that are already queued will now be rejected",
+	"settings.duplicates_confirm":  "Queueing a duplicate track now asks for confirmation",
+	"settings.recent_plays":        "Tracks played in the last %d hours ask for confirmation",
+	"settings.recent_plays_off":    "Recently played tracks are queued without asking",
+	"settings.public_enabled":      "This server's session is now public. Other servers can follow it with `/follow start server:%s`.",
+	"settings.public_disabled":     "This server's session is no longer public",
+	"settings.tts_on":              "Track titles are now read out before they play",
+	"settings.tts_off":             "Track titles are no longer read out",
+	"settings.same_channel_on":     "Only members in the bot's voice channel can control playback now. DJs are exempt.",
+	"settings.follow_dj_on":        "The bot now follows the member whose track is playing when they switch voice channels and nobody else is listening",
+	"settings.follow_dj_off":       "The bot stays in its voice channel when members switch channels",
+	"settings.same_channel_off":    "Anyone on the server can control playback again",
+
+	"settings.timeouts_updated": "Timeouts updated.",
+	"settings.idle_timeout":     "💤 Leave after nothing played for: %s",
+	"settings.empty_timeout":    "🚪 Leave after everyone left the channel for: %s (playback pauses right away)",
+	"settings.timeout_off":      "never",
+	"settings.timeout_default":  "%s (default)",
+	"settings.timeout_invalid":  "❌ `%s` isn't a timeout. Use a number of minutes, `off` or `default`.",
+
+	"inactivity.paused_empty": "⏸️ Everyone left the voice channel, so I paused. Come back to pick up where you left off.",
+	"inactivity.resumed":      "▶️ Welcome back, resuming playback",
+	"inactivity.left_empty":   "👋 Left the voice channel because nobody was listening",
+	"inactivity.left_idle":    "👋 Left the voice channel because nothing was playing",
+
+	"presence.guilds": "music in %d servers",
+
+	"plugins.failed":        "❌ The plugin couldn't handle that: %v",
+	"plugins.done":          "✅ Done",
+	"plugins.nothing_found": "Nothing found for %s",
+
+	"webhooks.invalid":  "❌ That webhook can't be used: %v",
+	"webhooks.too_many": "❌ A server can have at most %d webhooks. Remove one first.",
+	"webhooks.none":     "No webhooks are set. Track events aren't posted anywhere.",
+	"webhooks.list":     "Track starts, ends and errors are posted to:\n%s",
+
+	"backup.exported":          "Here's this server's backup. Restore it with /settings import; it only works with this bot. Webhooks aren't included, since their URLs let anyone post to them.",
+	"backup.imported":          "✅ Restored the settings, aliases, radio stations and deleted playlists from the backup of %s",
+	"backup.imported_other":    "✅ Restored the settings, aliases and radio stations from the backup of %s. It was taken in another server, so its members' playlists were left out; set the command, announcement and kiosk channels again.",
+	"backup.no_file":           "❌ Attach a file from /settings export",
+	"backup.too_big":           "❌ Backup files can be at most %d KB",
+	"backup.invalid":           "❌ That isn't a backup file: %v",
+	"backup.invalid_signature": "❌ That backup wasn't made by this bot or was changed after it was exported",
+	"backup.unavailable":       "❌ Backups aren't available on this bot right now",
+
+	"spotify.link":               "[Link your Spotify account](%s) to play your Liked Songs with `/play spotify:liked` and import your playlists with `/playlist import`. The link works for %d minutes and only for you.",
+	"spotify.link_unavailable":   "❌ Spotify account linking isn't set up on this bot",
+	"spotify.link_first":         "Link your Spotify account with `/spotify link` first",
+	"spotify.link_revoked":       "❌ Spotify no longer accepts the bot's access to your account. Link it again with `/spotify link`.",
+	"spotify.unlinked":           "Your Spotify account is unlinked and the bot's access to it was deleted. You can also remove the app at spotify.com/account/apps.",
+	"spotify.not_linked":         "You haven't linked a Spotify account",
+	"spotify.status":             "Linked to the Spotify account **%s** since <t:%d:D>",
+	"spotify.liked_songs":        "Liked Songs",
+	"spotify.playlist_not_found": "❌ You have no Spotify playlist named %q",
+	"spotify.library_empty":      "❌ %s has no songs",
+	"spotify.import_source":      "❌ Choose spotify:liked or spotify:<playlist name> to import",
+	"spotify.import_no_matches":  "❌ None of the songs in %s were found on YouTube",
+	"spotify.imported":           "Saved %d of %d songs from %s to playlist **%s**. Use `/playlist play %s` to queue it.",
+	"spotify.callback_linked":    "Your Spotify account is linked. You can close this page and go back to Discord.",
+	"spotify.callback_denied":    "The bot wasn't given access to your Spotify account. Run /spotify link again to retry.",
+	"spotify.callback_expired":   "This link has expired or was already used. Run /spotify link again.",
+	"spotify.callback_failed":    "Your Spotify account couldn't be linked. Please try again later.",
+
+	"settings.download_rate":         "Each download for this server is limited to %d KB/s",
+	"settings.download_rate_default": "Downloads for this server have no limit of their own",
+	"settings.download_rate_bot":     "The bot limits all downloads together to %d KB/s",
+
+	"settings.kiosk_channel":       "Songs posted in <#%s> are queued for anyone in voice. The bot needs the Manage Messages permission there to tidy up requests.",
+	"settings.kiosk_off":           "No kiosk channel is set",
+	"settings.kiosk_unavailable":   "❌ Kiosk channels are turned off on this bot. The operator needs to set KIOSK_ENABLED and enable the message content intent.",
+	"settings.nickname_on":         "The bot's nickname now shows whether music is playing",
+	"settings.nickname_station":    "The bot's nickname now shows the player state next to %s",
+	"settings.nickname_off":        "The bot's nickname no longer shows the player state",
+	"settings.station_too_long":    "❌ Station names can be at most %d characters long",
+	"settings.filter_updated":      "Content filter updated.",
+	"settings.explicit_on":         "Explicit tracks: rejected",
+	"settings.explicit_off":        "Explicit tracks: allowed",
+	"settings.explicit_no_spotify": "⚠️ Spotify isn't configured on this bot, so explicit tracks can't be detected",
+	"settings.keywords":            "Filtered words: %s",
+	"settings.no_keywords":         "Filtered words: none",
+	"settings.engine_unavailable":  "❌ The %s engine isn't available on this bot. Available engines: %s",
+	"settings.autoplay_updated":    "Autoplay settings updated.",
+	"settings.autoplay_seed":       "Seed: %s",
+	"settings.autoplay_no_seed":    "Seed: none",
+	"settings.autoplay_engine":     "Engine: %s",
+	"settings.autoplay_no_engine":  "Engine: none, autoplay plays videos related to the last track",
+
+	"settings.announcements_updated":    "Announcement settings updated.",
+	"settings.announce_channel":         "Announcement channel: <#%s>",
+	"settings.announce_request_channel": "Announcement channel: the channel music was requested from",
+	"settings.quiet_on":                 "Quiet mode: on, only errors are posted",
+	"settings.quiet_off":                "Quiet mode: off",
+	"settings.language_set":             "The bot will now answer in %s",
+	"settings.language_unsupported":     "❌ %s isn't a supported language",
+
+	"settings.api_token_created": "🔑 New REST API token, shown only once. Any previous token stops working.\n||`%s`||\nSend it as `Authorization: Bearer <token>` to `/api/v1/guilds/%s/...`.",
+	"settings.api_token_revoked": "The REST API token was revoked",
+	"settings.api_no_token":      "This server has no REST API token",
+}
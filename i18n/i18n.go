@@ -0,0 +1,105 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Supported languages
+const (
+	English = "en"
+	German  = "de"
+
+	// Default is used when a guild hasn't chosen a language
+	Default = English
+)
+
+// Catalog maps message keys to format strings
+type Catalog map[string]string
+
+// catalogs holds the messages of every supported language
+var catalogs = map[string]Catalog{
+	English: english,
+	German:  german,
+}
+
+// names holds the name of every language in that language
+var names = map[string]string{
+	English: "English",
+	German:  "Deutsch",
+}
+
+// locales maps the non-default languages to the Discord locales used when registering commands
+var locales = map[string][]discordgo.Locale{
+	German: {discordgo.German},
+}
+
+// Languages returns the supported language codes, sorted
+func Languages() []string {
+	languages := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Supported reports whether lang is a supported language code
+func Supported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// Name returns the name of a language in that language
+func Name(lang string) string {
+	if name, ok := names[lang]; ok {
+		return name
+	}
+	return lang
+}
+
+// FromLocale returns the supported language matching a Discord locale such as "de" or "en-US"
+func FromLocale(locale discordgo.Locale) (string, bool) {
+	lang := strings.ToLower(strings.SplitN(string(locale), "-", 2)[0])
+	return lang, Supported(lang)
+}
+
+// T returns the message for key in lang formatted with args. Messages missing
+// from a catalog fall back to English, and unknown keys are returned as is.
+func T(lang, key string, args ...interface{}) string {
+	format, ok := catalogs[lang][key]
+	if !ok {
+		format, ok = catalogs[Default][key]
+	}
+	if !ok {
+		format = key
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Localizations returns the translations of key for every Discord locale
+// other than the default language, for use in command registration.
+// It returns nil if key has no translations.
+func Localizations(key string) map[discordgo.Locale]string {
+	localized := make(map[discordgo.Locale]string)
+	for lang, catalog := range catalogs {
+		if lang == Default {
+			continue
+		}
+		if text, ok := catalog[key]; ok {
+			for _, locale := range locales[lang] {
+				localized[locale] = text
+			}
+		}
+	}
+	if len(localized) == 0 {
+		return nil
+	}
+	return localized
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"discordbot/audio"
+	"discordbot/events"
+	"discordbot/settings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// defaultIdleTimeout is how long the bot stays in voice with nothing playing
+	defaultIdleTimeout = 5 * time.Minute
+	// defaultEmptyTimeout is how long the bot waits for someone to come back to
+	// an empty voice channel before leaving it
+	defaultEmptyTimeout = 2 * time.Minute
+	// inactivityInterval is how often voice channels are checked for inactivity
+	inactivityInterval = 15 * time.Second
+)
+
+// inactivity is what the inactivity watcher remembers about a guild
+type inactivity struct {
+	idleSince  time.Time // When playback last stopped; zero while playing
+	emptySince time.Time // When the last listener left; zero while someone listens
+	autoPaused bool      // Whether the watcher paused playback for the empty channel
+}
+
+// guildTimeout returns a timeout setting in minutes as a duration, where zero
+// uses def and a negative value turns the timeout off, returned as zero
+func guildTimeout(minutes int, def time.Duration) time.Duration {
+	switch {
+	case minutes < 0:
+		return 0
+	case minutes == 0:
+		return def
+	default:
+		return time.Duration(minutes) * time.Minute
+	}
+}
+
+// idleTimeout returns how long the bot stays idle in a guild, zero meaning forever
+func idleTimeout(guild settings.Guild) time.Duration {
+	return guildTimeout(guild.IdleTimeoutMinutes, defaultIdleTimeout)
+}
+
+// emptyTimeout returns how long the bot stays in an empty channel of a guild,
+// zero meaning forever
+func emptyTimeout(guild settings.Guild) time.Duration {
+	return guildTimeout(guild.EmptyTimeoutMinutes, defaultEmptyTimeout)
+}
+
+// watchInactivity pauses playback in voice channels everyone left and leaves
+// channels that stayed empty or idle for longer than the guild allows
+func watchInactivity(ctx context.Context, s *discordgo.Session) {
+	states := make(map[string]*inactivity)
+	ticker := time.NewTicker(inactivityInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		voiceManager.Mu.Lock()
+		instances := make([]*audio.VoiceInstance, 0, len(voiceManager.Instances))
+		for _, vi := range voiceManager.Instances {
+			instances = append(instances, vi)
+		}
+		voiceManager.Mu.Unlock()
+
+		for _, vi := range instances {
+			if !vi.Connected() {
+				delete(states, vi.GuildID)
+				continue
+			}
+			state := states[vi.GuildID]
+			if state == nil {
+				state = &inactivity{}
+				states[vi.GuildID] = state
+			}
+			if checkInactivity(s, vi, state) {
+				delete(states, vi.GuildID)
+			}
+		}
+	}
+}
+
+// checkInactivity applies the guild's timeouts to one voice instance and
+// reports whether the bot left the channel
+func checkInactivity(s *discordgo.Session, vi *audio.VoiceInstance, state *inactivity) bool {
+	vi.Mu.Lock()
+	channelID, playing, paused, textChannelID := vi.ChannelID, vi.IsPlaying, vi.Paused, vi.TextChannelID
+	vi.Mu.Unlock()
+	guild := settingsStore.Get(vi.GuildID)
+	now := time.Now()
+
+	// Nobody is listening: pause right away and leave once the timeout passes.
+	// If the channel can't be read, nobody is assumed to have left.
+	count, err := listeners(s, vi.GuildID, channelID)
+	if err != nil {
+		log.Printf("Failed to count listeners in voice channel %s of guild %s: %v", channelID, vi.GuildID, err)
+	}
+	if timeout := emptyTimeout(guild); timeout > 0 && err == nil && count == 0 {
+		if state.emptySince.IsZero() {
+			state.emptySince = now
+		}
+		if playing && !paused && vi.Pause() {
+			state.autoPaused = true
+			publishPlayerEvent(vi, events.Paused)
+			if textChannelID != "" {
+				notifier.Send(textChannelID, trGuild(vi.GuildID, "inactivity.paused_empty"))
+			}
+		}
+		if now.Sub(state.emptySince) >= timeout {
+			leaveInactive(vi, textChannelID, "inactivity.left_empty")
+			return true
+		}
+		return false
+	}
+	state.emptySince = time.Time{}
+
+	// Pick up where we left off for whoever came back
+	if state.autoPaused {
+		state.autoPaused = false
+		if vi.Resume() {
+			publishPlayerEvent(vi, events.Resumed)
+			if textChannelID != "" {
+				notifier.Send(textChannelID, trGuild(vi.GuildID, "inactivity.resumed"))
+			}
+		}
+	}
+
+	if timeout := idleTimeout(guild); timeout > 0 && !playing {
+		if state.idleSince.IsZero() {
+			state.idleSince = now
+		}
+		if now.Sub(state.idleSince) >= timeout {
+			leaveInactive(vi, textChannelID, "inactivity.left_idle")
+			return true
+		}
+		return false
+	}
+	state.idleSince = time.Time{}
+	return false
+}
+
+// leaveInactive leaves an inactive voice channel and says why
+func leaveInactive(vi *audio.VoiceInstance, textChannelID, key string) {
+	log.Printf("Leaving voice in guild %s after inactivity (%s)", vi.GuildID, key)
+	if err := vi.Leave(); err != nil {
+		log.Printf("Error leaving voice channel in guild %s: %v", vi.GuildID, err)
+		return
+	}
+	forgetBitrateWarning(vi.GuildID)
+	if textChannelID != "" {
+		notifier.Send(textChannelID, trGuild(vi.GuildID, key))
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"discordbot/audio"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// handleKaraoke toggles vocal removal for the guild. The playing track is
+// restarted where it is so the filter applies straight away.
+func handleKaraoke(s *discordgo.Session, i *discordgo.InteractionCreate, vi *audio.VoiceInstance) {
+	if vi.IsRemote() {
+		errorResponse(s, i, tr(i, "karaoke.remote"))
+		return
+	}
+
+	vi.Mu.Lock()
+	vi.Karaoke = !vi.Karaoke
+	enabled := vi.Karaoke
+	vi.Mu.Unlock()
+
+	seekTo(vi, vi.Position())
+	if enabled {
+		editResponse(s, i, tr(i, "karaoke.enabled"))
+	} else {
+		editResponse(s, i, tr(i, "karaoke.disabled"))
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"discordbot/audio"
+	"discordbot/config"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// kioskCooldown is how long a guest waits between two kiosk requests
+	kioskCooldown = 10 * time.Second
+	// kioskReplyTTL is how long kiosk confirmations stay in the channel
+	kioskReplyTTL = 15 * time.Second
+	// maxKioskQuery caps the length of messages treated as song requests
+	maxKioskQuery = 200
+)
+
+// Which messages are deleted from a kiosk channel
+const (
+	kioskCleanupAll     = "all"
+	kioskCleanupChatter = "chatter"
+	kioskCleanupNone    = "none"
+)
+
+// Reactions left on kiosk requests that stay in the channel
+const (
+	kioskQueuedReaction = "✅"
+	kioskFailedReaction = "❌"
+)
+
+// kioskEnabled reports whether KIOSK_ENABLED turned on the message content intent kiosk channels need
+var kioskEnabled bool
+
+// kioskRequests holds when each guest last queued through a kiosk channel, keyed by guild and user
+var kioskRequests = struct {
+	sync.Mutex
+	last map[string]time.Time
+}{last: make(map[string]time.Time)}
+
+// setupKiosk reads KIOSK_ENABLED. Kiosk channels need the privileged message
+// content intent, which must also be enabled in the Discord developer portal.
+func setupKiosk() {
+	kioskEnabled = config.Bool("KIOSK_ENABLED", false)
+}
+
+// onKioskMessage queues songs named in plain messages posted to a guild's kiosk channel
+func onKioskMessage(s *discordgo.Session, m *discordgo.MessageCreate) {
+	if m.GuildID == "" || m.Author == nil || m.Author.Bot {
+		return
+	}
+	guild := settingsStore.Get(m.GuildID)
+	if guild.KioskChannelID != m.ChannelID {
+		return
+	}
+
+	query := strings.TrimSpace(m.Content)
+	request := isKioskRequest(query)
+
+	// Keep the channel clean; the confirmation replaces a deleted request
+	kept := true
+	if kioskDeletes(guild.KioskCleanup, request) {
+		if err := s.ChannelMessageDelete(m.ChannelID, m.ID); err != nil {
+			log.Printf("Error deleting kiosk message in guild %s: %v", m.GuildID, err)
+		} else {
+			kept = false
+		}
+	}
+	if !request {
+		return
+	}
+
+	reply := func(queued bool, content string) {
+		if kept {
+			kioskReact(s, m, queued)
+		}
+		kioskReply(s, m, content, kept)
+	}
+
+	if !kioskAllowed(m.GuildID, m.Author.ID) {
+		reply(false, trGuild(m.GuildID, "kiosk.slow_down", m.Author.ID))
+		return
+	}
+
+	vi := voiceManager.GetVoiceInstance(m.GuildID)
+	if !vi.Connected() {
+		if err := joinKioskChannel(s, m, vi); err != nil {
+			reply(false, err.Error())
+			return
+		}
+	}
+
+	track, err := kioskTrack(m, query)
+	if err != nil {
+		log.Printf("Kiosk search for %q in guild %s failed: %v", query, m.GuildID, err)
+		reply(false, trGuild(m.GuildID, "kiosk.not_found", m.Author.ID, query))
+		return
+	}
+	tracks := []*audio.Track{track}
+
+	if err := checkLimits(vi, m.Author.ID, tracks); err != nil {
+		reply(false, err.Error())
+		return
+	}
+	// Guests can't confirm prompts, so duplicates are simply turned away
+	if duplicate := findDuplicate(vi, tracks); duplicate != nil {
+		reply(false, trGuild(m.GuildID, "kiosk.duplicate", m.Author.ID, duplicate.DisplayName()))
+		return
+	}
+
+	// The confirmation says where the track landed in the queue
+	added := addTracks(s, m.ChannelID, vi, tracks, positionEnd)
+	reply(true, trGuild(m.GuildID, "kiosk.queued", m.Author.ID, added))
+}
+
+// isKioskRequest reports whether a kiosk message can be a song request.
+// Empty, multi-line and very long messages are chatter.
+func isKioskRequest(query string) bool {
+	return query != "" && !strings.Contains(query, "\n") && len([]rune(query)) <= maxKioskQuery
+}
+
+// kioskDeletes reports whether a kiosk message is deleted under the guild's cleanup setting
+func kioskDeletes(cleanup string, request bool) bool {
+	switch cleanup {
+	case kioskCleanupNone:
+		return false
+	case kioskCleanupChatter:
+		return !request
+	default:
+		return true
+	}
+}
+
+// kioskAllowed applies the per-guest cooldown and records the request if it may proceed
+func kioskAllowed(guildID, userID string) bool {
+	kioskRequests.Lock()
+	defer kioskRequests.Unlock()
+
+	key := guildID + ":" + userID
+	if time.Since(kioskRequests.last[key]) < kioskCooldown {
+		return false
+	}
+	kioskRequests.last[key] = time.Now()
+	return true
+}
+
+// joinKioskChannel joins the voice channel of the kiosk message's author
+func joinKioskChannel(s *discordgo.Session, m *discordgo.MessageCreate, vi *audio.VoiceInstance) error {
+	vs, err := findUserVoiceState(s, m.GuildID, m.Author.ID)
+	if err != nil {
+		return errors.New(trGuild(m.GuildID, "kiosk.not_in_voice", m.Author.ID))
+	}
+	if problem := voiceProblem(s, m.GuildID, vs.ChannelID, guildLanguage(m.GuildID)); problem != "" {
+		return errors.New(problem)
+	}
+	if err := vi.Join(s, vs.ChannelID); err != nil {
+		return errors.New(trGuild(m.GuildID, "voice.join_failed", err))
+	}
+
+	// Small delay to ensure voice connection is ready
+	time.Sleep(500 * time.Millisecond)
+	if !vi.Ready() {
+		return errors.New(trGuild(m.GuildID, "voice.connect_failed"))
+	}
+	return nil
+}
+
+// kioskTrack turns a kiosk message into a track: links are queued as is,
+// anything else is looked up on YouTube
+func kioskTrack(m *discordgo.MessageCreate, query string) (*audio.Track, error) {
+	track := &audio.Track{
+		URL:         query,
+		RequesterID: m.Author.ID,
+		Requester:   m.Author.Username,
+		AddedAt:     time.Now(),
+	}
+	if isLink(query) {
+		return track, nil
+	}
+	if err := searchTrack(track); err != nil {
+		return nil, err
+	}
+	return track, nil
+}
+
+// kioskReact marks a kiosk request that stays in the channel as queued or turned away
+func kioskReact(s *discordgo.Session, m *discordgo.MessageCreate, queued bool) {
+	emoji := kioskFailedReaction
+	if queued {
+		emoji = kioskQueuedReaction
+	}
+	if err := s.MessageReactionAdd(m.ChannelID, m.ID, emoji); err != nil {
+		log.Printf("Error reacting to kiosk request in guild %s: %v", m.GuildID, err)
+	}
+}
+
+// kioskReply posts a short confirmation in the kiosk channel and removes it again after a while.
+// If the request was kept the confirmation replies to it.
+func kioskReply(s *discordgo.Session, m *discordgo.MessageCreate, content string, kept bool) {
+	send := &discordgo.MessageSend{
+		Content: content,
+		// Mention the guest without pinging them
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	}
+	if kept {
+		send.Reference = m.Reference()
+	}
+	msg, err := s.ChannelMessageSendComplex(m.ChannelID, send)
+	if err != nil {
+		log.Printf("Error sending kiosk reply in guild %s: %v", m.GuildID, err)
+		return
+	}
+	time.AfterFunc(kioskReplyTTL, func() {
+		if err := s.ChannelMessageDelete(msg.ChannelID, msg.ID); err != nil {
+			log.Printf("Error deleting kiosk reply in guild %s: %v", m.GuildID, err)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+
+	"discordbot/i18n"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// guildLanguage returns the language a guild chose with /settings language,
+// or the default language if it hasn't chosen one
+func guildLanguage(guildID string) string {
+	if lang := settingsStore.Get(guildID).Language; i18n.Supported(lang) {
+		return lang
+	}
+	return i18n.Default
+}
+
+// interactionLanguage returns the language to answer an interaction in: the
+// guild's chosen language, else the guild's Discord locale if it's supported
+func interactionLanguage(i *discordgo.InteractionCreate) string {
+	if lang := settingsStore.Get(i.GuildID).Language; i18n.Supported(lang) {
+		return lang
+	}
+	if i.GuildLocale != nil {
+		if lang, ok := i18n.FromLocale(*i.GuildLocale); ok {
+			return lang
+		}
+	}
+	return i18n.Default
+}
+
+// tr returns a message translated for the guild an interaction came from
+func tr(i *discordgo.InteractionCreate, key string, args ...interface{}) string {
+	return i18n.T(interactionLanguage(i), key, args...)
+}
+
+// trGuild returns a message translated for a guild, for messages not tied to an interaction
+func trGuild(guildID, key string, args ...interface{}) string {
+	return i18n.T(guildLanguage(guildID), key, args...)
+}
+
+// localizeCommands adds the translated names and descriptions from the message
+// catalogs to commands before they are registered
+func localizeCommands(cmds []*discordgo.ApplicationCommand) {
+	for _, cmd := range cmds {
+		if names := i18n.Localizations("cmdname." + cmd.Name); names != nil {
+			cmd.NameLocalizations = &names
+		}
+		if descriptions := i18n.Localizations("cmd." + cmd.Name); descriptions != nil {
+			cmd.DescriptionLocalizations = &descriptions
+		}
+		localizeOptions(cmd.Name, cmd.Options)
+	}
+}
+
+// localizeOptions localizes command options and their choices, keyed by their path below the command
+func localizeOptions(path string, options []*discordgo.ApplicationCommandOption) {
+	for _, option := range options {
+		optionPath := path + "." + option.Name
+		option.NameLocalizations = i18n.Localizations("cmdname." + optionPath)
+		option.DescriptionLocalizations = i18n.Localizations("cmd." + optionPath)
+		for _, choice := range option.Choices {
+			choice.NameLocalizations = i18n.Localizations("choice." + optionPath + "." + fmt.Sprint(choice.Value))
+		}
+		localizeOptions(optionPath, option.Options)
+	}
+}
+
+// languageChoices returns the choices for /settings language, each named in its own language
+func languageChoices() []*discordgo.ApplicationCommandOptionChoice {
+	languages := i18n.Languages()
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, len(languages))
+	for idx, lang := range languages {
+		choices[idx] = &discordgo.ApplicationCommandOptionChoice{
+			Name:  i18n.Name(lang),
+			Value: lang,
+		}
+	}
+	return choices
+}
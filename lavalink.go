@@ -0,0 +1,31 @@
+package main
+
+import (
+	"log"
+
+	"discordbot/audio/lavalink"
+	"discordbot/config"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// lavalinkNode streams audio instead of yt-dlp and ffmpeg when LAVALINK_ADDRESS is set
+var lavalinkNode *lavalink.Node
+
+// setupLavalink routes playback through a Lavalink node if one is configured
+func setupLavalink(s *discordgo.Session) {
+	address := config.String("LAVALINK_ADDRESS", "")
+	if address == "" || readOnly {
+		return
+	}
+
+	cfg := lavalink.Config{
+		Address:  address,
+		Password: config.String("LAVALINK_PASSWORD", ""),
+		Secure:   config.Bool("LAVALINK_SECURE", false),
+	}
+
+	lavalinkNode = lavalink.New(cfg, s)
+	voiceManager.Remote = lavalinkNode
+	log.Printf("Playing audio through Lavalink node %s", address)
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"discordbot/audio"
+)
+
+// isYouTubeURL reports whether url points to YouTube
+func isYouTubeURL(url string) bool {
+	return strings.Contains(url, "youtube.com") || strings.Contains(url, "youtu.be")
+}
+
+// fillTrackInfo looks up the title, duration and whether a track is live if its duration is unknown.
+// If yt-dlp can't get the metadata, the title alone is taken from YouTube's oEmbed endpoint.
+func fillTrackInfo(track *audio.Track) error {
+	if track.Duration > 0 || !isYouTubeURL(track.URL) {
+		return nil
+	}
+
+	info, err := videos.GetVideoInfo(track.URL)
+	if err != nil {
+		// oEmbed still knows the titles of videos nobody can play, which
+		// shouldn't look playable
+		if track.Title != "" || restrictionName(err) != "" {
+			return err
+		}
+		embed, embedErr := videos.OEmbed(track.URL)
+		if embedErr != nil {
+			log.Printf("oEmbed fallback for %s failed: %v", track.URL, embedErr)
+			return err
+		}
+		track.Title = embed.Title
+		return nil
+	}
+	if track.Title == "" {
+		track.Title = info.Title
+	}
+	track.Duration = info.Duration
+	track.Live = info.IsLive
+	return nil
+}
+
+// trackInfoWorkers is how many track lookups run at once
+const trackInfoWorkers = 4
+
+// forEachTrack calls lookup for every track, a few at a time, and returns
+// once all calls have. lookup is given the track's index in tracks.
+func forEachTrack(tracks []*audio.Track, lookup func(n int, track *audio.Track)) {
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, trackInfoWorkers)
+	for n, track := range tracks {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(n int, track *audio.Track) {
+			defer func() { <-slots; wg.Done() }()
+			lookup(n, track)
+		}(n, track)
+	}
+	wg.Wait()
+}
+
+// fillTracksInfo runs fillTrackInfo for several tracks, a few at a time.
+// Lookups that fail are logged and leave their track as it is.
+func fillTracksInfo(tracks []*audio.Track) {
+	forEachTrack(tracks, func(_ int, track *audio.Track) {
+		if err := fillTrackInfo(track); err != nil {
+			log.Printf("Failed to get track info for %s: %v", track.URL, err)
+		}
+	})
+}
+
+// checkAllowed enforces the guild's blocklist and content filter on tracks,
+// however they are queued. The returned error is meant to be shown to the user.
+func checkAllowed(guildID string, tracks []*audio.Track) error {
+	if err := checkBlocklist(guildID, tracks); err != nil {
+		return err
+	}
+	return checkContentFilter(guildID, tracks)
+}
+
+// checkLimits enforces the guild's blocklist, content filter and enqueue limits on tracks requested by userID.
+// The returned error is meant to be shown to the user.
+func checkLimits(vi *audio.VoiceInstance, userID string, tracks []*audio.Track) error {
+	if err := checkAllowed(vi.GuildID, tracks); err != nil {
+		return err
+	}
+
+	// Turn away a single video that can't be played before anything is downloaded
+	if len(tracks) == 1 {
+		if name := restrictionName(fillTrackInfo(tracks[0])); name != "" {
+			return errors.New(trGuild(vi.GuildID, "limits."+name, tracks[0].DisplayName()))
+		}
+	}
+
+	limits := settingsStore.Get(vi.GuildID)
+
+	vi.Mu.Lock()
+	queued := len(vi.Queue)
+	pending := 0
+	for _, track := range vi.Queue {
+		if track.RequesterID == userID {
+			pending++
+		}
+	}
+	vi.Mu.Unlock()
+
+	if limits.MaxQueueSize > 0 && queued+len(tracks) > limits.MaxQueueSize {
+		if queued >= limits.MaxQueueSize {
+			return errors.New(trGuild(vi.GuildID, "limits.queue_full", limits.MaxQueueSize))
+		}
+		return errors.New(trGuild(vi.GuildID, "limits.queue_too_long", queued, limits.MaxQueueSize-queued, len(tracks)))
+	}
+
+	if limits.MaxPerUser > 0 && pending+len(tracks) > limits.MaxPerUser {
+		return errors.New(trGuild(vi.GuildID, "limits.per_user", limits.MaxPerUser, pending, len(tracks)))
+	}
+
+	if limits.MaxTrackSeconds > 0 {
+		for _, track := range tracks {
+			if err := fillTrackInfo(track); err != nil {
+				// Don't block the request if metadata is unavailable; playback will report real errors
+				log.Printf("Failed to get track info for %s: %v", track.URL, err)
+				continue
+			}
+			if err := trackTooLong(vi.GuildID, track); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// trackTooLong returns a message for the user if track is known to be longer than the guild allows
+func trackTooLong(guildID string, track *audio.Track) error {
+	maxSeconds := settingsStore.Get(guildID).MaxTrackSeconds
+	if maxSeconds <= 0 || track.Duration == 0 {
+		return nil
+	}
+	if maxLength := time.Duration(maxSeconds) * time.Second; track.Duration > maxLength {
+		return errors.New(trGuild(guildID, "limits.track_too_long",
+			track.DisplayName(), formatDuration(track.Duration), formatDuration(maxLength)))
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// handleLookup shows a link's metadata without queueing it, so moderators can
+// vet links before anyone plays them
+func handleLookup(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	url := strings.TrimSpace(i.ApplicationCommandData().Options[0].StringValue())
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		errorResponse(s, i, tr(i, "lookup.invalid_url"))
+		return
+	}
+
+	info, err := videos.GetVideoInfo(url)
+	if err != nil && isYouTubeURL(url) {
+		// Show what oEmbed knows rather than nothing
+		if embed, embedErr := videos.OEmbed(url); embedErr == nil {
+			log.Printf("Falling back to oEmbed for %s: %v", url, err)
+			info, err = embed, nil
+		}
+	}
+	if err != nil {
+		errorResponse(s, i, tr(i, "lookup.failed", err))
+		return
+	}
+
+	duration := tr(i, "lookup.live")
+	if !info.IsLive && info.Duration <= 0 {
+		duration = tr(i, "lookup.unknown")
+	} else if !info.IsLive {
+		duration = formatDuration(info.Duration)
+		if limit := settingsStore.Get(i.GuildID).MaxTrackSeconds; limit > 0 && info.Duration > time.Duration(limit)*time.Second {
+			duration += " " + tr(i, "lookup.too_long", formatDuration(time.Duration(limit)*time.Second))
+		}
+	}
+
+	uploaded := tr(i, "lookup.unknown")
+	if !info.Uploaded.IsZero() {
+		uploaded = info.Uploaded.Format("2006-01-02")
+	}
+
+	views := tr(i, "lookup.unknown")
+	if info.Views > 0 {
+		views = strconv.FormatInt(info.Views, 10)
+	}
+
+	// Only YouTube downloads are cached, keyed by video ID
+	cached := tr(i, "lookup.no")
+	if isYouTubeURL(url) && audioCache.Contains(info.ID) {
+		cached = tr(i, "lookup.yes")
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: info.Title,
+		URL:   info.Webpage,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: tr(i, "lookup.duration"), Value: duration, Inline: true},
+			{Name: tr(i, "lookup.uploader"), Value: orUnknown(i, info.Author), Inline: true},
+			{Name: tr(i, "lookup.uploaded"), Value: uploaded, Inline: true},
+			{Name: tr(i, "lookup.views"), Value: views, Inline: true},
+			{Name: tr(i, "lookup.availability"), Value: availabilityLabel(i, info.Availability), Inline: true},
+			{Name: tr(i, "lookup.cached"), Value: cached, Inline: true},
+		},
+	}
+	if info.Thumbnail != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: info.Thumbnail}
+	}
+	editEmbed(s, i, embed)
+}
+
+// availabilityLabel translates yt-dlp's availability value, keeping unknown values as they are
+func availabilityLabel(i *discordgo.InteractionCreate, availability string) string {
+	if availability == "" {
+		return tr(i, "lookup.unknown")
+	}
+	key := "lookup.availability." + availability
+	if label := tr(i, key); label != key {
+		return label
+	}
+	return availability
+}
+
+// orUnknown returns value, or a placeholder if it's empty
+func orUnknown(i *discordgo.InteractionCreate, value string) string {
+	if value == "" {
+		return tr(i, "lookup.unknown")
+	}
+	return value
+}
@@ -2,29 +2,61 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"os/signal"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
+	"discordbot/aliases"
+	"discordbot/apitokens"
 	"discordbot/audio"
+	"discordbot/audio/cache"
 	"discordbot/audio/spotify"
 	"discordbot/audio/youtube"
+	"discordbot/audit"
+	"discordbot/config"
+	"discordbot/events"
+	"discordbot/follows"
+	"discordbot/notify"
+	"discordbot/playcounts"
+	"discordbot/playlist"
+	"discordbot/privacy"
+	"discordbot/recommend"
+	"discordbot/sessions"
+	"discordbot/settings"
+	"discordbot/stats"
+	"discordbot/storage"
+	"discordbot/tts"
 
 	"github.com/bwmarrin/discordgo"
-	"github.com/joho/godotenv"
 )
 
 var (
 	voiceManager  *audio.VoiceManager
 	youtubeClient *youtube.Client
 	spotifyClient *spotify.Client
+	store         storage.Store
+	statsRecorder *stats.Recorder
+	aliasStore    *aliases.Store
+	playlistStore *playlist.Store
+	settingsStore *settings.Store
+	followStore   *follows.Store
+	privacyStore  *privacy.Store
+	apiTokens     *apitokens.Store
+	sessionStore  *sessions.Store
+	playCounts    *playcounts.Store
+	auditLog      *audit.Log
+	audioCache    *cache.Cache
+	eventBus      = events.NewBus()
+	notifier      *notify.Notifier
+	readOnly      bool // Mirror mode: no commands, playback or writes
 	commands      = []*discordgo.ApplicationCommand{
 		{
 			Name:        "ping",
@@ -45,55 +77,1215 @@ var (
 				{
 					Type:        discordgo.ApplicationCommandOptionString,
 					Name:        "url",
-					Description: "The URL to play",
+					Description: "The URL or alias to play",
 					Required:    true,
 				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "position",
+					Description: "Where to put the track in the queue",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "End of queue", Value: positionEnd},
+						{Name: "Play next", Value: positionNext},
+						{Name: "Play now", Value: positionNow},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "from",
+					Description: "For playlists, the position of the first entry to queue",
+					Required:    false,
+					MinValue:    &playlistMinPosition,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "to",
+					Description: "For playlists, the position of the last entry to queue",
+					Required:    false,
+					MinValue:    &playlistMinPosition,
+				},
 			},
 		},
+		{
+			Name:        "playmany",
+			Description: "Paste several URLs or search terms, one per line, to queue them all",
+		},
 		{
 			Name:        "queue",
-			Description: "View or add to the queue",
+			Description: "View, add to or save the queue",
 			Options: []*discordgo.ApplicationCommandOption{
 				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "url",
-					Description: "The URL to add to the queue",
-					Required:    false,
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "show",
+					Description: "Show the current queue",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "add",
+					Description: "Add a URL or alias to the queue",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "url",
+							Description: "The URL to add to the queue",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "from",
+							Description: "For playlists, the position of the first entry to queue",
+							Required:    false,
+							MinValue:    &playlistMinPosition,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "to",
+							Description: "For playlists, the position of the last entry to queue",
+							Required:    false,
+							MinValue:    &playlistMinPosition,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "save",
+					Description: "Save the current track and queue as a playlist",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "name",
+							Description: "The playlist name",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionBoolean,
+							Name:        "overwrite",
+							Description: "Replace an existing playlist with the same name",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "export",
+					Description: "Attach the current track and queue as a file",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "format",
+							Description: "The file format (defaults to JSON)",
+							Required:    false,
+							Choices: []*discordgo.ApplicationCommandOptionChoice{
+								{Name: "JSON", Value: "json"},
+								{Name: "Text (one URL per line)", Value: "text"},
+							},
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "import",
+					Description: "Queue the tracks of an exported queue file",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionAttachment,
+							Name:        "file",
+							Description: "A JSON or text file from /queue export",
+							Required:    true,
+						},
+					},
 				},
 			},
 		},
 		{
 			Name:        "repeat",
-			Description: "Toggle repeat mode",
+			Description: "Toggle repeat mode, or repeat the current track a number of times",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "times",
+					Description: "Play the current track this many more times before the queue continues (0 cancels)",
+					MinValue:    &loopsMin,
+					MaxValue:    maxLoops,
+				},
+			},
+		},
+		{
+			Name:        "replay",
+			Description: "Restart the current track from the beginning",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "after",
+					Description: "Play it again once it finishes instead of restarting it now",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "karaoke",
+			Description: "Toggle karaoke mode, which removes the vocals from the music",
+		},
+		{
+			Name:        "chapters",
+			Description: "List the chapters of the playing video",
+		},
+		{
+			Name:        "nextchapter",
+			Description: "Skip to the next chapter of the playing video",
 		},
 		{
 			Name:        "autoplay",
 			Description: "Toggle autoplay mode",
 		},
+		{
+			Name:        "top",
+			Description: "Show the most played tracks across all servers",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "limit",
+					Description: "How many tracks to show (default 10)",
+					Required:    false,
+					MinValue:    &topMinLimit,
+					MaxValue:    topMaxLimit,
+				},
+			},
+		},
+		{
+			Name:        "diagnose",
+			Description: "Check the bot's permissions in this channel and your voice channel",
+		},
+		{
+			Name:        "summon",
+			Description: "Bring the bot into your voice channel without interrupting the track",
+		},
+		{
+			Name:        "move",
+			Description: "Move the bot to another voice channel without interrupting the track (DJs only)",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionChannel,
+					Name:         "channel",
+					Description:  "The voice channel to move to",
+					Required:     true,
+					ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildVoice, discordgo.ChannelTypeGuildStageVoice},
+				},
+			},
+		},
+		{
+			Name:        "radio",
+			Description: "Play an internet radio station",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "play",
+					Description: "Play one of the server's radio stations",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "name",
+							Description: "The station, e.g. lofi, jazz, classical or news",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "position",
+							Description: "Where to put the station in the queue",
+							Required:    false,
+							Choices: []*discordgo.ApplicationCommandOptionChoice{
+								{Name: "End of queue", Value: positionEnd},
+								{Name: "Play next", Value: positionNext},
+								{Name: "Play now", Value: positionNow},
+							},
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "list",
+					Description: "List the server's radio stations",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "set",
+					Description: "Add a radio station or change its stream (DJs only)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "name",
+							Description: "The station name",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "url",
+							Description: "The stream URL",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "remove",
+					Description: "Remove a radio station from this server (DJs only)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "name",
+							Description: "The station name",
+							Required:    true,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:        "trending",
+			Description: "Pick tracks to queue from the music trending on YouTube",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "region",
+					Description: "Two-letter country code such as DE (default: the Invidious instance's region)",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "reverse",
+			Description: "Reverse the order of the queued tracks",
+		},
+		{
+			Name:        "removeuser",
+			Description: "Remove every queued track requested by a member",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionUser,
+					Name:        "member",
+					Description: "The member whose tracks to remove",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "leavecleanup",
+			Description: "Remove queued tracks requested by people who left the voice channel",
+		},
+		{
+			Name:        "stats",
+			Description: "Show listening statistics",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "music",
+					Description: "Most played tracks and most active requesters",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "window",
+							Description: "Time window to report on",
+							Required:    false,
+							Choices:     statsWindowChoices(),
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionUser,
+							Name:        "user",
+							Description: "Only count tracks requested by this user",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "runtime",
+					Description: "Uptime, resource usage and tool versions of the bot",
+				},
+			},
+		},
+		{
+			Name:        "alias",
+			Description: "Manage shortcuts for tracks and playlists",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "add",
+					Description: "Save an alias that can be used with /play",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "name",
+							Description: "The alias name",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "target",
+							Description: "The URL or one of your playlists the alias points to",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionBoolean,
+							Name:        "shared",
+							Description: "Share the alias with the whole server (DJs only)",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "remove",
+					Description: "Remove an alias",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "name",
+							Description: "The alias name",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionBoolean,
+							Name:        "shared",
+							Description: "Remove a server alias instead of a personal one (DJs only)",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "list",
+					Description: "List your aliases and the server's aliases",
+				},
+			},
+		},
+		{
+			Name:        "playlist",
+			Description: "Manage your playlists",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "create",
+					Description: "Create a new playlist",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "name",
+							Description: "The playlist name",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "add",
+					Description: "Add a URL or the current track to a playlist",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "name",
+							Description: "The playlist name",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "url",
+							Description: "The URL to add (defaults to the current track)",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "list",
+					Description: "List your playlists or the contents of one",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "name",
+							Description: "The playlist to show",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "play",
+					Description: "Add a whole playlist to the queue",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "name",
+							Description: "The playlist name",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "delete",
+					Description: "Delete a playlist",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "name",
+							Description: "The playlist name",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "import",
+					Description: "Save your Spotify Liked Songs or one of your Spotify playlists as a playlist",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "source",
+							Description: "spotify:liked or spotify:<playlist name> (link your account with /spotify link)",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "name",
+							Description: "Name for the playlist (defaults to the Spotify playlist's name)",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionBoolean,
+							Name:        "overwrite",
+							Description: "Replace an existing playlist with the same name",
+							Required:    false,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:        "settings",
+			Description: "Configure the bot for this server",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "limits",
+					Description: "Show or change enqueue limits (0 means unlimited)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "max_track_minutes",
+							Description: "Maximum track length in minutes",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "max_queue",
+							Description: "Maximum number of tracks in the queue",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "max_per_user",
+							Description: "Maximum pending tracks per user",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "enqueues_per_minute",
+							Description: "How often a member may queue tracks per minute (DJs are exempt)",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "command_cooldown",
+							Description: "Seconds a member must wait between /replay, /repeat, /say and similar commands",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "filter",
+					Description: "Reject explicit tracks or titles with certain words",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionBoolean,
+							Name:        "explicit",
+							Description: "Reject tracks Spotify marks as explicit",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "add_keyword",
+							Description: "Reject tracks with this word in the title",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "remove_keyword",
+							Description: "Stop rejecting tracks with this word in the title",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "autoplay",
+					Description: "Choose what autoplay plays when the queue runs empty",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "seed",
+							Description: "One of your playlists, a YouTube playlist URL or a genre (\"off\" to clear)",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "engine",
+							Description: "Where autoplay gets its recommendations from",
+							Required:    false,
+							Choices: []*discordgo.ApplicationCommandOptionChoice{
+								{Name: "Seed only", Value: recommend.EngineSeed},
+								{Name: "YouTube related videos", Value: recommend.EngineYouTube},
+								{Name: "Spotify recommendations", Value: recommend.EngineSpotify},
+								{Name: "Last.fm similar tracks", Value: recommend.EngineLastFM},
+							},
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "duplicates",
+					Description: "Choose how repeated tracks are handled",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionBoolean,
+							Name:        "block",
+							Description: "Reject duplicates instead of asking for confirmation",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "recent_hours",
+							Description: "Ask before queueing tracks played within this many hours (0 turns it off)",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "bitrate",
+					Description: "Show or change the audio bitrate, limited to the voice channel's bitrate",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "kbps",
+							Description: "Bitrate in kbps from 64 to 384 (0 uses the default)",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "downloads",
+					Description: "Show or change how fast tracks for this server are downloaded",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "kb_per_second",
+							Description: "Maximum download speed in KB/s (0 removes the limit)",
+							Required:    false,
+							MinValue:    &downloadRateMin,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "public",
+					Description: "Allow other servers to follow this server's track announcements",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionBoolean,
+							Name:        "enabled",
+							Description: "Whether the session is public",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "commandchannel",
+					Description: "Only allow music commands in certain text channels",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionChannel,
+							Name:         "add",
+							Description:  "Allow music commands in this channel",
+							Required:     false,
+							ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText, discordgo.ChannelTypeGuildVoice},
+						},
+						{
+							Type:         discordgo.ApplicationCommandOptionChannel,
+							Name:         "remove",
+							Description:  "Stop allowing music commands in this channel",
+							Required:     false,
+							ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText, discordgo.ChannelTypeGuildVoice},
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionBoolean,
+							Name:        "clear",
+							Description: "Allow music commands in every channel again",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "webhooks",
+					Description: "Post track starts, ends and errors as JSON to external services",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "add",
+							Description: "HTTPS URL to post events to",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "remove",
+							Description: "Stop posting events to this URL",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionBoolean,
+							Name:        "clear",
+							Description: "Remove all webhooks",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "export",
+					Description: "Download a signed backup of this server's settings, aliases and radio stations",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "import",
+					Description: "Restore settings, aliases and radio stations from a /settings export file",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionAttachment,
+							Name:        "file",
+							Description: "A backup file from /settings export",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "samechannel",
+					Description: "Only let members in the bot's voice channel control playback (DJs are exempt)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionBoolean,
+							Name:        "required",
+							Description: "Whether members must be in the bot's voice channel",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "followdj",
+					Description: "Move the bot along when the member whose track is playing switches voice channels",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionBoolean,
+							Name:        "enabled",
+							Description: "Whether the bot follows the member",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "timeouts",
+					Description: "Set or show how long the bot stays in voice while idle or alone",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "idle",
+							Description: "Minutes to stay with nothing playing, \"off\" to stay forever or \"default\"",
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "empty",
+							Description: "Minutes to wait in a channel everyone left, \"off\" to stay forever or \"default\"",
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "tts",
+					Description: "Read out track titles in the voice channel before they play",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionBoolean,
+							Name:        "announce",
+							Description: "Whether track titles are read out",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "kiosk",
+					Description: "Let anyone queue songs by posting their names in a channel",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionChannel,
+							Name:         "channel",
+							Description:  "Channel whose messages are queued as song requests",
+							Required:     false,
+							ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionBoolean,
+							Name:        "disable",
+							Description: "Turn the kiosk channel off",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "cleanup",
+							Description: "Which messages in the kiosk channel are deleted",
+							Required:    false,
+							Choices: []*discordgo.ApplicationCommandOptionChoice{
+								{Name: "All, requests are replaced by a confirmation", Value: kioskCleanupAll},
+								{Name: "Only messages that aren't song requests", Value: kioskCleanupChatter},
+								{Name: "None, requests get a reaction", Value: kioskCleanupNone},
+							},
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "nickname",
+					Description: "Show the player state in the bot's nickname",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionBoolean,
+							Name:        "enabled",
+							Description: "Whether the nickname shows the player state",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "station",
+							Description: "Name shown instead of the bot's username (\"off\" to clear)",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "language",
+					Description: "Choose the language the bot answers in",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "language",
+							Description: "The language to use",
+							Required:    true,
+							Choices:     languageChoices(),
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "api",
+					Description: "Create or revoke this server's REST API token",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "action",
+							Description: "What to do with the token",
+							Required:    true,
+							Choices: []*discordgo.ApplicationCommandOptionChoice{
+								{Name: "Create a new token", Value: "create"},
+								{Name: "Revoke the token", Value: "revoke"},
+							},
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "announcements",
+					Description: "Choose where track announcements are posted, or silence them",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:         discordgo.ApplicationCommandOptionChannel,
+							Name:         "channel",
+							Description:  "Channel for now playing messages",
+							Required:     false,
+							ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionBoolean,
+							Name:        "quiet",
+							Description: "Don't announce individual tracks at all",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionBoolean,
+							Name:        "reset_channel",
+							Description: "Post announcements in the channel the music was requested from again",
+							Required:    false,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:        "follow",
+			Description: "Follow another server's public music session",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "start",
+					Description: "Post another server's track changes in a channel",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "server",
+							Description: "The ID of the server to follow",
+							Required:    true,
+						},
+						{
+							Type:         discordgo.ApplicationCommandOptionChannel,
+							Name:         "channel",
+							Description:  "Where to post announcements (defaults to this channel)",
+							Required:     false,
+							ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "stop",
+					Description: "Stop following a server's session",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "server",
+							Description: "The ID of the followed server",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "list",
+					Description: "List the sessions this server follows",
+				},
+			},
+		},
+		{
+			Name:        "privacy",
+			Description: "Control whether your listening activity is recorded",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "optout",
+					Description: "Stop recording your history and stats, and delete what was recorded",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "optin",
+					Description: "Record your listening activity again",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "status",
+					Description: "Show whether your listening activity is recorded",
+				},
+			},
+		},
+		{
+			Name:        "spotify",
+			Description: "Link your Spotify account to play your Liked Songs and playlists",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "link",
+					Description: "Get a link to connect your Spotify account",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "unlink",
+					Description: "Disconnect your Spotify account and forget its access",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "status",
+					Description: "Show which Spotify account is linked",
+				},
+			},
+		},
+		{
+			Name:        "say",
+			Description: "Speak a short message in the voice channel",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "text",
+					Description: "What the bot should say",
+					Required:    true,
+					MaxLength:   tts.MaxLength,
+				},
+			},
+		},
+		{
+			Name:        "sound",
+			Description: "Play short clips from the server's soundboard",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "play",
+					Description: "Play a clip, over the music if something is playing",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "name",
+							Description: "Name of the clip",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "list",
+					Description: "List the server's clips",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "add",
+					Description: "Upload a clip of up to 10 seconds (admins only)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "name",
+							Description: "Name to play the clip by (letters, digits, - and _)",
+							Required:    true,
+							MaxLength:   32,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionAttachment,
+							Name:        "file",
+							Description: "The audio file",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "remove",
+					Description: "Delete a clip (admins only)",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "name",
+							Description: "Name of the clip",
+							Required:    true,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:        "blocklist",
+			Description: "Ban videos, uploader channels or domains on this server",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "add",
+					Description: "Block a video, uploader channel or domain",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "kind",
+							Description: "What to match",
+							Required:    true,
+							Choices: []*discordgo.ApplicationCommandOptionChoice{
+								{Name: "Video", Value: blockVideo},
+								{Name: "Uploader channel", Value: blockChannel},
+								{Name: "Domain", Value: blockDomain},
+							},
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "value",
+							Description: "Video link or ID, channel link, ID or @handle, or domain",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "remove",
+					Description: "Unblock a video, uploader channel or domain",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "kind",
+							Description: "What to match",
+							Required:    true,
+							Choices: []*discordgo.ApplicationCommandOptionChoice{
+								{Name: "Video", Value: blockVideo},
+								{Name: "Uploader channel", Value: blockChannel},
+								{Name: "Domain", Value: blockDomain},
+							},
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "value",
+							Description: "The entry to remove",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "list",
+					Description: "Show the blocklist",
+				},
+			},
+		},
+		{
+			Name:        "auditlog",
+			Description: "Show who recently ran which commands on this server (admins only)",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionUser,
+					Name:        "user",
+					Description: "Only show this member's commands",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "limit",
+					Description: "How many commands to show (default 20)",
+					Required:    false,
+					MinValue:    &auditMinLimit,
+					MaxValue:    auditMaxLimit,
+				},
+			},
+		},
+		{
+			Name:        "lookup",
+			Description: "Show a link's details without queueing it",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "url",
+					Description: "The link to look up",
+					Required:    true,
+				},
+			},
+		},
 	}
 )
 
 func init() {
-	// Load .env file
-	err := godotenv.Load()
+	// Read configuration from the environment, an optional .env file and secret files
+	err := config.Load()
 	if err != nil {
-		log.Fatal("Error loading .env file")
+		log.Fatal(err)
 	}
 
 	// Initialize voice manager
 	voiceManager = audio.NewVoiceManager()
+	voiceManager.OnQueueChange = func(guildID string, queue []*audio.Track) {
+		eventBus.Publish(events.Event{Type: events.QueueUpdate, GuildID: guildID, Queue: queue})
+	}
+
+	// Send Opus sources to Discord without transcoding them unless disabled
+	voiceManager.Passthrough = config.Bool("OPUS_PASSTHROUGH", true)
+
+	// Lower audio quality for new tracks when the host CPU is saturated.
+	// CPU_QUALITY_THRESHOLD is a percentage; 0 disables the fallback.
+	cpuThreshold := config.Float("CPU_QUALITY_THRESHOLD", 85)
+	if cpuThreshold > 0 {
+		voiceManager.Quality = audio.NewQualityGovernor(cpuThreshold / 100)
+	}
+
+	// Initialize persistent storage
+	store, err = storage.Open()
+	if err != nil {
+		log.Fatalf("Error initializing storage: %v", err)
+	}
+
+	// READ_ONLY runs a mirror against another instance's data, e.g. to test
+	// migrations or the dashboard on production data without touching it
+	// config.Load already rejected an invalid value
+	readOnly = config.Bool("READ_ONLY", false)
+	if readOnly {
+		store = storage.ReadOnly(store)
+	}
+	statsRecorder = stats.NewRecorder(store)
+	if !readOnly {
+		statsRecorder.Migrate()
+	}
+	aliasStore = aliases.NewStore(store)
+	playlistStore = playlist.NewStore(store)
+	settingsStore = settings.NewStore(store)
+	followStore = follows.NewStore(store)
+	privacyStore = privacy.NewStore(store)
+	apiTokens = apitokens.NewStore(store)
+	sessionStore = sessions.NewStore(store)
+	playCounts = playcounts.NewStore(store)
+	setupRadio()
+	auditLog = audit.NewLog(store)
+	setupBackups()
+
+	// Keep downloads around, evicting unpopular tracks first when the cache is full
+	audioCache = cache.New(cache.DefaultDir(), cache.DefaultMaxBytes(), playCounts.Plays)
+	audioCache.MigrateSounds()
 
 	// Initialize YouTube client with cache directory
-	cacheDir := filepath.Join(os.TempDir(), "discordbot", "cache")
-	youtubeClient = youtube.NewClient(cacheDir)
+	youtubeClient = youtube.NewClient(audioCache.Dir)
+	downloader = youtubeClient
+	searcher = youtubeSearcher{client: youtubeClient}
+	videos = youtubeClient
 
-	// Initialize Spotify client (will be disabled if not configured)
+	// Initialize Spotify client (will be disabled if not configured).
+	// It authenticates lazily, so this never waits on the Spotify API.
 	var spotifyErr error
 	spotifyClient, spotifyErr = spotify.NewClient(youtubeClient)
 	if spotifyErr != nil {
 		log.Printf("Warning: Spotify client initialization failed: %v", spotifyErr)
 		log.Printf("Spotify functionality will be disabled")
 	}
+	setupSpotifyLinking()
+
+	// Set up the autoplay engines guilds can choose from
+	recommenders = newRecommenders()
+	setupAutoplayAvoidance()
+
+	// Set up speech for /say and spoken track announcements
+	setupTTS()
+
+	// Read the trending feed for /trending from an Invidious instance
+	setupInvidious()
+
+	// Start plugins that add commands and sources
+	setupPlugins()
+
+	// Download upcoming tracks ahead of playback
+	setupDownloads()
+
+	// Apply reloadable settings again on SIGHUP
+	setupConfigReload()
 }
 
 // Global context for cancellation
@@ -144,6 +1336,25 @@ func cleanupChildProcesses() {
 }
 
 func main() {
+	// DEV_GUILD_ID registers the commands to a single guild, where changes show up
+	// instantly. -commands switches between that and global registration.
+	commandScope := flag.String("commands", "", `where to register slash commands: "guild" (DEV_GUILD_ID) or "global" (default "guild" if DEV_GUILD_ID is set)`)
+	flag.Parse()
+
+	devGuildID := config.String("DEV_GUILD_ID", "")
+	devMode := devGuildID != ""
+	switch *commandScope {
+	case "":
+	case "global":
+		devMode = false
+	case "guild":
+		if devGuildID == "" {
+			log.Fatal("-commands=guild needs DEV_GUILD_ID to be set")
+		}
+	default:
+		log.Fatalf("Invalid -commands %q: use \"guild\" or \"global\"", *commandScope)
+	}
+
 	// Ensure we clean up child processes on exit
 	defer func() {
 		if r := recover(); r != nil {
@@ -163,16 +1374,25 @@ func main() {
 			log.Println("Cleaning up voice connections...")
 			voiceManager.Cleanup()
 		}
+		stopPlugins()
 
 		// Clean up any remaining processes
 		cleanupChildProcesses()
 		log.Println("Shutdown complete")
 	}()
 
+	// Reach out to external providers without holding up startup
+	go authenticateSpotify(ctx)
+
+	// Watch host CPU to fall back to cheaper audio settings under load
+	if voiceManager.Quality != nil {
+		go voiceManager.Quality.Run(ctx)
+	}
+
 	// Create a new Discord session using the token from .env
-	token := os.Getenv("DISCORD_TOKEN")
+	token := config.String("DISCORD_TOKEN", "")
 	if token == "" {
-		log.Fatal("DISCORD_TOKEN not found in .env file")
+		log.Fatal("DISCORD_TOKEN is not set: set it or DISCORD_TOKEN_FILE in the environment or .env file")
 	}
 
 	discord, err := discordgo.New("Bot " + token)
@@ -184,11 +1404,62 @@ func main() {
 	discord.StateEnabled = true
 	discord.LogLevel = discordgo.LogDebug
 
-	// Register the interaction handler
-	discord.AddHandler(interactionCreate)
+	// Route channel messages through the notifier so API outages never stall playback
+	notifier = notify.New(discord)
+
+	// Hand playback to a Lavalink node if one is configured
+	setupLavalink(discord)
+
+	// A read-only mirror only serves HTTP; it never answers commands or plays audio
+	if readOnly {
+		log.Println("Running as a read-only mirror: commands, playback and writes are disabled")
+	} else {
+		// Relay track changes of public sessions to following guilds
+		startCrossPosting(discord)
+
+		// Mirror the player state in the nickname of guilds that enabled it
+		startNicknameStatus(discord)
+
+		// Show what is playing in the bot's activity status
+		startPresence(discord)
+
+		// Set the voice channel's status line to the playing track
+		startVoiceChannelStatus(discord)
+
+		// Tell external integrations about track starts, ends and errors
+		startWebhooks()
+
+		// Register the interaction handler
+		discord.AddHandler(interactionCreate)
+
+		// Queue songs named in kiosk channels if the operator allowed reading messages
+		setupKiosk()
+		if kioskEnabled {
+			discord.AddHandler(onKioskMessage)
+		}
+
+		// Stop playback right away when the bot is kicked from voice or the guild
+		discord.AddHandler(onVoiceStateUpdate)
+		discord.AddHandler(onDJMove)
+		discord.AddHandler(onGuildDelete)
+	}
+
+	// Serve health and readiness endpoints for container orchestration
+	startHTTPServer(discord)
+
+	// Let operators inspect and control the bot through a local socket
+	if !readOnly {
+		startAdmin(discord)
+	}
+
+	// Report anonymized usage if the operator opted in
+	startTelemetry(discord)
 
 	// We need to define our intents
 	discord.Identify.Intents = discordgo.IntentsGuildMessages | discordgo.IntentsGuilds | discordgo.IntentsGuildVoiceStates
+	if kioskEnabled {
+		discord.Identify.Intents |= discordgo.IntentsMessageContent
+	}
 
 	// Open a websocket connection to Discord and begin listening
 	err = discord.Open()
@@ -196,20 +1467,31 @@ func main() {
 		log.Fatal("Error opening connection: ", err)
 	}
 
-	// Register commands with global scope
-	log.Println("Registering commands...")
-	registeredCommands := make([]*discordgo.ApplicationCommand, len(commands))
-	for i, command := range commands {
-		cmd, err := discord.ApplicationCommandCreate(discord.State.User.ID, "", command)
-		if err != nil {
-			log.Printf("Cannot create '%v' command: %v", command.Name, err)
-			continue
-		}
-		registeredCommands[i] = cmd
-		log.Printf("Registered command: %s", cmd.Name)
+	if lavalinkNode != nil {
+		go lavalinkNode.Run(ctx)
+	}
+
+	// Register commands, translated for every supported language.
+	// A mirror leaves the commands of the primary instance alone.
+	if !readOnly {
+		registerCommands(discord, devGuildID, devMode)
+	}
+
+	// Pick up the sessions saved by the last controlled shutdown
+	if !readOnly {
+		go resumeSessions(discord)
+	}
+
+	// Leave voice channels that went quiet or empty
+	if !readOnly {
+		go watchInactivity(ctx, discord)
 	}
 
+	// Reload the configuration on SIGHUP
+	go watchReload(ctx)
+
 	// Set up signal handling
+	shutdown := newShutdownManager()
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
 
@@ -225,6 +1507,20 @@ func main() {
 			log.Println("Shutting down due to context cancellation...")
 		}
 
+		// Warn guilds with active playback and save their sessions first.
+		// A second signal skips the rest of the grace period.
+		abort := make(chan struct{})
+		prepared := make(chan struct{})
+		go func() {
+			select {
+			case <-signalChan:
+				close(abort)
+			case <-prepared:
+			}
+		}()
+		shutdown.Prepare(abort)
+		close(prepared)
+
 		// Create a new context with timeout for graceful shutdown
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -237,7 +1533,7 @@ func main() {
 			// Clean up voice connections
 			log.Println("Disconnecting from voice channels...")
 			for guildID, instance := range voiceManager.Instances {
-				if instance != nil && instance.Connection != nil {
+				if instance != nil && instance.Connected() {
 					log.Printf("Leaving voice channel in guild %s", guildID)
 					if err := instance.Leave(); err != nil {
 						log.Printf("Error leaving voice channel in guild %s: %v", guildID, err)
@@ -245,17 +1541,14 @@ func main() {
 				}
 			}
 
-			// Delete all registered commands
-			log.Println("Removing application commands...")
-			for i, cmd := range registeredCommands {
-				if cmd != nil {
-					log.Printf("Deleting command: %s", cmd.Name)
-					if err := discord.ApplicationCommandDelete(discord.State.User.ID, "", cmd.ID); err != nil {
-						log.Printf("Cannot delete '%v' command: %v", cmd.Name, err)
-					} else {
-						registeredCommands[i] = nil // Mark as deleted
-					}
-				}
+			// Commands stay registered so they keep working across restarts
+
+			// Stop plugin processes so they don't outlive the bot
+			stopPlugins()
+
+			// Deliver the last updates, such as the finished tracks
+			if !notifier.Drain(2 * time.Second) {
+				log.Println("Some message updates were not delivered before shutdown")
 			}
 
 			// Close the Discord session
@@ -292,8 +1585,75 @@ func main() {
 	<-ctx.Done()
 }
 
-func interactionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	// Log all incoming interactions for debugging
+// registerCommands brings the registered slash commands up to date with commands.
+// In dev mode they're registered to devGuildID only, where changes apply
+// instantly, instead of globally, where they take a while to propagate.
+func registerCommands(discord *discordgo.Session, devGuildID string, devMode bool) {
+	localizeCommands(commands)
+
+	if devMode {
+		log.Printf("Registering commands to dev guild %s...", devGuildID)
+		if err := syncCommands(discord, devGuildID, commands); err != nil {
+			log.Printf("Error registering commands: %v", err)
+		}
+		return
+	}
+
+	log.Println("Registering commands...")
+	if err := syncCommands(discord, "", commands); err != nil {
+		log.Printf("Error registering commands: %v", err)
+	}
+
+	// Remove copies left over from dev mode so the dev guild doesn't list every command twice
+	if devGuildID != "" {
+		if err := syncCommands(discord, devGuildID, nil); err != nil {
+			log.Printf("Error removing dev guild commands: %v", err)
+		}
+	}
+}
+
+// checkCommand runs the checks every command goes through before it is
+// dispatched, path being the command with its subcommand. If one fails, the
+// member has been answered privately and false is returned.
+func checkCommand(s *discordgo.Session, i *discordgo.InteractionCreate, path string) bool {
+	return checkCommandChannel(s, i, path) && checkSameChannel(s, i, path) && checkRateLimit(s, i, path)
+}
+
+func interactionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	// Music commands only make sense inside a guild
+	if i.Member == nil {
+		log.Printf("Ignoring interaction outside of a guild: %s", i.Type.String())
+		return
+	}
+
+	// Buttons are handled separately from slash commands
+	if i.Type == discordgo.InteractionMessageComponent {
+		log.Printf("Received component interaction: CustomID=%s, GuildID=%s, ChannelID=%s, UserID=%s",
+			i.MessageComponentData().CustomID,
+			i.GuildID,
+			i.ChannelID,
+			i.Member.User.ID)
+		handleComponent(s, i)
+		return
+	}
+
+	// Modals come back as their own interaction once submitted
+	if i.Type == discordgo.InteractionModalSubmit {
+		log.Printf("Received modal submission: CustomID=%s, GuildID=%s, UserID=%s",
+			i.ModalSubmitData().CustomID,
+			i.GuildID,
+			i.Member.User.ID)
+		handleModalSubmit(s, i)
+		return
+	}
+
+	// Handle the command
+	if i.Type != discordgo.InteractionApplicationCommand {
+		log.Printf("Ignoring non-command interaction: %s", i.Type.String())
+		return
+	}
+
+	// Log all incoming commands for debugging
 	log.Printf("Received interaction: Type=%s, Command=%s, GuildID=%s, ChannelID=%s, UserID=%s",
 		i.Type.String(),
 		i.ApplicationCommandData().Name,
@@ -301,27 +1661,30 @@ func interactionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		i.ChannelID,
 		i.Member.User.ID)
 
-	// Handle the command
-	if i.Type != discordgo.InteractionApplicationCommand {
-		log.Printf("Ignoring non-command interaction: %s", i.Type.String())
+	usage.Command(i.ApplicationCommandData().Name)
+	defer auditCommand(i)
+
+	// Music commands are kept to the guild's command channels and playback is
+	// controlled from the bot's voice channel if the guild wants it. Members
+	// spamming the queue or playback controls are turned away.
+	if !checkCommand(s, i, commandPath(i.ApplicationCommandData())) {
+		return
+	}
+
+	// Commands that open a modal must show it as their first response
+	if i.ApplicationCommandData().Name == "playmany" {
+		showPlayManyModal(s, i)
 		return
 	}
 
 	// Add a defer response to prevent "Unknown Integration" errors
-	initialContent := "Processing your command..."
 	log.Printf("Sending initial response for command: %s", i.ApplicationCommandData().Name)
-
-	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content: initialContent,
-		},
-	})
-	if err != nil {
+	if err := deferResponse(s, i); err != nil {
 		log.Printf("Error responding to interaction: %v", err)
 		// Try to send a follow-up message if the initial response fails
 		_, followUpErr := s.FollowupMessageCreate(i.Interaction, false, &discordgo.WebhookParams{
-			Content: "Error: Failed to process your command. Please try again.",
+			Content: tr(i, "command.failed"),
+			Flags:   discordgo.MessageFlagsEphemeral,
 		})
 		if followUpErr != nil {
 			log.Printf("Failed to send follow-up message: %v", followUpErr)
@@ -336,218 +1699,418 @@ func interactionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
 
 	switch i.ApplicationCommandData().Name {
 	case "ping":
-		content := "Pong!"
-		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-			Content: &content,
-		})
+		editResponse(s, i, tr(i, "ping.pong"))
 
 	case "join":
 		// Find the user's voice channel
 		vs, err := findUserVoiceState(s, i.GuildID, i.Member.User.ID)
 		if err != nil {
-			content := "You need to be in a voice channel first!"
-			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-				Content: &content,
-			})
+			errorResponse(s, i, tr(i, "voice.user_not_connected"))
+			return
+		}
+
+		if !voicePreflight(s, i, vs.ChannelID) {
 			return
 		}
 
 		// Join the voice channel
 		err = vi.Join(s, vs.ChannelID)
 		if err != nil {
-			content := fmt.Sprintf("Error joining voice channel: %v", err)
-			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-				Content: &content,
-			})
+			errorResponse(s, i, tr(i, "voice.join_failed", err))
 			return
 		}
 
-		content := "Joined voice channel!"
-		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-			Content: &content,
-		})
+		editResponse(s, i, tr(i, "voice.joined"))
 
 	case "leave":
-		if vi.Connection == nil {
-			content := "I'm not in a voice channel!"
-			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-				Content: &content,
-			})
+		if !vi.Connected() {
+			errorResponse(s, i, tr(i, "voice.bot_not_connected"))
 			return
 		}
 
 		// Leave the voice channel
 		err := vi.Leave()
 		if err != nil {
-			content := fmt.Sprintf("Error leaving voice channel: %v", err)
-			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-				Content: &content,
-			})
+			errorResponse(s, i, tr(i, "voice.leave_failed", err))
 			return
 		}
+		forgetBitrateWarning(i.GuildID)
 
-		content := "Left voice channel!"
-		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-			Content: &content,
-		})
+		editResponse(s, i, tr(i, "voice.left"))
 
 	case "play":
-		// Get the URL option
-		options := i.ApplicationCommandData().Options
-		url := options[0].StringValue()
-
-		// Check if we're in a voice channel
-		vs, err := findUserVoiceState(s, i.GuildID, i.Member.User.ID)
-		if err != nil {
-			content := "You need to be in a voice channel first!"
-			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-				Content: &content,
-			})
-			return
+		// Get the URL or alias and the queue position
+		var query string
+		var span playlistRange
+		position := positionEnd
+		for _, option := range i.ApplicationCommandData().Options {
+			switch option.Name {
+			case "url":
+				query = option.StringValue()
+			case "position":
+				position = option.StringValue()
+			case "from":
+				span.start = int(option.IntValue())
+			case "to":
+				span.end = int(option.IntValue())
+			}
 		}
 
 		// Join or move to the user's voice channel
-		err = vi.Join(s, vs.ChannelID)
-		if err != nil {
-			content := fmt.Sprintf("Error joining voice channel: %v", err)
-			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-				Content: &content,
-			})
+		if !joinUserChannel(s, i, vi) {
 			return
 		}
 
-		// Small delay to ensure voice connection is ready
-		time.Sleep(500 * time.Millisecond)
-
-		// Ensure we're connected to voice
-		if vi.Connection == nil || vi.Connection.Ready != true {
-			content := "Failed to connect to voice channel. Please try again."
-			s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-				Content: &content,
-			})
-			return
-		}
-
-		log.Printf("Adding URL to queue: %s", url)
-		vi.AddToQueue(url)
-		log.Printf("Queue length after add: %d", len(vi.Queue))
-
-		// Update the interaction to show we're starting to play
-		content := fmt.Sprintf("Added to queue: %s", url)
-		log.Printf("Updating interaction with queue status")
-		_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-			Content: &content,
-		})
+		tracks, err := resolveRequest(i, query, span)
 		if err != nil {
-			log.Printf("Failed to update interaction: %v", err)
+			errorResponse(s, i, tr(i, "error", err))
+			return
 		}
 
-		vi.Mu.Lock()
-		isPlaying := vi.IsPlaying
-		vi.Mu.Unlock()
-
-		log.Printf("Current play status - IsPlaying: %v", isPlaying)
-		if !isPlaying {
-			log.Printf("Starting playback in a new goroutine")
-			go playNextInQueue(s, i.ChannelID, vi)
-		} else {
-			log.Printf("Already playing, added to queue")
-		}
+		log.Printf("Adding %d track(s) to queue for: %s (position: %s)", len(tracks), query, position)
+		enqueueTracks(s, i, vi, tracks, position)
 
 	case "queue":
 		options := i.ApplicationCommandData().Options
-
 		if len(options) == 0 {
-			// Show the current queue
-			vi.Mu.Lock()
-			if len(vi.Queue) == 0 {
-				content := "The queue is empty"
-				s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-					Content: &content,
-				})
-			} else {
-				queueMsg := "Current queue:\n"
-				for idx, url := range vi.Queue {
-					queueMsg += fmt.Sprintf("%d. %s\n", idx+1, url)
-				}
-				s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-					Content: &queueMsg,
-				})
+			editResponse(s, i, tr(i, "command.choose_subcommand"))
+			return
+		}
+
+		switch options[0].Name {
+		case "show":
+			// Show the current queue with when each track should start
+			queue, starts, total := queueTimes(vi)
+			if len(queue) == 0 {
+				editResponse(s, i, tr(i, "queue.empty"))
+				return
 			}
-			vi.Mu.Unlock()
-		} else {
-			// Add URL to queue
-			url := options[0].StringValue()
-
-			// Check if we're in a voice channel
-			if vi.Connection == nil {
-				// Try to join the user's voice channel
-				vs, err := findUserVoiceState(s, i.GuildID, i.Member.User.ID)
-				if err != nil {
-					content := "You need to be in a voice channel first!"
-					s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-						Content: &content,
-					})
-					return
-				}
 
-				// Join the user's voice channel
-				err = vi.Join(s, vs.ChannelID)
-				if err != nil {
-					content := fmt.Sprintf("Error joining voice channel: %v", err)
-					s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-						Content: &content,
-					})
-					return
+			queueMsg := tr(i, "queue.header") + "\n"
+			for idx, track := range queue {
+				queueMsg += tr(i, "queue.entry", idx+1, track.DisplayName(), track.Requester)
+				queueMsg += " · " + formatETA(i.GuildID, starts[idx]) + "\n"
+			}
+			if total >= 0 {
+				queueMsg += tr(i, "queue.total", formatDuration(total))
+			} else {
+				queueMsg += tr(i, "queue.total_unknown")
+			}
+			editResponse(s, i, truncateMessage(queueMsg))
+
+		case "add":
+			var query string
+			var span playlistRange
+			for _, option := range options[0].Options {
+				switch option.Name {
+				case "url":
+					query = option.StringValue()
+				case "from":
+					span.start = int(option.IntValue())
+				case "to":
+					span.end = int(option.IntValue())
 				}
 			}
 
-			// Add the URL to the queue
-			vi.AddToQueue(url)
+			// Join the user's voice channel if we aren't connected yet
+			if !vi.Connected() && !joinUserChannel(s, i, vi) {
+				return
+			}
 
-			content := fmt.Sprintf("Added to queue: %s", url)
-			_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-				Content: &content,
-			})
+			tracks, err := resolveRequest(i, query, span)
 			if err != nil {
-				log.Printf("Failed to update interaction: %v", err)
+				errorResponse(s, i, tr(i, "error", err))
+				return
 			}
 
-			// If nothing is playing, start playing
-			if !vi.IsPlaying {
-				go playNextInQueue(s, i.ChannelID, vi)
-			}
+			enqueueTracks(s, i, vi, tracks, positionEnd)
+
+		case "save":
+			handleQueueSave(s, i, vi, options[0].Options)
+		case "export":
+			handleQueueExport(s, i, vi, options[0].Options)
+		case "import":
+			handleQueueImport(s, i, vi, options[0].Options)
 		}
 
 	case "repeat":
+		if options := i.ApplicationCommandData().Options; len(options) > 0 {
+			handleRepeatTimes(s, i, vi, int(options[0].IntValue()))
+			return
+		}
+
 		// Toggle repeat mode
 		vi.Mu.Lock()
 		vi.Repeat = !vi.Repeat
-		status := "enabled"
+		key := "repeat.enabled"
 		if !vi.Repeat {
-			status = "disabled"
+			key = "repeat.disabled"
 		}
 		vi.Mu.Unlock()
 
-		content := fmt.Sprintf("Repeat mode %s", status)
-		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-			Content: &content,
-		})
+		editResponse(s, i, tr(i, key))
+
+	case "replay":
+		handleReplay(s, i, vi)
+
+	case "karaoke":
+		handleKaraoke(s, i, vi)
+
+	case "chapters":
+		handleChapters(s, i, vi)
+
+	case "nextchapter":
+		handleNextChapter(s, i, vi)
 
 	case "autoplay":
 		// Toggle autoplay mode
 		vi.Mu.Lock()
 		vi.Autoplay = !vi.Autoplay
-		status := "enabled"
+		key := "autoplay.enabled"
 		if !vi.Autoplay {
-			status = "disabled"
+			key = "autoplay.disabled"
 		}
 		vi.Mu.Unlock()
 
-		content := fmt.Sprintf("Autoplay mode %s", status)
-		s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-			Content: &content,
-		})
+		editResponse(s, i, tr(i, key))
+
+	case "summon":
+		handleSummon(s, i, vi)
+
+	case "move":
+		handleMove(s, i, vi)
+
+	case "radio":
+		handleRadio(s, i, vi)
+
+	case "trending":
+		handleTrending(s, i)
+
+	case "reverse":
+		handleReverse(s, i, vi)
+
+	case "removeuser":
+		handleRemoveUser(s, i, vi)
+
+	case "leavecleanup":
+		handleLeaveCleanup(s, i, vi)
+
+	case "stats":
+		handleStats(s, i)
+
+	case "top":
+		handleTop(s, i)
+
+	case "diagnose":
+		handleDiagnose(s, i)
+
+	case "alias":
+		handleAlias(s, i)
+
+	case "playlist":
+		handlePlaylist(s, i, vi)
+
+	case "settings":
+		handleSettings(s, i)
+
+	case "follow":
+		handleFollow(s, i)
+
+	case "privacy":
+		handlePrivacy(s, i)
+
+	case "spotify":
+		handleSpotify(s, i)
+
+	case "lookup":
+		handleLookup(s, i)
+
+	case "blocklist":
+		handleBlocklist(s, i)
+
+	case "auditlog":
+		handleAuditLog(s, i)
+
+	case "say":
+		handleSay(s, i, vi)
+
+	case "sound":
+		handleSound(s, i, vi)
+
+	default:
+		handlePluginCommand(s, i, vi)
+	}
+}
+
+// newTrack creates a queue entry for url requested by the interaction's author
+func newTrack(i *discordgo.InteractionCreate, url string) *audio.Track {
+	return &audio.Track{
+		URL:         url,
+		RequesterID: i.Member.User.ID,
+		Requester:   i.Member.User.Username,
+		AddedAt:     time.Now(),
+	}
+}
+
+// resolveRequest turns a /play argument into the tracks to queue,
+// expanding aliases, the playlists they point to, YouTube Mixes and playlists,
+// Deezer or Apple Music links and linked Spotify libraries. span picks YouTube
+// playlist entries; if it's empty a range can follow the link, as in "<link> 5-20".
+func resolveRequest(i *discordgo.InteractionCreate, query string, span playlistRange) ([]*audio.Track, error) {
+	if span == (playlistRange{}) {
+		query, span = splitPlaylistRange(query)
+	}
+	target := resolveAlias(i, query)
+
+	if ownerID, name, ok := parsePlaylistRef(target); ok {
+		return playlistTracks(i, ownerID, name)
+	}
+
+	// Plugins resolve the queries of the sources they add
+	if tracks, ok, err := resolvePluginQuery(i, target); ok {
+		return tracks, err
+	}
+
+	// Deezer and Apple Music can't be streamed, so their tracks are matched on YouTube
+	if tracks, ok, err := resolveCatalogLink(i, target); ok {
+		return tracks, err
+	}
+
+	// So are the Liked Songs and playlists of a linked Spotify account
+	if tracks, ok, err := resolveSpotifyLibrary(i, target); ok {
+		return tracks, err
+	}
+
+	// A YouTube Mix queues a batch of its entries like a radio station
+	if tracks, ok, err := resolveMix(i, target); ok {
+		return tracks, err
+	}
+
+	// Playlists queue the selected part, or their first entries
+	if tracks, ok, err := resolvePlaylist(i, target, span); ok {
+		return tracks, err
+	}
+
+	return []*audio.Track{newTrack(i, target)}, nil
+}
+
+// joinUserChannel joins or moves to the voice channel of the interaction's author.
+// Failures are reported on the interaction and false is returned.
+func joinUserChannel(s *discordgo.Session, i *discordgo.InteractionCreate, vi *audio.VoiceInstance) bool {
+	// Check if we're in a voice channel
+	vs, err := findUserVoiceState(s, i.GuildID, i.Member.User.ID)
+	if err != nil {
+		errorResponse(s, i, tr(i, "voice.user_not_connected"))
+		return false
+	}
+
+	if !voicePreflight(s, i, vs.ChannelID) {
+		return false
+	}
+
+	// Join or move to the user's voice channel
+	err = vi.Join(s, vs.ChannelID)
+	if err != nil {
+		errorResponse(s, i, tr(i, "voice.join_failed", err))
+		return false
+	}
+
+	// Small delay to ensure voice connection is ready
+	time.Sleep(500 * time.Millisecond)
+
+	// Ensure we're connected to voice
+	if !vi.Ready() {
+		errorResponse(s, i, tr(i, "voice.connect_failed"))
+		return false
+	}
+
+	return true
+}
+
+// Queue positions accepted by enqueueTracks
+const (
+	positionEnd  = "end"
+	positionNext = "next"
+	positionNow  = "now"
+)
+
+// enqueueTracks adds tracks to the queue at the given position, confirms it on
+// the interaction and starts playback if nothing is playing yet. Position "now"
+// skips the current track after inserting.
+func enqueueTracks(s *discordgo.Session, i *discordgo.InteractionCreate, vi *audio.VoiceInstance, tracks []*audio.Track, position string) {
+	// Enforce the guild's queue and track length limits
+	if err := checkLimits(vi, i.Member.User.ID, tracks); err != nil {
+		errorResponse(s, i, tr(i, "error", err))
+		return
+	}
+
+	// Ask for confirmation before queueing something that is already queued
+	if duplicate := findDuplicate(vi, tracks); duplicate != nil {
+		if settingsStore.Get(vi.GuildID).BlockDuplicates {
+			errorResponse(s, i, tr(i, "duplicate.blocked", duplicate.DisplayName()))
+			return
+		}
+		confirmDuplicate(s, i, tracks, position, tr(i, "duplicate.confirm", duplicate.DisplayName()))
+		return
+	}
+
+	// Also ask before repeating something the guild heard recently
+	if track, playedAt := findRecentPlay(vi, tracks); track != nil {
+		confirmDuplicate(s, i, tracks, position, tr(i, "duplicate.recent", track.DisplayName(), formatAgo(i, time.Since(playedAt))))
+		return
+	}
+
+	editResponse(s, i, addTracks(s, i.ChannelID, vi, tracks, position))
+}
+
+// addTracks inserts tracks into the queue at the given position, starts
+// playback if needed and returns a confirmation message for the user
+func addTracks(s *discordgo.Session, channelID string, vi *audio.VoiceInstance, tracks []*audio.Track, position string) string {
+	if position == positionNext || position == positionNow {
+		vi.InsertIntoQueue(0, tracks...)
+	} else {
+		for _, track := range tracks {
+			vi.AddToQueue(track)
+		}
+	}
+	log.Printf("Queue length after add: %d", len(vi.Queue))
+
+	vi.Mu.Lock()
+	isPlaying := vi.IsPlaying
+	vi.Mu.Unlock()
+
+	log.Printf("Current play status - IsPlaying: %v", isPlaying)
+	if !isPlaying {
+		log.Printf("Starting playback in a new goroutine")
+		go playNextInQueue(s, channelID, vi)
+	} else if position == positionNow {
+		log.Printf("Skipping current track to play the new one now")
+		vi.Skip()
+	} else {
+		log.Printf("Already playing, added to queue")
+	}
+
+	// Describe what was queued
+	label := tracks[0].DisplayName()
+	if len(tracks) > 1 {
+		label = trGuild(vi.GuildID, "queue.track_count", len(tracks))
+	}
+	switch {
+	case position == positionNow && isPlaying:
+		return trGuild(vi.GuildID, "queue.playing_now", label)
+	case position == positionNext && isPlaying:
+		return trGuild(vi.GuildID, "queue.playing_next", label)
+	case isPlaying:
+		// Say where the first new track landed and roughly when it plays
+		queue, starts, _ := queueTimes(vi)
+		for idx, track := range queue {
+			if track == tracks[0] {
+				return trGuild(vi.GuildID, "queue.added_eta", label, idx+1, formatETA(vi.GuildID, starts[idx]))
+			}
+		}
+		return trGuild(vi.GuildID, "queue.added", label)
+	default:
+		return trGuild(vi.GuildID, "queue.added", label)
 	}
 }
 
@@ -567,11 +2130,86 @@ func findUserVoiceState(s *discordgo.Session, guildID, userID string) (*discordg
 	return nil, fmt.Errorf("user not found in voice channels")
 }
 
+// playerError posts a translated playback error to the guild and publishes it
+// to event subscribers such as dashboards and overlays
+func playerError(vi *audio.VoiceInstance, channelID, key string, args ...interface{}) {
+	message := trGuild(vi.GuildID, key, args...)
+	notifier.Send(channelID, message)
+	eventBus.Publish(events.Event{Type: events.PlayerError, GuildID: vi.GuildID, ChannelID: vi.ChannelID, Error: message})
+}
+
+// restrictions are the errors for videos no retry can get past, named as in
+// their message keys
+var restrictions = []struct {
+	err  error
+	name string
+}{
+	{youtube.ErrAgeRestricted, "age_restricted"},
+	{youtube.ErrRegionLocked, "region_locked"},
+	{youtube.ErrMembersOnly, "members_only"},
+	{youtube.ErrUnavailable, "unavailable"},
+}
+
+// restrictionName names the restriction err is, or returns "" if it's none
+func restrictionName(err error) string {
+	for _, restriction := range restrictions {
+		if errors.Is(err, restriction.err) {
+			return restriction.name
+		}
+	}
+	return ""
+}
+
+// downloadError reports a failed download, explaining sign-in, region and
+// other restrictions and listing what was tried instead of showing yt-dlp's output
+func downloadError(vi *audio.VoiceInstance, channelID string, err error) {
+	var failure *downloadFailure
+	switch name := restrictionName(err); {
+	case name != "":
+		playerError(vi, channelID, "player."+name)
+	case errors.As(err, &failure):
+		playerError(vi, channelID, "player.download_gave_up", len(failure.attempts), failure.Summary("\n"))
+	default:
+		playerError(vi, channelID, "player.download_failed", err)
+	}
+}
+
+// recordPlay records a finished track for listening statistics, unless the
+// requester opted out. Global play counts aren't tied to a user and are always
+// kept for tracks with a videoID.
+func recordPlay(vi *audio.VoiceInstance, track *audio.Track, videoID string, startedAt time.Time) {
+	// A seek or /replay restarts the track, which is recorded once it really ends
+	if restarting(vi.GuildID, track) {
+		return
+	}
+	recordable := !privacyStore.OptedOut(track.RequesterID)
+	if recordable {
+		if err := statsRecorder.RecordPlay(vi.GuildID, stats.Play{
+			URL:         track.URL,
+			Title:       track.Title,
+			RequesterID: track.RequesterID,
+			Requester:   track.Requester,
+			StartedAt:   startedAt,
+			Listened:    time.Since(startedAt),
+		}); err != nil {
+			log.Printf("Failed to record play statistics: %v", err)
+		}
+	}
+	if videoID != "" {
+		if err := playCounts.Record(videoID, track.Title); err != nil {
+			log.Printf("Failed to record play count: %v", err)
+		}
+	}
+	if recordable {
+		vi.AddToHistory(track)
+	}
+}
+
 // playNextInQueue plays the next item in the queue
 func playNextInQueue(s *discordgo.Session, channelID string, vi *audio.VoiceInstance) {
 	log.Printf("playNextInQueue started for channel: %s", channelID)
 
-	url, ok := vi.GetNextFromQueue()
+	track, ok := vi.GetNextFromQueue()
 	if !ok {
 		log.Println("No more items in queue, stopping playback")
 		vi.Mu.Lock()
@@ -579,74 +2217,249 @@ func playNextInQueue(s *discordgo.Session, channelID string, vi *audio.VoiceInst
 		vi.Mu.Unlock()
 		return
 	}
+	url := track.URL
 
 	log.Printf("Got next URL from queue: %s", url)
 
+	// Announcements go to the guild's configured channel if there is one
+	announceID, quiet := announcementTarget(vi.GuildID, channelID)
+
 	vi.Mu.Lock()
 	vi.IsPlaying = true
+	vi.TextChannelID = announceID
 	vi.Mu.Unlock()
 
-	log.Printf("Sending download message to channel")
-	// Send initial message
-	message, err := s.ChannelMessageSend(channelID, fmt.Sprintf("Downloading: %s", url))
-	if err != nil {
-		log.Printf("Failed to send download message: %v", err)
-	} else {
-		log.Printf("Download message sent with ID: %s", message.ID)
+	// Point out Discord's channel bitrate limit before anyone blames the bot
+	warnLowBitrate(s, vi, announceID)
+
+	// Encode at the guild's chosen bitrate, within what the voice channel allows
+	bitrate := guildBitrate(s, vi)
+	vi.Mu.Lock()
+	vi.Bitrate = bitrate
+	vi.Mu.Unlock()
+
+	// Live YouTube streams, radio stations and HLS streams have no download phase; they play as they air
+	live := (track.Live || audio.IsHLS(url)) && !isYouTubeURL(url)
+	var infoErr error
+	if !vi.IsRemote() && isYouTubeURL(url) {
+		if infoErr = fillTrackInfo(track); infoErr == nil {
+			live = track.Live
+		}
+	}
+
+	// Send initial message. If Discord is unavailable the update is queued
+	// and playback continues silently. Quiet guilds only hear about errors.
+	var message *notify.Message
+	if !quiet {
+		log.Printf("Sending download message to channel")
+		status := "player.downloading"
+		if live {
+			status = "player.connecting_live"
+		}
+		message = notifier.Send(announceID, trGuild(vi.GuildID, status, trackLabel(track, url)))
 	}
 
 	var audioFile string
 
+	// Continue where a restored session stopped, or start at the link's timestamp
+	start, resumed := takeResumeOffset(vi.GuildID, track)
+	if !resumed {
+		start, _ = linkStartOffset(url)
+	}
+
 	// Determine if it's a YouTube or Spotify URL
-	if strings.Contains(url, "youtube.com") || strings.Contains(url, "youtu.be") {
+	if vi.IsRemote() {
+		// The Lavalink node resolves and streams the track itself
+		usage.Provider("lavalink")
+		notifier.Edit(message, nowPlayingText(vi.GuildID, trackLabel(track, url), start))
+
+		startedAt := time.Now()
+		eventBus.Publish(events.Event{Type: events.TrackStart, GuildID: vi.GuildID, ChannelID: vi.ChannelID, Track: track})
+		stopProgress := showProgress(vi, message, url, track, start)
+		playURL := url
+		var err error
+		if track.Plugin != "" {
+			playURL, err = pluginStreamURL(track)
+		}
+		if err == nil {
+			err = vi.PlayRemote(playURL, start)
+		}
+		stopProgress()
+		if err != nil {
+			playerError(vi, announceID, "player.play_failed", err)
+		}
+		eventBus.Publish(events.Event{Type: events.TrackEnd, GuildID: vi.GuildID, ChannelID: vi.ChannelID, Track: track})
+
+		// Play counts are keyed by video, so other sources only count towards statistics
+		videoID := ""
+		if isYouTubeURL(url) {
+			videoID, _ = downloader.GetVideoID(url)
+		}
+		recordPlay(vi, track, videoID, startedAt)
+
+	} else if track.Plugin != "" {
+		usage.Provider("plugin")
+		notifier.Edit(message, nowPlayingText(vi.GuildID, trackLabel(track, url), start))
+
+		// Read out the title between tracks if the guild asked for it
+		if !quiet {
+			announceTrack(vi, track)
+		}
+
+		// The plugin hands out the audio URL right before playback, since it
+		// may expire. It is played from start like a file, so seeking,
+		// resuming and karaoke restart it at the right position.
+		startedAt := time.Now()
+		eventBus.Publish(events.Event{Type: events.TrackStart, GuildID: vi.GuildID, ChannelID: vi.ChannelID, Track: track})
+		stopProgress := showProgress(vi, message, url, track, start)
+		streamURL, err := pluginStreamURL(track)
+		if err == nil {
+			err = vi.PlayAudioFrom(streamURL, start)
+		}
+		stopProgress()
+		if err != nil {
+			playerError(vi, announceID, "player.play_failed", err)
+		}
+		eventBus.Publish(events.Event{Type: events.TrackEnd, GuildID: vi.GuildID, ChannelID: vi.ChannelID, Track: track})
+		recordPlay(vi, track, "", startedAt)
+
+	} else if live {
+		if isYouTubeURL(url) {
+			usage.Provider("youtube")
+		} else {
+			usage.Provider("stream")
+		}
+		notifier.Edit(message, trGuild(vi.GuildID, "player.now_playing_live", trackLabel(track, url)))
+
+		// Read out the title between tracks if the guild asked for it
+		if !quiet {
+			announceTrack(vi, track)
+		}
+
+		// Radio stations name the song they're on in their stream metadata.
+		// HLS playlists carry none.
+		stopTitles := func() {}
+		if !isYouTubeURL(url) && !audio.IsHLS(url) {
+			stopTitles = watchRadioTitles(vi, message, track, url)
+		}
+
+		// Stream the broadcast without a progress bar, reconnecting if it stalls
+		startedAt := time.Now()
+		eventBus.Publish(events.Event{Type: events.TrackStart, GuildID: vi.GuildID, ChannelID: vi.ChannelID, Track: track})
+		connected := false
+		err := vi.PlayStream(func() (string, error) {
+			// Radio and HLS streams are played straight from their URL. A
+			// finished HLS recording ends instead of starting over.
+			if !isYouTubeURL(url) {
+				if connected && audio.IsHLS(url) && audio.HLSFinished(url) {
+					return "", audio.ErrStreamEnded
+				}
+				connected = true
+				return url, nil
+			}
+			streamURL, err := videos.LiveStreamURL(url)
+			if errors.Is(err, youtube.ErrNotLive) {
+				return "", audio.ErrStreamEnded
+			}
+			return streamURL, err
+		})
+		stopTitles()
+		if err != nil {
+			playerError(vi, announceID, "player.play_failed", err)
+		}
+		eventBus.Publish(events.Event{Type: events.TrackEnd, GuildID: vi.GuildID, ChannelID: vi.ChannelID, Track: track})
+
+		videoID, _ := downloader.GetVideoID(url)
+		recordPlay(vi, track, videoID, startedAt)
+
+	} else if isYouTubeURL(url) {
+		usage.Provider("youtube")
+
 		// Extract video ID
-		videoID, err := youtubeClient.GetVideoID(url)
+		videoID, err := downloader.GetVideoID(url)
 		if err != nil {
-			s.ChannelMessageSend(channelID, "❌ Invalid YouTube URL")
+			playerError(vi, announceID, "player.invalid_youtube_url")
 			vi.Mu.Lock()
 			vi.IsPlaying = false
 			vi.Mu.Unlock()
 			return
 		}
 
-		// Download the audio
-		audioFile, err = youtubeClient.DownloadAudio(videoID)
+		// Keep the file from being evicted while it plays
+		release := audioCache.Acquire(videoID)
+		defer release()
+
+		// Skip videos the metadata already rules out instead of downloading them first
+		var tooLong error
+		if !audioCache.Contains(videoID) {
+			if restrictionName(infoErr) != "" {
+				err = infoErr
+			} else if tooLong = trackTooLong(vi.GuildID, track); tooLong != nil {
+				err = tooLong
+			}
+		}
+
+		// Download the audio unless it's already cached or being fetched ahead of time
+		if err == nil {
+			audioFile, err = downloads.Fetch(videoID, vi.GuildID)
+		}
 		if err != nil {
-			s.ChannelMessageSend(channelID, fmt.Sprintf("❌ Error downloading audio: %v", err))
+			if err == tooLong {
+				playerError(vi, announceID, "player.skipped", err)
+			} else {
+				downloadError(vi, announceID, err)
+			}
+
+			// Move on to the rest of the queue instead of stopping playback
 			vi.Mu.Lock()
-			vi.IsPlaying = false
+			continuePlay := len(vi.Queue) > 0
+			vi.IsPlaying = continuePlay
 			vi.Mu.Unlock()
+			if continuePlay {
+				go playNextInQueue(s, channelID, vi)
+			}
 			return
 		}
 
-		// Clean up the audio file when done
-		defer os.Remove(audioFile)
-
 		// Update the message to show we're now playing
-		s.ChannelMessageEdit(channelID, message.ID, fmt.Sprintf("🎵 Now playing: %s", url))
+		notifier.Edit(message, nowPlayingText(vi.GuildID, trackLabel(track, url), start))
+
+		// Read out the title between tracks if the guild asked for it
+		if !quiet {
+			announceTrack(vi, track)
+		}
 
 		// Play the audio file
-		err = vi.PlayAudio(audioFile)
+		startedAt := time.Now()
+		eventBus.Publish(events.Event{Type: events.TrackStart, GuildID: vi.GuildID, ChannelID: vi.ChannelID, Track: track})
+		stopProgress := showProgress(vi, message, url, track, start)
+		err = vi.PlayAudioFrom(audioFile, start)
+		stopProgress()
 		if err != nil {
-			s.ChannelMessageSend(channelID, fmt.Sprintf("❌ Error playing audio: %v", err))
+			playerError(vi, announceID, "player.play_failed", err)
 		}
+		eventBus.Publish(events.Event{Type: events.TrackEnd, GuildID: vi.GuildID, ChannelID: vi.ChannelID, Track: track})
+
+		recordPlay(vi, track, videoID, startedAt)
 
 	} else if strings.Contains(url, "spotify.com") {
+		usage.Provider("spotify")
 		if spotifyClient == nil {
-			s.ChannelMessageSend(channelID, "❌ Spotify support is not available")
+			playerError(vi, announceID, "player.spotify_unavailable")
 			vi.Mu.Lock()
 			vi.IsPlaying = false
 			vi.Mu.Unlock()
 			return
 		}
 
-		s.ChannelMessageSend(channelID, "❌ Spotify support is not yet implemented")
+		playerError(vi, announceID, "player.spotify_unsupported")
 		vi.Mu.Lock()
 		vi.IsPlaying = false
 		vi.Mu.Unlock()
 		return
 	} else {
-		s.ChannelMessageSend(channelID, "❌ Unsupported URL. Please provide a YouTube or Spotify URL.")
+		usage.Provider("unsupported")
+		playerError(vi, announceID, "player.unsupported_url")
 		vi.Mu.Lock()
 		vi.IsPlaying = false
 		vi.Mu.Unlock()
@@ -654,44 +2467,55 @@ func playNextInQueue(s *discordgo.Session, channelID string, vi *audio.VoiceInst
 	}
 
 	// Edit message to indicate track finished playing
-	s.ChannelMessageEdit(channelID, message.ID, fmt.Sprintf("✅ Finished playing: %s", url))
+	notifier.Edit(message, trGuild(vi.GuildID, "player.finished", trackLabel(track, url)))
 
+	// Play the track again while it has loops left, unless /replay or a seek
+	// already put it at the front
 	vi.Mu.Lock()
-	// Check repeat mode
-	if vi.Repeat {
-		// Add the current URL back to the queue
-		vi.Queue = append(vi.Queue, vi.CurrentURL)
+	loop := vi.Current != nil && vi.Current.Loops > 0 && (len(vi.Queue) == 0 || vi.Queue[0] != vi.Current)
+	if loop {
+		vi.Current.Loops--
 	}
-
-	// Check if autoplay should continue with next song
-	continuePlay := len(vi.Queue) > 0 || vi.Autoplay
 	vi.Mu.Unlock()
+	if loop {
+		vi.InsertIntoQueue(0, track)
+	}
 
-	if continuePlay {
-		// If we're in autoplay mode and the queue is empty, try to find a related video
-		vi.Mu.Lock()
-		isQueueEmpty := len(vi.Queue) == 0
-		isAutoplay := vi.Autoplay
-		currentURL := vi.CurrentURL
-		vi.Mu.Unlock()
+	// Check repeat mode and add the current track back to the queue, unless
+	// /replay already put it at the front
+	vi.Mu.Lock()
+	repeat := vi.Repeat && vi.Current != nil && (len(vi.Queue) == 0 || vi.Queue[0] != vi.Current)
+	current := vi.Current
+	vi.Mu.Unlock()
+	if repeat {
+		vi.AddToQueue(current)
+	}
 
-		if isQueueEmpty && isAutoplay {
-			// For now, just repeat the current track
-			// In a real implementation, you might want to implement a better autoplay system
-			vi.Mu.Lock()
-			vi.Queue = append(vi.Queue, currentURL)
-			vi.Mu.Unlock()
+	// If we're in autoplay mode and the queue is empty, keep the music going
+	vi.Mu.Lock()
+	needAutoplay := len(vi.Queue) == 0 && vi.Autoplay && vi.Current != nil
+	vi.Mu.Unlock()
 
-			// Recursively call playNextInQueue to play the next item
-			go playNextInQueue(s, channelID, vi)
+	if needAutoplay {
+		next, err := nextAutoplayTrack(vi, current)
+		if err != nil {
+			log.Printf("Autoplay failed in guild %s: %v", vi.GuildID, err)
+			playerError(vi, announceID, "autoplay.failed", err)
 		} else {
-			vi.Mu.Lock()
-			vi.IsPlaying = false
-			vi.Mu.Unlock()
+			vi.AddToQueue(next)
 		}
-	} else {
-		vi.Mu.Lock()
+	}
+
+	vi.Mu.Lock()
+	continuePlay := len(vi.Queue) > 0
+	if !continuePlay {
 		vi.IsPlaying = false
-		vi.Mu.Unlock()
+		vi.Current = nil
+	}
+	vi.Mu.Unlock()
+
+	if continuePlay {
+		// Recursively call playNextInQueue to play the next item
+		go playNextInQueue(s, channelID, vi)
 	}
 }
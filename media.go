@@ -0,0 +1,43 @@
+package main
+
+import (
+	"discordbot/audio"
+	"discordbot/audio/youtube"
+)
+
+var (
+	// downloader, searcher and videos are what playback and the command
+	// handlers fetch and look up tracks with; fakes can stand in for them
+	// in tests
+	downloader audio.Downloader
+	searcher   audio.Searcher
+	videos     videoLookup
+)
+
+// videoLookup reads the metadata of videos, playlists and live streams.
+// *youtube.Client implements it.
+type videoLookup interface {
+	GetVideoInfo(url string) (*youtube.VideoInfo, error)
+	OEmbed(url string) (*youtube.VideoInfo, error)
+	PlaylistEntries(url string, limit int) ([]youtube.Entry, error)
+	PlaylistRange(url string, start, end int) ([]youtube.Entry, error)
+	LiveStreamURL(url string) (string, error)
+}
+
+// youtubeSearcher searches YouTube through yt-dlp
+type youtubeSearcher struct {
+	client *youtube.Client
+}
+
+// SearchTracks returns up to limit YouTube results for query as tracks
+func (y youtubeSearcher) SearchTracks(query string, limit int) ([]*audio.Track, error) {
+	entries, err := y.client.Search(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	tracks := make([]*audio.Track, len(entries))
+	for n, entry := range entries {
+		tracks[n] = &audio.Track{URL: entry.URL, Title: entry.Title, Duration: entry.Duration}
+	}
+	return tracks, nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	"discordbot/audio"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// mixTracks is how many entries of a YouTube Mix are queued at once
+const mixTracks = 25
+
+// mixListPattern matches the list parameter of a YouTube Mix ("radio"), whose IDs start with RD
+var mixListPattern = regexp.MustCompile(`[?&]list=RD[\w-]+`)
+
+// resolveMix expands a YouTube Mix link into its first entries, so one song
+// starts a radio of related ones. ok is false for links without a mix.
+func resolveMix(i *discordgo.InteractionCreate, link string) (tracks []*audio.Track, ok bool, err error) {
+	if !isYouTubeURL(link) || !mixListPattern.MatchString(link) {
+		return nil, false, nil
+	}
+
+	entries, err := videos.PlaylistEntries(link, mixTracks)
+	if err != nil {
+		return nil, true, fmt.Errorf("error loading the mix: %v", err)
+	}
+	for _, entry := range entries {
+		track := newTrack(i, entry.URL)
+		track.Title = entry.Title
+		track.Duration = entry.Duration
+		tracks = append(tracks, track)
+	}
+	if len(tracks) == 0 {
+		return nil, true, fmt.Errorf("the mix is empty")
+	}
+	return tracks, true, nil
+}
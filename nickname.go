@@ -0,0 +1,152 @@
+package main
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"discordbot/events"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// nicknameSettle lets bursts of player events settle before the nickname is changed
+	nicknameSettle = 2 * time.Second
+	// nicknameInterval is the minimum time between two nickname changes in a guild
+	nicknameInterval = 15 * time.Second
+	// maxNicknameLength is Discord's limit for guild nicknames
+	maxNicknameLength = 32
+)
+
+// Glyphs shown in front of the bot's name for each player state
+const (
+	glyphPlaying = "▶"
+	glyphPaused  = "⏸"
+	glyphIdle    = "💤"
+)
+
+// nicknameState tracks the nickname the bot last set in a guild
+type nicknameState struct {
+	applied string
+	known   bool // Whether applied reflects the guild's actual nickname
+	changed time.Time
+	pending bool
+}
+
+var nicknames = struct {
+	sync.Mutex
+	guilds map[string]*nicknameState
+}{guilds: make(map[string]*nicknameState)}
+
+// startNicknameStatus keeps the bot's nickname in sync with the player state of guilds that enabled it
+func startNicknameStatus(s *discordgo.Session) {
+	eventBus.Subscribe(func(e events.Event) {
+		switch e.Type {
+		case events.TrackStart, events.TrackEnd, events.Paused, events.Resumed, events.QueueUpdate:
+			if settingsStore.Get(e.GuildID).NicknameStatus {
+				scheduleNickname(s, e.GuildID)
+			}
+		}
+	})
+}
+
+// scheduleNickname queues a nickname update for a guild, coalescing bursts and
+// spacing changes out so the member endpoint's rate limit is never hit
+func scheduleNickname(s *discordgo.Session, guildID string) {
+	nicknames.Lock()
+	defer nicknames.Unlock()
+
+	state, ok := nicknames.guilds[guildID]
+	if !ok {
+		state = &nicknameState{}
+		nicknames.guilds[guildID] = state
+	}
+	if state.pending {
+		return
+	}
+	state.pending = true
+
+	delay := nicknameSettle
+	if wait := time.Until(state.changed.Add(nicknameInterval)); wait > delay {
+		delay = wait
+	}
+	time.AfterFunc(delay, func() { applyNickname(s, guildID) })
+}
+
+// applyNickname sets the nickname matching the guild's current player state
+func applyNickname(s *discordgo.Session, guildID string) {
+	nicknames.Lock()
+	state := nicknames.guilds[guildID]
+	state.pending = false
+	if !state.known {
+		state.applied, state.known = currentNickname(s, guildID), true
+	}
+	applied := state.applied
+	nicknames.Unlock()
+
+	nick := statusNickname(s, guildID)
+	if nick == applied {
+		return
+	}
+	if err := s.GuildMemberNickname(guildID, "@me", nick); err != nil {
+		log.Printf("Error updating nickname in guild %s: %v", guildID, err)
+		return
+	}
+
+	nicknames.Lock()
+	state.applied = nick
+	state.changed = time.Now()
+	nicknames.Unlock()
+}
+
+// currentNickname returns the bot's nickname in a guild as known from the state cache
+func currentNickname(s *discordgo.Session, guildID string) string {
+	if s.State.User == nil {
+		return ""
+	}
+	member, err := s.State.Member(guildID, s.State.User.ID)
+	if err != nil {
+		return ""
+	}
+	return member.Nick
+}
+
+// statusNickname returns the nickname the bot should have in a guild; empty resets it to the username
+func statusNickname(s *discordgo.Session, guildID string) string {
+	guild := settingsStore.Get(guildID)
+	if !guild.NicknameStatus {
+		return ""
+	}
+
+	name := guild.StationName
+	if name == "" && s.State.User != nil {
+		name = s.State.User.Username
+	}
+	nick := playerGlyph(guildID) + " " + name
+	if runes := []rune(nick); len(runes) > maxNicknameLength {
+		nick = string(runes[:maxNicknameLength])
+	}
+	return nick
+}
+
+// playerGlyph returns the glyph describing a guild's player state
+func playerGlyph(guildID string) string {
+	voiceManager.Mu.Lock()
+	vi := voiceManager.Instances[guildID]
+	voiceManager.Mu.Unlock()
+	if vi == nil {
+		return glyphIdle
+	}
+
+	vi.Mu.Lock()
+	defer vi.Mu.Unlock()
+	switch {
+	case vi.IsPlaying && vi.Paused:
+		return glyphPaused
+	case vi.IsPlaying:
+		return glyphPlaying
+	default:
+		return glyphIdle
+	}
+}
@@ -0,0 +1,245 @@
+package notify
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// maxPending is the maximum number of undelivered updates kept while Discord is unavailable
+	maxPending = 100
+	// maxAge is how long an undelivered update stays relevant before it is dropped
+	maxAge = 10 * time.Minute
+	// minBackoff and maxBackoff bound the delay between delivery retries
+	minBackoff = 2 * time.Second
+	maxBackoff = time.Minute
+)
+
+// recoveredNotice is posted to affected channels once Discord accepts messages again
+const recoveredNotice = "⚠️ Discord was unavailable for a while, so some updates were delayed or dropped. Playback continued without interruption."
+
+// Message is a handle to a user-facing message that may not have been delivered yet
+type Message struct {
+	ChannelID string
+	id        string
+	content   string
+	updatedAt time.Time
+	queued    bool
+}
+
+// Notifier sends and edits channel messages without ever blocking playback.
+// Updates are queued and delivered in order by a background goroutine. A
+// message edited again before its update went out is only sent once, with
+// its latest content. Updates that fail because of Discord API errors or
+// outages are retried, and a notice is posted once the API recovers.
+type Notifier struct {
+	session  *discordgo.Session
+	mu       sync.Mutex
+	pending  []*Message
+	degraded map[string]bool
+	wake     chan struct{}
+}
+
+// New creates a notifier and starts its retry loop
+func New(session *discordgo.Session) *Notifier {
+	n := &Notifier{
+		session:  session,
+		degraded: make(map[string]bool),
+		wake:     make(chan struct{}, 1),
+	}
+	go n.run()
+	return n
+}
+
+// Send posts a new message to a channel
+func (n *Notifier) Send(channelID, content string) *Message {
+	m := &Message{ChannelID: channelID}
+	n.update(m, content)
+	return m
+}
+
+// Edit replaces the content of a message previously returned by Send.
+// A nil message is ignored.
+func (n *Notifier) Edit(m *Message, content string) {
+	if m == nil {
+		return
+	}
+	n.update(m, content)
+}
+
+// Degraded reports whether updates are currently being held back
+func (n *Notifier) Degraded() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.degraded) > 0
+}
+
+// Drain waits until every queued update has been delivered or timeout has
+// passed, e.g. before shutting down. It reports whether the queue emptied.
+func (n *Notifier) Drain(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		n.mu.Lock()
+		empty := len(n.pending) == 0
+		n.mu.Unlock()
+		if empty {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// update queues new content for m for the background goroutine to deliver
+func (n *Notifier) update(m *Message, content string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	m.content = content
+	m.updatedAt = time.Now()
+	n.enqueue(m)
+}
+
+// enqueue adds m to the pending queue. The caller must hold n.mu.
+func (n *Notifier) enqueue(m *Message) {
+	if m.queued {
+		// The latest content will be sent when the message reaches the front of the queue
+		return
+	}
+	m.queued = true
+	n.pending = append(n.pending, m)
+
+	// Drop the oldest update rather than growing without bound during long outages
+	if len(n.pending) > maxPending {
+		n.pending[0].queued = false
+		n.pending = n.pending[1:]
+	}
+
+	select {
+	case n.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliver sends or edits m with its current content and returns the content it sent
+func (n *Notifier) deliver(m *Message) (string, error) {
+	n.mu.Lock()
+	id, content := m.id, m.content
+	n.mu.Unlock()
+
+	if id != "" {
+		_, err := n.session.ChannelMessageEdit(m.ChannelID, id, content)
+		return content, err
+	}
+
+	msg, err := n.session.ChannelMessageSend(m.ChannelID, content)
+	if err != nil {
+		return content, err
+	}
+
+	n.mu.Lock()
+	m.id = msg.ID
+	n.mu.Unlock()
+	return content, nil
+}
+
+// run delivers queued updates, retrying with exponential backoff while Discord is unavailable
+func (n *Notifier) run() {
+	backoff := minBackoff
+	for {
+		n.mu.Lock()
+		hasPending := len(n.pending) > 0
+		n.mu.Unlock()
+
+		if !hasPending {
+			<-n.wake
+			continue
+		}
+
+		if n.flush() {
+			backoff = minBackoff
+			continue
+		}
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// flush delivers queued updates in order, returning false if Discord is still unavailable
+func (n *Notifier) flush() bool {
+	for {
+		n.mu.Lock()
+		if len(n.pending) == 0 {
+			channels := n.degraded
+			n.degraded = make(map[string]bool)
+			n.mu.Unlock()
+
+			for channelID := range channels {
+				if _, err := n.session.ChannelMessageSend(channelID, recoveredNotice); err != nil {
+					log.Printf("Failed to send recovery notice to channel %s: %v", channelID, err)
+				}
+			}
+			return true
+		}
+		m := n.pending[0]
+		n.mu.Unlock()
+
+		n.mu.Lock()
+		stale := time.Since(m.updatedAt) > maxAge
+		n.mu.Unlock()
+		if stale {
+			log.Printf("Dropping stale message update for channel %s", m.ChannelID)
+			n.dequeue(m, "")
+			continue
+		}
+
+		sent, err := n.deliver(m)
+		if err != nil && retryable(err) {
+			log.Printf("Discord API unavailable, holding back message updates for channel %s: %v", m.ChannelID, err)
+			n.mu.Lock()
+			n.degraded[m.ChannelID] = true
+			n.mu.Unlock()
+			return false
+		}
+		if err != nil {
+			log.Printf("Dropping message update for channel %s: %v", m.ChannelID, err)
+		}
+		n.dequeue(m, sent)
+	}
+}
+
+// dequeue removes m from the front of the queue once sent is its content.
+// A message edited while its update was out stays in front, so the edit is
+// sent next.
+func (n *Notifier) dequeue(m *Message, sent string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(n.pending) == 0 || n.pending[0] != m {
+		return
+	}
+	if sent != "" && m.content != sent {
+		return
+	}
+	m.queued = false
+	n.pending = n.pending[1:]
+}
+
+// retryable reports whether err is caused by an outage rather than a permanent failure
+// such as missing permissions or a deleted channel
+func retryable(err error) bool {
+	var restErr *discordgo.RESTError
+	if errors.As(err, &restErr) && restErr.Response != nil {
+		code := restErr.Response.StatusCode
+		return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+	}
+	// Network errors and timeouts
+	return true
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// djRoleName is the name of the role that grants DJ privileges
+const djRoleName = "DJ"
+
+// isAdmin reports whether the interaction's author may change guild settings
+func isAdmin(i *discordgo.InteractionCreate) bool {
+	if i.Member == nil {
+		return false
+	}
+	return i.Member.Permissions&(discordgo.PermissionAdministrator|discordgo.PermissionManageServer) != 0
+}
+
+// isDJ reports whether the interaction's author may manage shared guild resources.
+// Members with the Manage Server permission or a role named "DJ" count as DJs.
+func isDJ(s *discordgo.Session, i *discordgo.InteractionCreate) bool {
+	if i.Member == nil {
+		return false
+	}
+
+	if isAdmin(i) {
+		return true
+	}
+
+	return hasDJRole(s, i.GuildID, i.Member.Roles)
+}
+
+// memberIsDJ applies the isDJ rules to a guild member outside of an interaction,
+// where Discord doesn't hand us the member's computed permissions
+func memberIsDJ(s *discordgo.Session, guildID string, member *discordgo.Member) bool {
+	guild, err := s.State.Guild(guildID)
+	if err != nil {
+		return false
+	}
+	if member.User != nil && guild.OwnerID == member.User.ID {
+		return true
+	}
+
+	for _, roleID := range member.Roles {
+		role, err := s.State.Role(guildID, roleID)
+		if err != nil {
+			continue
+		}
+		if role.Permissions&(discordgo.PermissionAdministrator|discordgo.PermissionManageServer) != 0 {
+			return true
+		}
+	}
+
+	return hasDJRole(s, guildID, member.Roles)
+}
+
+// hasDJRole reports whether any of the roles is named "DJ"
+func hasDJRole(s *discordgo.Session, guildID string, roleIDs []string) bool {
+	for _, roleID := range roleIDs {
+		role, err := s.State.Role(guildID, roleID)
+		if err != nil {
+			continue
+		}
+		if strings.EqualFold(role.Name, djRoleName) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sameChannelCommands change what plays in the bot's voice channel. With the
+// guild's same-channel setting on, only members listening there may run them.
+var sameChannelCommands = map[string]bool{
+	"join":          true,
+	"leave":         true,
+	"summon":        true,
+	"move":          true,
+	"play":          true,
+	"playmany":      true,
+	"queue add":     true,
+	"queue import":  true,
+	"playlist play": true,
+	"radio play":    true,
+	"trending pick": true,
+	"replay":        true,
+	"nextchapter":   true,
+	"repeat":        true,
+	"karaoke":       true,
+	"autoplay":      true,
+	"reverse":       true,
+	"leavecleanup":  true,
+	"say":           true,
+	"sound play":    true,
+}
+
+// checkSameChannel makes sure members who control playback are in the bot's
+// voice channel when the guild requires it. Otherwise they are told so
+// privately and false is returned. DJs and an idle bot aren't restricted.
+func checkSameChannel(s *discordgo.Session, i *discordgo.InteractionCreate, path string) bool {
+	if !sameChannelCommands[path] {
+		return true
+	}
+	if !settingsStore.Get(i.GuildID).SameChannelOnly {
+		return true
+	}
+
+	bot, err := findUserVoiceState(s, i.GuildID, s.State.User.ID)
+	if err != nil || bot.ChannelID == "" {
+		return true
+	}
+	if vs, err := findUserVoiceState(s, i.GuildID, i.Member.User.ID); err == nil && vs.ChannelID == bot.ChannelID {
+		return true
+	}
+	if isDJ(s, i) {
+		return true
+	}
+
+	msg := tr(i, "voice.not_same_channel", bot.ChannelID)
+	usage.CommandError(commandName(path))
+	noteCommandError(i, msg)
+	respondEphemeral(s, i, msg)
+	return false
+}
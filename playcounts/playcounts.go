@@ -0,0 +1,98 @@
+package playcounts
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"discordbot/storage"
+)
+
+const collection = "play_counts"
+
+// Count is the global play count of a video across all guilds
+type Count struct {
+	VideoID    string    `json:"video_id"`
+	Title      string    `json:"title,omitempty"`
+	Plays      int       `json:"plays"`
+	LastPlayed time.Time `json:"last_played"`
+}
+
+// Store keeps per-video play counts in the persistent store
+type Store struct {
+	store storage.Store
+	mu    sync.Mutex
+}
+
+// NewStore creates a new play count store
+func NewStore(store storage.Store) *Store {
+	return &Store{store: store}
+}
+
+// Record counts a play of a video
+func (s *Store) Record(videoID, title string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var count Count
+	err := s.store.Get(collection, videoID, &count)
+	if err != nil && err != storage.ErrNotFound {
+		return fmt.Errorf("error loading play count: %v", err)
+	}
+
+	count.VideoID = videoID
+	if title != "" {
+		count.Title = title
+	}
+	count.Plays++
+	count.LastPlayed = time.Now()
+
+	if err := s.store.Put(collection, videoID, count); err != nil {
+		return fmt.Errorf("error saving play count: %v", err)
+	}
+	return nil
+}
+
+// Plays returns how often a video was played, or 0 if it's unknown
+func (s *Store) Plays(videoID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var count Count
+	if err := s.store.Get(collection, videoID, &count); err != nil {
+		return 0
+	}
+	return count.Plays
+}
+
+// Top returns the most played videos, most played first
+func (s *Store) Top(limit int) ([]Count, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	videoIDs, err := s.store.Keys(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]Count, 0, len(videoIDs))
+	for _, videoID := range videoIDs {
+		var count Count
+		if err := s.store.Get(collection, videoID, &count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+
+	sort.Slice(counts, func(a, b int) bool {
+		if counts[a].Plays != counts[b].Plays {
+			return counts[a].Plays > counts[b].Plays
+		}
+		return counts[a].LastPlayed.After(counts[b].LastPlayed)
+	})
+	if limit > 0 && len(counts) > limit {
+		counts = counts[:limit]
+	}
+	return counts, nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"discordbot/audio"
+	"discordbot/control"
+	"discordbot/events"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// externalRequesterID is the requester ID of tracks queued from outside Discord
+const externalRequesterID = "external"
+
+// botPlayer exposes the voice instances to the dashboard and other external clients
+type botPlayer struct {
+	s *discordgo.Session
+}
+
+// State returns a snapshot of a guild's player
+func (p botPlayer) State(guildID string) control.State {
+	vi := voiceManager.GetVoiceInstance(guildID)
+	connected := vi.Connected()
+	vi.Mu.Lock()
+	defer vi.Mu.Unlock()
+
+	state := control.State{
+		GuildID:   guildID,
+		ChannelID: vi.ChannelID,
+		Connected: connected,
+		Playing:   vi.IsPlaying,
+		Paused:    vi.Paused,
+		Repeat:    vi.Repeat,
+		Autoplay:  vi.Autoplay,
+		Queue:     make([]control.Track, 0, len(vi.Queue)),
+	}
+	if vi.IsPlaying && vi.Current != nil {
+		current := control.TrackFrom(vi.Current)
+		state.NowPlaying = &current
+	}
+	for _, track := range vi.Queue {
+		state.Queue = append(state.Queue, control.TrackFrom(track))
+	}
+	return state
+}
+
+// Enqueue adds a track to the end of the queue, applying the guild's limits.
+// The bot has to be in a voice channel already, since there is no user
+// whose channel it could join.
+func (p botPlayer) Enqueue(guildID, url, requester string) (control.Track, error) {
+	vi := voiceManager.GetVoiceInstance(guildID)
+	vi.Mu.Lock()
+	channelID := vi.TextChannelID
+	vi.Mu.Unlock()
+	if !vi.Connected() {
+		return control.Track{}, control.ErrNotConnected
+	}
+
+	track := &audio.Track{
+		URL:         url,
+		RequesterID: externalRequesterID,
+		Requester:   requester,
+		AddedAt:     time.Now(),
+	}
+	tracks := []*audio.Track{track}
+	if err := checkLimits(vi, externalRequesterID, tracks); err != nil {
+		return control.Track{}, err
+	}
+	if duplicate := findDuplicate(vi, tracks); duplicate != nil && settingsStore.Get(guildID).BlockDuplicates {
+		return control.Track{}, fmt.Errorf("%s is already in the queue", duplicate.DisplayName())
+	}
+
+	addTracks(p.s, channelID, vi, tracks, positionEnd)
+	return control.TrackFrom(track), nil
+}
+
+// Skip stops the current track so the next one starts, ending any loop of it
+func (p botPlayer) Skip(guildID string) error {
+	vi := voiceManager.GetVoiceInstance(guildID)
+	vi.Mu.Lock()
+	if vi.Current != nil {
+		vi.Current.Loops = 0
+	}
+	vi.Mu.Unlock()
+	if !vi.Skip() {
+		return control.ErrNotPlaying
+	}
+	return nil
+}
+
+// Pause holds the current track
+func (p botPlayer) Pause(guildID string) error {
+	vi := voiceManager.GetVoiceInstance(guildID)
+	if !vi.Pause() {
+		return control.ErrNotPlaying
+	}
+	publishPlayerEvent(vi, events.Paused)
+	return nil
+}
+
+// Resume continues a paused track
+func (p botPlayer) Resume(guildID string) error {
+	vi := voiceManager.GetVoiceInstance(guildID)
+	if !vi.Resume() {
+		return control.ErrNotPaused
+	}
+	publishPlayerEvent(vi, events.Resumed)
+	return nil
+}
+
+// Move reorders the queue
+func (p botPlayer) Move(guildID string, from, to int) error {
+	if !voiceManager.GetVoiceInstance(guildID).MoveInQueue(from-1, to-1) {
+		return control.ErrBadPosition
+	}
+	return nil
+}
+
+// Remove drops a track from the queue
+func (p botPlayer) Remove(guildID string, position int) error {
+	if _, ok := voiceManager.GetVoiceInstance(guildID).RemoveAt(position - 1); !ok {
+		return control.ErrBadPosition
+	}
+	return nil
+}
+
+// publishPlayerEvent tells event subscribers about a change to the current track
+func publishPlayerEvent(vi *audio.VoiceInstance, eventType events.Type) {
+	vi.Mu.Lock()
+	channelID, current := vi.ChannelID, vi.Current
+	vi.Mu.Unlock()
+	eventBus.Publish(events.Event{Type: eventType, GuildID: vi.GuildID, ChannelID: channelID, Track: current})
+}
@@ -0,0 +1,200 @@
+package playlist
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"discordbot/storage"
+)
+
+const collection = "playlists"
+
+// ErrNotFound is returned when a playlist does not exist
+var ErrNotFound = errors.New("playlist not found")
+
+// Entry is a single track saved in a playlist
+type Entry struct {
+	URL     string    `json:"url"`
+	Title   string    `json:"title,omitempty"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+// Playlist is a named list of tracks owned by a user
+type Playlist struct {
+	Name      string    `json:"name"`
+	OwnerID   string    `json:"owner_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Entries   []Entry   `json:"entries"`
+}
+
+// Store keeps user playlists in the persistent store
+type Store struct {
+	store storage.Store
+	mu    sync.Mutex
+}
+
+// NewStore creates a new playlist store
+func NewStore(store storage.Store) *Store {
+	return &Store{store: store}
+}
+
+// normalize returns the key used to look up a playlist by name
+func normalize(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// load returns all playlists of a user keyed by normalized name
+func (s *Store) load(userID string) (map[string]*Playlist, error) {
+	playlists := make(map[string]*Playlist)
+	err := s.store.Get(collection, userID, &playlists)
+	if err != nil && err != storage.ErrNotFound {
+		return nil, fmt.Errorf("error loading playlists: %v", err)
+	}
+	return playlists, nil
+}
+
+// Create creates an empty playlist for a user
+func (s *Store) Create(userID, name string) error {
+	return s.Save(userID, name, nil, false)
+}
+
+// Save creates a playlist with the given entries. If replace is false an
+// existing playlist with the same name is an error.
+func (s *Store) Save(userID, name string, entries []Entry, replace bool) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("playlist name cannot be empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	playlists, err := s.load(userID)
+	if err != nil {
+		return err
+	}
+	if _, exists := playlists[normalize(name)]; exists && !replace {
+		return fmt.Errorf("you already have a playlist named %q", name)
+	}
+
+	playlists[normalize(name)] = &Playlist{
+		Name:      name,
+		OwnerID:   userID,
+		CreatedAt: time.Now(),
+		Entries:   entries,
+	}
+	return s.store.Put(collection, userID, playlists)
+}
+
+// Get returns a user's playlist by name
+func (s *Store) Get(userID, name string) (*Playlist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	playlists, err := s.load(userID)
+	if err != nil {
+		return nil, err
+	}
+	playlist, ok := playlists[normalize(name)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return playlist, nil
+}
+
+// Add appends entries to a user's playlist and returns its new length
+func (s *Store) Add(userID, name string, entries ...Entry) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	playlists, err := s.load(userID)
+	if err != nil {
+		return 0, err
+	}
+	playlist, ok := playlists[normalize(name)]
+	if !ok {
+		return 0, ErrNotFound
+	}
+
+	playlist.Entries = append(playlist.Entries, entries...)
+	if err := s.store.Put(collection, userID, playlists); err != nil {
+		return 0, err
+	}
+	return len(playlist.Entries), nil
+}
+
+// Delete removes a user's playlist, reporting whether it existed
+func (s *Store) Delete(userID, name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	playlists, err := s.load(userID)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := playlists[normalize(name)]; !ok {
+		return false, nil
+	}
+	delete(playlists, normalize(name))
+	return true, s.store.Put(collection, userID, playlists)
+}
+
+// List returns all playlists of a user sorted by name
+func (s *Store) List(userID string) ([]*Playlist, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	playlists, err := s.load(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*Playlist, 0, len(playlists))
+	for _, playlist := range playlists {
+		list = append(list, playlist)
+	}
+	sort.Slice(list, func(a, b int) bool {
+		return normalize(list[a].Name) < normalize(list[b].Name)
+	})
+	return list, nil
+}
+
+// Owners returns the IDs of the users who have playlists
+func (s *Store) Owners() ([]string, error) {
+	owners, err := s.store.Keys(collection)
+	if err != nil {
+		return nil, fmt.Errorf("error listing playlists: %v", err)
+	}
+	return owners, nil
+}
+
+// Restore gives a user back the given playlists, skipping those whose name
+// the user has taken since, and returns how many were added
+func (s *Store) Restore(userID string, restored []*Playlist) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	playlists, err := s.load(userID)
+	if err != nil {
+		return 0, err
+	}
+	added := 0
+	for _, playlist := range restored {
+		key := normalize(playlist.Name)
+		if key == "" || playlists[key] != nil {
+			continue
+		}
+		copied := *playlist
+		copied.OwnerID = userID
+		playlists[key] = &copied
+		added++
+	}
+	if added == 0 {
+		return 0, nil
+	}
+	return added, s.store.Put(collection, userID, playlists)
+}
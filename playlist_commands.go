@@ -0,0 +1,227 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"discordbot/audio"
+	"discordbot/playlist"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// playlistRefPrefix marks alias targets that point to a user playlist
+const playlistRefPrefix = "playlist:"
+
+// playlistRef builds an alias target referencing a user's playlist
+func playlistRef(ownerID, name string) string {
+	return playlistRefPrefix + ownerID + ":" + name
+}
+
+// parsePlaylistRef parses an alias target created by playlistRef
+func parsePlaylistRef(target string) (ownerID, name string, ok bool) {
+	if !strings.HasPrefix(target, playlistRefPrefix) {
+		return "", "", false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(target, playlistRefPrefix), ":", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// playlistTracks returns the tracks of a playlist as queue entries requested by the interaction's author
+func playlistTracks(i *discordgo.InteractionCreate, ownerID, name string) ([]*audio.Track, error) {
+	pl, err := playlistStore.Get(ownerID, name)
+	if err == playlist.ErrNotFound {
+		return nil, errors.New(tr(i, "playlist.gone", name))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(pl.Entries) == 0 {
+		return nil, errors.New(tr(i, "playlist.empty", pl.Name))
+	}
+
+	tracks := make([]*audio.Track, len(pl.Entries))
+	for idx, entry := range pl.Entries {
+		tracks[idx] = newTrack(i, entry.URL)
+		tracks[idx].Title = entry.Title
+	}
+	return tracks, nil
+}
+
+// handlePlaylist handles the /playlist command and its subcommands
+func handlePlaylist(s *discordgo.Session, i *discordgo.InteractionCreate, vi *audio.VoiceInstance) {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		editResponse(s, i, tr(i, "command.choose_subcommand"))
+		return
+	}
+
+	subcommand := options[0]
+	var name, url, source string
+	var overwrite bool
+	for _, option := range subcommand.Options {
+		switch option.Name {
+		case "name":
+			name = option.StringValue()
+		case "url":
+			url = option.StringValue()
+		case "source":
+			source = option.StringValue()
+		case "overwrite":
+			overwrite = option.BoolValue()
+		}
+	}
+	userID := i.Member.User.ID
+
+	switch subcommand.Name {
+	case "create":
+		if err := playlistStore.Create(userID, name); err != nil {
+			errorResponse(s, i, tr(i, "error", err))
+			return
+		}
+		editResponse(s, i, tr(i, "playlist.created", name))
+
+	case "add":
+		entry := playlist.Entry{URL: url, AddedAt: time.Now()}
+		if url == "" {
+			// Default to the track that is currently playing
+			vi.Mu.Lock()
+			current := vi.Current
+			vi.Mu.Unlock()
+			if current == nil {
+				editResponse(s, i, tr(i, "playlist.nothing_playing"))
+				return
+			}
+			entry.URL = current.URL
+			entry.Title = current.Title
+		}
+
+		count, err := playlistStore.Add(userID, name, entry)
+		if err == playlist.ErrNotFound {
+			errorResponse(s, i, tr(i, "playlist.not_found", name))
+			return
+		}
+		if err != nil {
+			errorResponse(s, i, tr(i, "playlist.update_failed", err))
+			return
+		}
+		editResponse(s, i, tr(i, "playlist.added", entry.URL, name, count))
+
+	case "list":
+		if name == "" {
+			playlists, err := playlistStore.List(userID)
+			if err != nil {
+				errorResponse(s, i, tr(i, "playlist.list_failed", err))
+				return
+			}
+			if len(playlists) == 0 {
+				editResponse(s, i, tr(i, "playlist.none"))
+				return
+			}
+
+			var msg strings.Builder
+			msg.WriteString(tr(i, "playlist.header") + "\n")
+			for _, pl := range playlists {
+				msg.WriteString(tr(i, "playlist.list_entry", pl.Name, len(pl.Entries)) + "\n")
+			}
+			editResponse(s, i, msg.String())
+			return
+		}
+
+		pl, err := playlistStore.Get(userID, name)
+		if err == playlist.ErrNotFound {
+			errorResponse(s, i, tr(i, "playlist.not_found", name))
+			return
+		}
+		if err != nil {
+			errorResponse(s, i, tr(i, "playlist.load_failed", err))
+			return
+		}
+		if len(pl.Entries) == 0 {
+			editResponse(s, i, tr(i, "playlist.is_empty", pl.Name))
+			return
+		}
+
+		var msg strings.Builder
+		fmt.Fprintf(&msg, "**%s**\n", pl.Name)
+		for idx, entry := range pl.Entries {
+			label := entry.URL
+			if entry.Title != "" {
+				label = entry.Title
+			}
+			fmt.Fprintf(&msg, "%d. %s\n", idx+1, label)
+		}
+		editResponse(s, i, msg.String())
+
+	case "play":
+		tracks, err := playlistTracks(i, userID, name)
+		if err != nil {
+			errorResponse(s, i, tr(i, "error", err))
+			return
+		}
+
+		if !joinUserChannel(s, i, vi) {
+			return
+		}
+		enqueueTracks(s, i, vi, tracks, positionEnd)
+
+	case "delete":
+		deleted, err := playlistStore.Delete(userID, name)
+		if err != nil {
+			errorResponse(s, i, tr(i, "playlist.delete_failed", err))
+			return
+		}
+		if !deleted {
+			errorResponse(s, i, tr(i, "playlist.not_found", name))
+			return
+		}
+		editResponse(s, i, tr(i, "playlist.deleted", name))
+
+	case "import":
+		handlePlaylistImport(s, i, source, name, overwrite)
+	}
+}
+
+// handleQueueSave snapshots the current track and pending queue into a playlist
+func handleQueueSave(s *discordgo.Session, i *discordgo.InteractionCreate, vi *audio.VoiceInstance, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	var name string
+	var overwrite bool
+	for _, option := range options {
+		switch option.Name {
+		case "name":
+			name = option.StringValue()
+		case "overwrite":
+			overwrite = option.BoolValue()
+		}
+	}
+
+	vi.Mu.Lock()
+	tracks := make([]*audio.Track, 0, len(vi.Queue)+1)
+	if vi.Current != nil && vi.IsPlaying {
+		tracks = append(tracks, vi.Current)
+	}
+	tracks = append(tracks, vi.Queue...)
+	vi.Mu.Unlock()
+
+	if len(tracks) == 0 {
+		editResponse(s, i, tr(i, "playlist.queue_empty"))
+		return
+	}
+
+	now := time.Now()
+	entries := make([]playlist.Entry, len(tracks))
+	for idx, track := range tracks {
+		entries[idx] = playlist.Entry{URL: track.URL, Title: track.Title, AddedAt: now}
+	}
+
+	if err := playlistStore.Save(i.Member.User.ID, name, entries, overwrite); err != nil {
+		errorResponse(s, i, tr(i, "error", err))
+		return
+	}
+	editResponse(s, i, tr(i, "playlist.saved", len(entries), name, name))
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"discordbot/audio"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// playManyModalID identifies the /playmany modal when it is submitted
+	playManyModalID = "playmany"
+	// playManyInputID identifies the modal's multi-line text input
+	playManyInputID = "entries"
+	// maxPlayManyLines caps how many lines one /playmany submission may queue
+	maxPlayManyLines = 50
+)
+
+// showPlayManyModal answers /playmany with a modal to paste URLs and search terms into.
+// Modals have to be the first response, so this runs instead of deferring.
+func showPlayManyModal(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: playManyModalID,
+			Title:    tr(i, "playmany.title"),
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.TextInput{
+						CustomID:    playManyInputID,
+						Label:       tr(i, "playmany.label"),
+						Style:       discordgo.TextInputParagraph,
+						Placeholder: tr(i, "playmany.placeholder"),
+						Required:    true,
+						MaxLength:   4000,
+					},
+				}},
+			},
+		},
+	})
+	if err != nil {
+		log.Printf("Error showing /playmany modal: %v", err)
+	}
+}
+
+// handleModalSubmit dispatches submitted modals
+func handleModalSubmit(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	data := i.ModalSubmitData()
+	switch data.CustomID {
+	case playManyModalID:
+		handlePlayManySubmit(s, i, modalValue(data, playManyInputID))
+	default:
+		log.Printf("Ignoring unknown modal: %s", data.CustomID)
+	}
+}
+
+// modalValue returns the value of a submitted modal's text input
+func modalValue(data discordgo.ModalSubmitInteractionData, customID string) string {
+	for _, component := range data.Components {
+		row, ok := component.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, inner := range row.Components {
+			if input, ok := inner.(*discordgo.TextInput); ok && input.CustomID == customID {
+				return input.Value
+			}
+		}
+	}
+	return ""
+}
+
+// handlePlayManySubmit queues every line of a /playmany submission and
+// reports which lines were queued and which failed
+func handlePlayManySubmit(s *discordgo.Session, i *discordgo.InteractionCreate, text string) {
+	if err := deferResponse(s, i); err != nil {
+		log.Printf("Error responding to interaction: %v", err)
+		return
+	}
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		errorResponse(s, i, tr(i, "playmany.empty"))
+		return
+	}
+	if len(lines) > maxPlayManyLines {
+		errorResponse(s, i, tr(i, "playmany.too_many", maxPlayManyLines))
+		return
+	}
+
+	vi := voiceManager.GetVoiceInstance(i.GuildID)
+	if !vi.Connected() && !joinUserChannel(s, i, vi) {
+		return
+	}
+
+	var accepted []*audio.Track
+	var failures []string
+	for _, line := range lines {
+		tracks, err := playManyTracks(i, line)
+		if err == nil {
+			err = checkLimits(vi, i.Member.User.ID, append(accepted, tracks...))
+		}
+		if err == nil {
+			if duplicate := findDuplicate(vi, tracks); duplicate != nil {
+				err = errors.New(tr(i, "playmany.duplicate"))
+			}
+		}
+		if err != nil {
+			failures = append(failures, tr(i, "playmany.failed_line", line, err))
+			continue
+		}
+		accepted = append(accepted, tracks...)
+	}
+
+	var msg strings.Builder
+	if len(accepted) > 0 {
+		msg.WriteString(addTracks(s, i.ChannelID, vi, accepted, positionEnd) + "\n")
+	}
+	msg.WriteString(tr(i, "playmany.summary", len(lines)-len(failures), len(lines)) + "\n")
+	for _, failure := range failures {
+		msg.WriteString(failure + "\n")
+	}
+	editResponse(s, i, truncateMessage(msg.String()))
+}
+
+// playManyTracks resolves one /playmany line: links, aliases and playlists as
+// for /play, and anything else as a YouTube search
+func playManyTracks(i *discordgo.InteractionCreate, line string) ([]*audio.Track, error) {
+	tracks, err := resolveRequest(i, line, playlistRange{})
+	if err != nil {
+		return nil, err
+	}
+	for _, track := range tracks {
+		if !isLink(track.URL) {
+			if err := searchTrack(track); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return tracks, nil
+}
+
+// isLink reports whether a request is a URL rather than a search term
+func isLink(query string) bool {
+	return strings.HasPrefix(query, "http://") || strings.HasPrefix(query, "https://")
+}
+
+// searchTrack replaces a track's search term with the first YouTube result
+func searchTrack(track *audio.Track) error {
+	results, err := searcher.SearchTracks(track.URL, 1)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("no results for %q", track.URL)
+	}
+	track.URL = results[0].URL
+	track.Title = results[0].Title
+	track.Duration = results[0].Duration
+	return nil
+}
+
+// truncateMessage shortens content to Discord's message length limit
+func truncateMessage(content string) string {
+	const maxLength = 2000
+	if runes := []rune(content); len(runes) > maxLength {
+		return string(runes[:maxLength-1]) + "…"
+	}
+	return content
+}
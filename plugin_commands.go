@@ -0,0 +1,174 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"discordbot/audio"
+	"discordbot/config"
+	"discordbot/plugins"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+var (
+	// loadedPlugins are the plugins started from PLUGINS_DIR
+	loadedPlugins []*plugins.Plugin
+	// pluginCommands maps the slash commands plugins added to their plugin
+	pluginCommands = make(map[string]*plugins.Plugin)
+)
+
+// pluginOptionTypes maps the option types of the plugin protocol to Discord's
+var pluginOptionTypes = map[string]discordgo.ApplicationCommandOptionType{
+	"string":  discordgo.ApplicationCommandOptionString,
+	"integer": discordgo.ApplicationCommandOptionInteger,
+	"boolean": discordgo.ApplicationCommandOptionBoolean,
+}
+
+// setupPlugins starts the plugins in PLUGINS_DIR and adds their slash
+// commands to the ones registered with Discord
+func setupPlugins() {
+	dir := config.String("PLUGINS_DIR", "")
+	if dir == "" || readOnly {
+		return
+	}
+
+	loaded, err := plugins.Load(dir, config.List("PLUGIN_ENV"))
+	if err != nil {
+		log.Printf("Plugins disabled: %v", err)
+		return
+	}
+	loadedPlugins = loaded
+
+	taken := make(map[string]bool)
+	for _, cmd := range commands {
+		taken[cmd.Name] = true
+	}
+	for _, p := range loadedPlugins {
+		for _, cmd := range p.Manifest.Commands {
+			if taken[cmd.Name] {
+				log.Printf("Plugin %s: command /%s is already taken, skipping it", p.Name(), cmd.Name)
+				continue
+			}
+			command, err := pluginCommand(cmd)
+			if err != nil {
+				log.Printf("Plugin %s: %v", p.Name(), err)
+				continue
+			}
+			taken[cmd.Name] = true
+			pluginCommands[cmd.Name] = p
+			commands = append(commands, command)
+		}
+		log.Printf("Loaded plugin %s with %d command(s) and %d source(s)", p.Name(), len(p.Manifest.Commands), len(p.Manifest.Sources))
+	}
+}
+
+// pluginCommand turns a plugin's command description into a slash command
+func pluginCommand(cmd plugins.Command) (*discordgo.ApplicationCommand, error) {
+	command := &discordgo.ApplicationCommand{Name: cmd.Name, Description: cmd.Description}
+	for _, option := range cmd.Options {
+		optionType, ok := pluginOptionTypes[option.Type]
+		if !ok {
+			return nil, fmt.Errorf("option %s of /%s has unknown type %q", option.Name, cmd.Name, option.Type)
+		}
+		command.Options = append(command.Options, &discordgo.ApplicationCommandOption{
+			Type:        optionType,
+			Name:        option.Name,
+			Description: option.Description,
+			Required:    option.Required,
+		})
+	}
+	return command, nil
+}
+
+// handlePluginCommand passes a slash command to the plugin that added it and
+// queues the tracks it answers with
+func handlePluginCommand(s *discordgo.Session, i *discordgo.InteractionCreate, vi *audio.VoiceInstance) {
+	data := i.ApplicationCommandData()
+	p, ok := pluginCommands[data.Name]
+	if !ok {
+		errorResponse(s, i, tr(i, "command.failed"))
+		return
+	}
+
+	options := make(map[string]interface{})
+	for _, option := range data.Options {
+		options[option.Name] = option.Value
+	}
+	result, err := p.Command(plugins.CommandRequest{
+		Command:   data.Name,
+		GuildID:   i.GuildID,
+		ChannelID: i.ChannelID,
+		UserID:    i.Member.User.ID,
+		Locale:    string(i.Locale),
+		Options:   options,
+	})
+	if err != nil {
+		errorResponse(s, i, tr(i, "plugins.failed", err))
+		return
+	}
+
+	if len(result.Tracks) == 0 {
+		content := result.Content
+		if content == "" {
+			content = tr(i, "plugins.done")
+		}
+		editResponse(s, i, content)
+		return
+	}
+	if !joinUserChannel(s, i, vi) {
+		return
+	}
+	enqueueTracks(s, i, vi, pluginTracks(i, p, result.Tracks), positionEnd)
+}
+
+// resolvePluginQuery asks the plugin whose source handles query for its
+// tracks. ok is false if no plugin handles it.
+func resolvePluginQuery(i *discordgo.InteractionCreate, query string) (tracks []*audio.Track, ok bool, err error) {
+	for _, p := range loadedPlugins {
+		if !p.Handles(query) {
+			continue
+		}
+		found, err := p.Resolve(plugins.ResolveRequest{Query: query, GuildID: i.GuildID})
+		if err != nil {
+			return nil, true, err
+		}
+		if len(found) == 0 {
+			return nil, true, errors.New(tr(i, "plugins.nothing_found", query))
+		}
+		return pluginTracks(i, p, found), true, nil
+	}
+	return nil, false, nil
+}
+
+// pluginTracks turns a plugin's tracks into queue entries requested by the interaction's author
+func pluginTracks(i *discordgo.InteractionCreate, p *plugins.Plugin, found []plugins.Track) []*audio.Track {
+	tracks := make([]*audio.Track, 0, len(found))
+	for _, t := range found {
+		track := newTrack(i, t.URL)
+		track.Title = t.Title
+		track.Duration = time.Duration(t.DurationSeconds) * time.Second
+		track.Plugin = p.Name()
+		tracks = append(tracks, track)
+	}
+	return tracks
+}
+
+// stopPlugins stops every loaded plugin process
+func stopPlugins() {
+	for _, p := range loadedPlugins {
+		p.Stop()
+	}
+}
+
+// pluginStreamURL asks the plugin a track came from where its audio can be read
+func pluginStreamURL(track *audio.Track) (string, error) {
+	for _, p := range loadedPlugins {
+		if p.Name() == track.Plugin {
+			return p.Stream(track.URL)
+		}
+	}
+	return "", fmt.Errorf("plugin %s isn't loaded", track.Plugin)
+}
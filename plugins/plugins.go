@@ -0,0 +1,308 @@
+package plugins
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// callTimeout is how long a plugin may take to answer a call
+	callTimeout = 30 * time.Second
+	// restartDelay is the minimum time between two starts of a plugin that exited
+	restartDelay = 30 * time.Second
+	// stopTimeout is how long a plugin gets to exit after its input is closed before it is killed
+	stopTimeout = 2 * time.Second
+)
+
+// baseEnv are the variables every plugin gets from the bot's environment.
+// The rest, such as the bot's tokens and passwords, are kept from plugins
+// unless the operator passes them on.
+var baseEnv = []string{"PATH", "HOME", "LANG", "TZ"}
+
+// ErrNotRunning is returned for calls to a plugin that exited and can't be restarted yet
+var ErrNotRunning = errors.New("plugin is not running")
+
+// Plugin is a running plugin process
+type Plugin struct {
+	path     string
+	env      []string
+	Manifest Manifest
+
+	mu      sync.Mutex
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	exited  chan struct{} // Closed once the process has exited
+	pending map[int64]chan response
+	nextID  int64
+	running bool
+	stopped bool // Set by Stop, so the plugin isn't restarted
+	started time.Time
+}
+
+// request is a JSON-RPC request
+type request struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      int64       `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// response is a JSON-RPC response
+type response struct {
+	ID     int64           `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Load starts every executable in dir and asks it what it provides. Plugins
+// only see baseEnv and the variables named in pass from the bot's
+// environment. Plugins that fail to start are logged and left out.
+func Load(dir string, pass []string) ([]*Plugin, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading plugin directory: %v", err)
+	}
+
+	env := environment(append(append([]string{}, baseEnv...), pass...))
+	var loaded []*Plugin
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Mode()&0111 == 0 {
+			continue
+		}
+		p := &Plugin{path: filepath.Join(dir, entry.Name()), env: env}
+		p.mu.Lock()
+		err = p.start()
+		p.mu.Unlock()
+		if err != nil {
+			log.Printf("Failed to start plugin %s: %v", entry.Name(), err)
+			continue
+		}
+		if err := p.call("initialize", nil, &p.Manifest); err != nil {
+			log.Printf("Failed to initialize plugin %s: %v", entry.Name(), err)
+			p.Stop()
+			continue
+		}
+		if p.Manifest.Name == "" {
+			p.Manifest.Name = entry.Name()
+		}
+		loaded = append(loaded, p)
+	}
+	return loaded, nil
+}
+
+// Name returns the name the plugin gave itself
+func (p *Plugin) Name() string {
+	return p.Manifest.Name
+}
+
+// Command runs one of the plugin's slash commands
+func (p *Plugin) Command(req CommandRequest) (*CommandResult, error) {
+	var result CommandResult
+	if err := p.call("command", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Resolve turns a query into the plugin's tracks
+func (p *Plugin) Resolve(req ResolveRequest) ([]Track, error) {
+	var result ResolveResult
+	if err := p.call("resolve", req, &result); err != nil {
+		return nil, err
+	}
+	return result.Tracks, nil
+}
+
+// Stream returns where the audio of a track can be read right now
+func (p *Plugin) Stream(url string) (string, error) {
+	var result StreamResult
+	if err := p.call("stream", StreamRequest{URL: url}, &result); err != nil {
+		return "", err
+	}
+	if result.URL == "" {
+		return "", fmt.Errorf("plugin %s returned no audio for %s", p.Name(), url)
+	}
+	return result.URL, nil
+}
+
+// Handles reports whether query is meant for one of the plugin's sources
+func (p *Plugin) Handles(query string) bool {
+	for _, source := range p.Manifest.Sources {
+		for _, prefix := range source.Prefixes {
+			if prefix != "" && strings.HasPrefix(query, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Stop ends the plugin process for good. Closing its input asks it to exit;
+// if it hasn't after stopTimeout, it is killed.
+func (p *Plugin) Stop() {
+	p.mu.Lock()
+	p.stopped = true
+	if !p.running {
+		p.mu.Unlock()
+		return
+	}
+	cmd, exited := p.cmd, p.exited
+	p.stdin.Close()
+	p.mu.Unlock()
+
+	select {
+	case <-exited:
+	case <-time.After(stopTimeout):
+		log.Printf("Plugin %s didn't exit in time, killing it", p.Name())
+		cmd.Process.Kill()
+		<-exited
+	}
+}
+
+// environment returns the variables called names from the bot's environment
+// as NAME=value pairs, leaving out unset ones
+func environment(names []string) []string {
+	env := make([]string, 0, len(names))
+	for _, name := range names {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	return env
+}
+
+// start runs the plugin process and reads its responses in the background.
+// p.mu must be held, so only one process is ever started for the plugin.
+func (p *Plugin) start() error {
+	p.started = time.Now()
+	cmd := exec.Command(p.path)
+	cmd.Env = p.env
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	exited := make(chan struct{})
+	p.cmd = cmd
+	p.stdin = stdin
+	p.exited = exited
+	p.pending = make(map[int64]chan response)
+	p.running = true
+
+	name := filepath.Base(p.path)
+	go func() {
+		lines := bufio.NewScanner(stderr)
+		for lines.Scan() {
+			log.Printf("Plugin %s: %s", name, lines.Text())
+		}
+	}()
+
+	go func() {
+		lines := bufio.NewScanner(stdout)
+		lines.Buffer(make([]byte, 64*1024), 4*1024*1024)
+		for lines.Scan() {
+			var resp response
+			if err := json.Unmarshal(lines.Bytes(), &resp); err != nil {
+				log.Printf("Plugin %s sent invalid JSON: %v", name, err)
+				continue
+			}
+			p.mu.Lock()
+			reply, ok := p.pending[resp.ID]
+			delete(p.pending, resp.ID)
+			p.mu.Unlock()
+			if ok {
+				reply <- resp
+			}
+		}
+
+		err := cmd.Wait()
+		log.Printf("Plugin %s exited: %v", name, err)
+
+		// Fail the calls still waiting for an answer
+		p.mu.Lock()
+		p.running = false
+		for id, reply := range p.pending {
+			close(reply)
+			delete(p.pending, id)
+		}
+		p.mu.Unlock()
+		close(exited)
+	}()
+	return nil
+}
+
+// call sends a request and decodes the result into out, restarting the
+// plugin first if it exited a while ago
+func (p *Plugin) call(method string, params, out interface{}) error {
+	p.mu.Lock()
+	if !p.running {
+		if p.stopped || time.Since(p.started) < restartDelay {
+			p.mu.Unlock()
+			return ErrNotRunning
+		}
+		log.Printf("Restarting plugin %s", p.Name())
+		if err := p.start(); err != nil {
+			p.mu.Unlock()
+			return fmt.Errorf("error restarting plugin: %v", err)
+		}
+	}
+
+	p.nextID++
+	id := p.nextID
+	reply := make(chan response, 1)
+	p.pending[id] = reply
+	body, err := json.Marshal(request{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+	if err == nil {
+		_, err = p.stdin.Write(append(body, '\n'))
+	}
+	if err != nil {
+		delete(p.pending, id)
+		p.mu.Unlock()
+		return fmt.Errorf("error calling plugin %s: %v", p.Name(), err)
+	}
+	p.mu.Unlock()
+
+	select {
+	case resp, ok := <-reply:
+		if !ok {
+			return fmt.Errorf("plugin %s exited during %s", p.Name(), method)
+		}
+		if resp.Error != nil {
+			return fmt.Errorf("plugin %s: %s", p.Name(), resp.Error.Message)
+		}
+		if out == nil || len(resp.Result) == 0 {
+			return nil
+		}
+		return json.Unmarshal(resp.Result, out)
+	case <-time.After(callTimeout):
+		p.mu.Lock()
+		delete(p.pending, id)
+		p.mu.Unlock()
+		return fmt.Errorf("plugin %s didn't answer %s in time", p.Name(), method)
+	}
+}
@@ -0,0 +1,96 @@
+package plugins
+
+// Plugins are executables that talk JSON-RPC 2.0 with the bot over their
+// standard input and output, one JSON object per line. Anything they write
+// to standard error ends up in the bot's log. The bot calls these methods:
+//
+//	initialize  -> Manifest        once after starting the plugin
+//	command     CommandRequest -> CommandResult
+//	resolve     ResolveRequest -> ResolveResult
+//	stream      StreamRequest  -> StreamResult
+//
+// The protocol is JSON-RPC over stdio rather than gRPC on purpose. Plugins
+// are always child processes of the bot, so there is no network to cross
+// and nothing to secure or discover; the pipes are already private to the
+// two of them. A plugin can be a short script in any language without
+// generated stubs, and the bot needs no gRPC and protobuf dependencies.
+// What gRPC would add, streaming calls, isn't needed: audio never passes
+// through the plugin connection, only the URL FFmpeg reads it from.
+
+// Manifest describes what a plugin adds to the bot
+type Manifest struct {
+	Name     string    `json:"name"`
+	Commands []Command `json:"commands,omitempty"`
+	Sources  []Source  `json:"sources,omitempty"`
+}
+
+// Command is a slash command a plugin handles
+type Command struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Options     []Option `json:"options,omitempty"`
+}
+
+// Option is an option of a plugin's slash command
+type Option struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Type        string `json:"type"` // "string", "integer" or "boolean"
+	Required    bool   `json:"required,omitempty"`
+}
+
+// Source is a kind of /play query a plugin resolves into tracks
+type Source struct {
+	Name string `json:"name"`
+	// Prefixes are the starts of the queries the source handles, like
+	// "https://example.com/" or "example:"
+	Prefixes []string `json:"prefixes"`
+}
+
+// CommandRequest is a slash command run by a member
+type CommandRequest struct {
+	Command   string                 `json:"command"`
+	GuildID   string                 `json:"guild_id"`
+	ChannelID string                 `json:"channel_id"`
+	UserID    string                 `json:"user_id"`
+	Locale    string                 `json:"locale,omitempty"`
+	Options   map[string]interface{} `json:"options,omitempty"`
+}
+
+// CommandResult is the answer to a slash command. Tracks are queued for the
+// member as if they had asked for them with /play; Content answers the member
+// if there are none.
+type CommandResult struct {
+	Content string  `json:"content,omitempty"`
+	Tracks  []Track `json:"tracks,omitempty"`
+}
+
+// ResolveRequest asks a source for the tracks a query stands for
+type ResolveRequest struct {
+	Query   string `json:"query"`
+	GuildID string `json:"guild_id"`
+}
+
+// ResolveResult is the tracks a query stands for
+type ResolveResult struct {
+	Tracks []Track `json:"tracks"`
+}
+
+// Track is a track from a plugin source. URL identifies it to the plugin and
+// is passed back to resolve the audio when the track is about to play.
+type Track struct {
+	URL             string `json:"url"`
+	Title           string `json:"title,omitempty"`
+	DurationSeconds int    `json:"duration_seconds,omitempty"`
+}
+
+// StreamRequest asks for the audio of a track just before it plays
+type StreamRequest struct {
+	URL string `json:"url"`
+}
+
+// StreamResult is where the audio of a track can be read by FFmpeg. Plugins
+// can hand out short-lived URLs, since they are resolved right before playback.
+type StreamResult struct {
+	URL string `json:"url"`
+}
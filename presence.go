@@ -0,0 +1,150 @@
+package main
+
+import (
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"discordbot/audio"
+	"discordbot/events"
+	"discordbot/i18n"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// presenceSettle lets bursts of player events settle before the presence is changed
+	presenceSettle = 2 * time.Second
+	// presenceInterval is the minimum time between two presence updates; the
+	// gateway only takes a handful per minute
+	presenceInterval = 15 * time.Second
+	// presenceRefresh catches player changes that publish no event, like leaving voice
+	presenceRefresh = time.Minute
+	// maxActivityLength is Discord's limit for activity names
+	maxActivityLength = 128
+)
+
+// presence tracks the activity the bot last showed on this shard
+var presence = struct {
+	sync.Mutex
+	applied string
+	changed time.Time
+	pending bool
+}{}
+
+// startPresence keeps the bot's activity showing what it is playing. With a
+// single guild playing the track is named, with several only their number is,
+// counted for the guilds of this shard since each shard has its own presence.
+func startPresence(s *discordgo.Session) {
+	eventBus.Subscribe(func(e events.Event) {
+		switch e.Type {
+		case events.TrackStart, events.TrackEnd, events.Paused, events.Resumed:
+			schedulePresence(s)
+		}
+	})
+
+	// A new gateway session starts without an activity
+	s.AddHandler(func(s *discordgo.Session, _ *discordgo.Ready) {
+		presence.Lock()
+		presence.applied = ""
+		presence.Unlock()
+		schedulePresence(s)
+	})
+
+	go func() {
+		for range time.Tick(presenceRefresh) {
+			schedulePresence(s)
+		}
+	}()
+}
+
+// schedulePresence queues a presence update, coalescing bursts and spacing
+// updates out so the gateway's rate limit is never hit
+func schedulePresence(s *discordgo.Session) {
+	presence.Lock()
+	defer presence.Unlock()
+
+	if presence.pending {
+		return
+	}
+	presence.pending = true
+
+	delay := presenceSettle
+	if wait := time.Until(presence.changed.Add(presenceInterval)); wait > delay {
+		delay = wait
+	}
+	time.AfterFunc(delay, func() { applyPresence(s) })
+}
+
+// applyPresence sets the activity matching what is playing on this shard
+func applyPresence(s *discordgo.Session) {
+	presence.Lock()
+	presence.pending = false
+	applied := presence.applied
+	presence.Unlock()
+
+	activity := presenceActivity(s)
+	if activity == applied {
+		return
+	}
+
+	var err error
+	if activity == "" {
+		err = s.UpdateStatusComplex(discordgo.UpdateStatusData{Status: string(discordgo.StatusOnline)})
+	} else {
+		err = s.UpdateListeningStatus(activity)
+	}
+	if err != nil {
+		log.Printf("Error updating presence: %v", err)
+		return
+	}
+
+	presence.Lock()
+	presence.applied = activity
+	presence.changed = time.Now()
+	presence.Unlock()
+}
+
+// presenceActivity returns what the bot is listening to on this shard; empty clears the activity
+func presenceActivity(s *discordgo.Session) string {
+	voiceManager.Mu.Lock()
+	instances := make([]*audio.VoiceInstance, 0, len(voiceManager.Instances))
+	for _, vi := range voiceManager.Instances {
+		instances = append(instances, vi)
+	}
+	voiceManager.Mu.Unlock()
+
+	var playing []*audio.Track
+	for _, vi := range instances {
+		if !onShard(s, vi.GuildID) {
+			continue
+		}
+		vi.Mu.Lock()
+		if vi.IsPlaying && !vi.Paused && vi.Current != nil {
+			playing = append(playing, vi.Current)
+		}
+		vi.Mu.Unlock()
+	}
+
+	switch len(playing) {
+	case 0:
+		return ""
+	case 1:
+		return truncateText(playing[0].DisplayName(), maxActivityLength)
+	default:
+		return i18n.T(i18n.Default, "presence.guilds", len(playing))
+	}
+}
+
+// onShard reports whether a guild's events arrive on the session's shard
+func onShard(s *discordgo.Session, guildID string) bool {
+	if s.ShardCount <= 1 {
+		return true
+	}
+	id, err := strconv.ParseUint(guildID, 10, 64)
+	if err != nil {
+		return true
+	}
+	return int((id>>22)%uint64(s.ShardCount)) == s.ShardID
+}
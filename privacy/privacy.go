@@ -0,0 +1,54 @@
+package privacy
+
+import (
+	"log"
+	"time"
+
+	"discordbot/storage"
+)
+
+const collection = "privacy_optouts"
+
+// OptOut records when a user asked not to have their activity recorded
+type OptOut struct {
+	Since time.Time `json:"since"`
+}
+
+// Store keeps which users opted out of data collection, keyed by user ID.
+// Opt-outs apply across all guilds.
+type Store struct {
+	store storage.Store
+}
+
+// NewStore creates a new privacy store
+func NewStore(store storage.Store) *Store {
+	return &Store{store: store}
+}
+
+// OptOut excludes a user from history, statistics and profiles
+func (s *Store) OptOut(userID string) error {
+	if s.OptedOut(userID) {
+		return nil
+	}
+	return s.store.Put(collection, userID, OptOut{Since: time.Now()})
+}
+
+// OptIn lets a user's activity be recorded again
+func (s *Store) OptIn(userID string) error {
+	return s.store.Delete(collection, userID)
+}
+
+// OptedOut reports whether a user opted out of data collection.
+// If the opt-out can't be read the user is treated as opted out,
+// so a storage error never causes data to be recorded against their wish.
+func (s *Store) OptedOut(userID string) bool {
+	var optOut OptOut
+	err := s.store.Get(collection, userID, &optOut)
+	if err == storage.ErrNotFound {
+		return false
+	}
+	if err != nil {
+		log.Printf("Error loading privacy setting for user %s: %v", userID, err)
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// handlePrivacy handles the /privacy command and its subcommands
+func handlePrivacy(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		editResponse(s, i, tr(i, "command.choose_subcommand"))
+		return
+	}
+
+	userID := i.Member.User.ID
+	switch options[0].Name {
+	case "optout":
+		if err := privacyStore.OptOut(userID); err != nil {
+			errorResponse(s, i, tr(i, "privacy.save_failed", err))
+			return
+		}
+
+		// Remove what was already recorded, not just future activity
+		voiceManager.ForgetRequester(userID)
+		removed, err := statsRecorder.ForgetUser(userID)
+		if err != nil {
+			log.Printf("Failed to remove statistics for user %s: %v", userID, err)
+			editResponse(s, i, tr(i, "privacy.opted_out")+"\n"+tr(i, "privacy.forget_failed"))
+			return
+		}
+		editResponse(s, i, tr(i, "privacy.opted_out")+"\n"+tr(i, "privacy.forgotten", removed))
+
+	case "optin":
+		if err := privacyStore.OptIn(userID); err != nil {
+			errorResponse(s, i, tr(i, "privacy.save_failed", err))
+			return
+		}
+		editResponse(s, i, tr(i, "privacy.opted_in"))
+
+	case "status":
+		if privacyStore.OptedOut(userID) {
+			editResponse(s, i, tr(i, "privacy.status_out"))
+		} else {
+			editResponse(s, i, tr(i, "privacy.status_in"))
+		}
+	}
+}
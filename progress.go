@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"discordbot/audio"
+	"discordbot/notify"
+)
+
+const (
+	// progressInterval is how often the now-playing message's progress bar is
+	// refreshed; well below Discord's message edit rate limit
+	progressInterval = 15 * time.Second
+	// progressBarWidth is the number of segments in the progress bar
+	progressBarWidth = 14
+)
+
+// showProgress keeps a progress bar under the now-playing message up to date
+// while the track plays from start. The returned function stops the updates
+// and returns once the last edit is done, so a later "finished" edit is never
+// overwritten.
+func showProgress(vi *audio.VoiceInstance, message *notify.Message, url string, track *audio.Track, start time.Duration) (stop func()) {
+	if message == nil {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		// Live streams and sources without metadata get no bar
+		if err := fillTrackInfo(track); err != nil || track.Duration <= 0 {
+			return
+		}
+
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+
+		last := ""
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			vi.Mu.Lock()
+			paused := vi.Paused
+			vi.Mu.Unlock()
+
+			// Nothing changes while paused, so this edits once and then idles
+			content := nowPlayingText(vi.GuildID, trackLabel(track, url), start) + "\n" + progressLine(vi.Position(), track.Duration, paused)
+			if content != last {
+				notifier.Edit(message, content)
+				last = content
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// progressLine renders a progress bar with the elapsed and total time
+func progressLine(position, total time.Duration, paused bool) string {
+	if position > total {
+		position = total
+	}
+	filled := int(float64(progressBarWidth) * float64(position) / float64(total))
+	if filled >= progressBarWidth {
+		filled = progressBarWidth - 1
+	}
+
+	glyph := glyphPlaying
+	if paused {
+		glyph = glyphPaused
+	}
+	bar := strings.Repeat("▬", filled) + "🔘" + strings.Repeat("▬", progressBarWidth-filled-1)
+	return glyph + " " + bar + " `" + formatDuration(position) + " / " + formatDuration(total) + "`"
+}
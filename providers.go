@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Delays between attempts to reach Spotify at startup, doubling up to the maximum
+const (
+	spotifyRetryDelay    = 30 * time.Second
+	spotifyMaxRetryDelay = 10 * time.Minute
+)
+
+// authenticateSpotify checks the Spotify credentials in the background so
+// startup never waits on Spotify, retrying while the API is unreachable.
+// Requests made in the meantime fetch a token themselves.
+func authenticateSpotify(ctx context.Context) {
+	if spotifyClient == nil {
+		return
+	}
+
+	delay := spotifyRetryDelay
+	for {
+		err := spotifyClient.Authenticate()
+		if err == nil {
+			log.Println("Spotify client authenticated")
+			return
+		}
+		log.Printf("Warning: %v; retrying in %s", err, delay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > spotifyMaxRetryDelay {
+			delay = spotifyMaxRetryDelay
+		}
+	}
+}
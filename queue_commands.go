@@ -0,0 +1,100 @@
+package main
+
+import (
+	"discordbot/audio"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// voiceChannelMembers returns the IDs of the users in a voice channel
+func voiceChannelMembers(s *discordgo.Session, guildID, channelID string) (map[string]bool, error) {
+	guild, err := s.State.Guild(guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make(map[string]bool)
+	for _, vs := range guild.VoiceStates {
+		if vs.ChannelID == channelID {
+			members[vs.UserID] = true
+		}
+	}
+	return members, nil
+}
+
+// requesterLeft reports whether a track was queued by a member who is no
+// longer in the voice channel. Autoplay, API and plugin tracks have no member
+// to leave and always stay.
+func requesterLeft(track *audio.Track, members map[string]bool) bool {
+	switch {
+	case track.RequesterID == "", track.RequesterID == externalRequesterID:
+		return false
+	case track.Requester == autoplayRequester, track.Plugin != "":
+		return false
+	}
+	return !members[track.RequesterID]
+}
+
+// handleLeaveCleanup removes queued tracks whose requester is no longer in the
+// bot's voice channel
+func handleLeaveCleanup(s *discordgo.Session, i *discordgo.InteractionCreate, vi *audio.VoiceInstance) {
+	vi.Mu.Lock()
+	channelID := vi.ChannelID
+	vi.Mu.Unlock()
+
+	if channelID == "" {
+		errorResponse(s, i, tr(i, "voice.bot_not_connected"))
+		return
+	}
+
+	members, err := voiceChannelMembers(s, i.GuildID, channelID)
+	if err != nil {
+		errorResponse(s, i, tr(i, "cleanup.members_failed", err))
+		return
+	}
+
+	removed := vi.RemoveFromQueue(func(track *audio.Track) bool {
+		return requesterLeft(track, members)
+	})
+
+	if len(removed) == 0 {
+		editResponse(s, i, tr(i, "cleanup.nothing"))
+		return
+	}
+
+	requesters := make(map[string]bool)
+	for _, track := range removed {
+		requesters[track.RequesterID] = true
+	}
+	editResponse(s, i, tr(i, "cleanup.removed", len(removed), len(requesters)))
+}
+
+// handleReverse inverts the order of the pending queue, such as a playlist
+// imported newest first
+func handleReverse(s *discordgo.Session, i *discordgo.InteractionCreate, vi *audio.VoiceInstance) {
+	count := vi.ReverseQueue()
+	if count < 2 {
+		editResponse(s, i, tr(i, "reverse.too_short"))
+		return
+	}
+	editResponse(s, i, tr(i, "reverse.done", count))
+}
+
+// handleRemoveUser removes every queued track requested by a member. Only DJs
+// may remove other members' tracks.
+func handleRemoveUser(s *discordgo.Session, i *discordgo.InteractionCreate, vi *audio.VoiceInstance) {
+	userID := i.ApplicationCommandData().Options[0].UserValue(nil).ID
+	if userID != i.Member.User.ID && !isDJ(s, i) {
+		errorResponse(s, i, tr(i, "removeuser.dj_only"))
+		return
+	}
+
+	removed := vi.RemoveFromQueue(func(track *audio.Track) bool {
+		return track.RequesterID == userID
+	})
+	if len(removed) == 0 {
+		editResponse(s, i, tr(i, "removeuser.nothing", userID))
+		return
+	}
+	editResponse(s, i, tr(i, "removeuser.removed", len(removed), userID))
+}
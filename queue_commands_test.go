@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+
+	"discordbot/audio"
+)
+
+func TestRequesterLeft(t *testing.T) {
+	members := map[string]bool{"present": true}
+	tests := []struct {
+		name  string
+		track audio.Track
+		want  bool
+	}{
+		{"member still listening", audio.Track{RequesterID: "present"}, false},
+		{"member left", audio.Track{RequesterID: "gone"}, true},
+		{"autoplay", audio.Track{Requester: autoplayRequester}, false},
+		{"autoplay with a requester ID", audio.Track{RequesterID: "gone", Requester: autoplayRequester}, false},
+		{"API", audio.Track{RequesterID: externalRequesterID}, false},
+		{"plugin", audio.Track{RequesterID: "gone", Plugin: "radio"}, false},
+	}
+	for _, test := range tests {
+		if got := requesterLeft(&test.track, members); got != test.want {
+			t.Errorf("%s got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
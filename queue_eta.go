@@ -0,0 +1,54 @@
+package main
+
+import (
+	"time"
+
+	"discordbot/audio"
+)
+
+// queueTimes returns a snapshot of the queue with estimates, from the track
+// durations, of when each track will start and when the queue runs out.
+// Tracks of unknown length make every estimate after them unknown, which is
+// reported as -1.
+func queueTimes(vi *audio.VoiceInstance) (queue []*audio.Track, starts []time.Duration, total time.Duration) {
+	vi.Mu.Lock()
+	queue = append([]*audio.Track(nil), vi.Queue...)
+	current := vi.Current
+	if !vi.IsPlaying {
+		current = nil
+	}
+	vi.Mu.Unlock()
+
+	var elapsed time.Duration
+	if current != nil {
+		if current.Duration <= 0 {
+			elapsed = -1
+		} else if remaining := current.Duration - vi.Position(); remaining > 0 {
+			elapsed = remaining
+		}
+	}
+
+	starts = make([]time.Duration, len(queue))
+	for n, track := range queue {
+		starts[n] = elapsed
+		if elapsed >= 0 {
+			if track.Duration > 0 {
+				elapsed += track.Duration
+			} else {
+				elapsed = -1
+			}
+		}
+	}
+	return queue, starts, elapsed
+}
+
+// formatETA describes how long until something plays, e.g. "in ~23 min"
+func formatETA(guildID string, d time.Duration) string {
+	switch {
+	case d < 0:
+		return trGuild(guildID, "queue.eta_unknown")
+	case d < time.Minute:
+		return trGuild(guildID, "queue.eta_soon")
+	}
+	return trGuild(guildID, "queue.eta_minutes", int(d.Round(time.Minute)/time.Minute))
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"discordbot/audio"
+	"discordbot/bandwidth"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// maxImportBytes caps the size of a queue file accepted by /queue import
+	maxImportBytes = 256 << 10
+	// maxImportTracks caps how many tracks one /queue import may queue
+	maxImportTracks = 200
+)
+
+// queueFile is the JSON format written by /queue export and read by /queue import
+type queueFile struct {
+	ExportedAt time.Time        `json:"exported_at"`
+	Tracks     []queueFileTrack `json:"tracks"`
+}
+
+// queueFileTrack is one track of an exported queue
+type queueFileTrack struct {
+	URL             string `json:"url"`
+	Title           string `json:"title,omitempty"`
+	DurationSeconds int    `json:"duration_seconds,omitempty"`
+}
+
+// handleQueueExport attaches the current track and queue as a JSON file or a
+// plain list of URLs
+func handleQueueExport(s *discordgo.Session, i *discordgo.InteractionCreate, vi *audio.VoiceInstance, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	format := "json"
+	for _, option := range options {
+		if option.Name == "format" {
+			format = option.StringValue()
+		}
+	}
+
+	vi.Mu.Lock()
+	tracks := make([]*audio.Track, 0, len(vi.Queue)+1)
+	if vi.Current != nil && vi.IsPlaying {
+		tracks = append(tracks, vi.Current)
+	}
+	tracks = append(tracks, vi.Queue...)
+	vi.Mu.Unlock()
+
+	if len(tracks) == 0 {
+		editResponse(s, i, tr(i, "queue.empty"))
+		return
+	}
+
+	var data []byte
+	var name, contentType string
+	if format == "text" {
+		var buf bytes.Buffer
+		for _, track := range tracks {
+			buf.WriteString(track.URL + "\n")
+		}
+		data, name, contentType = buf.Bytes(), "queue.txt", "text/plain"
+	} else {
+		file := queueFile{ExportedAt: time.Now().UTC()}
+		for _, track := range tracks {
+			file.Tracks = append(file.Tracks, queueFileTrack{
+				URL:             track.URL,
+				Title:           track.Title,
+				DurationSeconds: int(track.Duration / time.Second),
+			})
+		}
+		var err error
+		data, err = json.MarshalIndent(file, "", "  ")
+		if err != nil {
+			errorResponse(s, i, tr(i, "error", err))
+			return
+		}
+		name, contentType = "queue.json", "application/json"
+	}
+
+	content := tr(i, "queue.exported", len(tracks))
+	_, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &content,
+		Files:   []*discordgo.File{{Name: name, ContentType: contentType, Reader: bytes.NewReader(data)}},
+	})
+	if err != nil {
+		log.Printf("Failed to update interaction: %v", err)
+	}
+}
+
+// handleQueueImport queues the tracks of a file written by /queue export
+func handleQueueImport(s *discordgo.Session, i *discordgo.InteractionCreate, vi *audio.VoiceInstance, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	var attachment *discordgo.MessageAttachment
+	for _, option := range options {
+		if option.Name == "file" {
+			attachment = i.ApplicationCommandData().Resolved.Attachments[option.Value.(string)]
+		}
+	}
+	if attachment == nil {
+		errorResponse(s, i, tr(i, "queue.import_no_file"))
+		return
+	}
+	if attachment.Size > maxImportBytes {
+		errorResponse(s, i, tr(i, "queue.import_too_big", maxImportBytes>>10))
+		return
+	}
+
+	data, err := fetchAttachment(attachment.URL, maxImportBytes, i.GuildID)
+	if err != nil {
+		errorResponse(s, i, tr(i, "error", err))
+		return
+	}
+	tracks, err := parseQueueFile(data)
+	if err != nil {
+		errorResponse(s, i, tr(i, "queue.import_invalid", err))
+		return
+	}
+	if len(tracks) == 0 {
+		errorResponse(s, i, tr(i, "queue.import_empty"))
+		return
+	}
+	if len(tracks) > maxImportTracks {
+		errorResponse(s, i, tr(i, "queue.import_too_many", maxImportTracks))
+		return
+	}
+
+	// Join the user's voice channel if we aren't connected yet
+	if !vi.Connected() && !joinUserChannel(s, i, vi) {
+		return
+	}
+
+	// Titles and lengths come from YouTube, not the file, so an edited file
+	// can't sneak past the guild's limits
+	fillTracksInfo(tracks)
+
+	now := time.Now()
+	for _, track := range tracks {
+		track.RequesterID = i.Member.User.ID
+		track.Requester = i.Member.User.Username
+		track.AddedAt = now
+	}
+	enqueueTracks(s, i, vi, tracks, positionEnd)
+}
+
+// parseQueueFile reads an exported queue, either JSON or one URL per line.
+// Blank lines and lines starting with # are ignored in the text format. Only
+// the URLs are kept; titles and lengths in the file aren't trusted.
+func parseQueueFile(data []byte) ([]*audio.Track, error) {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		var file queueFile
+		if err := json.Unmarshal(trimmed, &file); err != nil {
+			return nil, err
+		}
+		tracks := make([]*audio.Track, 0, len(file.Tracks))
+		for n, entry := range file.Tracks {
+			if !isLink(entry.URL) {
+				return nil, fmt.Errorf("track %d has no valid URL", n+1)
+			}
+			tracks = append(tracks, &audio.Track{URL: entry.URL})
+		}
+		return tracks, nil
+	}
+
+	var tracks []*audio.Track
+	scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !isLink(line) {
+			return nil, fmt.Errorf("line %d is not a URL", n)
+		}
+		tracks = append(tracks, &audio.Track{URL: line})
+	}
+	return tracks, scanner.Err()
+}
+
+// fetchAttachment downloads an attachment of at most maxBytes into memory,
+// at the guild's share of the download rate limit
+func fetchAttachment(url string, maxBytes int64, guildID string) ([]byte, error) {
+	rateLimit, done := startDownload(guildID)
+	defer done()
+
+	// Give a rate-limited download the time it needs at that rate
+	timeout := 30 * time.Second
+	if rateLimit > 0 {
+		timeout += time.Duration(maxBytes/rateLimit) * time.Second
+	}
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading the file: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error downloading the file: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(bandwidth.Reader(io.LimitReader(resp.Body, maxBytes+1), rateLimit))
+	if err != nil {
+		return nil, fmt.Errorf("error downloading the file: %v", err)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("the file is larger than %d KB", maxBytes>>10)
+	}
+	return data, nil
+}
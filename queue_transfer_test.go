@@ -0,0 +1,44 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseQueueFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "JSON",
+			data: `{"exported_at":"2024-01-02T03:04:05Z","tracks":[{"url":"https://youtu.be/a","title":"A","duration_seconds":60},{"url":"https://youtu.be/b"}]}`,
+			want: []string{"https://youtu.be/a", "https://youtu.be/b"},
+		},
+		{
+			name: "text",
+			data: "\n# exported queue\nhttps://youtu.be/a\n\n  https://youtu.be/b  \n",
+			want: []string{"https://youtu.be/a", "https://youtu.be/b"},
+		},
+		{name: "empty", data: "\n\n", want: nil},
+		{name: "JSON without URL", data: `{"tracks":[{"title":"A"}]}`, wantErr: true},
+		{name: "invalid JSON", data: `{"tracks":[`, wantErr: true},
+		{name: "text with a search term", data: "https://youtu.be/a\nnever gonna give you up\n", wantErr: true},
+	}
+	for _, test := range tests {
+		tracks, err := parseQueueFile([]byte(test.data))
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: got error %v, want error %v", test.name, err, test.wantErr)
+			continue
+		}
+		var urls []string
+		for _, track := range tracks {
+			urls = append(urls, track.URL)
+		}
+		if !reflect.DeepEqual(urls, test.want) {
+			t.Errorf("%s: got %q, want %q", test.name, urls, test.want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package radio
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"discordbot/bandwidth"
+)
+
+// ErrNoMetadata is returned for streams that don't send ICY metadata
+var ErrNoMetadata = errors.New("stream has no ICY metadata")
+
+// icyRetryDelay is how long WatchTitles waits before reconnecting to a dropped stream
+const icyRetryDelay = 10 * time.Second
+
+// icyClient reads streams for their metadata only, so it has no overall timeout
+var icyClient = &http.Client{}
+
+// WatchTitles follows the ICY metadata Shoutcast and Icecast servers interleave
+// with the audio and calls onTitle whenever the station starts a new song.
+// It reconnects if the stream drops and returns when ctx is done or the
+// stream turns out to have no metadata.
+func WatchTitles(ctx context.Context, url string, onTitle func(string)) error {
+	var last string
+	for {
+		err := readTitles(ctx, url, func(title string) {
+			if title != last {
+				last = title
+				onTitle(title)
+			}
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+		if errors.Is(err, ErrNoMetadata) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(icyRetryDelay):
+		}
+	}
+}
+
+// readTitles reads one connection to the stream, reporting every title in its metadata
+func readTitles(ctx context.Context, url string, onTitle func(string)) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Icy-MetaData", "1")
+
+	resp, err := icyClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("stream returned %s", resp.Status)
+	}
+
+	// The metadata interval is the number of audio bytes between metadata blocks
+	interval, err := strconv.Atoi(resp.Header.Get("Icy-Metaint"))
+	if err != nil || interval <= 0 {
+		return ErrNoMetadata
+	}
+
+	// The metadata connection counts against the bot's download rate like any other download
+	rate, done := bandwidth.Default.Start(0)
+	defer done()
+	body := bufio.NewReader(bandwidth.Reader(resp.Body, rate))
+	for {
+		if _, err := io.CopyN(io.Discard, body, int64(interval)); err != nil {
+			return err
+		}
+		// One byte gives the block's length in units of 16 bytes
+		length, err := body.ReadByte()
+		if err != nil {
+			return err
+		}
+		if length == 0 {
+			continue
+		}
+		block := make([]byte, int(length)*16)
+		if _, err := io.ReadFull(body, block); err != nil {
+			return err
+		}
+		if title := StreamTitle(string(block)); title != "" {
+			onTitle(title)
+		}
+	}
+}
+
+// StreamTitle extracts the song from an ICY metadata block such as
+// StreamTitle='Artist - Song';StreamUrl='https://example.com';
+func StreamTitle(metadata string) string {
+	const key = "StreamTitle='"
+	start := strings.Index(metadata, key)
+	if start < 0 {
+		return ""
+	}
+	value := strings.TrimRight(metadata[start+len(key):], "\x00")
+
+	// Titles may contain quotes themselves, so the value ends where the next
+	// field starts or at the last quote
+	if end := strings.Index(value, "';Stream"); end >= 0 {
+		value = value[:end]
+	} else if end := strings.LastIndex(value, "'"); end >= 0 {
+		value = value[:end]
+	}
+	return strings.TrimSpace(value)
+}
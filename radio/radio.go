@@ -0,0 +1,205 @@
+// Package radio keeps the internet radio stations guilds can play with /radio:
+// a library of presets shared by every guild, which each guild can extend,
+// override or trim
+package radio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"discordbot/storage"
+)
+
+const collection = "radio_stations"
+
+// ErrNotFound is returned when a guild has no station with the requested name
+var ErrNotFound = errors.New("radio station not found")
+
+// Presets is the library shipped with the bot, used unless RADIO_PRESETS_FILE replaces it
+var Presets = map[string]string{
+	"lofi":      "https://ice1.somafm.com/fluid-128-mp3",
+	"jazz":      "https://ice1.somafm.com/sonicuniverse-128-mp3",
+	"classical": "https://stream.srg-ssr.ch/m/rsc_de/mp3_128",
+	"news":      "https://stream.live.vc.bbcmedia.co.uk/bbc_world_service",
+}
+
+// Station is a named radio stream
+type Station struct {
+	Name string
+	URL  string
+}
+
+// Store combines the preset library with each guild's own stations
+type Store struct {
+	store   storage.Store
+	library map[string]string
+	mu      sync.Mutex
+}
+
+// NewStore creates a station store offering library to every guild
+func NewStore(store storage.Store, library map[string]string) *Store {
+	normalized := make(map[string]string, len(library))
+	for name, url := range library {
+		normalized[Normalize(name)] = url
+	}
+	return &Store{store: store, library: normalized}
+}
+
+// LoadLibrary reads a preset library from a JSON file mapping station names
+// to stream URLs. An empty path returns the built-in presets.
+func LoadLibrary(path string) (map[string]string, error) {
+	if path == "" {
+		return Presets, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading radio presets: %v", err)
+	}
+	library := make(map[string]string)
+	if err := json.Unmarshal(data, &library); err != nil {
+		return nil, fmt.Errorf("error parsing radio presets %s: %v", path, err)
+	}
+	return library, nil
+}
+
+// Normalize returns the canonical form of a station name
+func Normalize(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// load returns a guild's own stations. An empty URL hides a preset.
+func (s *Store) load(guildID string) (map[string]string, error) {
+	stations := make(map[string]string)
+	err := s.store.Get(collection, guildID, &stations)
+	if err != nil && err != storage.ErrNotFound {
+		return nil, fmt.Errorf("error loading radio stations: %v", err)
+	}
+	return stations, nil
+}
+
+// merged returns the stations a guild can play. The caller must hold s.mu.
+func (s *Store) merged(guildID string) (map[string]string, error) {
+	own, err := s.load(guildID)
+	if err != nil {
+		return nil, err
+	}
+	stations := make(map[string]string, len(s.library)+len(own))
+	for name, url := range s.library {
+		stations[name] = url
+	}
+	for name, url := range own {
+		if url == "" {
+			delete(stations, name)
+		} else {
+			stations[name] = url
+		}
+	}
+	return stations, nil
+}
+
+// Get returns a guild's station by name
+func (s *Store) Get(guildID, name string) (Station, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stations, err := s.merged(guildID)
+	if err != nil {
+		return Station{}, err
+	}
+	url, ok := stations[Normalize(name)]
+	if !ok {
+		return Station{}, ErrNotFound
+	}
+	return Station{Name: Normalize(name), URL: url}, nil
+}
+
+// List returns the stations a guild can play, sorted by name
+func (s *Store) List(guildID string) ([]Station, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stations, err := s.merged(guildID)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Station, 0, len(stations))
+	for name, url := range stations {
+		list = append(list, Station{Name: name, URL: url})
+	}
+	sort.Slice(list, func(a, b int) bool { return list[a].Name < list[b].Name })
+	return list, nil
+}
+
+// Set adds a station to a guild or points one of its stations, presets
+// included, at another stream
+func (s *Store) Set(guildID, name, url string) error {
+	name = Normalize(name)
+	if name == "" {
+		return fmt.Errorf("station name cannot be empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	own, err := s.load(guildID)
+	if err != nil {
+		return err
+	}
+	own[name] = url
+	return s.store.Put(collection, guildID, own)
+}
+
+// Remove takes a station off a guild's list, hiding it if it's a preset.
+// It reports whether the guild had the station.
+func (s *Store) Remove(guildID, name string) (bool, error) {
+	name = Normalize(name)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	own, err := s.load(guildID)
+	if err != nil {
+		return false, err
+	}
+	url, isOwn := own[name]
+	_, isPreset := s.library[name]
+	// Hidden presets are stored as an empty URL
+	listed := (isOwn && url != "") || (isPreset && !isOwn)
+	if !listed {
+		return false, nil
+	}
+
+	if isPreset {
+		own[name] = ""
+	} else {
+		delete(own, name)
+	}
+	return true, s.store.Put(collection, guildID, own)
+}
+
+// Own returns the stations a guild added or changed itself. Presets the
+// guild hid are listed with an empty URL.
+func (s *Store) Own(guildID string) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(guildID)
+}
+
+// Replace swaps a guild's own stations for the given ones, in the format returned by Own
+func (s *Store) Replace(guildID string, stations map[string]string) error {
+	normalized := make(map[string]string, len(stations))
+	for name, url := range stations {
+		if name = Normalize(name); name != "" {
+			normalized[name] = url
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.store.Put(collection, guildID, normalized)
+}
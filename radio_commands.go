@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"discordbot/audio"
+	"discordbot/config"
+	"discordbot/notify"
+	"discordbot/radio"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// radioStore holds the preset library and each guild's radio stations
+var radioStore *radio.Store
+
+// setupRadio loads the radio preset library, from RADIO_PRESETS_FILE if set
+func setupRadio() {
+	library, err := radio.LoadLibrary(config.String("RADIO_PRESETS_FILE", ""))
+	if err != nil {
+		log.Printf("Warning: %v, using the built-in radio presets", err)
+		library = radio.Presets
+	}
+	radioStore = radio.NewStore(store, library)
+}
+
+// handleRadio handles the /radio command and its subcommands
+func handleRadio(s *discordgo.Session, i *discordgo.InteractionCreate, vi *audio.VoiceInstance) {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		editResponse(s, i, tr(i, "command.choose_subcommand"))
+		return
+	}
+
+	subcommand := options[0]
+	var name, url string
+	position := positionEnd
+	for _, option := range subcommand.Options {
+		switch option.Name {
+		case "name":
+			name = option.StringValue()
+		case "url":
+			url = strings.TrimSpace(option.StringValue())
+		case "position":
+			position = option.StringValue()
+		}
+	}
+
+	// The station list is shared by the whole server
+	if (subcommand.Name == "set" || subcommand.Name == "remove") && !isDJ(s, i) {
+		errorResponse(s, i, tr(i, "radio.dj_only"))
+		return
+	}
+
+	switch subcommand.Name {
+	case "play":
+		station, err := radioStore.Get(i.GuildID, name)
+		if err == radio.ErrNotFound {
+			errorResponse(s, i, tr(i, "radio.not_found", name))
+			return
+		}
+		if err != nil {
+			errorResponse(s, i, tr(i, "error", err))
+			return
+		}
+
+		if !joinUserChannel(s, i, vi) {
+			return
+		}
+
+		// Radio streams never end, so they're played as they air like live videos
+		track := newTrack(i, station.URL)
+		track.Title = tr(i, "radio.title", station.Name)
+		track.Live = true
+		enqueueTracks(s, i, vi, []*audio.Track{track}, position)
+
+	case "list":
+		stations, err := radioStore.List(i.GuildID)
+		if err != nil {
+			errorResponse(s, i, tr(i, "error", err))
+			return
+		}
+		if len(stations) == 0 {
+			editResponse(s, i, tr(i, "radio.none"))
+			return
+		}
+
+		var msg strings.Builder
+		msg.WriteString(tr(i, "radio.header") + "\n")
+		for _, station := range stations {
+			fmt.Fprintf(&msg, "`%s` → <%s>\n", station.Name, station.URL)
+		}
+		editResponse(s, i, truncateMessage(msg.String()))
+
+	case "set":
+		if !isLink(url) || isYouTubeURL(url) {
+			errorResponse(s, i, tr(i, "radio.bad_url"))
+			return
+		}
+		if err := radioStore.Set(i.GuildID, name, url); err != nil {
+			errorResponse(s, i, tr(i, "error", err))
+			return
+		}
+		editResponse(s, i, tr(i, "radio.saved", radio.Normalize(name)))
+
+	case "remove":
+		removed, err := radioStore.Remove(i.GuildID, name)
+		if err != nil {
+			errorResponse(s, i, tr(i, "error", err))
+			return
+		}
+		if !removed {
+			errorResponse(s, i, tr(i, "radio.not_found", name))
+			return
+		}
+		editResponse(s, i, tr(i, "radio.removed", radio.Normalize(name)))
+	}
+}
+
+// watchRadioTitles keeps the now playing message of a radio station showing
+// the song the station announces in its ICY metadata, until stop is called.
+// stop returns once the watcher has made its last edit, so the message the
+// player shows next isn't overwritten by a late title.
+func watchRadioTitles(vi *audio.VoiceInstance, message *notify.Message, track *audio.Track, url string) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		err := radio.WatchTitles(ctx, url, func(title string) {
+			if ctx.Err() != nil {
+				return
+			}
+			notifier.Edit(message, trGuild(vi.GuildID, "player.now_playing_radio",
+				trackLabel(track, url), markdownEscaper.Replace(title)))
+		})
+		if err != nil && !errors.Is(err, radio.ErrNoMetadata) {
+			log.Printf("Stopped reading radio titles of %s: %v", url, err)
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-stopped
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// enqueueWindow is the period the per-user enqueue limit counts commands over
+const enqueueWindow = time.Minute
+
+// enqueueCommands add tracks to the queue and count towards the enqueue rate limit
+var enqueueCommands = map[string]bool{
+	"play":          true,
+	"playmany":      true,
+	"queue add":     true,
+	"queue import":  true,
+	"playlist play": true,
+	"radio play":    true,
+	"trending pick": true, // Picking tracks from a /trending menu
+}
+
+// cooldownCommands change playback for everyone in the channel, so each
+// member has to wait out the guild's cooldown between them
+var cooldownCommands = map[string]bool{
+	"replay":      true,
+	"nextchapter": true,
+	"repeat":      true,
+	"karaoke":     true,
+	"autoplay":    true,
+	"say":         true,
+	"sound play":  true,
+}
+
+// rateLimiter tracks recent commands per guild member
+type rateLimiter struct {
+	mu        sync.Mutex
+	enqueues  map[string][]time.Time // Recent enqueue commands by guild/user
+	cooldowns map[string]time.Time   // When each guild/user's cooldown ends
+	swept     time.Time
+}
+
+var rateLimits = &rateLimiter{
+	enqueues:  make(map[string][]time.Time),
+	cooldowns: make(map[string]time.Time),
+}
+
+// allowEnqueue records an enqueue command if the member ran fewer than limit
+// in the last minute. Otherwise it returns how long they have to wait.
+func (r *rateLimiter) allowEnqueue(key string, limit int, now time.Time) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sweep(now)
+
+	recent := pruneBefore(r.enqueues[key], now.Add(-enqueueWindow))
+	if len(recent) >= limit {
+		r.enqueues[key] = recent
+		return recent[0].Add(enqueueWindow).Sub(now)
+	}
+	r.enqueues[key] = append(recent, now)
+	return 0
+}
+
+// allowCooldown starts the member's cooldown if it isn't running yet.
+// Otherwise it returns how long it still runs.
+func (r *rateLimiter) allowCooldown(key string, cooldown time.Duration, now time.Time) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sweep(now)
+
+	if until := r.cooldowns[key]; until.After(now) {
+		return until.Sub(now)
+	}
+	r.cooldowns[key] = now.Add(cooldown)
+	return 0
+}
+
+// sweep forgets members whose limits ran out, at most once per window.
+// The caller must hold r.mu.
+func (r *rateLimiter) sweep(now time.Time) {
+	if now.Sub(r.swept) < enqueueWindow {
+		return
+	}
+	r.swept = now
+	for key, times := range r.enqueues {
+		if len(pruneBefore(times, now.Add(-enqueueWindow))) == 0 {
+			delete(r.enqueues, key)
+		}
+	}
+	for key, until := range r.cooldowns {
+		if !until.After(now) {
+			delete(r.cooldowns, key)
+		}
+	}
+}
+
+// pruneBefore drops the times before cutoff from the sorted times
+func pruneBefore(times []time.Time, cutoff time.Time) []time.Time {
+	for len(times) > 0 && times[0].Before(cutoff) {
+		times = times[1:]
+	}
+	return times
+}
+
+// commandPath returns a command with its subcommand, e.g. "queue add"
+func commandPath(data discordgo.ApplicationCommandInteractionData) string {
+	path := []string{data.Name}
+	options := data.Options
+	for len(options) > 0 && (options[0].Type == discordgo.ApplicationCommandOptionSubCommand ||
+		options[0].Type == discordgo.ApplicationCommandOptionSubCommandGroup) {
+		path = append(path, options[0].Name)
+		options = options[0].Options
+	}
+	return strings.Join(path, " ")
+}
+
+// commandName returns the top-level command of a command path
+func commandName(path string) string {
+	name, _, _ := strings.Cut(path, " ")
+	return name
+}
+
+// checkRateLimit enforces the guild's enqueue rate limit and command cooldown
+// before a command runs. If the member has to slow down they are told so
+// privately and false is returned. DJs aren't limited.
+func checkRateLimit(s *discordgo.Session, i *discordgo.InteractionCreate, path string) bool {
+	// Plugin commands may queue tracks, so they count as enqueues
+	enqueue := enqueueCommands[path] || pluginCommands[path] != nil
+	if !enqueue && !cooldownCommands[path] {
+		return true
+	}
+
+	guild := settingsStore.Get(i.GuildID)
+	if guild.EnqueuesPerMinute <= 0 && guild.CommandCooldownSeconds <= 0 {
+		return true
+	}
+	if isDJ(s, i) {
+		return true
+	}
+
+	key := i.GuildID + "/" + i.Member.User.ID
+	now := time.Now()
+	var wait time.Duration
+	var msg string
+	switch {
+	case enqueue && guild.EnqueuesPerMinute > 0:
+		wait = rateLimits.allowEnqueue(key, guild.EnqueuesPerMinute, now)
+		msg = tr(i, "ratelimit.enqueues", guild.EnqueuesPerMinute, now.Add(wait).Unix())
+	case cooldownCommands[path] && guild.CommandCooldownSeconds > 0:
+		wait = rateLimits.allowCooldown(key, time.Duration(guild.CommandCooldownSeconds)*time.Second, now)
+		msg = tr(i, "ratelimit.cooldown", "/"+path, now.Add(wait).Unix())
+	}
+	if wait <= 0 {
+		return true
+	}
+
+	usage.CommandError(commandName(path))
+	noteCommandError(i, msg)
+	respondEphemeral(s, i, msg)
+	return false
+}
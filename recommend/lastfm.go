@@ -0,0 +1,118 @@
+package recommend
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"discordbot/audio"
+	"discordbot/audio/youtube"
+)
+
+const (
+	lastFMEndpoint = "https://ws.audioscrobbler.com/2.0/"
+	lastFMLimit    = 30
+)
+
+// LastFM recommends tracks Last.fm lists as similar and plays them from YouTube
+type LastFM struct {
+	APIKey  string
+	YouTube *youtube.Client
+	HTTP    *http.Client
+}
+
+// NewLastFM creates a Last.fm recommender using the given API key
+func NewLastFM(apiKey string, yt *youtube.Client) *LastFM {
+	return &LastFM{
+		APIKey:  apiKey,
+		YouTube: yt,
+		HTTP:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// NextTrack picks a track Last.fm considers similar to the most recently played one
+func (r *LastFM) NextTrack(history []*audio.Track, seeds []string) (*audio.Track, error) {
+	query, err := seedQuery(r.YouTube, history, seeds)
+	if err != nil {
+		return nil, err
+	}
+
+	// Video titles are free-form, so let Last.fm work out artist and track first
+	var search struct {
+		Results struct {
+			TrackMatches struct {
+				Track []struct {
+					Name   string `json:"name"`
+					Artist string `json:"artist"`
+				} `json:"track"`
+			} `json:"trackmatches"`
+		} `json:"results"`
+	}
+	if err := r.call(url.Values{"method": {"track.search"}, "track": {query}, "limit": {"1"}}, &search); err != nil {
+		return nil, err
+	}
+	matches := search.Results.TrackMatches.Track
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no Last.fm track matches %q", query)
+	}
+
+	var similar struct {
+		SimilarTracks struct {
+			Track []struct {
+				Name   string `json:"name"`
+				Artist struct {
+					Name string `json:"name"`
+				} `json:"artist"`
+			} `json:"track"`
+		} `json:"similartracks"`
+	}
+	params := url.Values{
+		"method":      {"track.getsimilar"},
+		"artist":      {matches[0].Artist},
+		"track":       {matches[0].Name},
+		"autocorrect": {"1"},
+		"limit":       {fmt.Sprint(lastFMLimit)},
+	}
+	if err := r.call(params, &similar); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(similar.SimilarTracks.Track))
+	for _, track := range similar.SimilarTracks.Track {
+		names = append(names, fmt.Sprintf("%s - %s", track.Artist.Name, track.Name))
+	}
+
+	name, err := pickName(history, names)
+	if err != nil {
+		return nil, err
+	}
+	return findOnYouTube(r.YouTube, name)
+}
+
+// call performs a Last.fm API request and decodes the JSON response into v
+func (r *LastFM) call(params url.Values, v interface{}) error {
+	params.Set("api_key", r.APIKey)
+	params.Set("format", "json")
+
+	resp, err := r.HTTP.Get(lastFMEndpoint + "?" + params.Encode())
+	if err != nil {
+		return fmt.Errorf("Last.fm request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var apiErr struct {
+		Error   int    `json:"error"`
+		Message string `json:"message"`
+	}
+	if resp.StatusCode != http.StatusOK {
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("Last.fm returned %s: %s", resp.Status, apiErr.Message)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode Last.fm response: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package recommend
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"discordbot/audio"
+	"discordbot/audio/youtube"
+)
+
+// Engine names a guild can choose between
+const (
+	EngineSeed    = "seed"
+	EngineYouTube = "youtube"
+	EngineSpotify = "spotify"
+	EngineLastFM  = "lastfm"
+)
+
+// ErrNoRecommendation is returned when an engine has nothing new to offer
+var ErrNoRecommendation = errors.New("no recommendation found")
+
+// Recommender picks the next track for autoplay. History holds the guild's
+// recently played tracks, oldest first; seeds are the guild's configured seed
+// terms or URLs and are used when there is no history to go on.
+type Recommender interface {
+	NextTrack(history []*audio.Track, seeds []string) (*audio.Track, error)
+}
+
+// played reports whether a track with the given URL or title was played recently
+func played(history []*audio.Track, url, title string) bool {
+	title = strings.ToLower(title)
+	for _, track := range history {
+		if url != "" && track.URL == url {
+			return true
+		}
+		if title != "" && track.Title != "" && strings.Contains(strings.ToLower(track.Title), title) {
+			return true
+		}
+	}
+	return false
+}
+
+// pickTrack returns a random candidate that wasn't played recently
+func pickTrack(history []*audio.Track, candidates []*audio.Track) (*audio.Track, error) {
+	var fresh []*audio.Track
+	for _, candidate := range candidates {
+		if !played(history, candidate.URL, candidate.Title) {
+			fresh = append(fresh, candidate)
+		}
+	}
+	if len(fresh) == 0 {
+		return nil, ErrNoRecommendation
+	}
+	return fresh[rand.Intn(len(fresh))], nil
+}
+
+// pickName returns a random "Artist - Title" candidate that wasn't played recently
+func pickName(history []*audio.Track, names []string) (string, error) {
+	var fresh []string
+	for _, name := range names {
+		// YouTube titles rarely match exactly, so compare the title part only
+		title := name
+		if idx := strings.Index(name, " - "); idx >= 0 {
+			title = name[idx+3:]
+		}
+		if !played(history, "", title) {
+			fresh = append(fresh, name)
+		}
+	}
+	if len(fresh) == 0 {
+		return "", ErrNoRecommendation
+	}
+	return fresh[rand.Intn(len(fresh))], nil
+}
+
+// seedQuery returns the text to base recommendations on: the title of the
+// most recently played track, or else the first usable seed
+func seedQuery(yt *youtube.Client, history []*audio.Track, seeds []string) (string, error) {
+	for idx := len(history) - 1; idx >= 0; idx-- {
+		if history[idx].Title != "" {
+			return history[idx].Title, nil
+		}
+	}
+
+	for _, seed := range seeds {
+		if !strings.HasPrefix(seed, "http") {
+			return seed, nil
+		}
+		if youtube.IsPlaylistURL(seed) {
+			continue
+		}
+		info, err := yt.GetVideoInfo(seed)
+		if err == nil && info.Title != "" {
+			return info.Title, nil
+		}
+	}
+	return "", fmt.Errorf("nothing has played yet and no autoplay seed is configured")
+}
+
+// findOnYouTube returns the first YouTube result for query
+func findOnYouTube(yt *youtube.Client, query string) (*audio.Track, error) {
+	entries, err := yt.Search(query, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no YouTube video found for %q", query)
+	}
+	return entryTrack(entries[0]), nil
+}
+
+// entryTrack converts a YouTube search or playlist entry to a track
+func entryTrack(entry youtube.Entry) *audio.Track {
+	return &audio.Track{URL: entry.URL, Title: entry.Title, Duration: entry.Duration}
+}
@@ -0,0 +1,68 @@
+package recommend
+
+import (
+	"fmt"
+	"strings"
+
+	"discordbot/audio"
+	"discordbot/audio/youtube"
+)
+
+// seedSearchSize is how many search results a genre seed contributes
+const seedSearchSize = 25
+
+// Seeded only draws from the guild's seeds: the videos of seed playlists,
+// seed videos themselves and search results for genre seeds
+type Seeded struct {
+	YouTube *youtube.Client
+}
+
+// NewSeeded creates a seed-only recommender
+func NewSeeded(yt *youtube.Client) *Seeded {
+	return &Seeded{YouTube: yt}
+}
+
+// NextTrack picks a random track from the seeds that wasn't played recently
+func (r *Seeded) NextTrack(history []*audio.Track, seeds []string) (*audio.Track, error) {
+	if len(seeds) == 0 {
+		return nil, fmt.Errorf("no autoplay seed is configured")
+	}
+
+	var candidates []*audio.Track
+	for _, seed := range seeds {
+		switch {
+		case youtube.IsPlaylistURL(seed):
+			entries, err := r.YouTube.PlaylistEntries(seed, 0)
+			if err != nil {
+				return nil, fmt.Errorf("error loading seed playlist: %v", err)
+			}
+			for _, entry := range entries {
+				candidates = append(candidates, entryTrack(entry))
+			}
+		case strings.HasPrefix(seed, "http"):
+			candidates = append(candidates, &audio.Track{URL: seed})
+		default:
+			entries, err := r.YouTube.Search(seed+" music", seedSearchSize)
+			if err != nil {
+				return nil, fmt.Errorf("error searching for %q: %v", seed, err)
+			}
+			for _, entry := range entries {
+				candidates = append(candidates, entryTrack(entry))
+			}
+		}
+	}
+
+	track, err := pickTrack(history, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	// Seed videos are bare URLs, look up their title for the announcements
+	if track.Title == "" {
+		if info, err := r.YouTube.GetVideoInfo(track.URL); err == nil {
+			track.Title = info.Title
+			track.Duration = info.Duration
+		}
+	}
+	return track, nil
+}
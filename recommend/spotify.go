@@ -0,0 +1,64 @@
+package recommend
+
+import (
+	"discordbot/audio"
+	"discordbot/audio/spotify"
+	"discordbot/audio/youtube"
+)
+
+// spotifyLimit is how many Spotify recommendations are requested at a time
+const spotifyLimit = 20
+
+// Spotify recommends tracks using Spotify's recommendations and plays them from YouTube
+type Spotify struct {
+	Client  *spotify.Client
+	YouTube *youtube.Client
+}
+
+// NewSpotify creates a Spotify recommender
+func NewSpotify(client *spotify.Client, yt *youtube.Client) *Spotify {
+	return &Spotify{Client: client, YouTube: yt}
+}
+
+// NextTrack picks a Spotify recommendation for the most recently played track.
+// Tracks that came from Spotify seed the recommendations directly; anything
+// else is first matched to a Spotify track by its title.
+func (r *Spotify) NextTrack(history []*audio.Track, seeds []string) (*audio.Track, error) {
+	var names []string
+	if trackID := r.SeedTrackID(history, seeds); trackID != "" {
+		var err error
+		if names, err = r.Client.TrackRecommendations(trackID, spotifyLimit); err != nil {
+			return nil, err
+		}
+	} else {
+		query, err := seedQuery(r.YouTube, history, seeds)
+		if err != nil {
+			return nil, err
+		}
+		if names, err = r.Client.Recommendations(query, spotifyLimit); err != nil {
+			return nil, err
+		}
+	}
+
+	name, err := pickName(history, names)
+	if err != nil {
+		return nil, err
+	}
+	return findOnYouTube(r.YouTube, name)
+}
+
+// SeedTrackID returns the Spotify track ID of the most recently played track,
+// or of the first Spotify seed if nothing has played yet. It is empty if the
+// recommendations have to start from something that didn't come from Spotify.
+func (r *Spotify) SeedTrackID(history []*audio.Track, seeds []string) string {
+	if len(history) > 0 {
+		trackID, _ := r.Client.GetTrackID(history[len(history)-1].URL)
+		return trackID
+	}
+	for _, seed := range seeds {
+		if trackID, err := r.Client.GetTrackID(seed); err == nil {
+			return trackID
+		}
+	}
+	return ""
+}
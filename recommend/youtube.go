@@ -0,0 +1,58 @@
+package recommend
+
+import (
+	"fmt"
+
+	"discordbot/audio"
+	"discordbot/audio/youtube"
+)
+
+// mixSize is how many videos of a YouTube mix are considered
+const mixSize = 25
+
+// YouTube recommends videos from the mix YouTube generates for the last played video
+type YouTube struct {
+	Client *youtube.Client
+}
+
+// NewYouTube creates a YouTube related-video recommender
+func NewYouTube(client *youtube.Client) *YouTube {
+	return &YouTube{Client: client}
+}
+
+// NextTrack picks a video related to the most recently played track
+func (r *YouTube) NextTrack(history []*audio.Track, seeds []string) (*audio.Track, error) {
+	videoID := r.lastVideoID(history)
+	if videoID == "" {
+		// Nothing to relate to yet, start from the seeds instead
+		query, err := seedQuery(r.Client, history, seeds)
+		if err != nil {
+			return nil, err
+		}
+		return findOnYouTube(r.Client, query)
+	}
+
+	mix := fmt.Sprintf("https://www.youtube.com/watch?v=%s&list=RD%s", videoID, videoID)
+	entries, err := r.Client.PlaylistEntries(mix, mixSize)
+	if err != nil {
+		return nil, fmt.Errorf("error loading related videos: %v", err)
+	}
+
+	candidates := make([]*audio.Track, 0, len(entries))
+	for _, entry := range entries {
+		if entry.ID != videoID {
+			candidates = append(candidates, entryTrack(entry))
+		}
+	}
+	return pickTrack(history, candidates)
+}
+
+// lastVideoID returns the video ID of the most recently played YouTube track
+func (r *YouTube) lastVideoID(history []*audio.Track) string {
+	for idx := len(history) - 1; idx >= 0; idx-- {
+		if id, err := r.Client.GetVideoID(history[idx].URL); err == nil && id != "" {
+			return id
+		}
+	}
+	return ""
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"discordbot/audio/cache"
+	"discordbot/config"
+)
+
+// setupConfigReload re-reads the reloadable settings whenever the
+// configuration is reloaded
+func setupConfigReload() {
+	config.OnReload(func() {
+		audioCache.SetMaxBytes(cache.DefaultMaxBytes())
+		go evictCache()
+	})
+	config.OnReload(setupAutoplayAvoidance)
+	config.OnReload(setupDownloadRate)
+	config.OnReload(setupInvidious)
+	config.OnReload(func() {
+		youtubeClient.Cookies.Reload()
+		youtubeClient.Clients.Reload()
+	})
+}
+
+// watchReload reloads the configuration on SIGHUP until ctx is cancelled
+func watchReload(ctx context.Context) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+	for {
+		select {
+		case <-hangup:
+			log.Println("Received SIGHUP, reloading configuration")
+			if err := config.Reload(); err != nil {
+				log.Printf("Failed to reload configuration: %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"discordbot/audio"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// handleReplay restarts the current track, reusing its cached file or stream,
+// or puts it at the front of the queue to play again once it finishes
+func handleReplay(s *discordgo.Session, i *discordgo.InteractionCreate, vi *audio.VoiceInstance) {
+	after := false
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "after" {
+			after = option.BoolValue()
+		}
+	}
+
+	if !after {
+		// Seeking to the start restarts the track without counting the
+		// interrupted playback as a play
+		current, ok := seekTo(vi, 0)
+		if !ok {
+			errorResponse(s, i, tr(i, "replay.nothing_playing"))
+			return
+		}
+		editResponse(s, i, tr(i, "replay.restarted", current.DisplayName()))
+		return
+	}
+
+	vi.Mu.Lock()
+	current := vi.Current
+	if !vi.IsPlaying {
+		current = nil
+	}
+	vi.Mu.Unlock()
+	if current == nil {
+		errorResponse(s, i, tr(i, "replay.nothing_playing"))
+		return
+	}
+
+	vi.InsertIntoQueue(0, current)
+	editResponse(s, i, tr(i, "replay.queued", current.DisplayName()))
+}
+
+// maxLoops caps how often /repeat times can play a track again
+const maxLoops = 100
+
+// loopsMin is the smallest /repeat times value; MinValue takes a pointer
+var loopsMin = 0.0
+
+// handleRepeatTimes makes the current track play a number of more times before
+// the queue continues. The count lives on the track, so it follows the track
+// through seeks and replays.
+func handleRepeatTimes(s *discordgo.Session, i *discordgo.InteractionCreate, vi *audio.VoiceInstance, times int) {
+	vi.Mu.Lock()
+	current := vi.Current
+	if !vi.IsPlaying {
+		current = nil
+	}
+	if current != nil {
+		current.Loops = times
+	}
+	vi.Mu.Unlock()
+	if current == nil {
+		errorResponse(s, i, tr(i, "replay.nothing_playing"))
+		return
+	}
+
+	if times == 0 {
+		editResponse(s, i, tr(i, "repeat.times_cleared", current.DisplayName()))
+		return
+	}
+	editResponse(s, i, tr(i, "repeat.times", current.DisplayName(), times))
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ephemeralCommands answer only the user who ran them, since their output
+// is either personal or server configuration that shouldn't clutter the channel
+var ephemeralCommands = map[string]bool{
+	"settings":  true,
+	"follow":    true,
+	"alias":     true,
+	"diagnose":  true,
+	"privacy":   true,
+	"lookup":    true,
+	"blocklist": true,
+	"auditlog":  true,
+	"spotify":   true,
+}
+
+// isEphemeralCommand reports whether the interaction's command is answered ephemerally
+func isEphemeralCommand(i *discordgo.InteractionCreate) bool {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return false
+	}
+	return ephemeralCommands[i.ApplicationCommandData().Name]
+}
+
+// deferResponse acknowledges a slash command so it can be answered later.
+// Sensitive commands are deferred as ephemeral so their whole output stays private.
+func deferResponse(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	var flags discordgo.MessageFlags
+	if isEphemeralCommand(i) {
+		flags = discordgo.MessageFlagsEphemeral
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: flags,
+		},
+	})
+}
+
+// editResponse replaces the deferred interaction response with content
+func editResponse(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	_, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content: &content,
+	})
+	if err != nil {
+		log.Printf("Failed to update interaction: %v", err)
+	}
+}
+
+// editEmbed replaces the deferred interaction response with an embed
+func editEmbed(s *discordgo.Session, i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed) {
+	_, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	})
+	if err != nil {
+		log.Printf("Failed to update interaction: %v", err)
+	}
+}
+
+// errorResponse reports a failed command to its author only. A public deferred
+// response can't be made ephemeral after the fact, so it is removed and the
+// error is sent as an ephemeral follow-up instead.
+func errorResponse(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	if i.Type == discordgo.InteractionApplicationCommand {
+		usage.CommandError(i.ApplicationCommandData().Name)
+		noteCommandError(i, content)
+	}
+
+	if isEphemeralCommand(i) {
+		editResponse(s, i, content)
+		return
+	}
+
+	if err := s.InteractionResponseDelete(i.Interaction); err != nil {
+		log.Printf("Failed to delete interaction response: %v", err)
+	}
+	_, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Content: content,
+		Flags:   discordgo.MessageFlagsEphemeral,
+	})
+	if err != nil {
+		log.Printf("Failed to send error follow-up: %v", err)
+	}
+}
+
+// respondEphemeral answers an interaction with a message only its author can see
+func respondEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to respond to interaction: %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"discordbot/audio"
+	"discordbot/sessions"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxResumeAge is how old a saved session may be and still be resumed on start.
+// After a longer outage rejoining out of the blue would surprise the channel.
+const maxResumeAge = 30 * time.Minute
+
+// resumeOffsets holds where restored or seeked tracks pick up, keyed by guild
+var resumeOffsets = struct {
+	sync.Mutex
+	offsets map[string]resumeOffset
+}{offsets: make(map[string]resumeOffset)}
+
+// resumeOffset is the position a restored track resumes from
+type resumeOffset struct {
+	track    *audio.Track
+	position time.Duration
+}
+
+// takeResumeOffset returns where track should start playing if it was
+// restored or seeked, and whether it was. A seek to the start gives 0 and true.
+func takeResumeOffset(guildID string, track *audio.Track) (time.Duration, bool) {
+	resumeOffsets.Lock()
+	defer resumeOffsets.Unlock()
+
+	offset, ok := resumeOffsets.offsets[guildID]
+	if !ok {
+		return 0, false
+	}
+	delete(resumeOffsets.offsets, guildID)
+	if offset.track != track {
+		return 0, false
+	}
+	return offset.position, true
+}
+
+// restarting reports whether track is about to play again from a seek, so
+// the playback it interrupted isn't counted as a play of its own
+func restarting(guildID string, track *audio.Track) bool {
+	resumeOffsets.Lock()
+	defer resumeOffsets.Unlock()
+	offset, ok := resumeOffsets.offsets[guildID]
+	return ok && offset.track == track
+}
+
+// seekTo restarts the current track at position by putting it back at the
+// front of the queue with a resume offset and skipping to it. It returns false
+// if nothing is playing.
+func seekTo(vi *audio.VoiceInstance, position time.Duration) (*audio.Track, bool) {
+	vi.Mu.Lock()
+	current := vi.Current
+	if !vi.IsPlaying {
+		current = nil
+	}
+	vi.Mu.Unlock()
+	if current == nil {
+		return nil, false
+	}
+
+	resumeOffsets.Lock()
+	resumeOffsets.offsets[vi.GuildID] = resumeOffset{track: current, position: position}
+	resumeOffsets.Unlock()
+
+	vi.InsertIntoQueue(0, current)
+	vi.Skip()
+	return current, true
+}
+
+// resumeSessions rejoins the voice channels saved by the last controlled
+// shutdown and continues playback where it stopped
+func resumeSessions(s *discordgo.Session) {
+	// Rejoining through Lavalink needs the node connection first
+	if lavalinkNode != nil {
+		deadline := time.Now().Add(time.Minute)
+		for !lavalinkNode.Connected() {
+			if time.Now().After(deadline) {
+				log.Println("Not resuming sessions: the Lavalink node isn't connected")
+				return
+			}
+			time.Sleep(time.Second)
+		}
+	}
+
+	saved, err := sessionStore.List()
+	if err != nil {
+		log.Printf("Failed to load saved sessions: %v", err)
+		return
+	}
+
+	for _, session := range saved {
+		if !session.Resume {
+			continue
+		}
+
+		// A session is resumed at most once, even if rejoining fails
+		if err := sessionStore.Delete(session.GuildID); err != nil {
+			log.Printf("Failed to delete saved session for guild %s: %v", session.GuildID, err)
+		}
+		if time.Since(session.SavedAt) > maxResumeAge {
+			log.Printf("Not resuming session for guild %s saved at %s", session.GuildID, session.SavedAt.Format(time.RFC3339))
+			continue
+		}
+
+		if err := resumeSession(s, session); err != nil {
+			log.Printf("Failed to resume session for guild %s: %v", session.GuildID, err)
+		}
+	}
+}
+
+// resumeSession rejoins a saved session's voice channel and restarts its queue
+func resumeSession(s *discordgo.Session, session *sessions.Session) error {
+	vi := voiceManager.GetVoiceInstance(session.GuildID)
+	if err := vi.Join(s, session.VoiceChannelID); err != nil {
+		return err
+	}
+
+	vi.Mu.Lock()
+	vi.Repeat = session.Repeat
+	vi.Autoplay = session.Autoplay
+	vi.TextChannelID = session.TextChannelID
+	vi.Mu.Unlock()
+
+	tracks := session.Queue
+	if session.Current != nil {
+		tracks = append([]*audio.Track{session.Current}, tracks...)
+
+		resumeOffsets.Lock()
+		resumeOffsets.offsets[session.GuildID] = resumeOffset{track: session.Current, position: session.Position}
+		resumeOffsets.Unlock()
+	}
+	if len(tracks) == 0 {
+		return nil
+	}
+	for _, track := range tracks {
+		vi.AddToQueue(track)
+	}
+
+	log.Printf("Resuming session for guild %s with %d track(s)", session.GuildID, len(tracks))
+	notifier.Send(session.TextChannelID, trGuild(session.GuildID, "shutdown.resumed"))
+	go playNextInQueue(s, session.TextChannelID, vi)
+	return nil
+}
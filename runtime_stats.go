@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"discordbot/audio"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// versionTimeout bounds how long asking yt-dlp or FFmpeg for its version may take
+const versionTimeout = 5 * time.Second
+
+// botStarted is when the process started, for the uptime in /stats runtime
+var botStarted = time.Now()
+
+// handleRuntimeStats shows how the bot process is doing, for self-hosters debugging performance
+func handleRuntimeStats(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	s.State.RLock()
+	guilds := len(s.State.Guilds)
+	s.State.RUnlock()
+
+	voiceManager.Mu.Lock()
+	instances := make([]*audio.VoiceInstance, 0, len(voiceManager.Instances))
+	for _, vi := range voiceManager.Instances {
+		instances = append(instances, vi)
+	}
+	voiceManager.Mu.Unlock()
+	connected := 0
+	for _, vi := range instances {
+		if vi.Connected() {
+			connected++
+		}
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	cacheSize := tr(i, "lookup.unknown")
+	if size, err := audioCache.Size(); err == nil {
+		cacheSize = formatBytes(size) + " / " + formatBytes(audioCache.MaxBytes())
+	}
+
+	cookieJars := tr(i, "runtime.cookies_none")
+	if working, total := youtubeClient.Cookies.Counts(); total > 0 {
+		cookieJars = tr(i, "runtime.cookies_value", working, total)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: tr(i, "runtime.title"),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: tr(i, "runtime.uptime"), Value: formatUptime(time.Since(botStarted)), Inline: true},
+			{Name: tr(i, "runtime.guilds"), Value: strconv.Itoa(guilds), Inline: true},
+			{Name: tr(i, "runtime.voice"), Value: strconv.Itoa(connected), Inline: true},
+			{Name: tr(i, "runtime.memory"), Value: tr(i, "runtime.memory_value", formatBytes(int64(mem.HeapAlloc)), formatBytes(int64(mem.Sys))), Inline: true},
+			{Name: tr(i, "runtime.goroutines"), Value: strconv.Itoa(runtime.NumGoroutine()), Inline: true},
+			{Name: tr(i, "runtime.latency"), Value: s.HeartbeatLatency().Round(time.Millisecond).String(), Inline: true},
+			{Name: tr(i, "runtime.cache"), Value: cacheSize, Inline: true},
+			{Name: tr(i, "runtime.cookies"), Value: cookieJars, Inline: true},
+			{Name: "yt-dlp", Value: orUnknown(i, toolVersion("yt-dlp", "--version")), Inline: true},
+			{Name: "FFmpeg", Value: orUnknown(i, ffmpegVersion()), Inline: true},
+		},
+		Footer: &discordgo.MessageEmbedFooter{Text: runtime.Version()},
+	}
+	editEmbed(s, i, embed)
+}
+
+// toolVersion returns the first line an external program prints for its version flag, or empty if it can't be run
+func toolVersion(name string, args ...string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), versionTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return ""
+	}
+	line, _, _ := strings.Cut(string(output), "\n")
+	return strings.TrimSpace(line)
+}
+
+// ffmpegVersion returns FFmpeg's version number from its "ffmpeg version X ..." banner
+func ffmpegVersion() string {
+	fields := strings.Fields(toolVersion("ffmpeg", "-version"))
+	if len(fields) >= 3 && fields[1] == "version" {
+		return fields[2]
+	}
+	return strings.Join(fields, " ")
+}
+
+// formatBytes formats a byte count in MiB
+func formatBytes(bytes int64) string {
+	return fmt.Sprintf("%.1f MiB", float64(bytes)/(1<<20))
+}
+
+// formatUptime formats a long duration as days, hours and minutes
+func formatUptime(d time.Duration) string {
+	days := int(d / (24 * time.Hour))
+	hours := int(d/time.Hour) % 24
+	minutes := int(d/time.Minute) % 60
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	}
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
@@ -0,0 +1,100 @@
+package sessions
+
+import (
+	"fmt"
+	"time"
+
+	"discordbot/audio"
+	"discordbot/storage"
+)
+
+const collection = "sessions"
+
+// Session is a saved snapshot of a guild's player
+type Session struct {
+	GuildID        string         `json:"guild_id"`
+	VoiceChannelID string         `json:"voice_channel_id"`
+	TextChannelID  string         `json:"text_channel_id"`
+	Current        *audio.Track   `json:"current,omitempty"`
+	Position       time.Duration  `json:"position,omitempty"` // How far into Current playback was
+	Queue          []*audio.Track `json:"queue"`
+	Repeat         bool           `json:"repeat"`
+	Autoplay       bool           `json:"autoplay"`
+	SavedAt        time.Time      `json:"saved_at"`
+
+	// Resume marks sessions saved by a controlled shutdown, which the next start rejoins
+	Resume bool `json:"resume,omitempty"`
+}
+
+// Store keeps saved sessions in the persistent store
+type Store struct {
+	store storage.Store
+}
+
+// NewStore creates a new session store
+func NewStore(store storage.Store) *Store {
+	return &Store{store: store}
+}
+
+// Snapshot captures the current state of a voice instance
+func Snapshot(vi *audio.VoiceInstance) *Session {
+	position := vi.Position()
+
+	vi.Mu.Lock()
+	defer vi.Mu.Unlock()
+
+	session := &Session{
+		GuildID:        vi.GuildID,
+		VoiceChannelID: vi.ChannelID,
+		TextChannelID:  vi.TextChannelID,
+		Queue:          append([]*audio.Track(nil), vi.Queue...),
+		Repeat:         vi.Repeat,
+		Autoplay:       vi.Autoplay,
+		SavedAt:        time.Now(),
+	}
+	if vi.IsPlaying {
+		session.Current = vi.Current
+		session.Position = position
+	}
+	return session
+}
+
+// Save stores a session, replacing any previously saved session of the guild
+func (s *Store) Save(session *Session) error {
+	if err := s.store.Put(collection, session.GuildID, session); err != nil {
+		return fmt.Errorf("error saving session: %v", err)
+	}
+	return nil
+}
+
+// Get returns the saved session of a guild
+func (s *Store) Get(guildID string) (*Session, error) {
+	var session Session
+	if err := s.store.Get(collection, guildID, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// List returns all saved sessions
+func (s *Store) List() ([]*Session, error) {
+	guildIDs, err := s.store.Keys(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*Session
+	for _, guildID := range guildIDs {
+		session, err := s.Get(guildID)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, session)
+	}
+	return list, nil
+}
+
+// Delete removes the saved session of a guild
+func (s *Store) Delete(guildID string) error {
+	return s.store.Delete(collection, guildID)
+}
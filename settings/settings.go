@@ -0,0 +1,159 @@
+package settings
+
+import (
+	"fmt"
+	"sync"
+
+	"discordbot/storage"
+)
+
+const collection = "settings"
+
+// Guild holds the configurable settings of a guild.
+// Zero values mean "no limit" or "feature disabled".
+type Guild struct {
+	// Enqueue limits
+	MaxTrackSeconds int `json:"max_track_seconds,omitempty"`
+	MaxQueueSize    int `json:"max_queue_size,omitempty"`
+	MaxPerUser      int `json:"max_per_user,omitempty"`
+
+	// Rate limits for members who aren't DJs
+	EnqueuesPerMinute      int `json:"enqueues_per_minute,omitempty"`
+	CommandCooldownSeconds int `json:"command_cooldown_seconds,omitempty"`
+
+	// CommandChannels are the text channels music commands work in; empty allows all
+	CommandChannels []string `json:"command_channels,omitempty"`
+
+	// SameChannelOnly lets only members in the bot's voice channel control playback
+	SameChannelOnly bool `json:"same_channel_only,omitempty"`
+
+	// FollowDJ moves the bot along when the requester of the current track
+	// switches voice channels and leaves nobody behind
+	FollowDJ bool `json:"follow_dj,omitempty"`
+
+	// IdleTimeoutMinutes is how long the bot stays in voice with nothing playing and
+	// EmptyTimeoutMinutes how long it waits in a channel everyone left.
+	// Zero uses the bot's default and a negative value never leaves.
+	IdleTimeoutMinutes  int `json:"idle_timeout_minutes,omitempty"`
+	EmptyTimeoutMinutes int `json:"empty_timeout_minutes,omitempty"`
+
+	// BlockDuplicates rejects tracks that are already queued instead of asking for confirmation
+	BlockDuplicates bool `json:"block_duplicates,omitempty"`
+
+	// RecentPlayHours asks for confirmation before queueing a track played within this many hours
+	RecentPlayHours int `json:"recent_play_hours,omitempty"`
+
+	// Blocklist: requests matching any of these are rejected
+	BlockedVideos   []string `json:"blocked_videos,omitempty"`   // YouTube video IDs
+	BlockedChannels []string `json:"blocked_channels,omitempty"` // Uploader channel IDs, @handles or names, lowercase
+	BlockedDomains  []string `json:"blocked_domains,omitempty"`  // Host names, lowercase; subdomains match too
+
+	// ExplicitFilter rejects tracks Spotify flags as explicit
+	ExplicitFilter bool `json:"explicit_filter,omitempty"`
+
+	// FilteredKeywords rejects tracks whose titles contain any of these words, lowercase
+	FilteredKeywords []string `json:"filtered_keywords,omitempty"`
+
+	// Bitrate is the Opus bitrate in kbps music is encoded at; 0 uses the bot's default
+	Bitrate int `json:"bitrate,omitempty"`
+
+	// DownloadRateKB caps each download requested for the guild in KB per second; 0 leaves it to the bot's limit
+	DownloadRateKB int `json:"download_rate_kb,omitempty"`
+
+	// AutoplaySeed is a playlist reference, YouTube playlist URL or genre autoplay draws from
+	AutoplaySeed string `json:"autoplay_seed,omitempty"`
+
+	// AutoplayEngine names the recommender autoplay uses; empty draws from the seed only
+	AutoplayEngine string `json:"autoplay_engine,omitempty"`
+
+	// AnnounceChannelID is where track announcements are posted instead of the request channel
+	AnnounceChannelID string `json:"announce_channel_id,omitempty"`
+
+	// QuietMode suppresses per-track announcements; errors are still posted
+	QuietMode bool `json:"quiet_mode,omitempty"`
+
+	// Language is the i18n language code bot responses are written in
+	Language string `json:"language,omitempty"`
+
+	// PublicSession lets other guilds follow this guild's track announcements
+	PublicSession bool `json:"public_session,omitempty"`
+
+	// SpokenAnnouncements reads out each track's title before it plays
+	SpokenAnnouncements bool `json:"spoken_announcements,omitempty"`
+
+	// KioskChannelID is a channel where plain messages from anyone are queued as song requests
+	KioskChannelID string `json:"kiosk_channel_id,omitempty"`
+	// KioskCleanup is which kiosk messages are deleted: all of them (empty),
+	// only those that aren't song requests ("chatter") or none ("none")
+	KioskCleanup string `json:"kiosk_cleanup,omitempty"`
+
+	// Webhooks receive the guild's track starts, ends and player errors as JSON
+	Webhooks []string `json:"webhooks,omitempty"`
+
+	// NicknameStatus prefixes the bot's nickname with a glyph showing the player state
+	NicknameStatus bool `json:"nickname_status,omitempty"`
+
+	// StationName replaces the bot's username in the status nickname
+	StationName string `json:"station_name,omitempty"`
+}
+
+// Store keeps guild settings in the persistent store with an in-memory cache
+type Store struct {
+	store storage.Store
+	mu    sync.Mutex
+	cache map[string]Guild
+}
+
+// NewStore creates a new settings store
+func NewStore(store storage.Store) *Store {
+	return &Store{
+		store: store,
+		cache: make(map[string]Guild),
+	}
+}
+
+// Get returns the settings of a guild, or the defaults if none were saved
+func (s *Store) Get(guildID string) Guild {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	guild, err := s.load(guildID)
+	if err != nil {
+		// Fall back to defaults so a storage problem never blocks playback
+		return Guild{}
+	}
+	return guild
+}
+
+// Update applies fn to the settings of a guild and saves the result
+func (s *Store) Update(guildID string, fn func(*Guild)) (Guild, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	guild, err := s.load(guildID)
+	if err != nil {
+		return guild, err
+	}
+
+	fn(&guild)
+	if err := s.store.Put(collection, guildID, guild); err != nil {
+		return guild, fmt.Errorf("error saving settings: %v", err)
+	}
+	s.cache[guildID] = guild
+	return guild, nil
+}
+
+// load returns the cached or stored settings of a guild. The caller must hold s.mu.
+func (s *Store) load(guildID string) (Guild, error) {
+	if guild, ok := s.cache[guildID]; ok {
+		return guild, nil
+	}
+
+	var guild Guild
+	err := s.store.Get(collection, guildID, &guild)
+	if err != nil && err != storage.ErrNotFound {
+		return Guild{}, fmt.Errorf("error loading settings: %v", err)
+	}
+	s.cache[guildID] = guild
+	return guild, nil
+}
@@ -0,0 +1,604 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"discordbot/bandwidth"
+	"discordbot/i18n"
+	"discordbot/recommend"
+	"discordbot/settings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// handleSettings handles the /settings command and its subcommands
+func handleSettings(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if !isAdmin(i) {
+		errorResponse(s, i, tr(i, "settings.admin_only"))
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		editResponse(s, i, tr(i, "command.choose_subcommand"))
+		return
+	}
+
+	switch options[0].Name {
+	case "limits":
+		handleLimitSettings(s, i, options[0].Options)
+	case "filter":
+		handleFilterSettings(s, i, options[0].Options)
+	case "autoplay":
+		handleAutoplaySettings(s, i, options[0].Options)
+	case "duplicates":
+		handleDuplicateSettings(s, i, options[0].Options)
+	case "bitrate":
+		handleBitrateSettings(s, i, options[0].Options)
+	case "downloads":
+		handleDownloadSettings(s, i, options[0].Options)
+	case "public":
+		enabled := options[0].Options[0].BoolValue()
+		_, err := settingsStore.Update(i.GuildID, func(g *settings.Guild) {
+			g.PublicSession = enabled
+		})
+		if err != nil {
+			errorResponse(s, i, tr(i, "error", err))
+			return
+		}
+		if enabled {
+			editResponse(s, i, tr(i, "settings.public_enabled", i.GuildID))
+		} else {
+			editResponse(s, i, tr(i, "settings.public_disabled"))
+		}
+	case "commandchannel":
+		handleCommandChannelSettings(s, i, options[0].Options)
+	case "webhooks":
+		handleWebhookSettings(s, i, options[0].Options)
+	case "export":
+		handleSettingsExport(s, i)
+	case "import":
+		handleSettingsImport(s, i, options[0].Options)
+	case "samechannel":
+		required := options[0].Options[0].BoolValue()
+		_, err := settingsStore.Update(i.GuildID, func(g *settings.Guild) {
+			g.SameChannelOnly = required
+		})
+		if err != nil {
+			errorResponse(s, i, tr(i, "error", err))
+			return
+		}
+		if required {
+			editResponse(s, i, tr(i, "settings.same_channel_on"))
+		} else {
+			editResponse(s, i, tr(i, "settings.same_channel_off"))
+		}
+	case "followdj":
+		enabled := options[0].Options[0].BoolValue()
+		_, err := settingsStore.Update(i.GuildID, func(g *settings.Guild) {
+			g.FollowDJ = enabled
+		})
+		if err != nil {
+			errorResponse(s, i, tr(i, "error", err))
+			return
+		}
+		if enabled {
+			editResponse(s, i, tr(i, "settings.follow_dj_on"))
+		} else {
+			editResponse(s, i, tr(i, "settings.follow_dj_off"))
+		}
+	case "timeouts":
+		handleTimeoutSettings(s, i, options[0].Options)
+	case "tts":
+		enabled := options[0].Options[0].BoolValue()
+		_, err := settingsStore.Update(i.GuildID, func(g *settings.Guild) {
+			g.SpokenAnnouncements = enabled
+		})
+		if err != nil {
+			errorResponse(s, i, tr(i, "error", err))
+			return
+		}
+		switch {
+		case !enabled:
+			editResponse(s, i, tr(i, "settings.tts_off"))
+		case speaker == nil:
+			editResponse(s, i, tr(i, "settings.tts_on")+"\n"+tr(i, "tts.unavailable"))
+		default:
+			editResponse(s, i, tr(i, "settings.tts_on"))
+		}
+	case "kiosk":
+		handleKioskSettings(s, i, options[0].Options)
+	case "nickname":
+		handleNicknameSettings(s, i, options[0].Options)
+	case "announcements":
+		handleAnnouncementSettings(s, i, options[0].Options)
+	case "api":
+		handleAPISettings(s, i, options[0].Options[0].StringValue())
+	case "language":
+		lang := options[0].Options[0].StringValue()
+		if !i18n.Supported(lang) {
+			errorResponse(s, i, tr(i, "settings.language_unsupported", lang))
+			return
+		}
+		_, err := settingsStore.Update(i.GuildID, func(g *settings.Guild) {
+			g.Language = lang
+		})
+		if err != nil {
+			errorResponse(s, i, tr(i, "error", err))
+			return
+		}
+		// Confirm in the newly chosen language
+		editResponse(s, i, i18n.T(lang, "settings.language_set", i18n.Name(lang)))
+	}
+}
+
+// handleAutoplaySettings updates or shows the guild's autoplay seed and engine
+func handleAutoplaySettings(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	var seed, engine string
+	var setSeed, setEngine bool
+	for _, option := range options {
+		switch option.Name {
+		case "seed":
+			seed, setSeed = strings.TrimSpace(option.StringValue()), true
+			if strings.EqualFold(seed, "off") || strings.EqualFold(seed, "none") {
+				seed = ""
+			}
+			// Seeds naming one of the admin's playlists point to that playlist
+			if pl, err := playlistStore.Get(i.Member.User.ID, seed); err == nil && seed != "" {
+				seed = playlistRef(pl.OwnerID, pl.Name)
+			}
+		case "engine":
+			engine, setEngine = option.StringValue(), true
+			if _, ok := recommenders[engine]; !ok {
+				errorResponse(s, i, tr(i, "settings.engine_unavailable", engine, strings.Join(availableEngines(), ", ")))
+				return
+			}
+		}
+	}
+
+	guild, err := settingsStore.Update(i.GuildID, func(g *settings.Guild) {
+		if setSeed {
+			g.AutoplaySeed = seed
+		}
+		if setEngine {
+			g.AutoplayEngine = engine
+		}
+	})
+	if err != nil {
+		errorResponse(s, i, tr(i, "error", err))
+		return
+	}
+
+	var msg strings.Builder
+	if len(options) > 0 {
+		msg.WriteString(tr(i, "settings.autoplay_updated") + "\n")
+	}
+	if guild.AutoplaySeed != "" {
+		msg.WriteString(tr(i, "settings.autoplay_seed", describeAliasTarget(i, guild.AutoplaySeed)) + "\n")
+	} else {
+		msg.WriteString(tr(i, "settings.autoplay_no_seed") + "\n")
+	}
+	switch {
+	case guild.AutoplayEngine != "":
+		msg.WriteString(tr(i, "settings.autoplay_engine", guild.AutoplayEngine) + "\n")
+	case guild.AutoplaySeed != "":
+		msg.WriteString(tr(i, "settings.autoplay_engine", recommend.EngineSeed) + "\n")
+	default:
+		msg.WriteString(tr(i, "settings.autoplay_no_engine") + "\n")
+	}
+	editResponse(s, i, msg.String())
+}
+
+// handleKioskSettings sets, clears or shows the guild's kiosk channel
+func handleKioskSettings(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	if !kioskEnabled {
+		errorResponse(s, i, tr(i, "settings.kiosk_unavailable"))
+		return
+	}
+
+	guild, err := settingsStore.Update(i.GuildID, func(g *settings.Guild) {
+		for _, option := range options {
+			switch option.Name {
+			case "channel":
+				g.KioskChannelID = option.ChannelValue(nil).ID
+			case "disable":
+				if option.BoolValue() {
+					g.KioskChannelID = ""
+				}
+			case "cleanup":
+				g.KioskCleanup = option.StringValue()
+				if g.KioskCleanup == kioskCleanupAll {
+					g.KioskCleanup = ""
+				}
+			}
+		}
+	})
+	if err != nil {
+		errorResponse(s, i, tr(i, "error", err))
+		return
+	}
+
+	if guild.KioskChannelID != "" {
+		cleanup := guild.KioskCleanup
+		if cleanup == "" {
+			cleanup = kioskCleanupAll
+		}
+		editResponse(s, i, tr(i, "settings.kiosk_channel", guild.KioskChannelID)+"\n"+tr(i, "kiosk.cleanup_"+cleanup))
+	} else {
+		editResponse(s, i, tr(i, "settings.kiosk_off"))
+	}
+}
+
+// handleNicknameSettings toggles the player state nickname and sets the station name it shows
+func handleNicknameSettings(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	var enabled, setStation bool
+	var station string
+	for _, option := range options {
+		switch option.Name {
+		case "enabled":
+			enabled = option.BoolValue()
+		case "station":
+			station, setStation = strings.TrimSpace(option.StringValue()), true
+			if strings.EqualFold(station, "off") || strings.EqualFold(station, "none") {
+				station = ""
+			}
+		}
+	}
+	// Leave room for the glyph in front of the name
+	if len([]rune(station)) > maxNicknameLength-3 {
+		errorResponse(s, i, tr(i, "settings.station_too_long", maxNicknameLength-3))
+		return
+	}
+
+	guild, err := settingsStore.Update(i.GuildID, func(g *settings.Guild) {
+		g.NicknameStatus = enabled
+		if setStation {
+			g.StationName = station
+		}
+	})
+	if err != nil {
+		errorResponse(s, i, tr(i, "error", err))
+		return
+	}
+	scheduleNickname(s, i.GuildID)
+
+	switch {
+	case !guild.NicknameStatus:
+		editResponse(s, i, tr(i, "settings.nickname_off"))
+	case guild.StationName != "":
+		editResponse(s, i, tr(i, "settings.nickname_station", guild.StationName))
+	default:
+		editResponse(s, i, tr(i, "settings.nickname_on"))
+	}
+}
+
+// handleAPISettings creates or revokes the guild's REST API token
+func handleAPISettings(s *discordgo.Session, i *discordgo.InteractionCreate, action string) {
+	switch action {
+	case "create":
+		token, err := apiTokens.Create(i.GuildID, i.Member.User.ID)
+		if err != nil {
+			errorResponse(s, i, tr(i, "error", err))
+			return
+		}
+		editResponse(s, i, tr(i, "settings.api_token_created", token, i.GuildID))
+	case "revoke":
+		revoked, err := apiTokens.Revoke(i.GuildID)
+		if err != nil {
+			errorResponse(s, i, tr(i, "error", err))
+			return
+		}
+		if !revoked {
+			editResponse(s, i, tr(i, "settings.api_no_token"))
+			return
+		}
+		editResponse(s, i, tr(i, "settings.api_token_revoked"))
+	}
+}
+
+// handleAnnouncementSettings updates or shows where track announcements are posted
+func handleAnnouncementSettings(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	guild, err := settingsStore.Update(i.GuildID, func(g *settings.Guild) {
+		for _, option := range options {
+			switch option.Name {
+			case "channel":
+				g.AnnounceChannelID = option.ChannelValue(nil).ID
+			case "quiet":
+				g.QuietMode = option.BoolValue()
+			case "reset_channel":
+				if option.BoolValue() {
+					g.AnnounceChannelID = ""
+				}
+			}
+		}
+	})
+	if err != nil {
+		errorResponse(s, i, tr(i, "error", err))
+		return
+	}
+
+	var msg strings.Builder
+	if len(options) > 0 {
+		msg.WriteString(tr(i, "settings.announcements_updated") + "\n")
+	}
+	if guild.AnnounceChannelID != "" {
+		msg.WriteString(tr(i, "settings.announce_channel", guild.AnnounceChannelID) + "\n")
+	} else {
+		msg.WriteString(tr(i, "settings.announce_request_channel") + "\n")
+	}
+	if guild.QuietMode {
+		msg.WriteString(tr(i, "settings.quiet_on") + "\n")
+	} else {
+		msg.WriteString(tr(i, "settings.quiet_off") + "\n")
+	}
+	editResponse(s, i, msg.String())
+}
+
+// announcementTarget returns the channel track announcements of a guild go to,
+// falling back to channelID, and whether per-track announcements are suppressed
+func announcementTarget(guildID, channelID string) (string, bool) {
+	guild := settingsStore.Get(guildID)
+	if guild.AnnounceChannelID != "" {
+		return guild.AnnounceChannelID, guild.QuietMode
+	}
+	return channelID, guild.QuietMode
+}
+
+// handleLimitSettings updates or shows the guild's enqueue limits
+func handleLimitSettings(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	guild, err := settingsStore.Update(i.GuildID, func(g *settings.Guild) {
+		for _, option := range options {
+			value := int(option.IntValue())
+			if value < 0 {
+				value = 0
+			}
+			switch option.Name {
+			case "max_track_minutes":
+				g.MaxTrackSeconds = value * 60
+			case "max_queue":
+				g.MaxQueueSize = value
+			case "max_per_user":
+				g.MaxPerUser = value
+			case "enqueues_per_minute":
+				g.EnqueuesPerMinute = value
+			case "command_cooldown":
+				g.CommandCooldownSeconds = value
+			}
+		}
+	})
+	if err != nil {
+		errorResponse(s, i, tr(i, "error", err))
+		return
+	}
+
+	var msg strings.Builder
+	if len(options) > 0 {
+		msg.WriteString(tr(i, "settings.limits_updated") + "\n")
+	}
+	msg.WriteString(tr(i, "settings.max_track_length", formatLimit(i, guild.MaxTrackSeconds, func(v int) string {
+		return formatDuration(time.Duration(v) * time.Second)
+	})) + "\n")
+	msg.WriteString(tr(i, "settings.max_queue_size", formatLimit(i, guild.MaxQueueSize, func(v int) string {
+		return tr(i, "queue.track_count", v)
+	})) + "\n")
+	msg.WriteString(tr(i, "settings.max_per_user", formatLimit(i, guild.MaxPerUser, func(v int) string {
+		return tr(i, "queue.track_count", v)
+	})) + "\n")
+	msg.WriteString(tr(i, "settings.enqueues_per_minute", formatLimit(i, guild.EnqueuesPerMinute, func(v int) string {
+		return tr(i, "settings.per_minute", v)
+	})) + "\n")
+	msg.WriteString(tr(i, "settings.command_cooldown", formatLimit(i, guild.CommandCooldownSeconds, func(v int) string {
+		return formatDuration(time.Duration(v) * time.Second)
+	})) + "\n")
+	editResponse(s, i, msg.String())
+}
+
+// handleFilterSettings shows or changes the guild's explicit content filter and filtered keywords
+func handleFilterSettings(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	guild, err := settingsStore.Update(i.GuildID, func(g *settings.Guild) {
+		for _, option := range options {
+			switch option.Name {
+			case "explicit":
+				g.ExplicitFilter = option.BoolValue()
+			case "add_keyword":
+				if keyword := normalizeKeyword(option.StringValue()); keyword != "" && !contains(g.FilteredKeywords, keyword) {
+					g.FilteredKeywords = append(g.FilteredKeywords, keyword)
+				}
+			case "remove_keyword":
+				// Build a new slice so readers of the previous settings are unaffected
+				keyword := normalizeKeyword(option.StringValue())
+				var kept []string
+				for _, existing := range g.FilteredKeywords {
+					if existing != keyword {
+						kept = append(kept, existing)
+					}
+				}
+				g.FilteredKeywords = kept
+			}
+		}
+	})
+	if err != nil {
+		errorResponse(s, i, tr(i, "error", err))
+		return
+	}
+
+	var msg strings.Builder
+	if len(options) > 0 {
+		msg.WriteString(tr(i, "settings.filter_updated") + "\n")
+	}
+	if guild.ExplicitFilter {
+		msg.WriteString(tr(i, "settings.explicit_on") + "\n")
+		if spotifyClient == nil {
+			msg.WriteString(tr(i, "settings.explicit_no_spotify") + "\n")
+		}
+	} else {
+		msg.WriteString(tr(i, "settings.explicit_off") + "\n")
+	}
+	if len(guild.FilteredKeywords) > 0 {
+		msg.WriteString(tr(i, "settings.keywords", "`"+strings.Join(guild.FilteredKeywords, "`, `")+"`") + "\n")
+	} else {
+		msg.WriteString(tr(i, "settings.no_keywords") + "\n")
+	}
+	editResponse(s, i, msg.String())
+}
+
+// handleDuplicateSettings shows or changes how queued and recently played tracks are handled
+func handleDuplicateSettings(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	guild, err := settingsStore.Update(i.GuildID, func(g *settings.Guild) {
+		for _, option := range options {
+			switch option.Name {
+			case "block":
+				g.BlockDuplicates = option.BoolValue()
+			case "recent_hours":
+				g.RecentPlayHours = int(option.IntValue())
+				if g.RecentPlayHours < 0 {
+					g.RecentPlayHours = 0
+				}
+			}
+		}
+	})
+	if err != nil {
+		errorResponse(s, i, tr(i, "error", err))
+		return
+	}
+
+	var msg strings.Builder
+	if guild.BlockDuplicates {
+		msg.WriteString(tr(i, "settings.duplicates_blocked") + "\n")
+	} else {
+		msg.WriteString(tr(i, "settings.duplicates_confirm") + "\n")
+	}
+	if guild.RecentPlayHours > 0 {
+		msg.WriteString(tr(i, "settings.recent_plays", guild.RecentPlayHours) + "\n")
+	} else {
+		msg.WriteString(tr(i, "settings.recent_plays_off") + "\n")
+	}
+	editResponse(s, i, msg.String())
+}
+
+// handleBitrateSettings shows or changes the bitrate music is encoded at
+func handleBitrateSettings(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	guild := settingsStore.Get(i.GuildID)
+	if len(options) > 0 {
+		kbps := int(options[0].IntValue())
+		if kbps != 0 && (kbps < minGuildBitrate || kbps > maxGuildBitrate) {
+			errorResponse(s, i, tr(i, "settings.bitrate_range", minGuildBitrate, maxGuildBitrate))
+			return
+		}
+		var err error
+		guild, err = settingsStore.Update(i.GuildID, func(g *settings.Guild) {
+			g.Bitrate = kbps
+		})
+		if err != nil {
+			errorResponse(s, i, tr(i, "error", err))
+			return
+		}
+	}
+
+	if guild.Bitrate == 0 {
+		editResponse(s, i, tr(i, "settings.bitrate_default"))
+		return
+	}
+	msg := tr(i, "settings.bitrate", guild.Bitrate)
+	if channelBitrate := voiceChannelBitrate(s, i.GuildID); channelBitrate > 0 && channelBitrate < guild.Bitrate*1000 {
+		msg += "\n" + tr(i, "settings.bitrate_clamped", channelBitrate/1000)
+	}
+	editResponse(s, i, msg)
+}
+
+// handleDownloadSettings updates or shows the guild's download rate limit
+func handleDownloadSettings(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	guild := settingsStore.Get(i.GuildID)
+	if len(options) > 0 {
+		kb := int(options[0].IntValue())
+		var err error
+		guild, err = settingsStore.Update(i.GuildID, func(g *settings.Guild) {
+			g.DownloadRateKB = kb
+		})
+		if err != nil {
+			errorResponse(s, i, tr(i, "error", err))
+			return
+		}
+	}
+
+	msg := tr(i, "settings.download_rate_default")
+	if guild.DownloadRateKB > 0 {
+		msg = tr(i, "settings.download_rate", guild.DownloadRateKB)
+	}
+	if limit := bandwidth.Default.Total() >> 10; limit > 0 {
+		msg += "\n" + tr(i, "settings.download_rate_bot", limit)
+	}
+	editResponse(s, i, msg)
+}
+
+// handleTimeoutSettings updates or shows the guild's idle and empty-channel timeouts
+func handleTimeoutSettings(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	values := make(map[string]int)
+	for _, option := range options {
+		minutes, ok := parseTimeout(option.StringValue())
+		if !ok {
+			errorResponse(s, i, tr(i, "settings.timeout_invalid", option.StringValue()))
+			return
+		}
+		values[option.Name] = minutes
+	}
+
+	guild, err := settingsStore.Update(i.GuildID, func(g *settings.Guild) {
+		if minutes, ok := values["idle"]; ok {
+			g.IdleTimeoutMinutes = minutes
+		}
+		if minutes, ok := values["empty"]; ok {
+			g.EmptyTimeoutMinutes = minutes
+		}
+	})
+	if err != nil {
+		errorResponse(s, i, tr(i, "error", err))
+		return
+	}
+
+	var msg strings.Builder
+	if len(options) > 0 {
+		msg.WriteString(tr(i, "settings.timeouts_updated") + "\n")
+	}
+	msg.WriteString(tr(i, "settings.idle_timeout", formatTimeout(i, guild.IdleTimeoutMinutes, idleTimeout(guild))) + "\n")
+	msg.WriteString(tr(i, "settings.empty_timeout", formatTimeout(i, guild.EmptyTimeoutMinutes, emptyTimeout(guild))) + "\n")
+	editResponse(s, i, msg.String())
+}
+
+// parseTimeout reads a timeout option: a number of minutes, "off" (-1) or "default" (0)
+func parseTimeout(value string) (int, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "off", "never", "0":
+		return -1, true
+	case "default":
+		return 0, true
+	}
+	minutes, err := strconv.Atoi(value)
+	if err != nil || minutes < 0 {
+		return 0, false
+	}
+	return minutes, true
+}
+
+// formatTimeout describes a timeout setting and the duration it resolves to
+func formatTimeout(i *discordgo.InteractionCreate, minutes int, timeout time.Duration) string {
+	switch {
+	case timeout == 0:
+		return tr(i, "settings.timeout_off")
+	case minutes == 0:
+		return tr(i, "settings.timeout_default", formatDuration(timeout))
+	default:
+		return formatDuration(timeout)
+	}
+}
+
+// formatLimit formats a limit value, where zero means unlimited
+func formatLimit(i *discordgo.InteractionCreate, value int, format func(int) string) string {
+	if value <= 0 {
+		return tr(i, "settings.unlimited")
+	}
+	return format(value)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"discordbot/audio"
+	"discordbot/config"
+	"discordbot/sessions"
+)
+
+// defaultShutdownGrace is how long guilds are warned before a controlled shutdown
+const defaultShutdownGrace = 60 * time.Second
+
+// shutdownManager coordinates a controlled shutdown: it warns every guild with
+// active playback, waits out a grace period and saves their sessions before
+// the voice connections are torn down
+type shutdownManager struct {
+	grace time.Duration
+}
+
+// newShutdownManager creates a shutdown manager using SHUTDOWN_GRACE_SECONDS if set
+func newShutdownManager() *shutdownManager {
+	seconds := config.Int("SHUTDOWN_GRACE_SECONDS", int(defaultShutdownGrace/time.Second), func(seconds int) bool { return seconds >= 0 })
+	return &shutdownManager{grace: time.Duration(seconds) * time.Second}
+}
+
+// activeInstances returns the voice instances that are playing or have a queue
+func (m *shutdownManager) activeInstances() []*audio.VoiceInstance {
+	voiceManager.Mu.Lock()
+	defer voiceManager.Mu.Unlock()
+
+	var active []*audio.VoiceInstance
+	for _, instance := range voiceManager.Instances {
+		instance.Mu.Lock()
+		playing := instance.IsPlaying || len(instance.Queue) > 0
+		instance.Mu.Unlock()
+		if playing && instance.Connected() {
+			active = append(active, instance)
+		}
+	}
+	return active
+}
+
+// Prepare announces the shutdown to active guilds, waits for the grace period
+// (or until abort is signaled) and then saves their sessions, including the
+// playback position, for the next start to resume
+func (m *shutdownManager) Prepare(abort <-chan struct{}) {
+	active := m.activeInstances()
+	if len(active) == 0 {
+		return
+	}
+
+	if m.grace > 0 {
+		// Save right away too, so a crash or kill during the grace period
+		// still leaves something to resume
+		m.saveSessions(active)
+
+		log.Printf("Announcing shutdown to %d guild(s) with active playback", len(active))
+		for _, instance := range active {
+			instance.Mu.Lock()
+			channelID := instance.TextChannelID
+			instance.Mu.Unlock()
+			if channelID != "" {
+				notice := trGuild(instance.GuildID, "shutdown.notice", formatGrace(instance.GuildID, m.grace))
+				notifier.Send(channelID, notice)
+			}
+		}
+
+		select {
+		case <-time.After(m.grace):
+		case <-abort:
+			log.Println("Skipping the rest of the shutdown grace period")
+		}
+	}
+
+	// Snapshot again after the grace period so the saved queue is as fresh as possible
+	m.saveSessions(active)
+}
+
+// saveSessions saves the sessions of the given guilds, replacing any saved earlier
+func (m *shutdownManager) saveSessions(active []*audio.VoiceInstance) {
+	for _, instance := range active {
+		session := sessions.Snapshot(instance)
+		session.Resume = true
+		if err := sessionStore.Save(session); err != nil {
+			log.Printf("Failed to save session for guild %s: %v", instance.GuildID, err)
+		} else {
+			log.Printf("Saved session for guild %s", instance.GuildID)
+		}
+	}
+}
+
+// formatGrace formats a grace period as seconds or minutes in a guild's language
+func formatGrace(guildID string, d time.Duration) string {
+	if d%time.Minute == 0 && d >= 2*time.Minute {
+		return trGuild(guildID, "time.minutes", int(d/time.Minute))
+	}
+	return trGuild(guildID, "time.seconds", int(d/time.Second))
+}
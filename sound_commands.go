@@ -0,0 +1,179 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"discordbot/audio"
+	"discordbot/audio/cache"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// maxSoundLength is the longest clip the soundboard accepts
+	maxSoundLength = 10 * time.Second
+	// maxSoundBytes caps the size of uploaded clips
+	maxSoundBytes = 2 << 20
+	// maxSounds is how many clips a guild can keep
+	maxSounds = 25
+)
+
+// handleSound handles the /sound command and its subcommands
+func handleSound(s *discordgo.Session, i *discordgo.InteractionCreate, vi *audio.VoiceInstance) {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		editResponse(s, i, tr(i, "command.choose_subcommand"))
+		return
+	}
+
+	subcommand := options[0]
+	var name, attachmentID string
+	for _, option := range subcommand.Options {
+		switch option.Name {
+		case "name":
+			name = strings.ToLower(strings.TrimSpace(option.StringValue()))
+		case "file":
+			attachmentID = option.Value.(string)
+		}
+	}
+
+	// Only admins manage the clips; anyone may play them
+	if (subcommand.Name == "add" || subcommand.Name == "remove") && !isAdmin(i) {
+		errorResponse(s, i, tr(i, "sound.admin_only"))
+		return
+	}
+
+	// Names end up in file paths, so check them before anything looks one up
+	if subcommand.Name != "list" && !cache.ValidSoundName(name) {
+		errorResponse(s, i, tr(i, "sound.invalid_name"))
+		return
+	}
+
+	switch subcommand.Name {
+	case "add":
+		handleSoundAdd(s, i, name, attachmentID)
+
+	case "remove":
+		removed, err := audioCache.RemoveSound(i.GuildID, name)
+		if err != nil {
+			errorResponse(s, i, tr(i, "error", err))
+			return
+		}
+		if !removed {
+			errorResponse(s, i, tr(i, "sound.not_found", name))
+			return
+		}
+		editResponse(s, i, tr(i, "sound.removed", name))
+
+	case "play":
+		handleSoundPlay(s, i, vi, name)
+
+	case "list":
+		names, err := audioCache.Sounds(i.GuildID)
+		if err != nil {
+			errorResponse(s, i, tr(i, "error", err))
+			return
+		}
+		if len(names) == 0 {
+			editResponse(s, i, tr(i, "sound.none"))
+			return
+		}
+		editResponse(s, i, tr(i, "sound.list", "`"+strings.Join(names, "`, `")+"`"))
+	}
+}
+
+// handleSoundAdd stores an uploaded clip in the guild's soundboard
+func handleSoundAdd(s *discordgo.Session, i *discordgo.InteractionCreate, name, attachmentID string) {
+	attachment := i.ApplicationCommandData().Resolved.Attachments[attachmentID]
+	if attachment == nil {
+		errorResponse(s, i, tr(i, "sound.no_file"))
+		return
+	}
+	if attachment.Size > maxSoundBytes {
+		errorResponse(s, i, tr(i, "sound.too_big", maxSoundBytes>>20))
+		return
+	}
+
+	if !audioCache.HasSound(i.GuildID, name) {
+		names, err := audioCache.Sounds(i.GuildID)
+		if err != nil {
+			errorResponse(s, i, tr(i, "error", err))
+			return
+		}
+		if len(names) >= maxSounds {
+			errorResponse(s, i, tr(i, "sound.full", maxSounds))
+			return
+		}
+	}
+
+	upload, err := saveAttachment(attachment.URL, i.GuildID)
+	if err != nil {
+		errorResponse(s, i, tr(i, "sound.save_failed", err))
+		return
+	}
+	defer os.Remove(upload)
+
+	path, err := audioCache.PrepareSound(i.GuildID, name)
+	if err == nil {
+		err = audio.ConvertClip(upload, path, maxSoundLength)
+	}
+	if err != nil {
+		errorResponse(s, i, tr(i, "sound.save_failed", err))
+		return
+	}
+	editResponse(s, i, tr(i, "sound.saved", name))
+}
+
+// handleSoundPlay plays a clip in the voice channel, over the music if something is playing
+func handleSoundPlay(s *discordgo.Session, i *discordgo.InteractionCreate, vi *audio.VoiceInstance, name string) {
+	if !audioCache.HasSound(i.GuildID, name) {
+		errorResponse(s, i, tr(i, "sound.not_found", name))
+		return
+	}
+	if vi.IsRemote() {
+		errorResponse(s, i, tr(i, "sound.remote"))
+		return
+	}
+
+	// Join the user's voice channel if we aren't connected yet
+	if !vi.Connected() && !joinUserChannel(s, i, vi) {
+		return
+	}
+
+	path, err := audioCache.SoundPath(i.GuildID, name)
+	if err == nil {
+		err = vi.Say(path)
+	}
+	switch {
+	case errors.Is(err, audio.ErrSpeaking):
+		errorResponse(s, i, tr(i, "sound.busy"))
+	case err != nil:
+		errorResponse(s, i, tr(i, "error", err))
+	default:
+		editResponse(s, i, tr(i, "sound.played", name))
+	}
+}
+
+// saveAttachment downloads an uploaded clip to a temporary file for ffmpeg
+// and returns its path
+func saveAttachment(url, guildID string) (string, error) {
+	data, err := fetchAttachment(url, maxSoundBytes, guildID)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.CreateTemp("", "sound-*")
+	if err != nil {
+		return "", fmt.Errorf("error creating temporary file: %v", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(data); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("error saving the file: %v", err)
+	}
+	return file.Name(), nil
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"discordbot/audio"
+	"discordbot/audio/catalog"
+	"discordbot/audio/spotify"
+	"discordbot/config"
+	"discordbot/i18n"
+	"discordbot/playlist"
+	"discordbot/spotifylinks"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// spotifyLinkTTL is how long a /spotify link URL can be used
+	spotifyLinkTTL = 10 * time.Minute
+	// spotifyLibraryTimeout bounds reading a user's library for one request
+	spotifyLibraryTimeout = 2 * time.Minute
+	// maxSpotifyImportTracks caps how many songs /playlist import saves
+	maxSpotifyImportTracks = 100
+
+	// spotifyPrefix marks /play and /playlist import sources in a linked Spotify
+	// library: spotify:liked for Liked Songs, spotify:<name> for a playlist
+	spotifyPrefix = "spotify:"
+	// likedSource is the source naming a user's Liked Songs
+	likedSource = "liked"
+)
+
+var (
+	// spotifyAuth links Spotify accounts. It stays nil, disabling /spotify link,
+	// unless SPOTIFY_REDIRECT_URL and the Spotify credentials are set.
+	spotifyAuth *spotify.UserAuth
+	// spotifyRedirect is the parsed SPOTIFY_REDIRECT_URL the callback is served on
+	spotifyRedirect *url.URL
+	// spotifyLinks keeps the accounts users linked
+	spotifyLinks *spotifylinks.Store
+)
+
+// pendingSpotifyLink is a /spotify link URL waiting for the user to grant access
+type pendingSpotifyLink struct {
+	userID  string
+	lang    string
+	expires time.Time
+}
+
+// pendingSpotifyLinks holds the outstanding link URLs by OAuth state
+var pendingSpotifyLinks = struct {
+	sync.Mutex
+	byState map[string]pendingSpotifyLink
+}{byState: make(map[string]pendingSpotifyLink)}
+
+// setupSpotifyLinking lets users link their Spotify accounts if
+// SPOTIFY_REDIRECT_URL is set. The callback is served by the HTTP server.
+func setupSpotifyLinking() {
+	key := config.String("SPOTIFY_TOKEN_KEY", "")
+	links, err := spotifylinks.NewStore(store, key)
+	if err != nil {
+		// Nothing new is linked without the key; the plain store can only
+		// unlink accounts
+		log.Printf("Spotify account linking disabled: %v", err)
+		spotifyLinks, _ = spotifylinks.NewStore(store, "")
+		return
+	}
+	spotifyLinks = links
+
+	redirect := config.String("SPOTIFY_REDIRECT_URL", "")
+	if redirect == "" || readOnly {
+		return
+	}
+	parsed, err := url.Parse(redirect)
+	if err != nil || parsed.Host == "" {
+		log.Printf("Spotify account linking disabled: invalid SPOTIFY_REDIRECT_URL %q", redirect)
+		return
+	}
+	if config.String("HTTP_ADDR", "") == "" {
+		log.Printf("Spotify account linking disabled: HTTP_ADDR must be set to receive the callback")
+		return
+	}
+
+	auth, err := spotify.NewUserAuth(redirect)
+	if err != nil {
+		log.Printf("Spotify account linking disabled: %v", err)
+		return
+	}
+	spotifyAuth = auth
+	spotifyRedirect = parsed
+	if key == "" {
+		log.Printf("Warning: SPOTIFY_TOKEN_KEY isn't set, so linked Spotify tokens are stored unencrypted")
+	}
+}
+
+// registerSpotifyCallback mounts the Spotify OAuth2 callback on mux if linking is enabled
+func registerSpotifyCallback(mux *http.ServeMux) {
+	if spotifyAuth == nil {
+		return
+	}
+	path := spotifyRedirect.Path
+	if path == "" {
+		path = "/"
+	}
+	mux.HandleFunc("GET "+path, handleSpotifyCallback)
+}
+
+// handleSpotifyCallback completes a /spotify link flow and saves the user's token
+func handleSpotifyCallback(w http.ResponseWriter, r *http.Request) {
+	state := r.URL.Query().Get("state")
+	pendingSpotifyLinks.Lock()
+	pending, ok := pendingSpotifyLinks.byState[state]
+	delete(pendingSpotifyLinks.byState, state)
+	pendingSpotifyLinks.Unlock()
+	if !ok || time.Now().After(pending.expires) {
+		http.Error(w, i18n.T(i18n.Default, "spotify.callback_expired"), http.StatusBadRequest)
+		return
+	}
+	if r.URL.Query().Get("error") != "" {
+		http.Error(w, i18n.T(pending.lang, "spotify.callback_denied"), http.StatusForbidden)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	token, err := spotifyAuth.Exchange(ctx, r.URL.Query().Get("code"))
+	if err != nil {
+		log.Printf("Failed to link Spotify account of user %s: %v", pending.userID, err)
+		http.Error(w, i18n.T(pending.lang, "spotify.callback_failed"), http.StatusBadGateway)
+		return
+	}
+	account, err := spotifyAuth.Client(ctx, token).DisplayName(ctx)
+	if err != nil {
+		log.Printf("Failed to read Spotify account of user %s: %v", pending.userID, err)
+		http.Error(w, i18n.T(pending.lang, "spotify.callback_failed"), http.StatusBadGateway)
+		return
+	}
+
+	err = spotifyLinks.Save(pending.userID, spotifylinks.Link{Account: account, Token: token, LinkedAt: time.Now()})
+	if err != nil {
+		log.Printf("Failed to save Spotify link of user %s: %v", pending.userID, err)
+		http.Error(w, i18n.T(pending.lang, "spotify.callback_failed"), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, i18n.T(pending.lang, "spotify.callback_linked"))
+}
+
+// handleSpotify handles the /spotify command and its subcommands
+func handleSpotify(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		editResponse(s, i, tr(i, "command.choose_subcommand"))
+		return
+	}
+
+	userID := i.Member.User.ID
+	switch options[0].Name {
+	case "link":
+		if spotifyAuth == nil {
+			errorResponse(s, i, tr(i, "spotify.link_unavailable"))
+			return
+		}
+		state, err := newSpotifyLinkState(userID, interactionLanguage(i))
+		if err != nil {
+			errorResponse(s, i, tr(i, "error", err))
+			return
+		}
+		editResponse(s, i, tr(i, "spotify.link", spotifyAuth.AuthURL(state), int(spotifyLinkTTL/time.Minute)))
+
+	case "unlink":
+		removed, err := spotifyLinks.Delete(userID)
+		if err != nil {
+			errorResponse(s, i, tr(i, "error", err))
+			return
+		}
+		if !removed {
+			editResponse(s, i, tr(i, "spotify.not_linked"))
+			return
+		}
+		editResponse(s, i, tr(i, "spotify.unlinked"))
+
+	case "status":
+		link, err := spotifyLinks.Get(userID)
+		if errors.Is(err, spotifylinks.ErrNotLinked) {
+			editResponse(s, i, tr(i, "spotify.not_linked"))
+			return
+		}
+		if err != nil {
+			errorResponse(s, i, tr(i, "error", err))
+			return
+		}
+		editResponse(s, i, tr(i, "spotify.status", link.Account, link.LinkedAt.Unix()))
+	}
+}
+
+// newSpotifyLinkState registers an OAuth state for a user's /spotify link URL
+func newSpotifyLinkState(userID, lang string) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating link state: %v", err)
+	}
+	state := hex.EncodeToString(b)
+
+	pendingSpotifyLinks.Lock()
+	defer pendingSpotifyLinks.Unlock()
+
+	// Forget unused links while we're here
+	now := time.Now()
+	for key, pending := range pendingSpotifyLinks.byState {
+		if now.After(pending.expires) {
+			delete(pendingSpotifyLinks.byState, key)
+		}
+	}
+	pendingSpotifyLinks.byState[state] = pendingSpotifyLink{userID: userID, lang: lang, expires: now.Add(spotifyLinkTTL)}
+	return state, nil
+}
+
+// parseSpotifySource splits a spotify:liked or spotify:<playlist> source
+func parseSpotifySource(source string) (name string, ok bool) {
+	source = strings.TrimSpace(source)
+	if len(source) <= len(spotifyPrefix) || !strings.EqualFold(source[:len(spotifyPrefix)], spotifyPrefix) {
+		return "", false
+	}
+	name = strings.TrimSpace(source[len(spotifyPrefix):])
+	// Spotify's own URIs such as spotify:track:<id> aren't library sources
+	if strings.Contains(name, ":") {
+		return "", false
+	}
+	return name, name != ""
+}
+
+// spotifyLibrary reads up to limit songs of the interaction author's Liked
+// Songs or named playlist, returning the name of what was read
+func spotifyLibrary(i *discordgo.InteractionCreate, name string, limit int) (string, []catalog.Song, error) {
+	userID := i.Member.User.ID
+	link, err := spotifyLinks.Get(userID)
+	if errors.Is(err, spotifylinks.ErrNotLinked) {
+		return "", nil, errors.New(tr(i, "spotify.link_first"))
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	if spotifyAuth == nil {
+		return "", nil, errors.New(tr(i, "spotify.link_unavailable"))
+	}
+
+	libraryCtx, cancel := context.WithTimeout(ctx, spotifyLibraryTimeout)
+	defer cancel()
+	client := spotifyAuth.Client(libraryCtx, link.Token)
+
+	var songs []catalog.Song
+	if strings.EqualFold(name, likedSource) {
+		name = tr(i, "spotify.liked_songs")
+		songs, err = client.LikedSongs(libraryCtx, limit)
+	} else {
+		name, songs, err = client.Playlist(libraryCtx, name, limit)
+	}
+
+	// Keep the refreshed token so the next request doesn't refresh again
+	if token, tokenErr := client.Token(); tokenErr == nil {
+		if err := spotifyLinks.UpdateToken(userID, token); err != nil {
+			log.Printf("Failed to save refreshed Spotify token of user %s: %v", userID, err)
+		}
+	}
+
+	switch {
+	case errors.Is(err, spotify.ErrLinkRevoked):
+		if _, err := spotifyLinks.Delete(userID); err != nil {
+			log.Printf("Failed to remove revoked Spotify link of user %s: %v", userID, err)
+		}
+		return "", nil, errors.New(tr(i, "spotify.link_revoked"))
+	case errors.Is(err, spotify.ErrPlaylistNotFound):
+		return "", nil, errors.New(tr(i, "spotify.playlist_not_found", name))
+	case err != nil:
+		return "", nil, err
+	case len(songs) == 0:
+		return "", nil, errors.New(tr(i, "spotify.library_empty", name))
+	}
+	return name, songs, nil
+}
+
+// resolveSpotifyLibrary turns a spotify:liked or spotify:<playlist> request
+// into YouTube matches of the author's saved songs. ok is false for other queries.
+func resolveSpotifyLibrary(i *discordgo.InteractionCreate, query string) (tracks []*audio.Track, ok bool, err error) {
+	name, ok := parseSpotifySource(query)
+	if !ok {
+		return nil, false, nil
+	}
+	name, songs, err := spotifyLibrary(i, name, maxCatalogTracks)
+	if err != nil {
+		return nil, true, err
+	}
+	tracks = matchOnYouTube(i, songs)
+	if len(tracks) == 0 {
+		return nil, true, fmt.Errorf("no YouTube matches found for %s", name)
+	}
+	return tracks, true, nil
+}
+
+// handlePlaylistImport saves the YouTube matches of a linked Spotify
+// library's Liked Songs or playlist as one of the user's playlists
+func handlePlaylistImport(s *discordgo.Session, i *discordgo.InteractionCreate, source, name string, overwrite bool) {
+	library, ok := parseSpotifySource(source)
+	if !ok {
+		errorResponse(s, i, tr(i, "spotify.import_source"))
+		return
+	}
+	library, songs, err := spotifyLibrary(i, library, maxSpotifyImportTracks)
+	if err != nil {
+		errorResponse(s, i, tr(i, "error", err))
+		return
+	}
+	if name == "" {
+		name = library
+	}
+
+	tracks := matchOnYouTube(i, songs)
+	if len(tracks) == 0 {
+		errorResponse(s, i, tr(i, "spotify.import_no_matches", library))
+		return
+	}
+
+	now := time.Now()
+	entries := make([]playlist.Entry, len(tracks))
+	for idx, track := range tracks {
+		entries[idx] = playlist.Entry{URL: track.URL, Title: track.Title, AddedAt: now}
+	}
+	if err := playlistStore.Save(i.Member.User.ID, name, entries, overwrite); err != nil {
+		errorResponse(s, i, tr(i, "error", err))
+		return
+	}
+	editResponse(s, i, tr(i, "spotify.imported", len(entries), len(songs), library, name, name))
+}
@@ -0,0 +1,172 @@
+// Package spotifylinks keeps the Spotify accounts users linked to the bot.
+// Their tokens, which give access to the accounts until they are revoked,
+// are encrypted in the store if the bot was given a key.
+package spotifylinks
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"discordbot/storage"
+
+	"golang.org/x/oauth2"
+)
+
+const collection = "spotify_links"
+
+var (
+	// ErrNotLinked is returned for users who haven't linked a Spotify account
+	ErrNotLinked = errors.New("no Spotify account linked")
+	// ErrNoKey is returned for encrypted links while the bot has no key to read them
+	ErrNoKey = errors.New("the Spotify link is encrypted, but no key is configured")
+)
+
+// Link is a user's Spotify account as the bot can access it
+type Link struct {
+	// Account is the Spotify display name, shown back to the user
+	Account  string        `json:"account"`
+	Token    *oauth2.Token `json:"token"`
+	LinkedAt time.Time     `json:"linked_at"`
+}
+
+// storedLink is a link as kept in the store, its token either sealed or,
+// without a key, as it is
+type storedLink struct {
+	Account     string        `json:"account"`
+	Token       *oauth2.Token `json:"token,omitempty"`
+	SealedToken string        `json:"sealed_token,omitempty"`
+	LinkedAt    time.Time     `json:"linked_at"`
+}
+
+// Store keeps linked accounts, keyed by Discord user ID. Links apply across all guilds.
+type Store struct {
+	store storage.Store
+	aead  cipher.AEAD // Seals tokens; nil stores them as they are
+}
+
+// NewStore creates a new link store. Tokens are encrypted with AES-GCM under
+// a key derived from secret; an empty secret stores them in plain text.
+func NewStore(store storage.Store, secret string) (*Store, error) {
+	s := &Store{store: store}
+	if secret == "" {
+		return s, nil
+	}
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	if s.aead, err = cipher.NewGCM(block); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Get returns the account a user linked. Links saved in plain text before a
+// key was configured are encrypted on the way.
+func (s *Store) Get(userID string) (Link, error) {
+	var stored storedLink
+	err := s.store.Get(collection, userID, &stored)
+	if err == storage.ErrNotFound || (err == nil && stored.Token == nil && stored.SealedToken == "") {
+		return Link{}, ErrNotLinked
+	}
+	if err != nil {
+		return Link{}, fmt.Errorf("error loading Spotify link: %v", err)
+	}
+
+	link := Link{Account: stored.Account, Token: stored.Token, LinkedAt: stored.LinkedAt}
+	if stored.SealedToken != "" {
+		if link.Token, err = s.open(stored.SealedToken); err != nil {
+			return Link{}, err
+		}
+	} else if s.aead != nil {
+		if err := s.Save(userID, link); err != nil {
+			log.Printf("Failed to encrypt the Spotify link of user %s: %v", userID, err)
+		}
+	}
+	return link, nil
+}
+
+// Save links an account to a user, replacing any previous one
+func (s *Store) Save(userID string, link Link) error {
+	stored := storedLink{Account: link.Account, Token: link.Token, LinkedAt: link.LinkedAt}
+	if s.aead != nil && link.Token != nil {
+		sealed, err := s.seal(link.Token)
+		if err != nil {
+			return fmt.Errorf("error encrypting Spotify link: %v", err)
+		}
+		stored.Token, stored.SealedToken = nil, sealed
+	}
+	if err := s.store.Put(collection, userID, stored); err != nil {
+		return fmt.Errorf("error saving Spotify link: %v", err)
+	}
+	return nil
+}
+
+// seal encrypts a token and returns it base64 encoded, nonce first
+func (s *Store) seal(token *oauth2.Token) (string, error) {
+	plain, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(s.aead.Seal(nonce, nonce, plain, nil)), nil
+}
+
+// open decrypts a token sealed by seal
+func (s *Store) open(sealed string) (*oauth2.Token, error) {
+	if s.aead == nil {
+		return nil, ErrNoKey
+	}
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < s.aead.NonceSize() {
+		return nil, errors.New("the Spotify link is corrupted")
+	}
+	nonce, ciphertext := data[:s.aead.NonceSize()], data[s.aead.NonceSize():]
+	plain, err := s.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("the Spotify link can't be decrypted with the configured key")
+	}
+	var token oauth2.Token
+	if err := json.Unmarshal(plain, &token); err != nil {
+		return nil, fmt.Errorf("error decoding Spotify link: %v", err)
+	}
+	return &token, nil
+}
+
+// UpdateToken stores a refreshed token for a user's existing link
+func (s *Store) UpdateToken(userID string, token *oauth2.Token) error {
+	link, err := s.Get(userID)
+	if err != nil {
+		return err
+	}
+	if link.Token.AccessToken == token.AccessToken && link.Token.RefreshToken == token.RefreshToken {
+		return nil
+	}
+	link.Token = token
+	return s.Save(userID, link)
+}
+
+// Delete unlinks a user's account, reporting whether one was linked. It
+// works even if the link can't be decrypted.
+func (s *Store) Delete(userID string) (bool, error) {
+	var stored storedLink
+	if err := s.store.Get(collection, userID, &stored); err == storage.ErrNotFound {
+		return false, nil
+	}
+	if err := s.store.Delete(collection, userID); err != nil {
+		return false, fmt.Errorf("error removing Spotify link: %v", err)
+	}
+	return true, nil
+}
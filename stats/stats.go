@@ -0,0 +1,350 @@
+package stats
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"discordbot/storage"
+)
+
+const collection = "stats"
+
+// retention is how long individual plays are kept before their day is pruned
+const retention = 365 * 24 * time.Hour
+
+// dayLayout is the format of the day in document keys
+const dayLayout = "2006-01-02"
+
+// Play is a single finished playback
+type Play struct {
+	URL         string        `json:"url"`
+	Title       string        `json:"title,omitempty"`
+	RequesterID string        `json:"requester_id"`
+	Requester   string        `json:"requester"`
+	StartedAt   time.Time     `json:"started_at"`
+	Listened    time.Duration `json:"listened"`
+}
+
+// Entry is a ranked row in a summary
+type Entry struct {
+	Key      string
+	Label    string
+	Plays    int
+	Listened time.Duration
+}
+
+// Summary aggregates the plays of a guild over a time window
+type Summary struct {
+	Plays         int
+	Listened      time.Duration
+	TopTracks     []Entry
+	TopRequesters []Entry
+}
+
+// Recorder records plays per guild in the persistent store. Each guild's
+// plays are kept in one document per day, so recording a play only rewrites
+// that day's plays.
+type Recorder struct {
+	store  storage.Store
+	mu     sync.Mutex
+	guild  map[string]*sync.Mutex // Locks the documents of one guild
+	pruned map[string]string      // The day each guild's expired days were last pruned
+}
+
+// NewRecorder creates a new stats recorder
+func NewRecorder(store storage.Store) *Recorder {
+	return &Recorder{store: store, guild: make(map[string]*sync.Mutex), pruned: make(map[string]string)}
+}
+
+// dayKey returns the key of the document holding a guild's plays on the day t falls on
+func dayKey(guildID string, t time.Time) string {
+	return guildID + "/" + t.UTC().Format(dayLayout)
+}
+
+// lock locks a guild's documents and returns the func that unlocks them
+func (r *Recorder) lock(guildID string) func() {
+	r.mu.Lock()
+	mu, ok := r.guild[guildID]
+	if !ok {
+		mu = &sync.Mutex{}
+		r.guild[guildID] = mu
+	}
+	r.mu.Unlock()
+	mu.Lock()
+	return mu.Unlock
+}
+
+// dayKeys returns the keys of a guild's documents with the day each one
+// holds, or of every guild's if guildID is empty
+func (r *Recorder) dayKeys(guildID string) (map[string]time.Time, error) {
+	keys, err := r.store.Keys(collection)
+	if err != nil {
+		return nil, fmt.Errorf("error listing stats: %v", err)
+	}
+	days := make(map[string]time.Time)
+	for _, key := range keys {
+		guild, day, ok := strings.Cut(key, "/")
+		if !ok || (guildID != "" && guild != guildID) {
+			continue
+		}
+		if t, err := time.Parse(dayLayout, day); err == nil {
+			days[key] = t
+		}
+	}
+	return days, nil
+}
+
+// load returns the plays stored under key
+func (r *Recorder) load(key string) ([]Play, error) {
+	var plays []Play
+	err := r.store.Get(collection, key, &plays)
+	if err == storage.ErrNotFound {
+		return nil, nil
+	}
+	return plays, err
+}
+
+// loadSince returns the plays of a guild that started after since, oldest first
+func (r *Recorder) loadSince(guildID string, since time.Time) ([]Play, error) {
+	unlock := r.lock(guildID)
+	defer unlock()
+
+	days, err := r.dayKeys(guildID)
+	if err != nil {
+		return nil, err
+	}
+	// A day's document can hold plays from just before since
+	first := since.UTC().Truncate(24 * time.Hour)
+
+	var plays []Play
+	for key, day := range days {
+		if day.Before(first) {
+			continue
+		}
+		dayPlays, err := r.load(key)
+		if err != nil {
+			return nil, fmt.Errorf("error loading stats: %v", err)
+		}
+		for _, p := range dayPlays {
+			if p.StartedAt.After(since) {
+				plays = append(plays, p)
+			}
+		}
+	}
+	sort.Slice(plays, func(a, b int) bool { return plays[a].StartedAt.Before(plays[b].StartedAt) })
+	return plays, nil
+}
+
+// RecordPlay stores a finished playback for a guild
+func (r *Recorder) RecordPlay(guildID string, play Play) error {
+	unlock := r.lock(guildID)
+	defer unlock()
+
+	key := dayKey(guildID, play.StartedAt)
+	plays, err := r.load(key)
+	if err != nil {
+		return fmt.Errorf("error loading stats: %v", err)
+	}
+	if err := r.store.Put(collection, key, append(plays, play)); err != nil {
+		return err
+	}
+	r.prune(guildID)
+	return nil
+}
+
+// prune deletes a guild's days that are older than the retention period,
+// at most once a day per guild. r.lock(guildID) must be held.
+func (r *Recorder) prune(guildID string) {
+	today := time.Now().UTC().Format(dayLayout)
+	r.mu.Lock()
+	done := r.pruned[guildID] == today
+	r.pruned[guildID] = today
+	r.mu.Unlock()
+	if done {
+		return
+	}
+
+	days, err := r.dayKeys(guildID)
+	if err != nil {
+		log.Printf("Failed to prune play statistics: %v", err)
+		return
+	}
+	cutoff := time.Now().Add(-retention).UTC().Truncate(24 * time.Hour)
+	for key, day := range days {
+		if day.Before(cutoff) {
+			if err := r.store.Delete(collection, key); err != nil {
+				log.Printf("Failed to prune play statistics: %v", err)
+			}
+		}
+	}
+}
+
+// Migrate splits guilds' plays saved as one document per guild into one
+// document per day
+func (r *Recorder) Migrate() {
+	keys, err := r.store.Keys(collection)
+	if err != nil {
+		log.Printf("Warning: failed to migrate play statistics: %v", err)
+		return
+	}
+	migrated := 0
+	for _, guildID := range keys {
+		if strings.Contains(guildID, "/") {
+			continue
+		}
+		if err := r.migrateGuild(guildID); err != nil {
+			log.Printf("Warning: failed to migrate play statistics of guild %s: %v", guildID, err)
+			continue
+		}
+		migrated++
+	}
+	if migrated > 0 {
+		log.Printf("Split the play statistics of %d guilds into days", migrated)
+	}
+}
+
+// migrateGuild moves the plays of a guild's old document into its days
+func (r *Recorder) migrateGuild(guildID string) error {
+	unlock := r.lock(guildID)
+	defer unlock()
+
+	plays, err := r.load(guildID)
+	if err != nil {
+		return err
+	}
+	byDay := make(map[string][]Play)
+	for _, p := range plays {
+		key := dayKey(guildID, p.StartedAt)
+		byDay[key] = append(byDay[key], p)
+	}
+	for key, dayPlays := range byDay {
+		existing, err := r.load(key)
+		if err != nil {
+			return err
+		}
+		if err := r.store.Put(collection, key, append(dayPlays, existing...)); err != nil {
+			return err
+		}
+	}
+	return r.store.Delete(collection, guildID)
+}
+
+// ForgetUser removes the plays requested by a user from every guild
+// and returns how many were removed
+func (r *Recorder) ForgetUser(userID string) (int, error) {
+	days, err := r.dayKeys("")
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for key := range days {
+		guildID, _, _ := strings.Cut(key, "/")
+		n, err := r.forget(guildID, key, userID)
+		removed += n
+		if err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// forget removes the plays requested by a user from one of a guild's days
+func (r *Recorder) forget(guildID, key, userID string) (int, error) {
+	unlock := r.lock(guildID)
+	defer unlock()
+
+	plays, err := r.load(key)
+	if err != nil {
+		return 0, fmt.Errorf("error loading stats: %v", err)
+	}
+	kept := plays[:0]
+	for _, p := range plays {
+		if p.RequesterID != userID {
+			kept = append(kept, p)
+		}
+	}
+	if len(kept) == len(plays) {
+		return 0, nil
+	}
+	if len(kept) == 0 {
+		return len(plays), r.store.Delete(collection, key)
+	}
+	return len(plays) - len(kept), r.store.Put(collection, key, kept)
+}
+
+// RecentPlays returns the plays of a guild that started after since, oldest first
+func (r *Recorder) RecentPlays(guildID string, since time.Time) ([]Play, error) {
+	return r.loadSince(guildID, since)
+}
+
+// Summary aggregates the plays of a guild since the given time.
+// If userID is set only plays requested by that user are counted.
+// A zero since includes all recorded plays.
+func (r *Recorder) Summary(guildID, userID string, since time.Time, limit int) (*Summary, error) {
+	plays, err := r.loadSince(guildID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &Summary{}
+	tracks := make(map[string]*Entry)
+	requesters := make(map[string]*Entry)
+
+	for _, p := range plays {
+		if userID != "" && p.RequesterID != userID {
+			continue
+		}
+
+		summary.Plays++
+		summary.Listened += p.Listened
+
+		label := p.Title
+		if label == "" {
+			label = p.URL
+		}
+		addEntry(tracks, p.URL, label, p.Listened)
+		addEntry(requesters, p.RequesterID, p.Requester, p.Listened)
+	}
+
+	summary.TopTracks = rank(tracks, limit)
+	summary.TopRequesters = rank(requesters, limit)
+	return summary, nil
+}
+
+// addEntry counts a play towards the entry identified by key
+func addEntry(entries map[string]*Entry, key, label string, listened time.Duration) {
+	entry, ok := entries[key]
+	if !ok {
+		entry = &Entry{Key: key}
+		entries[key] = entry
+	}
+	// Keep the most recent label, e.g. when a user changes their name
+	entry.Label = label
+	entry.Plays++
+	entry.Listened += listened
+}
+
+// rank sorts entries by play count and listening time and keeps the first limit
+func rank(entries map[string]*Entry, limit int) []Entry {
+	ranked := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		ranked = append(ranked, *entry)
+	}
+
+	sort.Slice(ranked, func(a, b int) bool {
+		if ranked[a].Plays != ranked[b].Plays {
+			return ranked[a].Plays > ranked[b].Plays
+		}
+		return ranked[a].Listened > ranked[b].Listened
+	})
+
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
@@ -0,0 +1,130 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"discordbot/storage"
+)
+
+// newTestRecorder returns a recorder on an empty file store
+func newTestRecorder(t *testing.T) (*Recorder, storage.Store) {
+	t.Helper()
+	store, err := storage.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+	return NewRecorder(store), store
+}
+
+func TestRecordPlayWritesOneDay(t *testing.T) {
+	r, store := newTestRecorder(t)
+	now := time.Now()
+	plays := []Play{
+		{URL: "a", RequesterID: "alice", StartedAt: now.Add(-48 * time.Hour)},
+		{URL: "b", RequesterID: "bob", StartedAt: now.Add(-time.Minute)},
+		{URL: "a", RequesterID: "alice", StartedAt: now},
+	}
+	for _, play := range plays {
+		if err := r.RecordPlay("guild", play); err != nil {
+			t.Fatalf("RecordPlay: %v", err)
+		}
+	}
+
+	var earlier []Play
+	if err := store.Get(collection, dayKey("guild", plays[0].StartedAt), &earlier); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if len(earlier) != 1 || earlier[0].URL != "a" {
+		t.Errorf("document of two days ago holds %+v, want only its own play", earlier)
+	}
+
+	summary, err := r.Summary("guild", "", time.Time{}, 5)
+	if err != nil {
+		t.Fatalf("Summary: %v", err)
+	}
+	if summary.Plays != 3 {
+		t.Errorf("Summary plays got %d, want 3", summary.Plays)
+	}
+	if len(summary.TopTracks) == 0 || summary.TopTracks[0].Key != "a" || summary.TopTracks[0].Plays != 2 {
+		t.Errorf("top track got %+v, want a with 2 plays", summary.TopTracks)
+	}
+
+	recent, err := r.RecentPlays("guild", now.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("RecentPlays: %v", err)
+	}
+	if len(recent) != 2 || recent[0].URL != "b" || recent[1].URL != "a" {
+		t.Errorf("RecentPlays got %+v, want b then a", recent)
+	}
+}
+
+func TestRecordPlayPrunesExpiredDays(t *testing.T) {
+	r, store := newTestRecorder(t)
+	old := dayKey("guild", time.Now().Add(-retention-48*time.Hour))
+	if err := store.Put(collection, old, []Play{{URL: "old"}}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := r.RecordPlay("guild", Play{URL: "new", StartedAt: time.Now()}); err != nil {
+		t.Fatalf("RecordPlay: %v", err)
+	}
+	var plays []Play
+	if err := store.Get(collection, old, &plays); err != storage.ErrNotFound {
+		t.Errorf("expired day got error %v, want %v", err, storage.ErrNotFound)
+	}
+}
+
+func TestForgetUser(t *testing.T) {
+	r, _ := newTestRecorder(t)
+	now := time.Now()
+	for _, play := range []Play{
+		{URL: "a", RequesterID: "alice", StartedAt: now.Add(-72 * time.Hour)},
+		{URL: "b", RequesterID: "bob", StartedAt: now},
+		{URL: "c", RequesterID: "alice", StartedAt: now},
+	} {
+		if err := r.RecordPlay("guild", play); err != nil {
+			t.Fatalf("RecordPlay: %v", err)
+		}
+	}
+
+	removed, err := r.ForgetUser("alice")
+	if err != nil {
+		t.Fatalf("ForgetUser: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("ForgetUser removed %d plays, want 2", removed)
+	}
+	summary, err := r.Summary("guild", "", time.Time{}, 5)
+	if err != nil {
+		t.Fatalf("Summary: %v", err)
+	}
+	if summary.Plays != 1 || summary.TopRequesters[0].Key != "bob" {
+		t.Errorf("Summary after ForgetUser got %+v, want bob's play only", summary)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	r, store := newTestRecorder(t)
+	now := time.Now()
+	legacy := []Play{
+		{URL: "a", StartedAt: now.Add(-72 * time.Hour)},
+		{URL: "b", StartedAt: now},
+	}
+	if err := store.Put(collection, "guild", legacy); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	r.Migrate()
+
+	var plays []Play
+	if err := store.Get(collection, "guild", &plays); err != storage.ErrNotFound {
+		t.Errorf("old document got error %v, want %v", err, storage.ErrNotFound)
+	}
+	recent, err := r.RecentPlays("guild", time.Time{})
+	if err != nil {
+		t.Fatalf("RecentPlays: %v", err)
+	}
+	if len(recent) != 2 || recent[0].URL != "a" || recent[1].URL != "b" {
+		t.Errorf("RecentPlays after Migrate got %+v, want a then b", recent)
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// statsWindows maps the /stats window choices to their duration.
+// A zero duration means all recorded history.
+var statsWindows = []struct {
+	Name     string
+	Value    string
+	Duration time.Duration
+}{
+	{"Last 24 hours", "day", 24 * time.Hour},
+	{"Last 7 days", "week", 7 * 24 * time.Hour},
+	{"Last 30 days", "month", 30 * 24 * time.Hour},
+	{"All time", "all", 0},
+}
+
+// statsWindowChoices returns the window choices for the /stats command
+func statsWindowChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, len(statsWindows))
+	for idx, window := range statsWindows {
+		choices[idx] = &discordgo.ApplicationCommandOptionChoice{
+			Name:  window.Name,
+			Value: window.Value,
+		}
+	}
+	return choices
+}
+
+// handleStats handles the /stats command and its subcommands
+func handleStats(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		editResponse(s, i, tr(i, "command.choose_subcommand"))
+		return
+	}
+
+	switch options[0].Name {
+	case "music":
+		handleMusicStats(s, i, options[0].Options)
+	case "runtime":
+		handleRuntimeStats(s, i)
+	}
+}
+
+// handleMusicStats shows the guild's most played tracks and most active requesters
+func handleMusicStats(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	window := statsWindows[1]
+	var user *discordgo.User
+
+	for _, option := range options {
+		switch option.Name {
+		case "window":
+			for _, w := range statsWindows {
+				if w.Value == option.StringValue() {
+					window = w
+				}
+			}
+		case "user":
+			user = option.UserValue(s)
+		}
+	}
+
+	var since time.Time
+	if window.Duration > 0 {
+		since = time.Now().Add(-window.Duration)
+	}
+
+	userID := ""
+	if user != nil {
+		userID = user.ID
+	}
+
+	summary, err := statsRecorder.Summary(i.GuildID, userID, since, 5)
+	if err != nil {
+		log.Printf("Failed to load statistics: %v", err)
+		errorResponse(s, i, tr(i, "stats.load_failed"))
+		return
+	}
+
+	windowName := tr(i, "stats.window."+window.Value)
+	if summary.Plays == 0 {
+		editResponse(s, i, tr(i, "stats.none", windowName))
+		return
+	}
+
+	var msg strings.Builder
+	if user != nil {
+		msg.WriteString(tr(i, "stats.user_header", user.Username, windowName) + "\n")
+	} else {
+		msg.WriteString(tr(i, "stats.header", windowName) + "\n")
+	}
+	msg.WriteString(tr(i, "stats.totals", summary.Plays, formatDuration(summary.Listened)) + "\n")
+
+	msg.WriteString("\n" + tr(i, "stats.top_tracks") + "\n")
+	for idx, entry := range summary.TopTracks {
+		msg.WriteString(tr(i, "stats.track_entry", idx+1, entry.Label, entry.Plays) + "\n")
+	}
+
+	if user == nil {
+		msg.WriteString("\n" + tr(i, "stats.top_requesters") + "\n")
+		for idx, entry := range summary.TopRequesters {
+			msg.WriteString(tr(i, "stats.requester_entry", idx+1, entry.Label, entry.Plays, formatDuration(entry.Listened)) + "\n")
+		}
+	}
+
+	editResponse(s, i, msg.String())
+}
+
+// Bounds and default of the /top limit option
+var (
+	topMinLimit     = 1.0
+	topMaxLimit     = 25.0
+	topDefaultLimit = 10
+)
+
+// handleTop shows the most played tracks across all guilds
+func handleTop(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	limit := topDefaultLimit
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "limit" {
+			limit = int(option.IntValue())
+		}
+	}
+
+	top, err := playCounts.Top(limit)
+	if err != nil {
+		log.Printf("Failed to load play counts: %v", err)
+		errorResponse(s, i, tr(i, "stats.load_failed"))
+		return
+	}
+	if len(top) == 0 {
+		editResponse(s, i, tr(i, "top.none"))
+		return
+	}
+
+	var msg strings.Builder
+	msg.WriteString(tr(i, "top.header") + "\n")
+	for idx, count := range top {
+		label := count.Title
+		if label == "" {
+			label = "https://www.youtube.com/watch?v=" + count.VideoID
+		}
+		msg.WriteString(tr(i, "stats.track_entry", idx+1, label, count.Plays) + "\n")
+	}
+	editResponse(s, i, msg.String())
+}
+
+// formatDuration formats a duration as h:mm:ss or m:ss
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	h := int(d / time.Hour)
+	m := int(d/time.Minute) % 60
+	sec := int(d/time.Second) % 60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, sec)
+	}
+	return fmt.Sprintf("%d:%02d", m, sec)
+}
@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"fmt"
+
+	"discordbot/config"
+)
+
+// Open opens the store STORAGE_DRIVER selects: "file" (the default) keeps
+// JSON files in DATA_DIR, "postgres" keeps them in the database at DATABASE_URL
+func Open() (Store, error) {
+	switch driver := config.String("STORAGE_DRIVER", "file"); driver {
+	case "file":
+		return NewFileStore(config.String("DATA_DIR", ""))
+	case "postgres":
+		url := config.String("DATABASE_URL", "")
+		if url == "" {
+			return nil, fmt.Errorf("STORAGE_DRIVER=postgres needs DATABASE_URL")
+		}
+		return NewPostgresStore(url)
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_DRIVER %q, use file or postgres", driver)
+	}
+}
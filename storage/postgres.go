@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	_ "github.com/lib/pq"
+)
+
+// PostgresStore is a Store that keeps every value as a JSONB row in one table
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore connects to the database at url and creates the documents
+// table if it doesn't exist yet
+func NewPostgresStore(url string) (*PostgresStore, error) {
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database: %v", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error connecting to database: %v", err)
+	}
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS documents (
+		collection TEXT NOT NULL,
+		key TEXT NOT NULL,
+		value JSONB NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (collection, key)
+	)`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error creating documents table: %v", err)
+	}
+	return &PostgresStore{db: db}, nil
+}
+
+// Get decodes the value stored under key into v
+func (ps *PostgresStore) Get(collection, key string, v interface{}) error {
+	var data []byte
+	err := ps.db.QueryRow(`SELECT value FROM documents WHERE collection = $1 AND key = $2`, collection, key).Scan(&data)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error reading %s/%s: %v", collection, key, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("error decoding %s/%s: %v", collection, key, err)
+	}
+	return nil
+}
+
+// Put encodes v and stores it under key, replacing any existing value
+func (ps *PostgresStore) Put(collection, key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error encoding %s/%s: %v", collection, key, err)
+	}
+	_, err = ps.db.Exec(`INSERT INTO documents (collection, key, value) VALUES ($1, $2, $3)
+		ON CONFLICT (collection, key) DO UPDATE SET value = EXCLUDED.value, updated_at = now()`,
+		collection, key, data)
+	if err != nil {
+		return fmt.Errorf("error writing %s/%s: %v", collection, key, err)
+	}
+	return nil
+}
+
+// Delete removes key from the collection. Deleting a missing key is not an error.
+func (ps *PostgresStore) Delete(collection, key string) error {
+	_, err := ps.db.Exec(`DELETE FROM documents WHERE collection = $1 AND key = $2`, collection, key)
+	if err != nil {
+		return fmt.Errorf("error deleting %s/%s: %v", collection, key, err)
+	}
+	return nil
+}
+
+// Keys lists all keys stored in a collection
+func (ps *PostgresStore) Keys(collection string) ([]string, error) {
+	rows, err := ps.db.Query(`SELECT key FROM documents WHERE collection = $1 ORDER BY key`, collection)
+	if err != nil {
+		return nil, fmt.Errorf("error listing %s: %v", collection, err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("error listing %s: %v", collection, err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error listing %s: %v", collection, err)
+	}
+	return keys, nil
+}
+
+// Close closes the database connection
+func (ps *PostgresStore) Close() error {
+	return ps.db.Close()
+}
@@ -0,0 +1,27 @@
+package storage
+
+import "errors"
+
+// ErrReadOnly is returned when writing to a read-only store
+var ErrReadOnly = errors.New("store is read-only")
+
+// readOnlyStore rejects all writes to the wrapped store
+type readOnlyStore struct {
+	Store
+}
+
+// ReadOnly wraps store so it can be read but never changed, e.g. for a mirror
+// instance running against production data
+func ReadOnly(store Store) Store {
+	return readOnlyStore{Store: store}
+}
+
+// Put always fails with ErrReadOnly
+func (readOnlyStore) Put(collection, key string, v interface{}) error {
+	return ErrReadOnly
+}
+
+// Delete always fails with ErrReadOnly
+func (readOnlyStore) Delete(collection, key string) error {
+	return ErrReadOnly
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound is returned when a key does not exist in a collection
+var ErrNotFound = errors.New("not found")
+
+// Store is a simple document store used to persist bot state.
+// Values are grouped into collections and encoded as JSON.
+type Store interface {
+	Get(collection, key string, v interface{}) error
+	Put(collection, key string, v interface{}) error
+	Delete(collection, key string) error
+	Keys(collection string) ([]string, error)
+}
+
+// FileStore is a Store that keeps one JSON file per key on disk
+type FileStore struct {
+	Dir string
+	mu  sync.RWMutex
+}
+
+// NewFileStore creates a file store rooted at dir
+func NewFileStore(dir string) (*FileStore, error) {
+	if dir == "" {
+		dir = "data"
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating data directory: %v", err)
+	}
+	return &FileStore{Dir: dir}, nil
+}
+
+// path returns the file path for a key, escaping it so any string is safe to use
+func (fs *FileStore) path(collection, key string) string {
+	return filepath.Join(fs.Dir, url.PathEscape(collection), url.PathEscape(key)+".json")
+}
+
+// Get decodes the value stored under key into v
+func (fs *FileStore) Get(collection, key string, v interface{}) error {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	data, err := os.ReadFile(fs.path(collection, key))
+	if os.IsNotExist(err) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error reading %s/%s: %v", collection, key, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("error decoding %s/%s: %v", collection, key, err)
+	}
+	return nil
+}
+
+// Put encodes v and stores it under key, replacing any existing value
+func (fs *FileStore) Put(collection, key string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding %s/%s: %v", collection, key, err)
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	path := fs.path(collection, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating collection directory: %v", err)
+	}
+
+	// Write to a temporary file first so a crash never leaves a half-written value
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("error writing %s/%s: %v", collection, key, err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Delete removes key from the collection. Deleting a missing key is not an error.
+func (fs *FileStore) Delete(collection, key string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	err := os.Remove(fs.path(collection, key))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error deleting %s/%s: %v", collection, key, err)
+	}
+	return nil
+}
+
+// Collections lists the collections stored in the data directory
+func (fs *FileStore) Collections() ([]string, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	entries, err := os.ReadDir(fs.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("error listing collections: %v", err)
+	}
+
+	var collections []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		collection, err := url.PathUnescape(entry.Name())
+		if err != nil {
+			continue
+		}
+		collections = append(collections, collection)
+	}
+	return collections, nil
+}
+
+// Keys lists all keys stored in a collection
+func (fs *FileStore) Keys(collection string) ([]string, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	entries, err := os.ReadDir(filepath.Join(fs.Dir, url.PathEscape(collection)))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error listing %s: %v", collection, err)
+	}
+
+	var keys []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		key, err := url.PathUnescape(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
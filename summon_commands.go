@@ -0,0 +1,80 @@
+package main
+
+import (
+	"time"
+
+	"discordbot/audio"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// handleSummon brings the bot into the caller's voice channel. Members who
+// aren't DJs can't take it away from others who are still listening.
+func handleSummon(s *discordgo.Session, i *discordgo.InteractionCreate, vi *audio.VoiceInstance) {
+	vs, err := findUserVoiceState(s, i.GuildID, i.Member.User.ID)
+	if err != nil {
+		errorResponse(s, i, tr(i, "voice.user_not_connected"))
+		return
+	}
+
+	vi.Mu.Lock()
+	channelID := vi.ChannelID
+	vi.Mu.Unlock()
+	if vi.Connected() && channelID != vs.ChannelID && !isDJ(s, i) {
+		count, err := listeners(s, i.GuildID, channelID)
+		if err != nil {
+			errorResponse(s, i, tr(i, "error", err))
+			return
+		}
+		if count > 0 {
+			errorResponse(s, i, tr(i, "move.others_listening", channelID))
+			return
+		}
+	}
+
+	moveBot(s, i, vi, vs.ChannelID)
+}
+
+// handleMove moves the bot to a voice channel of the DJ's choice
+func handleMove(s *discordgo.Session, i *discordgo.InteractionCreate, vi *audio.VoiceInstance) {
+	if !isDJ(s, i) {
+		errorResponse(s, i, tr(i, "move.dj_only"))
+		return
+	}
+	moveBot(s, i, vi, i.ApplicationCommandData().Options[0].ChannelValue(nil).ID)
+}
+
+// moveBot takes the bot to a voice channel after checking it may connect and
+// speak there. A track that is playing carries on without restarting.
+func moveBot(s *discordgo.Session, i *discordgo.InteractionCreate, vi *audio.VoiceInstance, channelID string) {
+	vi.Mu.Lock()
+	current := vi.ChannelID
+	vi.Mu.Unlock()
+	if vi.Connected() && current == channelID {
+		editResponse(s, i, tr(i, "move.already_there", channelID))
+		return
+	}
+	if !voicePreflight(s, i, channelID) {
+		return
+	}
+
+	if vi.Connected() {
+		if err := vi.Move(channelID); err != nil {
+			errorResponse(s, i, tr(i, "voice.join_failed", err))
+			return
+		}
+	} else {
+		if err := vi.Join(s, channelID); err != nil {
+			errorResponse(s, i, tr(i, "voice.join_failed", err))
+			return
+		}
+
+		// Small delay to ensure voice connection is ready
+		time.Sleep(500 * time.Millisecond)
+		if !vi.Ready() {
+			errorResponse(s, i, tr(i, "voice.connect_failed"))
+			return
+		}
+	}
+	editResponse(s, i, tr(i, "move.moved", channelID))
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"discordbot/config"
+	"discordbot/telemetry"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// defaultTelemetryInterval is how often usage reports are sent
+const defaultTelemetryInterval = time.Hour
+
+// usage collects anonymized usage counts. It stays nil, recording nothing,
+// unless the operator opts in by setting TELEMETRY_ENDPOINT.
+var usage *telemetry.Reporter
+
+// startTelemetry sends usage reports to TELEMETRY_ENDPOINT if it is set
+func startTelemetry(s *discordgo.Session) {
+	endpoint := config.String("TELEMETRY_ENDPOINT", "")
+	if endpoint == "" || readOnly {
+		return
+	}
+
+	minutes := config.Int("TELEMETRY_INTERVAL_MINUTES", int(defaultTelemetryInterval/time.Minute), func(minutes int) bool { return minutes > 0 })
+	interval := time.Duration(minutes) * time.Minute
+
+	reporter, err := telemetry.New(endpoint, store, func() int {
+		s.State.RLock()
+		defer s.State.RUnlock()
+		return len(s.State.Guilds)
+	})
+	if err != nil {
+		log.Printf("Telemetry disabled: %v", err)
+		return
+	}
+
+	usage = reporter
+	go usage.Run(ctx, interval)
+	log.Printf("Sending anonymized usage reports every %s", interval)
+}
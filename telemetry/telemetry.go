@@ -0,0 +1,199 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"discordbot/storage"
+)
+
+const (
+	collection  = "telemetry"
+	instanceKey = "instance"
+)
+
+// CommandUsage counts how often a command ran and how often it failed
+type CommandUsage struct {
+	Count  int `json:"count"`
+	Errors int `json:"errors"`
+}
+
+// Report is the anonymized usage sent to the operator's endpoint. It holds
+// aggregate counts only: no guild, channel or user IDs and no track URLs.
+type Report struct {
+	InstanceID  string                  `json:"instance_id"`
+	PeriodStart time.Time               `json:"period_start"`
+	PeriodEnd   time.Time               `json:"period_end"`
+	Guilds      int                     `json:"guilds"`
+	Commands    map[string]CommandUsage `json:"commands"`
+	Providers   map[string]int          `json:"providers"`
+}
+
+// Reporter aggregates usage in memory and periodically posts it to an endpoint.
+// A nil Reporter records nothing, so callers don't need to check whether
+// telemetry is enabled.
+type Reporter struct {
+	endpoint   string
+	instanceID string
+	client     *http.Client
+	guilds     func() int
+
+	mu        sync.Mutex
+	since     time.Time
+	commands  map[string]CommandUsage
+	providers map[string]int
+}
+
+// New creates a reporter posting to endpoint. The instance ID is random and
+// kept in the store so reports from one deployment can be told apart without
+// identifying it. guilds returns the number of guilds the bot is in.
+func New(endpoint string, store storage.Store, guilds func() int) (*Reporter, error) {
+	var instanceID string
+	err := store.Get(collection, instanceKey, &instanceID)
+	if err == storage.ErrNotFound {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("error generating instance ID: %v", err)
+		}
+		instanceID = hex.EncodeToString(b)
+		err = store.Put(collection, instanceKey, instanceID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error loading telemetry instance ID: %v", err)
+	}
+
+	return &Reporter{
+		endpoint:   endpoint,
+		instanceID: instanceID,
+		client:     &http.Client{Timeout: 30 * time.Second},
+		guilds:     guilds,
+		since:      time.Now(),
+		commands:   make(map[string]CommandUsage),
+		providers:  make(map[string]int),
+	}, nil
+}
+
+// Command counts a slash command run
+func (r *Reporter) Command(name string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	usage := r.commands[name]
+	usage.Count++
+	r.commands[name] = usage
+}
+
+// CommandError counts a slash command that failed
+func (r *Reporter) CommandError(name string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	usage := r.commands[name]
+	usage.Errors++
+	r.commands[name] = usage
+}
+
+// Provider counts a track played from a source such as "youtube" or "spotify"
+func (r *Reporter) Provider(name string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[name]++
+}
+
+// Run sends a report every interval until ctx is done, then sends a final one
+func (r *Reporter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.flush()
+		case <-ctx.Done():
+			r.flush()
+			return
+		}
+	}
+}
+
+// flush sends the counts gathered since the last successful report.
+// If sending fails the counts are kept for the next attempt.
+func (r *Reporter) flush() {
+	r.mu.Lock()
+	report := Report{
+		InstanceID:  r.instanceID,
+		PeriodStart: r.since,
+		PeriodEnd:   time.Now(),
+		Commands:    r.commands,
+		Providers:   r.providers,
+	}
+	r.since = report.PeriodEnd
+	r.commands = make(map[string]CommandUsage)
+	r.providers = make(map[string]int)
+	r.mu.Unlock()
+
+	if len(report.Commands) == 0 && len(report.Providers) == 0 {
+		return
+	}
+	if r.guilds != nil {
+		report.Guilds = r.guilds()
+	}
+
+	if err := r.send(report); err != nil {
+		log.Printf("Failed to send usage report: %v", err)
+		r.restore(report)
+	}
+}
+
+// send posts a report to the endpoint
+func (r *Reporter) send(report Report) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("error encoding report: %v", err)
+	}
+
+	resp, err := r.client.Post(r.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// restore merges the counts of an unsent report back so they go out with the next one
+func (r *Reporter) restore(report Report) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.since = report.PeriodStart
+	for name, usage := range report.Commands {
+		current := r.commands[name]
+		current.Count += usage.Count
+		current.Errors += usage.Errors
+		r.commands[name] = current
+	}
+	for name, count := range report.Providers {
+		r.providers[name] += count
+	}
+}
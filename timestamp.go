@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// timestampPattern matches YouTube's t values: plain seconds or e.g. 1h2m3s
+var timestampPattern = regexp.MustCompile(`^(?:(\d+)h)?(?:(\d+)m)?(?:(\d+)s?)?$`)
+
+// linkStartOffset returns the start time given by a YouTube link's t
+// parameter or #t= fragment, and whether it has a valid one. t=0 gives 0 and true.
+func linkStartOffset(link string) (time.Duration, bool) {
+	if !isYouTubeURL(link) {
+		return 0, false
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return 0, false
+	}
+	value := parsed.Query().Get("t")
+	if fragment, err := url.ParseQuery(parsed.Fragment); value == "" && err == nil {
+		value = fragment.Get("t")
+	}
+	return parseTimestamp(value)
+}
+
+// parseTimestamp parses "90", "90s" or "1m30s" style timestamps and reports
+// whether value is one
+func parseTimestamp(value string) (time.Duration, bool) {
+	matches := timestampPattern.FindStringSubmatch(value)
+	if value == "" || matches == nil {
+		return 0, false
+	}
+	var offset time.Duration
+	for n, unit := range []time.Duration{time.Hour, time.Minute, time.Second} {
+		if matches[n+1] != "" {
+			amount, _ := strconv.Atoi(matches[n+1])
+			offset += time.Duration(amount) * unit
+		}
+	}
+	return offset, true
+}
+
+// nowPlayingText is the now-playing message for the track labelled label,
+// mentioning where playback started if it didn't start at the beginning
+func nowPlayingText(guildID, label string, start time.Duration) string {
+	if start > 0 {
+		return trGuild(guildID, "player.now_playing_from", label, formatDuration(start))
+	}
+	return trGuild(guildID, "player.now_playing", label)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"90", 90 * time.Second, true},
+		{"90s", 90 * time.Second, true},
+		{"1m30s", 90 * time.Second, true},
+		{"1h2m3s", time.Hour + 2*time.Minute + 3*time.Second, true},
+		{"2m", 2 * time.Minute, true},
+		{"0", 0, true},
+		{"0s", 0, true},
+		{"", 0, false},
+		{"abc", 0, false},
+		{"1m30", 90 * time.Second, true},
+		{"-5", 0, false},
+		{"1.5", 0, false},
+	}
+	for _, test := range tests {
+		got, ok := parseTimestamp(test.value)
+		if got != test.want || ok != test.wantOK {
+			t.Errorf("parseTimestamp(%q) got %s, %t, want %s, %t", test.value, got, ok, test.want, test.wantOK)
+		}
+	}
+}
+
+func TestLinkStartOffset(t *testing.T) {
+	tests := []struct {
+		link   string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=43", 43 * time.Second, true},
+		{"https://youtu.be/dQw4w9WgXcQ?t=1m2s", 62 * time.Second, true},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ#t=90", 90 * time.Second, true},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=0", 0, true},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", 0, false},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=soon", 0, false},
+		{"https://example.com/song.mp3?t=30", 0, false},
+	}
+	for _, test := range tests {
+		got, ok := linkStartOffset(test.link)
+		if got != test.want || ok != test.wantOK {
+			t.Errorf("linkStartOffset(%q) got %s, %t, want %s, %t", test.link, got, ok, test.want, test.wantOK)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"strings"
+
+	"discordbot/audio"
+)
+
+// markdownEscaper keeps titles from being read as Discord formatting
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, `*`, `\*`, `_`, `\_`, `~`, `\~`, "`", "\\`", `|`, `\|`,
+)
+
+// trackLabel names a track in player messages: its title in bold followed by
+// the link, which Discord still previews, or only the link if the title is unknown
+func trackLabel(track *audio.Track, url string) string {
+	if track.Title == "" {
+		return url
+	}
+	return "**" + markdownEscaper.Replace(track.Title) + "** " + url
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"discordbot/audio"
+	"discordbot/audio/invidious"
+	"discordbot/config"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// maxTrendingTracks is how many trending tracks the menu offers, Discord's limit for select menus
+	maxTrendingTracks = 25
+	// trendingTTL is how long a trending menu can be picked from
+	trendingTTL = 15 * time.Minute
+	// trendingSelectPrefix starts the custom ID of trending menus
+	trendingSelectPrefix = "trending:"
+	// maxMenuText is Discord's limit for select menu labels and descriptions
+	maxMenuText = 100
+)
+
+// invidiousClient fetches the trending feed, or holds nil without INVIDIOUS_URL
+var invidiousClient atomic.Pointer[invidious.Client]
+
+// regionPattern matches the two-letter country codes /trending accepts
+var regionPattern = regexp.MustCompile(`^[A-Za-z]{2}$`)
+
+// trendingMenu holds the tracks a /trending menu offers
+type trendingMenu struct {
+	RequesterID string
+	Videos      []invidious.Video
+	Expires     time.Time
+}
+
+// trendingMenus holds the open trending menus, keyed by token
+var trendingMenus = struct {
+	sync.Mutex
+	menus map[string]*trendingMenu
+}{menus: make(map[string]*trendingMenu)}
+
+// setupInvidious sets up the Invidious instance at INVIDIOUS_URL for
+// /trending. It runs again when the configuration is reloaded.
+func setupInvidious() {
+	baseURL := config.String("INVIDIOUS_URL", "")
+	if baseURL == "" {
+		invidiousClient.Store(nil)
+		log.Printf("INVIDIOUS_URL not set, /trending will be disabled")
+		return
+	}
+	if current := invidiousClient.Load(); current != nil && current.BaseURL == strings.TrimRight(baseURL, "/") {
+		return
+	}
+	invidiousClient.Store(invidious.NewClient(baseURL))
+}
+
+// handleTrending shows a menu of the trending music in a region to queue from
+func handleTrending(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	client := invidiousClient.Load()
+	if client == nil {
+		errorResponse(s, i, tr(i, "trending.unavailable"))
+		return
+	}
+
+	var region string
+	for _, option := range i.ApplicationCommandData().Options {
+		if option.Name == "region" {
+			region = strings.TrimSpace(option.StringValue())
+		}
+	}
+	if region != "" && !regionPattern.MatchString(region) {
+		errorResponse(s, i, tr(i, "trending.bad_region", region))
+		return
+	}
+
+	videos, err := client.TrendingMusic(region)
+	if err != nil {
+		log.Printf("Failed to load trending music for region %q: %v", region, err)
+		errorResponse(s, i, tr(i, "trending.failed"))
+		return
+	}
+	if len(videos) == 0 {
+		editResponse(s, i, tr(i, "trending.empty"))
+		return
+	}
+	if len(videos) > maxTrendingTracks {
+		videos = videos[:maxTrendingTracks]
+	}
+
+	token := newConfirmationToken()
+	trendingMenus.Lock()
+	// Drop expired menus while we're here
+	for key, menu := range trendingMenus.menus {
+		if time.Now().After(menu.Expires) {
+			delete(trendingMenus.menus, key)
+		}
+	}
+	trendingMenus.menus[token] = &trendingMenu{
+		RequesterID: i.Member.User.ID,
+		Videos:      videos,
+		Expires:     time.Now().Add(trendingTTL),
+	}
+	trendingMenus.Unlock()
+
+	options := make([]discordgo.SelectMenuOption, len(videos))
+	for idx, video := range videos {
+		description := video.Author
+		if video.Duration > 0 {
+			description += " · " + formatDuration(video.Duration)
+		}
+		options[idx] = discordgo.SelectMenuOption{
+			Label:       truncateText(video.Title, maxMenuText),
+			Value:       video.ID,
+			Description: truncateText(description, maxMenuText),
+		}
+	}
+	minValues := 1
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					CustomID:    trendingSelectPrefix + token,
+					Placeholder: tr(i, "trending.placeholder"),
+					MinValues:   &minValues,
+					MaxValues:   len(options),
+					Options:     options,
+				},
+			},
+		},
+	}
+
+	content := tr(i, "trending.header")
+	if region != "" {
+		content = tr(i, "trending.header_region", strings.ToUpper(region))
+	}
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content:    &content,
+		Components: &components,
+	})
+	if err != nil {
+		log.Printf("Failed to send trending menu: %v", err)
+	}
+}
+
+// handleTrendingSelect queues the tracks picked from a trending menu. The
+// menu stays so more tracks can be picked; each pick is answered like /play.
+func handleTrendingSelect(s *discordgo.Session, i *discordgo.InteractionCreate, token string) {
+	trendingMenus.Lock()
+	menu, ok := trendingMenus.menus[token]
+	trendingMenus.Unlock()
+
+	switch {
+	case !ok || time.Now().After(menu.Expires):
+		respondEphemeral(s, i, tr(i, "trending.expired"))
+		return
+	case menu.RequesterID != i.Member.User.ID:
+		respondEphemeral(s, i, tr(i, "trending.not_requester"))
+		return
+	}
+
+	// Picks queue tracks, so they go through the same checks as /play
+	picks := i.MessageComponentData().Values
+	defer recordCommand(i, "trending", "/trending pick:"+strings.Join(picks, ","))
+	if !checkCommand(s, i, "trending pick") {
+		return
+	}
+
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+	if err != nil {
+		log.Printf("Failed to acknowledge trending pick: %v", err)
+		return
+	}
+
+	vi := voiceManager.GetVoiceInstance(i.GuildID)
+	if !vi.Connected() && !joinUserChannel(s, i, vi) {
+		return
+	}
+
+	var tracks []*audio.Track
+	for _, id := range picks {
+		for _, video := range menu.Videos {
+			if video.ID == id {
+				track := newTrack(i, video.URL())
+				track.Title = video.Title
+				track.Duration = video.Duration
+				tracks = append(tracks, track)
+			}
+		}
+	}
+	if len(tracks) == 0 {
+		errorResponse(s, i, tr(i, "trending.expired"))
+		return
+	}
+
+	enqueueTracks(s, i, vi, tracks, positionEnd)
+}
+
+// truncateText shortens text to at most limit characters, ending it with an ellipsis if cut
+func truncateText(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
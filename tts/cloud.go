@@ -0,0 +1,135 @@
+package tts
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const (
+	// googleCloudURL is the Google Cloud Text-to-Speech synthesis endpoint
+	googleCloudURL = "https://texttospeech.googleapis.com/v1/text:synthesize"
+	// azureURL is the Azure Speech synthesis endpoint, formatted with the region
+	azureURL = "https://%s.tts.speech.microsoft.com/cognitiveservices/v1"
+)
+
+// azureVoices are the voices the azure engine speaks with, by language
+var azureVoices = map[string]string{
+	"en": "en-US-JennyNeural",
+	"de": "de-DE-KatjaNeural",
+}
+
+// GoogleCloud speaks through the Google Cloud Text-to-Speech API
+type GoogleCloud struct {
+	client   *http.Client
+	endpoint string
+	key      string
+	voice    string
+}
+
+// googleCloudRequest is the body of a Google Cloud synthesis request
+type googleCloudRequest struct {
+	Input struct {
+		Text string `json:"text"`
+	} `json:"input"`
+	Voice struct {
+		LanguageCode string `json:"languageCode"`
+		Name         string `json:"name,omitempty"`
+	} `json:"voice"`
+	AudioConfig struct {
+		AudioEncoding string `json:"audioEncoding"`
+	} `json:"audioConfig"`
+}
+
+// Synthesize has Google Cloud speak text as an MP3 file
+func (s *GoogleCloud) Synthesize(text, lang string) (string, error) {
+	text, err := checkText(text)
+	if err != nil {
+		return "", err
+	}
+
+	var req googleCloudRequest
+	req.Input.Text = text
+	req.Voice.LanguageCode = locale(lang)
+	req.Voice.Name = s.voice
+	req.AudioConfig.AudioEncoding = "MP3"
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := s.client.Post(s.endpoint+"?key="+url.QueryEscape(s.key), "application/json", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("error requesting speech: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("speech request failed: %s", resp.Status)
+	}
+
+	var result struct {
+		AudioContent string `json:"audioContent"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode the speech response: %v", err)
+	}
+	audio, err := base64.StdEncoding.DecodeString(result.AudioContent)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode the speech: %v", err)
+	}
+	return tempAudio(bytes.NewReader(audio), ".mp3")
+}
+
+// Azure speaks through the Azure AI Speech API
+type Azure struct {
+	client   *http.Client
+	endpoint string
+	key      string
+	voice    string
+}
+
+// Synthesize has Azure speak text as an MP3 file
+func (s *Azure) Synthesize(text, lang string) (string, error) {
+	text, err := checkText(text)
+	if err != nil {
+		return "", err
+	}
+
+	voice := s.voice
+	if voice == "" {
+		voice = azureVoices[lang]
+	}
+	if voice == "" {
+		voice = azureVoices["en"]
+	}
+
+	// Azure takes SSML, so the text is escaped to keep it from adding markup
+	var escaped bytes.Buffer
+	if err := xml.EscapeText(&escaped, []byte(text)); err != nil {
+		return "", err
+	}
+	ssml := fmt.Sprintf(`<speak version="1.0" xml:lang="%s"><voice name="%s">%s</voice></speak>`,
+		locale(lang), voice, escaped.String())
+
+	req, err := http.NewRequest(http.MethodPost, s.endpoint, bytes.NewReader([]byte(ssml)))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Ocp-Apim-Subscription-Key", s.key)
+	req.Header.Set("Content-Type", "application/ssml+xml")
+	req.Header.Set("X-Microsoft-OutputFormat", "audio-24khz-48kbitrate-mono-mp3")
+	req.Header.Set("User-Agent", "discordbot")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error requesting speech: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("speech request failed: %s", resp.Status)
+	}
+	return tempAudio(resp.Body, ".mp3")
+}
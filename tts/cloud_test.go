@@ -0,0 +1,90 @@
+package tts
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGoogleCloudSynthesize(t *testing.T) {
+	var got googleCloudRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key := r.URL.Query().Get("key"); key != "secret" {
+			t.Errorf("key got %q, want %q", key, "secret")
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"audioContent": base64.StdEncoding.EncodeToString([]byte("mp3"))})
+	}))
+	defer server.Close()
+
+	s := &GoogleCloud{client: server.Client(), endpoint: server.URL, key: "secret"}
+	path, err := s.Synthesize("Hallo Welt", "de")
+	if err != nil {
+		t.Fatalf("Synthesize: %v", err)
+	}
+	defer os.Remove(path)
+
+	if got.Input.Text != "Hallo Welt" || got.Voice.LanguageCode != "de-DE" || got.AudioConfig.AudioEncoding != "MP3" {
+		t.Errorf("request got %+v, want the text in de-DE as MP3", got)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "mp3" {
+		t.Errorf("speech file holds %q, want %q", data, "mp3")
+	}
+}
+
+func TestAzureSynthesize(t *testing.T) {
+	var ssml string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key := r.Header.Get("Ocp-Apim-Subscription-Key"); key != "secret" {
+			t.Errorf("key got %q, want %q", key, "secret")
+		}
+		body, _ := io.ReadAll(r.Body)
+		ssml = string(body)
+		w.Write([]byte("mp3"))
+	}))
+	defer server.Close()
+
+	s := &Azure{client: server.Client(), endpoint: server.URL, key: "secret"}
+	path, err := s.Synthesize("Rock & <Roll>", "en")
+	if err != nil {
+		t.Fatalf("Synthesize: %v", err)
+	}
+	defer os.Remove(path)
+
+	if !strings.Contains(ssml, `<voice name="en-US-JennyNeural">Rock &amp; &lt;Roll&gt;</voice>`) {
+		t.Errorf("SSML got %s, want the escaped text in the English voice", ssml)
+	}
+}
+
+func TestCloudSynthesizeFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	providers := map[string]Provider{
+		"google-cloud": &GoogleCloud{client: server.Client(), endpoint: server.URL, key: "secret"},
+		"azure":        &Azure{client: server.Client(), endpoint: server.URL, key: "secret"},
+	}
+	for name, provider := range providers {
+		if path, err := provider.Synthesize("hello", "en"); err == nil {
+			os.Remove(path)
+			t.Errorf("%s got no error for a rejected request", name)
+		}
+	}
+}
+
+func TestNewNeedsCredentials(t *testing.T) {
+	for _, engine := range []string{EngineGoogleCloud, EngineAzure} {
+		if _, err := New(engine, Options{}); err == nil {
+			t.Errorf("New(%q) without credentials got no error", engine)
+		}
+	}
+}
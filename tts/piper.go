@@ -0,0 +1,65 @@
+package tts
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Piper speaks with a local Piper, a neural engine that sounds more natural
+// than espeak but needs a voice model per language
+type Piper struct {
+	binary string
+	models map[string]string
+}
+
+// newPiper finds the piper binary and checks there is a model to speak with
+func newPiper(models map[string]string) (*Piper, error) {
+	path, err := exec.LookPath("piper")
+	if err != nil {
+		return nil, fmt.Errorf("piper is not installed")
+	}
+	if len(models) == 0 {
+		return nil, fmt.Errorf("the piper engine needs a voice model")
+	}
+	return &Piper{binary: path, models: models}, nil
+}
+
+// model returns the voice model for lang, falling back to the default one
+// and then to any model so something is said at all
+func (s *Piper) model(lang string) string {
+	if model, ok := s.models[lang]; ok {
+		return model
+	}
+	if model, ok := s.models[""]; ok {
+		return model
+	}
+	for _, model := range s.models {
+		return model
+	}
+	return ""
+}
+
+// Synthesize renders text to a WAV file with the voice model for lang
+func (s *Piper) Synthesize(text, lang string) (string, error) {
+	text, err := checkText(text)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.CreateTemp("", "tts-*.wav")
+	if err != nil {
+		return "", fmt.Errorf("error creating speech file: %v", err)
+	}
+	file.Close()
+
+	// Piper reads the text on stdin, one utterance per line
+	cmd := exec.Command(s.binary, "--model", s.model(lang), "--output_file", file.Name())
+	cmd.Stdin = strings.NewReader(strings.ReplaceAll(text, "\n", " "))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("piper failed: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return file.Name(), nil
+}
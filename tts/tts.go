@@ -0,0 +1,179 @@
+package tts
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Engine names TTS_ENGINE accepts
+const (
+	EngineEspeak      = "espeak"
+	EnginePiper       = "piper"
+	EngineGoogle      = "google"
+	EngineGoogleCloud = "google-cloud"
+	EngineAzure       = "azure"
+)
+
+// MaxLength is the longest text spoken in one go; Google's endpoint rejects longer ones
+const MaxLength = 200
+
+// googleURL is the Google Translate speech endpoint used by the google engine
+const googleURL = "https://translate.google.com/translate_tts"
+
+// Provider turns short texts into speech audio files
+type Provider interface {
+	// Synthesize speaks text in the language lang (e.g. "en" or "de") into a
+	// temporary audio file and returns its path. The caller removes the file.
+	Synthesize(text, lang string) (string, error)
+}
+
+// Options configure the engines that need more than their name
+type Options struct {
+	// PiperModels maps languages to the Piper voice model speaking them; the
+	// model under "" speaks every other language
+	PiperModels map[string]string
+
+	GoogleAPIKey string // Google Cloud API key with Text-to-Speech enabled
+	GoogleVoice  string // Google Cloud voice name; empty picks one for the language
+
+	AzureKey    string // Azure Speech resource key
+	AzureRegion string // Azure region of the Speech resource, e.g. westeurope
+	AzureVoice  string // Azure voice name; empty picks one for the language
+}
+
+// New creates the provider for engine. The local espeak and piper engines
+// need their binary in PATH; the cloud engines need outbound HTTPS, and
+// google-cloud and azure an API key in opts.
+func New(engine string, opts Options) (Provider, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	switch engine {
+	case EngineEspeak:
+		for _, name := range []string{"espeak-ng", "espeak"} {
+			if path, err := exec.LookPath(name); err == nil {
+				return &Espeak{binary: path}, nil
+			}
+		}
+		return nil, fmt.Errorf("neither espeak-ng nor espeak is installed")
+	case EnginePiper:
+		return newPiper(opts.PiperModels)
+	case EngineGoogle:
+		return &Google{client: client}, nil
+	case EngineGoogleCloud:
+		if opts.GoogleAPIKey == "" {
+			return nil, fmt.Errorf("the google-cloud engine needs an API key")
+		}
+		return &GoogleCloud{client: client, endpoint: googleCloudURL, key: opts.GoogleAPIKey, voice: opts.GoogleVoice}, nil
+	case EngineAzure:
+		if opts.AzureKey == "" || opts.AzureRegion == "" {
+			return nil, fmt.Errorf("the azure engine needs a key and a region")
+		}
+		endpoint := fmt.Sprintf(azureURL, opts.AzureRegion)
+		return &Azure{client: client, endpoint: endpoint, key: opts.AzureKey, voice: opts.AzureVoice}, nil
+	default:
+		return nil, fmt.Errorf("unknown TTS engine %q", engine)
+	}
+}
+
+// locales are the regional variants cloud voices are picked for, by language
+var locales = map[string]string{
+	"en": "en-US",
+	"de": "de-DE",
+}
+
+// locale returns the regional variant of lang to pick cloud voices for
+func locale(lang string) string {
+	if l, ok := locales[lang]; ok {
+		return l
+	}
+	return lang
+}
+
+// tempAudio writes audio to a temporary file with the extension ext and
+// returns its path
+func tempAudio(r io.Reader, ext string) (string, error) {
+	file, err := os.CreateTemp("", "tts-*"+ext)
+	if err != nil {
+		return "", fmt.Errorf("error creating speech file: %v", err)
+	}
+	defer file.Close()
+	if _, err := io.Copy(file, r); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("error saving speech: %v", err)
+	}
+	return file.Name(), nil
+}
+
+// checkText trims text and checks that it can be spoken in one go
+func checkText(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", fmt.Errorf("nothing to say")
+	}
+	if len([]rune(text)) > MaxLength {
+		return "", fmt.Errorf("text is longer than %d characters", MaxLength)
+	}
+	return text, nil
+}
+
+// Espeak speaks with a local espeak-ng or espeak
+type Espeak struct {
+	binary string
+}
+
+// Synthesize renders text to a WAV file with espeak-ng or espeak
+func (s *Espeak) Synthesize(text, lang string) (string, error) {
+	text, err := checkText(text)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.CreateTemp("", "tts-*.wav")
+	if err != nil {
+		return "", fmt.Errorf("error creating speech file: %v", err)
+	}
+	file.Close()
+
+	// Pass the text on stdin so it is never parsed as options
+	cmd := exec.Command(s.binary, "-v", lang, "-w", file.Name(), "--stdin")
+	cmd.Stdin = strings.NewReader(text)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("espeak failed: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return file.Name(), nil
+}
+
+// Google speaks through the Google Translate speech endpoint
+type Google struct {
+	client *http.Client
+}
+
+// Synthesize downloads text spoken by Google Translate as an MP3 file
+func (s *Google) Synthesize(text, lang string) (string, error) {
+	text, err := checkText(text)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{
+		"ie":     {"UTF-8"},
+		"client": {"tw-ob"},
+		"tl":     {lang},
+		"q":      {text},
+	}
+	resp, err := s.client.Get(googleURL + "?" + query.Encode())
+	if err != nil {
+		return "", fmt.Errorf("error requesting speech: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("speech request failed: %s", resp.Status)
+	}
+	return tempAudio(resp.Body, ".mp3")
+}
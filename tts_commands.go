@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	"discordbot/audio"
+	"discordbot/config"
+	"discordbot/tts"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// speaker synthesizes /say messages and track announcements; nil if TTS is off
+var speaker tts.Provider
+
+// speechTurns makes speech take turns within a guild, so an announcement
+// and a /say don't synthesize and talk over each other
+var speechTurns = struct {
+	sync.Mutex
+	guilds map[string]*sync.Mutex
+}{guilds: make(map[string]*sync.Mutex)}
+
+// setupTTS sets up the TTS_ENGINE speech engine, espeak unless configured otherwise
+func setupTTS() {
+	engine := config.String("TTS_ENGINE", tts.EngineEspeak)
+	if engine == "off" {
+		return
+	}
+
+	synth, err := tts.New(engine, tts.Options{
+		PiperModels:  piperModels(config.List("PIPER_MODEL")),
+		GoogleAPIKey: config.String("GOOGLE_TTS_API_KEY", ""),
+		GoogleVoice:  config.String("GOOGLE_TTS_VOICE", ""),
+		AzureKey:     config.String("AZURE_SPEECH_KEY", ""),
+		AzureRegion:  config.String("AZURE_SPEECH_REGION", ""),
+		AzureVoice:   config.String("AZURE_SPEECH_VOICE", ""),
+	})
+	if err != nil {
+		log.Printf("Warning: text-to-speech disabled: %v", err)
+		return
+	}
+	speaker = synth
+}
+
+// piperModels reads PIPER_MODEL entries, either a model path for every
+// language or lang=path for one language
+func piperModels(entries []string) map[string]string {
+	models := make(map[string]string)
+	for _, entry := range entries {
+		lang, path, ok := strings.Cut(entry, "=")
+		if !ok {
+			lang, path = "", entry
+		}
+		models[strings.TrimSpace(lang)] = strings.TrimSpace(path)
+	}
+	return models
+}
+
+// speechTurn returns the lock speech in a guild takes turns with
+func speechTurn(guildID string) *sync.Mutex {
+	speechTurns.Lock()
+	defer speechTurns.Unlock()
+	turn, ok := speechTurns.guilds[guildID]
+	if !ok {
+		turn = &sync.Mutex{}
+		speechTurns.guilds[guildID] = turn
+	}
+	return turn
+}
+
+// speak says text in the guild's voice channel in the guild's language,
+// after whatever the guild is already being told
+func speak(vi *audio.VoiceInstance, text string) error {
+	turn := speechTurn(vi.GuildID)
+	turn.Lock()
+	defer turn.Unlock()
+
+	file, err := speaker.Synthesize(text, guildLanguage(vi.GuildID))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file)
+	return vi.Say(file)
+}
+
+// announceTrack speaks the title of the track about to play if the guild wants spoken announcements
+func announceTrack(vi *audio.VoiceInstance, track *audio.Track) {
+	if speaker == nil || !settingsStore.Get(vi.GuildID).SpokenAnnouncements {
+		return
+	}
+	if err := fillTrackInfo(track); err != nil || track.Title == "" {
+		// Reading out a URL helps nobody
+		return
+	}
+	if err := speak(vi, trGuild(vi.GuildID, "tts.now_playing", track.Title)); err != nil {
+		log.Printf("Error announcing track in guild %s: %v", vi.GuildID, err)
+	}
+}
+
+// handleSay speaks a short message in the voice channel, over the music if something is playing
+func handleSay(s *discordgo.Session, i *discordgo.InteractionCreate, vi *audio.VoiceInstance) {
+	if speaker == nil {
+		errorResponse(s, i, tr(i, "tts.unavailable"))
+		return
+	}
+	if vi.IsRemote() {
+		errorResponse(s, i, tr(i, "tts.remote"))
+		return
+	}
+
+	text := strings.TrimSpace(i.ApplicationCommandData().Options[0].StringValue())
+	if len([]rune(text)) > tts.MaxLength {
+		errorResponse(s, i, tr(i, "tts.too_long", tts.MaxLength))
+		return
+	}
+
+	// Join the user's voice channel if we aren't connected yet
+	if !vi.Connected() && !joinUserChannel(s, i, vi) {
+		return
+	}
+
+	err := speak(vi, text)
+	switch {
+	case errors.Is(err, audio.ErrSpeaking):
+		errorResponse(s, i, tr(i, "tts.busy"))
+	case err != nil:
+		errorResponse(s, i, tr(i, "tts.failed", err))
+	default:
+		editResponse(s, i, tr(i, "tts.said"))
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"log"
+	"strings"
+
+	"discordbot/i18n"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// stagePermissions are the permissions the bot needs to play in a stage
+// channel. Request to Speak isn't enough: a request waits for a moderator,
+// so the bot makes itself a speaker, which takes Mute Members.
+var stagePermissions = append(append([]requiredPermission{}, voicePermissions...),
+	requiredPermission{"Mute Members", discordgo.PermissionVoiceMuteMembers, "diagnose.reason.stage_speaker"})
+
+// channelPermissions returns the permissions the bot needs in a voice or stage channel
+func channelPermissions(s *discordgo.Session, channelID string) []requiredPermission {
+	if channel, err := s.State.Channel(channelID); err == nil && channel.Type == discordgo.ChannelTypeGuildStageVoice {
+		return stagePermissions
+	}
+	return voicePermissions
+}
+
+// voicePreflight checks that the bot can join and be heard in a voice channel
+// before it tries, and responds with what is wrong if it can't
+func voicePreflight(s *discordgo.Session, i *discordgo.InteractionCreate, channelID string) bool {
+	if problem := voiceProblem(s, i.GuildID, channelID, interactionLanguage(i)); problem != "" {
+		errorResponse(s, i, problem)
+		return false
+	}
+	return true
+}
+
+// voiceProblem explains in lang why the bot can't play in a voice channel,
+// naming each missing permission, or returns "" if nothing is in the way
+func voiceProblem(s *discordgo.Session, guildID, channelID, lang string) string {
+	required := channelPermissions(s, channelID)
+	missing, err := missingPermissions(s, channelID, required)
+	if err != nil {
+		// Let the join itself report the problem
+		log.Printf("Failed to check permissions in voice channel %s: %v", channelID, err)
+		return ""
+	}
+	if len(missing) > 0 {
+		var msg strings.Builder
+		msg.WriteString(i18n.T(lang, "voice.missing_permissions", channelID))
+		for _, p := range required {
+			for _, name := range missing {
+				if name == p.Name {
+					msg.WriteString("\n• **" + p.Name + "** — " + i18n.T(lang, p.ReasonKey))
+				}
+			}
+		}
+		return msg.String()
+	}
+
+	if channelFull(s, guildID, channelID) {
+		return i18n.T(lang, "voice.channel_full", channelID)
+	}
+	return ""
+}
+
+// channelFull reports whether a voice channel's user limit keeps the bot out.
+// Members with Move Members can join full channels anyway.
+func channelFull(s *discordgo.Session, guildID, channelID string) bool {
+	channel, err := s.State.Channel(channelID)
+	if err != nil || channel.UserLimit == 0 {
+		return false
+	}
+	members, err := voiceChannelMembers(s, guildID, channelID)
+	if err != nil || members[s.State.User.ID] || len(members) < channel.UserLimit {
+		return false
+	}
+	perms, err := s.State.UserChannelPermissions(s.State.User.ID, channelID)
+	if err != nil {
+		return false
+	}
+	return perms&(discordgo.PermissionVoiceMoveMembers|discordgo.PermissionAdministrator) == 0
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"log"
+
+	"discordbot/audio"
+	"discordbot/sessions"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// onVoiceStateUpdate notices when the bot is disconnected from voice by someone
+// else, e.g. kicked by a moderator, and releases the guild's player
+func onVoiceStateUpdate(s *discordgo.Session, v *discordgo.VoiceStateUpdate) {
+	if s.State.User == nil || v.UserID != s.State.User.ID || v.ChannelID != "" {
+		return
+	}
+
+	// Our own Leave and channel switches also produce an empty channel update.
+	// Those either already cleared the connection or have since reconnected,
+	// which the state cache reflects by the time this handler runs.
+	if vs, err := s.State.VoiceState(v.GuildID, s.State.User.ID); err == nil && vs.ChannelID != "" {
+		return
+	}
+
+	vi := voiceManager.GetVoiceInstance(v.GuildID)
+	vi.Mu.Lock()
+	textChannelID := vi.TextChannelID
+	vi.Mu.Unlock()
+	if !vi.Connected() {
+		return
+	}
+
+	log.Printf("Disconnected from voice in guild %s by someone else", v.GuildID)
+	releaseGuild(s, vi)
+	if textChannelID != "" {
+		notifier.Send(textChannelID, trGuild(v.GuildID, "voice.released"))
+	}
+}
+
+// onGuildDelete releases the player of a guild the bot was kicked or banned from
+func onGuildDelete(s *discordgo.Session, g *discordgo.GuildDelete) {
+	// Unavailable guilds are an outage on Discord's side, not a removal
+	if g.Unavailable {
+		return
+	}
+
+	log.Printf("Removed from guild %s", g.ID)
+	releaseGuild(s, voiceManager.GetVoiceInstance(g.ID))
+	voiceManager.Remove(g.ID)
+}
+
+// releaseGuild saves a guild's session and stops its player right away
+func releaseGuild(s *discordgo.Session, vi *audio.VoiceInstance) {
+	session := sessions.Snapshot(vi)
+	if session.Current != nil || len(session.Queue) > 0 {
+		if err := sessionStore.Save(session); err != nil {
+			log.Printf("Failed to save session for guild %s: %v", vi.GuildID, err)
+		} else {
+			log.Printf("Saved session for guild %s", vi.GuildID)
+		}
+	}
+	vi.Release()
+	forgetBitrateWarning(vi.GuildID)
+
+	// Make the next join start from a fresh connection instead of the closed one
+	s.Lock()
+	delete(s.VoiceConnections, vi.GuildID)
+	s.Unlock()
+}
+
+// onDJMove follows the member whose track is playing into another voice
+// channel if the guild wants that and nobody else is left listening
+func onDJMove(s *discordgo.Session, v *discordgo.VoiceStateUpdate) {
+	if s.State.User == nil || v.UserID == s.State.User.ID || v.BeforeUpdate == nil {
+		return
+	}
+	if v.ChannelID == "" || v.ChannelID == v.BeforeUpdate.ChannelID {
+		return
+	}
+	if !settingsStore.Get(v.GuildID).FollowDJ {
+		return
+	}
+
+	vi := voiceManager.GetVoiceInstance(v.GuildID)
+	vi.Mu.Lock()
+	channelID, current, playing, textChannelID := vi.ChannelID, vi.Current, vi.IsPlaying, vi.TextChannelID
+	vi.Mu.Unlock()
+	if !playing || current == nil || current.RequesterID != v.UserID || v.BeforeUpdate.ChannelID != channelID {
+		return
+	}
+
+	// Stay for anyone still listening
+	count, err := listeners(s, v.GuildID, channelID)
+	if err != nil {
+		log.Printf("Failed to count listeners in voice channel %s of guild %s: %v", channelID, v.GuildID, err)
+		return
+	}
+	if count > 0 {
+		return
+	}
+
+	// Don't follow into a channel the bot can't speak in or that is full
+	if problem := voiceProblem(s, v.GuildID, v.ChannelID, guildLanguage(v.GuildID)); problem != "" {
+		if textChannelID != "" {
+			notifier.Send(textChannelID, problem)
+		}
+		return
+	}
+
+	if err := vi.Move(v.ChannelID); err != nil {
+		log.Printf("Failed to follow %s to voice channel %s in guild %s: %v", v.UserID, v.ChannelID, v.GuildID, err)
+		return
+	}
+	if textChannelID != "" {
+		notifier.Send(textChannelID, trGuild(v.GuildID, "voice.followed", v.ChannelID))
+	}
+}
+
+// listeners counts the members other than bots in a voice channel
+func listeners(s *discordgo.Session, guildID, channelID string) (int, error) {
+	members, err := voiceChannelMembers(s, guildID, channelID)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for userID := range members {
+		if member, err := s.State.Member(guildID, userID); err == nil && member.User != nil && member.User.Bot {
+			continue
+		}
+		if userID == s.State.User.ID {
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"discordbot/events"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// voiceStatusSettle lets bursts of player events settle before the channel status is changed
+	voiceStatusSettle = 2 * time.Second
+	// maxVoiceStatusLength is Discord's limit for voice channel statuses
+	maxVoiceStatusLength = 500
+)
+
+// voiceStatusState tracks the status the bot last set on a guild's voice channel
+type voiceStatusState struct {
+	channelID string
+	applied   string
+	pending   bool
+}
+
+var voiceStatuses = struct {
+	sync.Mutex
+	guilds map[string]*voiceStatusState
+}{guilds: make(map[string]*voiceStatusState)}
+
+// startVoiceChannelStatus keeps the status line of the bot's voice channel set
+// to the playing track, so members see it without opening the chat
+func startVoiceChannelStatus(s *discordgo.Session) {
+	eventBus.Subscribe(func(e events.Event) {
+		switch e.Type {
+		case events.TrackStart, events.TrackEnd:
+			scheduleVoiceStatus(s, e.GuildID)
+		}
+	})
+
+	// Leaving, being kicked and moving publish no player events
+	s.AddHandler(func(s *discordgo.Session, v *discordgo.VoiceStateUpdate) {
+		if s.State.User != nil && v.UserID == s.State.User.ID {
+			scheduleVoiceStatus(s, v.GuildID)
+		}
+	})
+}
+
+// scheduleVoiceStatus queues a channel status update for a guild, coalescing bursts
+func scheduleVoiceStatus(s *discordgo.Session, guildID string) {
+	voiceStatuses.Lock()
+	defer voiceStatuses.Unlock()
+
+	state, ok := voiceStatuses.guilds[guildID]
+	if !ok {
+		state = &voiceStatusState{}
+		voiceStatuses.guilds[guildID] = state
+	}
+	if state.pending {
+		return
+	}
+	state.pending = true
+	time.AfterFunc(voiceStatusSettle, func() { applyVoiceStatus(s, guildID) })
+}
+
+// applyVoiceStatus sets the status of the bot's voice channel to the playing
+// track and clears it on a channel the bot has stopped playing in
+func applyVoiceStatus(s *discordgo.Session, guildID string) {
+	voiceStatuses.Lock()
+	state := voiceStatuses.guilds[guildID]
+	state.pending = false
+	previousChannel, applied := state.channelID, state.applied
+	voiceStatuses.Unlock()
+
+	vi := voiceManager.GetVoiceInstance(guildID)
+	connected := vi.Connected()
+	vi.Mu.Lock()
+	channelID, status := vi.ChannelID, ""
+	if vi.IsPlaying && vi.Current != nil {
+		status = "🎵 " + truncateText(vi.Current.DisplayName(), maxVoiceStatusLength-2)
+	}
+	vi.Mu.Unlock()
+	if !connected {
+		channelID, status = "", ""
+	}
+
+	// Don't leave a stale status behind in the channel we moved out of
+	if previousChannel != "" && previousChannel != channelID && applied != "" {
+		if err := setVoiceStatus(s, previousChannel, ""); err != nil {
+			log.Printf("Error clearing voice channel status in guild %s: %v", guildID, err)
+		}
+		applied = ""
+	}
+
+	if channelID != "" && status != applied {
+		if err := setVoiceStatus(s, channelID, status); err != nil {
+			log.Printf("Error updating voice channel status in guild %s: %v", guildID, err)
+			status = applied
+		}
+	}
+
+	voiceStatuses.Lock()
+	state.channelID, state.applied = channelID, status
+	voiceStatuses.Unlock()
+}
+
+// setVoiceStatus sets the status line of a voice channel; empty clears it.
+// The bot needs the Set Voice Channel Status permission for this.
+func setVoiceStatus(s *discordgo.Session, channelID, status string) error {
+	endpoint := discordgo.EndpointChannel(channelID) + "/voice-status"
+	_, err := s.RequestWithBucketID("PUT", endpoint, struct {
+		Status string `json:"status"`
+	}{status}, endpoint)
+	return err
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"log"
+	"strings"
+
+	"discordbot/config"
+	"discordbot/events"
+	"discordbot/settings"
+	"discordbot/webhooks"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxGuildWebhooks caps how many webhooks a guild can set
+const maxGuildWebhooks = 5
+
+// globalWebhooks receive the track events of every guild, from WEBHOOK_URLS
+var globalWebhooks []string
+
+// startWebhooks posts track starts, ends and player errors to the webhooks
+// in WEBHOOK_URLS and those the guild set with /settings webhooks
+func startWebhooks() {
+	globalWebhooks = config.List("WEBHOOK_URLS")
+
+	sender := webhooks.NewSender()
+	eventBus.Subscribe(func(e events.Event) {
+		switch e.Type {
+		case events.TrackStart, events.TrackEnd, events.PlayerError:
+		default:
+			return
+		}
+
+		// Don't tell other services who asked for a track if they opted out with /privacy
+		anonymous := e.Track != nil && e.Track.RequesterID != "" && privacyStore.OptedOut(e.Track.RequesterID)
+		payload := webhooks.FromEvent(e, anonymous)
+		deliver := func(webhook string, trusted bool) {
+			if err := sender.Send(ctx, webhook, trusted, payload); err != nil {
+				log.Printf("Failed to deliver %s webhook for guild %s: %v", e.Type, e.GuildID, err)
+			}
+		}
+		for _, webhook := range globalWebhooks {
+			go deliver(webhook, true)
+		}
+		for _, webhook := range settingsStore.Get(e.GuildID).Webhooks {
+			go deliver(webhook, false)
+		}
+	})
+}
+
+// handleWebhookSettings adds, removes, clears or lists the guild's webhooks
+func handleWebhookSettings(s *discordgo.Session, i *discordgo.InteractionCreate, options []*discordgo.ApplicationCommandInteractionDataOption) {
+	for _, option := range options {
+		if option.Name == "add" {
+			if err := webhooks.ValidateURL(option.StringValue()); err != nil {
+				errorResponse(s, i, tr(i, "webhooks.invalid", err))
+				return
+			}
+			if current := settingsStore.Get(i.GuildID).Webhooks; len(current) >= maxGuildWebhooks && !contains(current, option.StringValue()) {
+				errorResponse(s, i, tr(i, "webhooks.too_many", maxGuildWebhooks))
+				return
+			}
+		}
+	}
+
+	guild, err := settingsStore.Update(i.GuildID, func(g *settings.Guild) {
+		for _, option := range options {
+			switch option.Name {
+			case "add":
+				if webhook := option.StringValue(); !contains(g.Webhooks, webhook) {
+					g.Webhooks = append(g.Webhooks, webhook)
+				}
+			case "remove":
+				// Build a new slice so readers of the previous settings are unaffected
+				var kept []string
+				for _, existing := range g.Webhooks {
+					if existing != option.StringValue() {
+						kept = append(kept, existing)
+					}
+				}
+				g.Webhooks = kept
+			case "clear":
+				if option.BoolValue() {
+					g.Webhooks = nil
+				}
+			}
+		}
+	})
+	if err != nil {
+		errorResponse(s, i, tr(i, "error", err))
+		return
+	}
+
+	if len(guild.Webhooks) == 0 {
+		editResponse(s, i, tr(i, "webhooks.none"))
+		return
+	}
+	editResponse(s, i, tr(i, "webhooks.list", "• <"+strings.Join(guild.Webhooks, ">\n• <")+">"))
+}
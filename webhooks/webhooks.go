@@ -0,0 +1,189 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"discordbot/events"
+)
+
+// retryDelay is how long a failed delivery waits before its single retry
+const retryDelay = 2 * time.Second
+
+// ErrPrivateAddress is returned for guild webhooks pointing into the bot's own network
+var ErrPrivateAddress = errors.New("webhook address is not public")
+
+// Payload is the JSON body posted to webhooks
+type Payload struct {
+	Event     events.Type `json:"event"`
+	GuildID   string      `json:"guild_id"`
+	ChannelID string      `json:"channel_id,omitempty"` // Voice channel the bot is playing in
+	Track     *Track      `json:"track,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	Time      time.Time   `json:"time"`
+}
+
+// Track is the metadata of the track an event is about
+type Track struct {
+	URL             string    `json:"url"`
+	Title           string    `json:"title,omitempty"`
+	DurationSeconds int       `json:"duration_seconds,omitempty"`
+	RequesterID     string    `json:"requester_id,omitempty"`
+	Requester       string    `json:"requester,omitempty"`
+	AddedAt         time.Time `json:"added_at"`
+	Live            bool      `json:"live,omitempty"`
+}
+
+// FromEvent builds the payload of a player event. If anonymous is set, the
+// track's requester is left out, for users who opted out of tracking.
+func FromEvent(e events.Event, anonymous bool) Payload {
+	payload := Payload{
+		Event:     e.Type,
+		GuildID:   e.GuildID,
+		ChannelID: e.ChannelID,
+		Error:     e.Error,
+		Time:      e.Time,
+	}
+	if t := e.Track; t != nil {
+		payload.Track = &Track{
+			URL:             t.URL,
+			Title:           t.Title,
+			DurationSeconds: int(t.Duration.Seconds()),
+			RequesterID:     t.RequesterID,
+			Requester:       t.Requester,
+			AddedAt:         t.AddedAt,
+			Live:            t.Live,
+		}
+		if anonymous {
+			payload.Track.RequesterID = ""
+			payload.Track.Requester = ""
+		}
+	}
+	return payload
+}
+
+// Sender posts payloads to webhooks. Webhooks the operator configured may
+// point anywhere; those set by guilds may only reach public addresses.
+type Sender struct {
+	trusted *http.Client
+	public  *http.Client
+}
+
+// NewSender creates a webhook sender
+func NewSender() *Sender {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+				return ErrPrivateAddress
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &Sender{
+		trusted: &http.Client{Timeout: 10 * time.Second},
+		public:  &http.Client{Timeout: 10 * time.Second, Transport: transport},
+	}
+}
+
+// Send posts a payload to a webhook, retrying once if the receiver is
+// unreachable or fails. trusted marks webhooks set by the operator.
+func (s *Sender) Send(ctx context.Context, webhook string, trusted bool, payload Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error encoding payload: %v", err)
+	}
+
+	client := s.public
+	if trusted {
+		client = s.trusted
+	}
+
+	err = post(ctx, client, webhook, body)
+	var temporary *statusError
+	if err == nil || errors.Is(err, ErrPrivateAddress) || (errors.As(err, &temporary) && temporary.code < 500) {
+		return err
+	}
+
+	select {
+	case <-time.After(retryDelay):
+	case <-ctx.Done():
+		return err
+	}
+	return post(ctx, client, webhook, body)
+}
+
+// statusError is a delivery the receiver answered with an error status
+type statusError struct {
+	code   int
+	status string
+}
+
+// Error describes the status
+func (e *statusError) Error() string {
+	return "unexpected status " + e.status
+}
+
+// post sends one delivery of body to a webhook
+func post(ctx context.Context, client *http.Client, webhook string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "discordbot-webhooks")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &statusError{code: resp.StatusCode, status: resp.Status}
+	}
+	return nil
+}
+
+// ValidateURL checks that a webhook set by a guild is an HTTPS URL that
+// doesn't name a private address. Host names are checked again on connect.
+func ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("%q is not a URL", raw)
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("webhooks must use https")
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrPrivateAddress
+	}
+	if ip := net.ParseIP(host); ip != nil && !publicIP(ip) {
+		return ErrPrivateAddress
+	}
+	return nil
+}
+
+// publicIP reports whether ip is reachable on the public internet
+func publicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified() || ip.IsMulticast() || ip.IsInterfaceLocalMulticast())
+}
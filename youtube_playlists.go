@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"discordbot/audio"
+	"discordbot/audio/youtube"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxPlaylistTracks caps how many entries of a YouTube playlist one request queues
+const maxPlaylistTracks = 100
+
+// playlistMinPosition is the lowest value of the from and to options
+var playlistMinPosition = 1.0
+
+// playlistRange selects entries of a playlist by position, counting from 1
+// and including both ends. Zero leaves that end open.
+type playlistRange struct {
+	start, end int
+}
+
+// trailingRange matches a range like "5-20" or "5-" after a link
+var trailingRange = regexp.MustCompile(`^(.*\S)\s+(\d+)-(\d*)$`)
+
+// splitPlaylistRange separates a trailing entry range such as "5-20" from a request
+func splitPlaylistRange(query string) (string, playlistRange) {
+	matches := trailingRange.FindStringSubmatch(strings.TrimSpace(query))
+	if matches == nil || !isLink(matches[1]) {
+		return query, playlistRange{}
+	}
+	start, _ := strconv.Atoi(matches[2])
+	end, _ := strconv.Atoi(matches[3])
+	return matches[1], playlistRange{start: start, end: end}
+}
+
+// resolvePlaylist expands a YouTube playlist link into the entries selected by
+// span, starting at the link's &index= if span doesn't say otherwise. A watch
+// link that merely came from a playlist stays a single video unless it has an
+// index or a range was asked for. ok is false for links without a playlist.
+func resolvePlaylist(i *discordgo.InteractionCreate, link string, span playlistRange) (tracks []*audio.Track, ok bool, err error) {
+	if !isYouTubeURL(link) || !youtube.IsPlaylistURL(link) {
+		return nil, false, nil
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return nil, false, nil
+	}
+	query := parsed.Query()
+
+	if span.start == 0 {
+		if index, err := strconv.Atoi(query.Get("index")); err == nil && index > 0 {
+			span.start = index
+		}
+	}
+	isVideo := query.Get("v") != "" || parsed.Host == "youtu.be"
+	if isVideo && span == (playlistRange{}) {
+		return nil, false, nil
+	}
+
+	if span.start == 0 {
+		span.start = 1
+	}
+	if span.end != 0 && span.end < span.start {
+		return nil, true, fmt.Errorf("the playlist range %d-%d ends before it starts", span.start, span.end)
+	}
+	if span.end == 0 || span.end-span.start >= maxPlaylistTracks {
+		span.end = span.start + maxPlaylistTracks - 1
+	}
+
+	entries, err := videos.PlaylistRange(link, span.start, span.end)
+	if err != nil {
+		return nil, true, fmt.Errorf("error loading the playlist: %v", err)
+	}
+	for _, entry := range entries {
+		track := newTrack(i, entry.URL)
+		track.Title = entry.Title
+		track.Duration = entry.Duration
+		tracks = append(tracks, track)
+	}
+	if len(tracks) == 0 {
+		return nil, true, fmt.Errorf("the playlist has no entries from position %d", span.start)
+	}
+	return tracks, true, nil
+}